@@ -1,23 +1,192 @@
 package cmd
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"log"
 	"net/url"
+	"os"
 	"strings"
 
 	"github.com/spf13/cobra"
 
 	apperrors "github.com/grigri/pv/internal/errors"
 	"github.com/grigri/pv/internal/model"
+	"github.com/grigri/pv/internal/scaffold"
 	"github.com/grigri/pv/internal/service"
+	"github.com/grigri/pv/internal/utils"
 )
 
 type AddCmd *cobra.Command
 
 type add struct {
 	promptService service.PromptService
+	recursive     bool
+	dryRun        bool
+	json          bool
+
+	// 从标准输入读取正文时使用的元数据
+	metadata model.Prompt
+}
+
+// addedPrompt is a prompt printed by pv add --json
+type addedPrompt struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Author      string   `json:"author"`
+	GistURL     string   `json:"gist_url"`
+	Description string   `json:"description,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	Version     string   `json:"version,omitempty"`
+	// Action is create or update for the prompts of a bulk add
+	Action service.AddAction `json:"action,omitempty"`
+}
+
+// newAddedPrompt returns the --json output of a prompt
+func newAddedPrompt(prompt model.Prompt, action service.AddAction) addedPrompt {
+	return addedPrompt{
+		ID:          prompt.ID,
+		Name:        prompt.Name,
+		Author:      prompt.Author,
+		GistURL:     prompt.GistURL,
+		Description: prompt.Description,
+		Tags:        prompt.Tags,
+		Version:     prompt.Version,
+		Action:      action,
+	}
+}
+
+// writeJSON 以缩进的 JSON 格式输出 value
+func writeJSON(out io.Writer, value interface{}) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
+
+// run 根据参数选择单个添加或批量添加
+func (ac *add) run(cmd *cobra.Command, args []string) error {
+	if ac.hasMetadata() {
+		// 给出元数据时正文来自标准输入
+		if len(args) > 1 || (len(args) == 1 && args[0] != "-") {
+			return fmt.Errorf("--name、--author 等参数只能与标准输入一起使用，例如 pv add - --name X --author Y")
+		}
+		args = []string{"-"}
+	}
+
+	if len(args) == 1 && !ac.recursive && !ac.dryRun && !ac.isBulkPath(args[0]) {
+		if ac.json {
+			return ac.executeJSON(cmd, args[0])
+		}
+		ac.execute(cmd, args)
+		return nil
+	}
+	if len(args) == 0 {
+		ac.execute(cmd, args)
+		return nil
+	}
+	return ac.executeBulk(cmd, args)
+}
+
+// hasMetadata 判断是否通过参数给出了提示词的元数据
+func (ac *add) hasMetadata() bool {
+	m := ac.metadata
+	return m.Name != "" || m.Author != "" || m.Description != "" || len(m.Tags) > 0 || m.Version != ""
+}
+
+// executeJSON 添加单个提示词并以 JSON 格式输出，便于脚本使用
+func (ac *add) executeJSON(cmd *cobra.Command, argument string) error {
+	ctx, cancel := commandContext(cmd)
+	defer cancel()
+
+	prompt, err := ac.addPrompt(ctx, cmd.InOrStdin(), argument)
+	if err != nil {
+		return err
+	}
+	return writeJSON(cmd.OutOrStdout(), newAddedPrompt(*prompt, ""))
+}
+
+// isBulkPath 判断参数是否为目录或 glob 模式，需要批量添加
+func (ac *add) isBulkPath(arg string) bool {
+	if ac.isGistURL(arg) {
+		return false
+	}
+	if strings.ContainsAny(arg, "*?[") {
+		return true
+	}
+	info, err := os.Stat(arg)
+	return err == nil && info.IsDir()
+}
+
+// executeBulk 先验证所有文件并显示添加计划，全部通过后一次性添加
+func (ac *add) executeBulk(cmd *cobra.Command, args []string) error {
+	ctx, cancel := commandContext(cmd)
+	defer cancel()
+	out := cmd.OutOrStdout()
+	if ac.json {
+		// 标准输出只留给 JSON
+		out = cmd.ErrOrStderr()
+	}
+
+	plan, err := ac.promptService.PlanAddFromFiles(ctx, args, ac.recursive)
+	if err != nil {
+		return err
+	}
+
+	if len(plan.Failures) > 0 {
+		fmt.Fprintf(out, "❌ %d 个文件未通过验证:\n", len(plan.Failures))
+		for _, failure := range plan.Failures {
+			fmt.Fprintf(out, "  %s: %v\n", failure.Path, failure.Err)
+		}
+		fmt.Fprintln(out)
+	}
+
+	if len(plan.Adds) > 0 {
+		fmt.Fprintf(out, "将新建 %d 个、更新 %d 个提示词:\n", plan.Count(service.AddCreate), plan.Count(service.AddUpdate))
+		for _, add := range plan.Adds {
+			marker := "+"
+			if add.Action == service.AddUpdate {
+				marker = "~"
+			}
+			fmt.Fprintf(out, "  %s %s (%s)  %s\n", marker, add.Prompt.Name, add.Prompt.Author, add.Path)
+		}
+		fmt.Fprintln(out)
+	}
+
+	if len(plan.Failures) > 0 {
+		return apperrors.ErrAddPlanHasFailures
+	}
+	if ac.dryRun {
+		fmt.Fprintln(out, "dry run：没有做任何修改")
+		if ac.json {
+			planned := make([]addedPrompt, 0, len(plan.Adds))
+			for _, add := range plan.Adds {
+				prompt := *add.Prompt
+				if add.Existing != nil {
+					prompt.ID, prompt.GistURL = add.Existing.ID, add.Existing.GistURL
+				}
+				planned = append(planned, newAddedPrompt(prompt, add.Action))
+			}
+			return writeJSON(cmd.OutOrStdout(), planned)
+		}
+		return nil
+	}
+
+	stored, err := ac.promptService.ApplyAddPlan(ctx, plan)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "✅ 已添加 %d 个提示词\n", len(stored))
+	if ac.json {
+		added := make([]addedPrompt, 0, len(stored))
+		for i, prompt := range stored {
+			added = append(added, newAddedPrompt(prompt, plan.Adds[i].Action))
+		}
+		return writeJSON(cmd.OutOrStdout(), added)
+	}
+	return nil
 }
 
 func (ac *add) execute(cmd *cobra.Command, args []string) {
@@ -28,6 +197,7 @@ func (ac *add) execute(cmd *cobra.Command, args []string) {
 		fmt.Println("Usage:")
 		fmt.Println("  pv add <file-path>")
 		fmt.Println("  pv add <gist-url>")
+		fmt.Println("  pv add -")
 		fmt.Println()
 		fmt.Println("Examples:")
 		fmt.Println("  pv add my-prompt.yaml")
@@ -35,21 +205,24 @@ func (ac *add) execute(cmd *cobra.Command, args []string) {
 		return
 	}
 
+	ctx, cancel := commandContext(cmd)
+	defer cancel()
+
 	argument := args[0]
-	var prompt *model.Prompt
-	var err error
 
-	// Determine if the argument is a URL or file path
-	if ac.isGistURL(argument) {
+	// Determine if the argument is stdin, a URL or a file path
+	switch {
+	case argument == "-":
+		fmt.Println("正在从标准输入添加提示词")
+	case ac.isGistURL(argument):
 		fmt.Printf("正在从 URL 导入提示词: %s\n", argument)
-		prompt, err = ac.handleURLMode(argument)
-	} else {
+	default:
 		fmt.Printf("正在从文件添加提示词: %s\n", argument)
-		prompt, err = ac.handleFileMode(argument)
 	}
+	prompt, err := ac.addPrompt(ctx, cmd.InOrStdin(), argument)
 	if err != nil {
 		// Handle different types of errors with user-friendly messages
-		var appErr *apperrors.AppError
+		var appErr apperrors.AppError
 		if errors.As(err, &appErr) {
 			switch appErr.Type {
 			case apperrors.ErrValidation:
@@ -93,14 +266,43 @@ func (ac *add) execute(cmd *cobra.Command, args []string) {
 	fmt.Println("Run 'pv list' to see all your prompts.")
 }
 
+// addPrompt 根据参数从标准输入、gist URL 或文件添加单个提示词
+func (ac *add) addPrompt(ctx context.Context, stdin io.Reader, argument string) (*model.Prompt, error) {
+	switch {
+	case argument == "-":
+		return ac.handleStdinMode(ctx, stdin)
+	case ac.isGistURL(argument):
+		return ac.handleURLMode(ctx, argument)
+	default:
+		return ac.handleFileMode(ctx, argument)
+	}
+}
+
+// handleStdinMode 处理标准输入模式
+// 给出 --name 等元数据时标准输入只是正文，否则是完整的提示词文件
+func (ac *add) handleStdinMode(ctx context.Context, stdin io.Reader) (*model.Prompt, error) {
+	data, err := io.ReadAll(stdin)
+	if err != nil {
+		return nil, apperrors.NewAppError(apperrors.ErrValidation, "failed to read standard input", err)
+	}
+
+	content := string(data)
+	if ac.hasMetadata() {
+		if content, err = scaffold.Compose(ac.metadata, content); err != nil {
+			return nil, apperrors.NewAppError(apperrors.ErrValidation, "failed to build prompt file", err)
+		}
+	}
+	return ac.promptService.AddFromContent(ctx, content)
+}
+
 // handleFileMode 处理文件路径模式
-func (ac *add) handleFileMode(filePath string) (*model.Prompt, error) {
-	return ac.promptService.AddFromFile(filePath)
+func (ac *add) handleFileMode(ctx context.Context, filePath string) (*model.Prompt, error) {
+	return ac.promptService.AddFromFile(ctx, filePath)
 }
 
 // handleURLMode 处理 gist URL 模式
-func (ac *add) handleURLMode(gistURL string) (*model.Prompt, error) {
-	return ac.promptService.AddFromURL(gistURL)
+func (ac *add) handleURLMode(ctx context.Context, gistURL string) (*model.Prompt, error) {
+	return ac.promptService.AddFromURL(ctx, gistURL)
 }
 
 // isGistURL 判断字符串是否为 gist URL
@@ -114,8 +316,8 @@ func (ac *add) isGistURL(str string) bool {
 		return false
 	}
 	
-	// 检查是否为 GitHub Gist URL
-	if parsedURL.Host != "gist.github.com" {
+	// 检查是否为配置的 Gist 主机（默认 gist.github.com）
+	if !utils.IsGistHost(parsedURL.Host) {
 		return false
 	}
 	
@@ -142,8 +344,8 @@ func (ac *add) isGistURL(str string) bool {
 
 func NewAddCommand(promptService service.PromptService) AddCmd {
 	ac := &add{promptService: promptService}
-	return &cobra.Command{
-		Use:   "add <file-path|gist-url>",
+	cmd := &cobra.Command{
+		Use:   "add <file-path|dir|glob|gist-url|->...",
 		Short: "Add a prompt from a YAML file or public gist URL to your vault",
 		Long: `Add a prompt from a YAML file or public GitHub Gist URL to your vault.
 
@@ -158,10 +360,41 @@ For YAML files, the file should contain prompt metadata and content in the follo
   content: |
     Your prompt content here...
 
+Markdown files (.md) put the same metadata between --- lines at the top, Hugo/Jekyll style,
+followed by the Markdown prompt body. They are stored as .md in the gist so GitHub renders them.
+
 For gist URLs, the gist must be public and contain a valid prompt in YAML format.
 
-The prompt will be uploaded to GitHub Gists (if from file) or imported to your local index (if from URL).`,
-		Args: cobra.ExactArgs(1),
-		Run:  ac.execute,
+The prompt will be uploaded to GitHub Gists (if from file) or imported to your local index (if from URL).
+
+Several files, directories and glob patterns add many prompts at once. Directories contribute
+their .yaml and .yml files and the .md files that start with front matter (use --recursive to
+include subdirectories). Every file is validated
+first and all failures are reported together; nothing is added unless all files are valid.
+A prompt with the name and author of an existing prompt updates it. Use --dry-run to only
+show which prompts would be created and updated.
+
+Use - to read a prompt file from standard input. With --name, --author and the other metadata
+flags, standard input holds only the prompt body. --json prints the added prompts, including
+their gist URLs, as JSON for scripts.`,
+		Example: `  pv add my-prompt.yaml
+  pv add notes.md
+  pv add https://gist.github.com/user/abc123
+  pv add prompts/ --recursive --dry-run
+  pv add "prompts/*.yaml" extra.yaml
+  generate-prompt | pv add - --json
+  echo "Review {code}" | pv add --name Review --author alice --tag code`,
+		Args: cobra.ArbitraryArgs,
+		RunE: ac.run,
 	}
+
+	cmd.Flags().BoolVarP(&ac.recursive, "recursive", "r", false, "Include prompt files in subdirectories")
+	cmd.Flags().BoolVar(&ac.dryRun, "dry-run", false, "Validate the files and show the plan without adding anything")
+	cmd.Flags().BoolVar(&ac.json, "json", false, "Print the added prompts as JSON")
+	cmd.Flags().StringVar(&ac.metadata.Name, "name", "", "Prompt name, with the body read from standard input")
+	cmd.Flags().StringVar(&ac.metadata.Author, "author", "", "Prompt author, with the body read from standard input")
+	cmd.Flags().StringVar(&ac.metadata.Description, "description", "", "Prompt description, with the body read from standard input")
+	cmd.Flags().StringSliceVar(&ac.metadata.Tags, "tag", nil, "Prompt tag, repeatable, with the body read from standard input")
+	cmd.Flags().StringVar(&ac.metadata.Version, "version", "", "Prompt version, with the body read from standard input")
+	return cmd
 }
@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/grigri/pv/internal/errors"
+	"github.com/grigri/pv/internal/model"
+	"github.com/grigri/pv/internal/service"
+	"github.com/spf13/cobra"
+)
+
+// bulkAddPromptService serves a fixed add plan and records the plans applied
+type bulkAddPromptService struct {
+	*MockPromptService
+	plan    *service.AddPlan
+	planned [][]string
+	applied []*service.AddPlan
+}
+
+func (m *bulkAddPromptService) PlanAddFromFiles(ctx context.Context, paths []string, recursive bool) (*service.AddPlan, error) {
+	m.planned = append(m.planned, paths)
+	return m.plan, nil
+}
+
+func (m *bulkAddPromptService) ApplyAddPlan(ctx context.Context, plan *service.AddPlan) ([]model.Prompt, error) {
+	m.applied = append(m.applied, plan)
+	var stored []model.Prompt
+	for _, add := range plan.Adds {
+		stored = append(stored, *add.Prompt)
+	}
+	return stored, nil
+}
+
+func TestAddCommand_Bulk(t *testing.T) {
+	plan := &service.AddPlan{Adds: []service.PlannedAdd{
+		{Path: "prompts/review.yaml", Prompt: &model.Prompt{Name: "Review", Author: "alice"}, Action: service.AddUpdate},
+		{Path: "prompts/summary.yaml", Prompt: &model.Prompt{Name: "Summary", Author: "alice"}, Action: service.AddCreate},
+	}}
+
+	run := func(t *testing.T, plan *service.AddPlan, args ...string) (*bulkAddPromptService, string, error) {
+		t.Helper()
+		promptService := &bulkAddPromptService{MockPromptService: NewMockPromptService(), plan: plan}
+		cmd := (*cobra.Command)(NewAddCommand(promptService))
+
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetErr(&out)
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+		return promptService, out.String(), err
+	}
+
+	t.Run("directory", func(t *testing.T) {
+		promptService, out, err := run(t, plan, t.TempDir())
+		if err != nil {
+			t.Fatalf("add failed: %v", err)
+		}
+		if !strings.Contains(out, "将新建 1 个、更新 1 个提示词") || !strings.Contains(out, "~ Review (alice)  prompts/review.yaml") || !strings.Contains(out, "+ Summary (alice)  prompts/summary.yaml") {
+			t.Errorf("Expected the plan to be shown, got %q", out)
+		}
+		if len(promptService.applied) != 1 || !strings.Contains(out, "已添加 2 个提示词") {
+			t.Errorf("Expected the plan to be applied, got %d applies, %q", len(promptService.applied), out)
+		}
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		promptService, out, err := run(t, plan, "prompts/*.yaml", "--dry-run")
+		if err != nil {
+			t.Fatalf("add failed: %v", err)
+		}
+		if len(promptService.planned) != 1 || promptService.planned[0][0] != "prompts/*.yaml" {
+			t.Errorf("Expected the glob to be planned, got %v", promptService.planned)
+		}
+		if len(promptService.applied) != 0 || !strings.Contains(out, "dry run") {
+			t.Errorf("Expected nothing to be applied, got %q", out)
+		}
+	})
+
+	t.Run("failures", func(t *testing.T) {
+		failing := &service.AddPlan{
+			Adds: plan.Adds,
+			Failures: []service.AddFailure{
+				{Path: "prompts/broken.yaml", Err: errors.NewAppError(errors.ErrValidation, "invalid prompt metadata", nil)},
+				{Path: "missing.yaml", Err: errors.ErrFileNotFound},
+			},
+		}
+		promptService, out, err := run(t, failing, "prompts/broken.yaml", "missing.yaml")
+		if err == nil {
+			t.Fatal("Expected the add to fail")
+		}
+		if !strings.Contains(out, "2 个文件未通过验证") || !strings.Contains(out, "prompts/broken.yaml: invalid prompt metadata") || !strings.Contains(out, "missing.yaml: Prompt file not found") {
+			t.Errorf("Expected all failures to be reported, got %q", out)
+		}
+		if len(promptService.applied) != 0 {
+			t.Errorf("Expected nothing to be applied, got %d applies", len(promptService.applied))
+		}
+	})
+}
+
+// stdinAddPromptService records the content added to it
+type stdinAddPromptService struct {
+	*MockPromptService
+	added []string
+}
+
+func (m *stdinAddPromptService) AddFromContent(ctx context.Context, content string) (*model.Prompt, error) {
+	m.added = append(m.added, content)
+	return &model.Prompt{
+		ID:      "0123456789abcdef0123456789abcdef",
+		Name:    "Review",
+		Author:  "alice",
+		GistURL: "https://gist.github.com/alice/0123456789abcdef0123456789abcdef",
+	}, nil
+}
+
+func TestAddCommand_Stdin(t *testing.T) {
+	run := func(t *testing.T, stdin string, args ...string) (*stdinAddPromptService, string, error) {
+		t.Helper()
+		promptService := &stdinAddPromptService{MockPromptService: NewMockPromptService()}
+		cmd := (*cobra.Command)(NewAddCommand(promptService))
+
+		var out bytes.Buffer
+		cmd.SetIn(strings.NewReader(stdin))
+		cmd.SetOut(&out)
+		cmd.SetErr(&out)
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+		return promptService, out.String(), err
+	}
+
+	t.Run("prompt file", func(t *testing.T) {
+		content := "name: Review\nauthor: alice\n---\nReview {code}\n"
+		promptService, out, err := run(t, content, "-", "--json")
+		if err != nil {
+			t.Fatalf("add failed: %v", err)
+		}
+		if len(promptService.added) != 1 || promptService.added[0] != content {
+			t.Errorf("Expected stdin to be added as is, got %q", promptService.added)
+		}
+		if !strings.Contains(out, `"gist_url": "https://gist.github.com/alice/0123456789abcdef0123456789abcdef"`) || strings.Contains(out, "action") {
+			t.Errorf("Expected the added prompt as JSON, got %q", out)
+		}
+	})
+
+	t.Run("body with metadata flags", func(t *testing.T) {
+		promptService, _, err := run(t, "Review {code}\n", "--name", "Review", "--author", "alice", "--tag", "code", "--tag", "go", "--json")
+		if err != nil {
+			t.Fatalf("add failed: %v", err)
+		}
+		want := "name: \"Review\"\nauthor: \"alice\"\ntags:\n  - \"code\"\n  - \"go\"\n---\nReview {code}\n"
+		if len(promptService.added) != 1 || promptService.added[0] != want {
+			t.Errorf("Expected %q to be added, got %q", want, promptService.added)
+		}
+	})
+
+	t.Run("metadata flags with a file", func(t *testing.T) {
+		promptService, _, err := run(t, "", "review.yaml", "--name", "Review")
+		if err == nil || len(promptService.added) != 0 {
+			t.Errorf("Expected metadata flags to require stdin, got %v", err)
+		}
+	})
+}
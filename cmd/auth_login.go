@@ -12,6 +12,9 @@ import (
 	"github.com/grigri/pv/internal/service"
 )
 
+// gitLabTokenPrefix is the prefix of GitLab personal access tokens
+const gitLabTokenPrefix = "glpat-"
+
 // AuthLoginCmd is the auth login command type
 type AuthLoginCmd = *cobra.Command
 
@@ -48,15 +51,19 @@ func (al *authLogin) execute(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to read token: %w", err)
 	}
 
+	// 读取 token 之后才开始计时，--timeout 不包含用户输入的时间
+	ctx, cancel := commandContext(cmd)
+	defer cancel()
+
 	// Validate and save token
 	fmt.Println("\nValidating token...")
-	if err := al.service.Login(token); err != nil {
+	if err := al.service.Login(ctx, token); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		return err
 	}
 
 	// Get user info to display success message
-	status, err := al.service.GetStatus()
+	status, err := al.service.GetStatus(ctx)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Warning: Login succeeded but failed to get user info: %v\n", err)
 		fmt.Println("✓ Token validated successfully")
@@ -96,8 +103,13 @@ func validateToken(token string) error {
 		return fmt.Errorf("token cannot be empty")
 	}
 
-	// Check token length (GitHub PATs are typically 40+ characters)
-	if len(token) < 40 {
+	// Check token length (GitHub PATs are typically 40+ characters,
+	// GitLab PATs are "glpat-" followed by 20 characters)
+	if strings.HasPrefix(token, gitLabTokenPrefix) {
+		if len(token) < len(gitLabTokenPrefix)+20 {
+			return fmt.Errorf("token appears too short (%d characters). Please ensure you copied the entire token", len(token))
+		}
+	} else if len(token) < 40 {
 		return fmt.Errorf("token appears too short (%d characters). GitHub Personal Access Tokens are typically 40+ characters. Please ensure you copied the entire token", len(token))
 	}
 
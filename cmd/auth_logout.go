@@ -31,7 +31,10 @@ func NewAuthLogoutCommand(authService service.AuthService) AuthLogoutCmd {
 
 // execute runs the logout command
 func (al *authLogout) execute(cmd *cobra.Command, args []string) error {
-	if err := al.service.Logout(); err != nil {
+	ctx, cancel := commandContext(cmd)
+	defer cancel()
+
+	if err := al.service.Logout(ctx); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: Failed to logout: %v\n", err)
 		return err
 	}
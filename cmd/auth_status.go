@@ -31,7 +31,10 @@ func NewAuthStatusCommand(authService service.AuthService) AuthStatusCmd {
 
 // execute runs the status command
 func (as *authStatus) execute(cmd *cobra.Command, args []string) error {
-	status, err := as.service.GetStatus()
+	ctx, cancel := commandContext(cmd)
+	defer cancel()
+
+	status, err := as.service.GetStatus(ctx)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: Failed to check authentication status: %v\n", err)
 		return err
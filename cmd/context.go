@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"context"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// timeoutFlag 是限制单条命令远程调用总时长的全局参数名
+const timeoutFlag = "timeout"
+
+// commandContext 返回命令使用的 context
+// 它继承 cobra 的命令 context（main 中已接入 Ctrl-C 信号），并在设置了 --timeout 时加上超时。
+// 直接调用 Run 的测试没有命令 context，此时使用 context.Background()。
+func commandContext(cmd *cobra.Command) (context.Context, context.CancelFunc) {
+	ctx := context.Background()
+	var timeout time.Duration
+	if cmd != nil {
+		if cmdCtx := cmd.Context(); cmdCtx != nil {
+			ctx = cmdCtx
+		}
+		timeout, _ = cmd.Flags().GetDuration(timeoutFlag)
+	}
+
+	if timeout > 0 {
+		return context.WithTimeout(ctx, timeout)
+	}
+	return context.WithCancel(ctx)
+}
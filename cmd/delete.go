@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/spf13/cobra"
@@ -8,6 +9,7 @@ import (
 	"github.com/grigri/pv/internal/infra"
 	"github.com/grigri/pv/internal/service"
 	"github.com/grigri/pv/internal/tui"
+	"github.com/grigri/pv/internal/utils"
 )
 
 type DeleteCmd = *cobra.Command
@@ -30,15 +32,18 @@ func (dc *delete) execute(cmd *cobra.Command, args []string) {
 		fmt.Println("Examples:")
 		fmt.Println("  pv delete                    # 显示所有提示供选择")
 		fmt.Println("  pv delete golang             # 筛选包含 'golang' 的提示")
-		fmt.Println("  pv delete https://gist.github.com/user/abc123")
+		fmt.Printf("  pv delete %s/user/abc123\n", utils.CurrentGitHubEndpoints().GistBaseURL())
 		return
 	}
 
+	ctx, cancel := commandContext(cmd)
+	defer cancel()
+
 	// Route to appropriate mode based on arguments
 	switch len(args) {
 	case 0:
 		// 交互式模式 - 显示所有提示供用户选择
-		dc.handleInteractiveMode()
+		dc.handleInteractiveMode(ctx)
 	case 1:
 		arg := args[0]
 		// Check if it looks like a URL first
@@ -46,14 +51,14 @@ func (dc *delete) execute(cmd *cobra.Command, args []string) {
 			// It's a URL-like string, check if it's a valid Gist URL
 			if dc.isGistURL(arg) {
 				// Valid Gist URL - direct deletion mode
-				dc.handleDirectMode(arg)
+				dc.handleDirectMode(ctx, arg)
 			} else {
 				// Invalid Gist URL - show error
 				dc.handleInvalidURL(arg)
 			}
 		} else {
 			// Not URL-like - keyword filtering mode
-			dc.handleFilterMode(arg)
+			dc.handleFilterMode(ctx, arg)
 		}
 	}
 }
@@ -65,8 +70,8 @@ func (dc *delete) isGistURL(input string) bool {
 		return false
 	}
 	
-	// Check if it contains gist.github.com
-	if !contains(input, "gist.github.com") {
+	// Check if it points at the configured gist host (gist.github.com by default)
+	if !utils.IsGistURLHost(input) {
 		return false
 	}
 	
@@ -160,11 +165,11 @@ func (dc *delete) extractGistID(url string) string {
 }
 
 // handleInteractiveMode handles the interactive deletion mode (no arguments)
-func (dc *delete) handleInteractiveMode() {
+func (dc *delete) handleInteractiveMode(ctx context.Context) {
 	fmt.Println("🔄 Interactive mode - loading all prompts...")
 	
-	// Step 1: Call promptService.ListPrompts() to get all prompts
-	prompts, err := dc.promptService.ListPrompts()
+	// Step 1: Call promptService.ListPrompts(ctx) to get all prompts
+	prompts, err := dc.promptService.ListPrompts(ctx)
 	if err != nil {
 		fmt.Printf("❌ Error loading prompts: %v\n", err)
 		return
@@ -227,7 +232,7 @@ func (dc *delete) handleInteractiveMode() {
 	fmt.Printf("🗑️  Deleting prompt '%s'...\n", selectedPrompt.Name)
 	
 	// Use the Gist URL to identify the prompt for deletion
-	err = dc.promptService.DeleteByURL(selectedPrompt.GistURL)
+	err = dc.promptService.DeleteByURL(ctx, selectedPrompt.GistURL)
 	if err != nil {
 		fmt.Printf("❌ Failed to delete prompt: %v\n", err)
 		fmt.Println()
@@ -249,11 +254,11 @@ func (dc *delete) handleInteractiveMode() {
 }
 
 // handleFilterMode handles the keyword filtering deletion mode
-func (dc *delete) handleFilterMode(keyword string) {
+func (dc *delete) handleFilterMode(ctx context.Context, keyword string) {
 	fmt.Printf("🔄 Filter mode - searching for prompts matching '%s'...\n", keyword)
 	
-	// Step 1: Call promptService.FilterPrompts(keyword) to filter prompts
-	filteredPrompts, err := dc.promptService.FilterPrompts(keyword)
+	// Step 1: Call promptService.FilterPrompts(ctx, keyword) to filter prompts
+	filteredPrompts, err := dc.promptService.FilterPrompts(ctx, keyword)
 	if err != nil {
 		fmt.Printf("❌ Error filtering prompts: %v\n", err)
 		fmt.Println()
@@ -341,7 +346,7 @@ func (dc *delete) handleFilterMode(keyword string) {
 	fmt.Printf("🗑️  Deleting prompt '%s' (matched by keyword '%s')...\n", selectedPrompt.Name, keyword)
 	
 	// Use the Gist URL to identify the prompt for deletion
-	err = dc.promptService.DeleteByURL(selectedPrompt.GistURL)
+	err = dc.promptService.DeleteByURL(ctx, selectedPrompt.GistURL)
 	if err != nil {
 		fmt.Printf("❌ Failed to delete prompt: %v\n", err)
 		fmt.Println()
@@ -366,19 +371,20 @@ func (dc *delete) handleFilterMode(keyword string) {
 }
 
 // handleDirectMode handles the direct URL deletion mode
-func (dc *delete) handleDirectMode(gistURL string) {
+func (dc *delete) handleDirectMode(ctx context.Context, gistURL string) {
 	fmt.Printf("🔄 Direct mode - processing URL: %s\n", gistURL)
 	
 	// Step 1: Validate and parse the GitHub Gist URL
 	if !dc.isGistURL(gistURL) {
+		gistBase := utils.CurrentGitHubEndpoints().GistBaseURL()
 		fmt.Println("❌ Error: Invalid GitHub Gist URL format")
 		fmt.Println()
 		fmt.Println("Valid URL formats:")
-		fmt.Println("  https://gist.github.com/username/gist-id")
-		fmt.Println("  https://gist.github.com/gist-id")
+		fmt.Printf("  %s/username/gist-id\n", gistBase)
+		fmt.Printf("  %s/gist-id\n", gistBase)
 		fmt.Println()
 		fmt.Println("Example:")
-		fmt.Println("  pv delete https://gist.github.com/user/1234567890abcdef1234567890abcdef")
+		fmt.Printf("  pv delete %s/user/1234567890abcdef1234567890abcdef\n", gistBase)
 		return
 	}
 	
@@ -390,17 +396,17 @@ func (dc *delete) handleDirectMode(gistURL string) {
 		fmt.Println("Please ensure the URL contains a valid Gist ID (20 or 32 character hex string)")
 		fmt.Println()
 		fmt.Println("Example:")
-		fmt.Println("  https://gist.github.com/user/1234567890abcdef1234567890abcdef")
+		fmt.Printf("  %s/user/1234567890abcdef1234567890abcdef\n", utils.CurrentGitHubEndpoints().GistBaseURL())
 		return
 	}
 	
 	fmt.Printf("🔍 Searching for prompt with Gist ID: %s\n", gistID)
 	fmt.Println()
 	
-	// Step 2: Call promptService.DeleteByURL(gistURL) to find the prompt
+	// Step 2: Call promptService.DeleteByURL(ctx, gistURL) to find the prompt
 	// First, we need to find the prompt to show details before deletion
 	// We'll use the store to find the prompt by Gist ID
-	prompts, err := dc.store.Get(gistID)
+	prompts, err := dc.store.Get(ctx, gistID)
 	if err != nil {
 		fmt.Printf("❌ Error searching for prompt: %v\n", err)
 		fmt.Println()
@@ -466,7 +472,7 @@ func (dc *delete) handleDirectMode(gistURL string) {
 	fmt.Printf("🗑️  Deleting prompt '%s' from URL: %s...\n", targetPrompt.Name, gistURL)
 	
 	// Use the PromptService DeleteByURL method for deletion
-	err = dc.promptService.DeleteByURL(gistURL)
+	err = dc.promptService.DeleteByURL(ctx, gistURL)
 	if err != nil {
 		fmt.Printf("❌ Failed to delete prompt: %v\n", err)
 		fmt.Println()
@@ -496,19 +502,22 @@ func (dc *delete) handleInvalidURL(invalidURL string) {
 	fmt.Printf("❌ Error: Invalid GitHub Gist URL format: %s\n", invalidURL)
 	fmt.Println()
 	
+	// Examples use the configured gist host (gist.github.com or GitHub Enterprise)
+	gistBase := utils.CurrentGitHubEndpoints().GistBaseURL()
+	
 	// Provide specific guidance based on the URL pattern
-	if contains(invalidURL, "gist.github.com") {
-		fmt.Println("The URL contains 'gist.github.com' but doesn't match the expected format.")
+	if utils.IsGistURLHost(invalidURL) {
+		fmt.Println("The URL points at the gist host but doesn't match the expected format.")
 		fmt.Println()
 		fmt.Println("Valid GitHub Gist URL formats:")
-		fmt.Println("  https://gist.github.com/username/gist-id")
-		fmt.Println("  https://gist.github.com/gist-id")
+		fmt.Printf("  %s/username/gist-id\n", gistBase)
+		fmt.Printf("  %s/gist-id\n", gistBase)
 		fmt.Println()
 		fmt.Println("Where gist-id is a 20 or 32 character hexadecimal string.")
 		fmt.Println()
 		fmt.Println("Examples:")
-		fmt.Println("  https://gist.github.com/user/1234567890abcdef1234567890abcdef")
-		fmt.Println("  https://gist.github.com/abcdef1234567890abcd")
+		fmt.Printf("  %s/user/1234567890abcdef1234567890abcdef\n", gistBase)
+		fmt.Printf("  %s/abcdef1234567890abcd\n", gistBase)
 	} else {
 		fmt.Println("This appears to be a URL but not a GitHub Gist URL.")
 		fmt.Println()
@@ -516,7 +525,7 @@ func (dc *delete) handleInvalidURL(invalidURL string) {
 		fmt.Printf("  pv delete \"%s\"\n", invalidURL)
 		fmt.Println()
 		fmt.Println("For GitHub Gist URLs, use the format:")
-		fmt.Println("  https://gist.github.com/username/gist-id")
+		fmt.Printf("  %s/username/gist-id\n", gistBase)
 	}
 }
 
@@ -2,11 +2,13 @@ package cmd
 
 import (
 	"bytes"
+	"context"
 	"io"
 	"os"
 	"strings"
 	"testing"
 
+	"github.com/grigri/pv/internal/archive"
 	"github.com/grigri/pv/internal/errors"
 	"github.com/grigri/pv/internal/infra"
 	"github.com/grigri/pv/internal/model"
@@ -49,7 +51,7 @@ func NewMockPromptService() *MockPromptService {
 	}
 }
 
-func (m *MockPromptService) AddFromFile(filePath string) (*model.Prompt, error) {
+func (m *MockPromptService) AddFromFile(ctx context.Context, filePath string) (*model.Prompt, error) {
 	m.addFromFileCalls = append(m.addFromFileCalls, filePath)
 	if m.addFromFileError != nil {
 		return nil, m.addFromFileError
@@ -57,7 +59,7 @@ func (m *MockPromptService) AddFromFile(filePath string) (*model.Prompt, error)
 	return m.addFromFileResult, nil
 }
 
-func (m *MockPromptService) DeleteByKeyword(keyword string) error {
+func (m *MockPromptService) DeleteByKeyword(ctx context.Context, keyword string) error {
 	m.deleteByKeywordCalls = append(m.deleteByKeywordCalls, keyword)
 	if m.deleteByKeywordFunc != nil {
 		return m.deleteByKeywordFunc(keyword)
@@ -65,7 +67,7 @@ func (m *MockPromptService) DeleteByKeyword(keyword string) error {
 	return m.deleteByKeywordError
 }
 
-func (m *MockPromptService) DeleteByURL(gistURL string) error {
+func (m *MockPromptService) DeleteByURL(ctx context.Context, gistURL string) error {
 	m.deleteByURLCalls = append(m.deleteByURLCalls, gistURL)
 	if m.deleteByURLFunc != nil {
 		return m.deleteByURLFunc(gistURL)
@@ -73,7 +75,7 @@ func (m *MockPromptService) DeleteByURL(gistURL string) error {
 	return m.deleteByURLError
 }
 
-func (m *MockPromptService) ListPrompts() ([]model.Prompt, error) {
+func (m *MockPromptService) ListPrompts(ctx context.Context) ([]model.Prompt, error) {
 	m.listPromptsCalls++
 	if m.listForDeletionFunc != nil {
 		return m.listForDeletionFunc()
@@ -84,7 +86,7 @@ func (m *MockPromptService) ListPrompts() ([]model.Prompt, error) {
 	return m.listPromptsResult, nil
 }
 
-func (m *MockPromptService) FilterPrompts(keyword string) ([]model.Prompt, error) {
+func (m *MockPromptService) FilterPrompts(ctx context.Context, keyword string) ([]model.Prompt, error) {
 	m.filterPromptsCalls = append(m.filterPromptsCalls, keyword)
 	if m.filterForDeletionFunc != nil {
 		return m.filterForDeletionFunc(keyword)
@@ -96,43 +98,145 @@ func (m *MockPromptService) FilterPrompts(keyword string) ([]model.Prompt, error
 }
 
 // GetPromptByURL implements the missing PromptService method for testing
-func (m *MockPromptService) GetPromptByURL(gistURL string) (*model.Prompt, error) {
+func (m *MockPromptService) GetPromptByURL(ctx context.Context, gistURL string) (*model.Prompt, error) {
 	// This method is not used by delete command but required by interface
 	return nil, nil
 }
 
 // GetPromptContent implements the missing PromptService method for testing
-func (m *MockPromptService) GetPromptContent(prompt *model.Prompt) (string, error) {
+func (m *MockPromptService) GetPromptContent(ctx context.Context, prompt *model.Prompt) (string, error) {
 	// This method is not used by delete command but required by interface
 	return "", nil
 }
 
-func (m *MockPromptService) AddFromURL(gistURL string) (*model.Prompt, error) {
+func (m *MockPromptService) AddFromURL(ctx context.Context, gistURL string) (*model.Prompt, error) {
 	return nil, errors.NewAppError(errors.ErrValidation, "not implemented", nil)
 }
 
-func (m *MockPromptService) FilterPrivatePrompts(keyword string) ([]model.Prompt, error) {
+func (m *MockPromptService) FilterPrivatePrompts(ctx context.Context, keyword string) ([]model.Prompt, error) {
 	return nil, errors.NewAppError(errors.ErrValidation, "not implemented", nil)
 }
 
-func (m *MockPromptService) SharePrompt(prompt *model.Prompt) (*model.Prompt, error) {
+func (m *MockPromptService) SharePrompt(ctx context.Context, prompt *model.Prompt) (*model.Prompt, error) {
 	return nil, errors.NewAppError(errors.ErrValidation, "not implemented", nil)
 }
 
-func (m *MockPromptService) ValidateGistAccess(gistURL string) (*service.GistInfo, error) {
+func (m *MockPromptService) ValidateGistAccess(ctx context.Context, gistURL string) (*service.GistInfo, error) {
 	return &service.GistInfo{}, nil
 }
 
-func (m *MockPromptService) ListPrivatePrompts() ([]model.Prompt, error) {
+func (m *MockPromptService) ListPrivatePrompts(ctx context.Context) ([]model.Prompt, error) {
 	return nil, errors.NewAppError(errors.ErrValidation, "not implemented", nil)
 }
 
 // Sync implements the PromptService interface for testing
-func (m *MockPromptService) Sync() error {
+func (m *MockPromptService) Sync(ctx context.Context) (*infra.SyncPlan, error) {
+	// This method is not used by delete command but required by interface
+	return &infra.SyncPlan{}, nil
+}
+
+// FetchPromptContents implements the PromptService interface for testing
+func (m *MockPromptService) FetchPromptContents(ctx context.Context, prompts []model.Prompt, progress service.ContentProgress) error {
 	// This method is not used by delete command but required by interface
 	return nil
 }
 
+// ReplayOfflineChanges implements the PromptService interface for testing
+func (m *MockPromptService) ReplayOfflineChanges(ctx context.Context) (*infra.ReplayReport, error) {
+	// This method is not used by delete command but required by interface
+	return &infra.ReplayReport{}, nil
+}
+
+// GetPromptHistory implements the PromptService interface for testing
+func (m *MockPromptService) GetPromptHistory(ctx context.Context, prompt *model.Prompt) ([]model.Revision, error) {
+	// This method is not used by delete command but required by interface
+	return nil, nil
+}
+
+// GetPromptRevision implements the PromptService interface for testing
+func (m *MockPromptService) GetPromptRevision(ctx context.Context, prompt *model.Prompt, sha string) (string, error) {
+	// This method is not used by delete command but required by interface
+	return "", nil
+}
+
+// DiffRevisions implements the PromptService interface for testing
+func (m *MockPromptService) DiffRevisions(ctx context.Context, prompt *model.Prompt, from, to string) (*service.PromptDiff, error) {
+	// This method is not used by delete command but required by interface
+	return nil, nil
+}
+
+// DiffWithFile implements the PromptService interface for testing
+func (m *MockPromptService) DiffWithFile(ctx context.Context, prompt *model.Prompt, filePath string) (*service.PromptDiff, error) {
+	// This method is not used by delete command but required by interface
+	return nil, nil
+}
+
+// DiffWithExport implements the PromptService interface for testing
+func (m *MockPromptService) DiffWithExport(ctx context.Context, prompt *model.Prompt) (*service.PromptDiff, error) {
+	// This method is not used by delete command but required by interface
+	return nil, nil
+}
+
+// AddFromContent implements the PromptService interface for testing
+func (m *MockPromptService) AddFromContent(ctx context.Context, content string) (*model.Prompt, error) {
+	// This method is not used by delete command but required by interface
+	return nil, nil
+}
+
+// ValidatePromptContent implements the PromptService interface for testing
+func (m *MockPromptService) ValidatePromptContent(content string) (*model.Prompt, error) {
+	// This method is not used by delete command but required by interface
+	return nil, nil
+}
+
+// PlanAddFromFiles implements the PromptService interface for testing
+func (m *MockPromptService) PlanAddFromFiles(ctx context.Context, paths []string, recursive bool) (*service.AddPlan, error) {
+	// This method is not used by delete command but required by interface
+	return &service.AddPlan{}, nil
+}
+
+// PlanAddFromContents implements the PromptService interface for testing
+func (m *MockPromptService) PlanAddFromContents(ctx context.Context, sources []service.PromptSource) (*service.AddPlan, error) {
+	// This method is not used by delete command but required by interface
+	return &service.AddPlan{}, nil
+}
+
+// ExportArchive implements the PromptService interface for testing
+func (m *MockPromptService) ExportArchive(ctx context.Context, progress service.ContentProgress) (*archive.Archive, error) {
+	// This method is not used by delete command but required by interface
+	return &archive.Archive{}, nil
+}
+
+// RestoreArchive implements the PromptService interface for testing
+func (m *MockPromptService) RestoreArchive(ctx context.Context, a *archive.Archive) (*service.RestoreReport, error) {
+	// This method is not used by delete command but required by interface
+	return &service.RestoreReport{}, nil
+}
+
+// ApplyAddPlan implements the PromptService interface for testing
+func (m *MockPromptService) ApplyAddPlan(ctx context.Context, plan *service.AddPlan) ([]model.Prompt, error) {
+	// This method is not used by delete command but required by interface
+	return nil, nil
+}
+
+// UpdatePromptContent implements the PromptService interface for testing
+func (m *MockPromptService) UpdatePromptContent(ctx context.Context, prompt *model.Prompt, content string) (*model.Prompt, error) {
+	// This method is not used by delete command but required by interface
+	return nil, nil
+}
+
+// PreviewRollback implements the PromptService interface for testing
+func (m *MockPromptService) PreviewRollback(ctx context.Context, prompt *model.Prompt, sha string) (*service.PromptDiff, error) {
+	// This method is not used by delete command but required by interface
+	return nil, nil
+}
+
+// RollbackPrompt implements the PromptService interface for testing
+func (m *MockPromptService) RollbackPrompt(ctx context.Context, prompt *model.Prompt, sha string) (*service.RollbackResult, error) {
+	// This method is not used by delete command but required by interface
+	return nil, nil
+}
+
 func (m *MockPromptService) Reset() {
 	m.addFromFileResult = nil
 	m.addFromFileError = nil
@@ -169,14 +273,14 @@ func NewMockStore() *MockStore {
 	}
 }
 
-func (m *MockStore) List() ([]model.Prompt, error) {
+func (m *MockStore) List(ctx context.Context) ([]model.Prompt, error) {
 	if m.listError != nil {
 		return nil, m.listError
 	}
 	return m.prompts, nil
 }
 
-func (m *MockStore) Add(prompt model.Prompt) error {
+func (m *MockStore) Add(ctx context.Context, prompt model.Prompt) error {
 	if m.addError != nil {
 		return m.addError
 	}
@@ -184,7 +288,7 @@ func (m *MockStore) Add(prompt model.Prompt) error {
 	return nil
 }
 
-func (m *MockStore) Delete(keyword string) error {
+func (m *MockStore) Delete(ctx context.Context, keyword string) error {
 	if m.deleteError != nil {
 		return m.deleteError
 	}
@@ -197,7 +301,7 @@ func (m *MockStore) Delete(keyword string) error {
 	return nil
 }
 
-func (m *MockStore) Update(prompt model.Prompt) error {
+func (m *MockStore) Update(ctx context.Context, prompt model.Prompt) error {
 	if m.updateError != nil {
 		return m.updateError
 	}
@@ -211,7 +315,7 @@ func (m *MockStore) Update(prompt model.Prompt) error {
 	return nil
 }
 
-func (m *MockStore) Get(keyword string) ([]model.Prompt, error) {
+func (m *MockStore) Get(ctx context.Context, keyword string) ([]model.Prompt, error) {
 	if m.getError != nil {
 		return nil, m.getError
 	}
@@ -229,20 +333,20 @@ func (m *MockStore) Get(keyword string) ([]model.Prompt, error) {
 }
 
 // GetContent implements the missing Store method for testing
-func (m *MockStore) GetContent(gistID string) (string, error) {
+func (m *MockStore) GetContent(ctx context.Context, gistID string) (string, error) {
 	// This method is not used by delete command but required by interface
 	return "", nil
 }
 
-func (m *MockStore) CreatePublicGist(prompt model.Prompt) (string, error) {
+func (m *MockStore) CreatePublicGist(ctx context.Context, prompt model.Prompt) (string, error) {
 	return "https://gist.github.com/test/123", nil
 }
 
-func (m *MockStore) UpdateGist(gistURL string, prompt model.Prompt) error {
+func (m *MockStore) UpdateGist(ctx context.Context, gistURL string, prompt model.Prompt) error {
 	return nil
 }
 
-func (m *MockStore) GetGistInfo(gistURL string) (*infra.GistInfo, error) {
+func (m *MockStore) GetGistInfo(ctx context.Context, gistURL string) (*infra.GistInfo, error) {
 	return &infra.GistInfo{
 		ID:        "123",
 		URL:       gistURL,
@@ -251,19 +355,19 @@ func (m *MockStore) GetGistInfo(gistURL string) (*infra.GistInfo, error) {
 	}, nil
 }
 
-func (m *MockStore) AddExport(prompt model.IndexedPrompt) error {
+func (m *MockStore) AddExport(ctx context.Context, prompt model.IndexedPrompt) error {
 	return nil
 }
 
-func (m *MockStore) UpdateExport(prompt model.IndexedPrompt) error {
+func (m *MockStore) UpdateExport(ctx context.Context, prompt model.IndexedPrompt) error {
 	return nil
 }
 
-func (m *MockStore) GetExports() ([]model.IndexedPrompt, error) {
+func (m *MockStore) GetExports(ctx context.Context) ([]model.IndexedPrompt, error) {
 	return []model.IndexedPrompt{}, nil
 }
 
-func (m *MockStore) FindExistingPromptByURL(gistURL string) (*model.Prompt, error) {
+func (m *MockStore) FindExistingPromptByURL(ctx context.Context, gistURL string) (*model.Prompt, error) {
 	return nil, nil
 }
 
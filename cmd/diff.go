@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/grigri/pv/internal/diff"
+	"github.com/grigri/pv/internal/service"
+	"github.com/grigri/pv/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+// DiffCmd represents the diff command
+type DiffCmd struct {
+	promptService service.PromptService
+	tui           tui.TUIInterface
+	from          string
+	to            string
+	file          string
+	export        bool
+	json          bool
+}
+
+// NewDiffCommand creates a new diff command
+func NewDiffCommand(promptService service.PromptService, tui tui.TUIInterface) *cobra.Command {
+	diffCmd := &DiffCmd{
+		promptService: promptService,
+		tui:           tui,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "diff <keyword|gist_url>",
+		Short: "比较提示词的修订、本地文件或公开副本",
+		Long: `以统一 diff 格式显示提示词的变化，元数据（name、author、description、tags、version）
+的变化与正文的变化分开显示。
+
+支持三种比较方式:
+
+1. 两个修订之间（默认比较最新修订与上一个修订）:
+   pv diff "keyword" [--from <sha>] [--to <sha>]
+
+2. Vault 中的版本与本地 YAML 文件:
+   pv diff "keyword" --file my-prompt.yaml
+
+3. 私有提示词与其分享出的公开副本:
+   pv diff "keyword" --export
+
+使用 --json 输出结构化结果，便于其他工具处理。`,
+		Args: cobra.ExactArgs(1),
+		RunE: diffCmd.run,
+	}
+
+	cmd.Flags().StringVar(&diffCmd.from, "from", "", "比较的起始修订 SHA（默认为 --to 的上一个修订）")
+	cmd.Flags().StringVar(&diffCmd.to, "to", "", "比较的目标修订 SHA（默认为最新修订）")
+	cmd.Flags().StringVar(&diffCmd.file, "file", "", "与 vault 中的版本比较的本地 YAML 文件")
+	cmd.Flags().BoolVar(&diffCmd.export, "export", false, "与分享出的公开副本比较")
+	cmd.Flags().BoolVar(&diffCmd.json, "json", false, "以 JSON 格式输出差异")
+	cmd.MarkFlagsMutuallyExclusive("file", "export")
+	cmd.MarkFlagsMutuallyExclusive("file", "from")
+	cmd.MarkFlagsMutuallyExclusive("file", "to")
+	cmd.MarkFlagsMutuallyExclusive("export", "from")
+	cmd.MarkFlagsMutuallyExclusive("export", "to")
+
+	return cmd
+}
+
+// run 执行 diff 命令的主要逻辑
+func (d *DiffCmd) run(cmd *cobra.Command, args []string) error {
+	ctx, cancel := commandContext(cmd)
+	defer cancel()
+
+	prompt, err := selectPrompt(ctx, d.promptService, d.tui, args[0])
+	if err != nil {
+		return err
+	}
+	if prompt == nil {
+		fmt.Fprintln(cmd.OutOrStdout(), "取消比较")
+		return nil
+	}
+
+	var promptDiff *service.PromptDiff
+	switch {
+	case d.file != "":
+		promptDiff, err = d.promptService.DiffWithFile(ctx, prompt, d.file)
+	case d.export:
+		promptDiff, err = d.promptService.DiffWithExport(ctx, prompt)
+	default:
+		promptDiff, err = d.promptService.DiffRevisions(ctx, prompt, d.from, d.to)
+	}
+	if err != nil {
+		return fmt.Errorf("比较提示词失败: %w", err)
+	}
+
+	if d.json {
+		encoder := json.NewEncoder(cmd.OutOrStdout())
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(promptDiff)
+	}
+
+	printPromptDiff(cmd.OutOrStdout(), promptDiff)
+	return nil
+}
+
+// printPromptDiff 以统一 diff 格式打印差异；输出到终端时带颜色
+func printPromptDiff(out io.Writer, promptDiff *service.PromptDiff) {
+	renderer := lipgloss.NewRenderer(out)
+	newStyle := func(color string) lipgloss.Style {
+		return renderer.NewStyle().Foreground(lipgloss.Color(color)).TabWidth(lipgloss.NoTabConversion)
+	}
+	headerStyle := renderer.NewStyle().Bold(true)
+	hunkStyle := newStyle("6")
+	deleteStyle := newStyle("1")
+	insertStyle := newStyle("2")
+
+	fmt.Fprintln(out, headerStyle.Render("--- "+promptDiff.From))
+	fmt.Fprintln(out, headerStyle.Render("+++ "+promptDiff.To))
+
+	if !promptDiff.HasChanges() {
+		fmt.Fprintln(out, "✅ 没有差异")
+		return
+	}
+
+	if len(promptDiff.Metadata) > 0 {
+		fmt.Fprintln(out, "元数据:")
+		for _, change := range promptDiff.Metadata {
+			fmt.Fprintf(out, "  %s: %s → %s\n", change.Field,
+				deleteStyle.Render(fmt.Sprintf("%q", change.Old)),
+				insertStyle.Render(fmt.Sprintf("%q", change.New)))
+		}
+	}
+
+	if len(promptDiff.Body) > 0 && len(promptDiff.Metadata) > 0 {
+		fmt.Fprintln(out, "正文:")
+	}
+	for _, hunk := range promptDiff.Body {
+		fmt.Fprintln(out, hunkStyle.Render(hunk.Header()))
+		for _, line := range hunk.Lines {
+			text := string(line.Op) + line.Text
+			switch line.Op {
+			case diff.Delete:
+				text = deleteStyle.Render(text)
+			case diff.Insert:
+				text = insertStyle.Render(text)
+			}
+			fmt.Fprintln(out, text)
+		}
+	}
+}
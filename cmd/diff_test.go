@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/grigri/pv/internal/diff"
+	"github.com/grigri/pv/internal/service"
+)
+
+func TestPrintPromptDiff(t *testing.T) {
+	promptDiff := &service.PromptDiff{
+		From:     "a1b2c3d",
+		To:       "b2c3d4e",
+		Metadata: []service.MetadataChange{{Field: "version", Old: "1.0", New: "1.1"}},
+		Body:     diff.Hunks(diff.Lines("Review {{code}}\nBe brief.\n", "Review {{code}}\nBe thorough.\n"), diff.DefaultContext),
+	}
+
+	var out bytes.Buffer
+	printPromptDiff(&out, promptDiff)
+
+	want := "--- a1b2c3d\n+++ b2c3d4e\n" +
+		"元数据:\n  version: \"1.0\" → \"1.1\"\n" +
+		"正文:\n@@ -1,2 +1,2 @@\n Review {{code}}\n-Be brief.\n+Be thorough.\n"
+	if out.String() != want {
+		t.Errorf("Unexpected output:\n%s\nwant:\n%s", out.String(), want)
+	}
+
+	// Output that isn't a terminal carries no color codes
+	if bytes.Contains(out.Bytes(), []byte("\x1b[")) {
+		t.Error("Expected no ANSI escapes when not writing to a terminal")
+	}
+
+	out.Reset()
+	printPromptDiff(&out, &service.PromptDiff{From: "a", To: "b"})
+	if want := "--- a\n+++ b\n✅ 没有差异\n"; out.String() != want {
+		t.Errorf("Expected %q for identical prompts, got %q", want, out.String())
+	}
+}
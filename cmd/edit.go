@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/grigri/pv/internal/model"
+	"github.com/grigri/pv/internal/service"
+	"github.com/grigri/pv/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+// EditCmd represents the edit command
+type EditCmd struct {
+	promptService service.PromptService
+	tui           tui.TUIInterface
+	// runEditor 打开编辑器编辑 path，编辑器退出后返回；测试中替换为直接改写文件
+	runEditor func(path string) error
+}
+
+// NewEditCommand creates a new edit command
+func NewEditCommand(promptService service.PromptService, tui tui.TUIInterface) *cobra.Command {
+	editCmd := &EditCmd{
+		promptService: promptService,
+		tui:           tui,
+		runEditor:     runEditor,
+	}
+	return editCmd.command()
+}
+
+// command builds the cobra command running this edit command
+func (e *EditCmd) command() *cobra.Command {
+	return &cobra.Command{
+		Use:   "edit [keyword|gist_url]",
+		Short: "在编辑器中修改提示词",
+		Long: `在 $VISUAL 或 $EDITOR 指定的编辑器中打开提示词的 YAML 内容，保存并退出后写回 Gist。
+
+保存的内容会重新验证；验证失败时编辑器会重新打开，错误信息显示在文件开头。
+清空文件或不做修改直接退出可以放弃编辑。
+
+修改 name 会同时重命名 Gist 中的提示词文件；name 和 author 不能与其他提示词相同。`,
+		Example: `  pv edit
+  pv edit "代码审查"
+  EDITOR="code --wait" pv edit https://gist.github.com/user/gist_id`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: e.run,
+	}
+}
+
+// run 执行 edit 命令的主要逻辑
+func (e *EditCmd) run(cmd *cobra.Command, args []string) error {
+	ctx, cancel := commandContext(cmd)
+	defer cancel()
+	out := cmd.OutOrStdout()
+
+	var arg string
+	if len(args) > 0 {
+		arg = args[0]
+	}
+	prompt, err := selectPrompt(ctx, e.promptService, e.tui, arg)
+	if err != nil {
+		return err
+	}
+	if prompt == nil {
+		fmt.Fprintln(out, "取消编辑")
+		return nil
+	}
+
+	content, err := e.promptService.GetPromptContent(ctx, prompt)
+	if err != nil {
+		return fmt.Errorf("获取提示词内容失败: %w", err)
+	}
+
+	// 临时文件沿用提示词文件的扩展名，便于编辑器高亮 Markdown
+	pattern := "pv-edit-*" + filepath.Ext(prompt.FileName())
+
+	var updated *model.Prompt
+	outcome, err := editUntilValid(e.runEditor, cmd.ErrOrStderr(), pattern, content, func(edited string) error {
+		var err error
+		updated, err = e.promptService.UpdatePromptContent(ctx, prompt, edited)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	switch outcome {
+	case editEmptied:
+		fmt.Fprintln(out, "文件为空，取消编辑")
+	case editUnchanged:
+		fmt.Fprintln(out, "内容没有变化，无需保存")
+	case editSaved:
+		fmt.Fprintf(out, "✅ 已保存提示词 '%s'\n", updated.Name)
+		if updated.Name != prompt.Name {
+			fmt.Fprintf(out, "提示词已从 '%s' 重命名为 '%s'\n", prompt.Name, updated.Name)
+		}
+	}
+	return nil
+}
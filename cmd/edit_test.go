@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/grigri/pv/internal/errors"
+	"github.com/grigri/pv/internal/model"
+	"github.com/grigri/pv/internal/tui"
+)
+
+const editTestContent = "name: Review\nauthor: alice\n---\nReview {{code}}\n"
+
+// editPromptService serves the content of a prompt and accepts edits without "invalid" in them
+type editPromptService struct {
+	*MockPromptService
+	saved []string
+}
+
+func (m *editPromptService) GetPromptContent(ctx context.Context, prompt *model.Prompt) (string, error) {
+	return editTestContent, nil
+}
+
+func (m *editPromptService) UpdatePromptContent(ctx context.Context, prompt *model.Prompt, content string) (*model.Prompt, error) {
+	if strings.Contains(content, "invalid") {
+		return nil, errors.NewAppError(errors.ErrValidation, "name is required", nil)
+	}
+	m.saved = append(m.saved, content)
+	updated := *prompt
+	updated.Name = strings.TrimPrefix(strings.SplitN(content, "\n", 2)[0], "name: ")
+	return &updated, nil
+}
+
+func TestEditCommand(t *testing.T) {
+	prompt := model.Prompt{ID: "0123456789abcdef0123456789abcdef", Name: "Review", Author: "alice"}
+
+	// run edits the prompt with an editor that writes the given contents in turn,
+	// recording what the editor was opened with each time
+	run := func(t *testing.T, edits ...string) (*editPromptService, []string, string, error) {
+		t.Helper()
+		promptService := &editPromptService{MockPromptService: NewMockPromptService()}
+		promptService.filterPromptsResult = []model.Prompt{prompt}
+
+		var opened []string
+		editCmd := &EditCmd{promptService: promptService, tui: tui.NewMockTUI(), runEditor: func(path string) error {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			opened = append(opened, string(data))
+			if len(opened) > len(edits) {
+				t.Fatalf("Editor opened %d times, expected at most %d", len(opened), len(edits))
+			}
+			return os.WriteFile(path, []byte(edits[len(opened)-1]), 0600)
+		}}
+		cmd := editCmd.command()
+
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetErr(&out)
+		cmd.SetArgs([]string{"review"})
+		err := cmd.Execute()
+		return promptService, opened, out.String(), err
+	}
+
+	t.Run("saves the edit", func(t *testing.T) {
+		edited := strings.Replace(editTestContent, "Review {{code}}", "Review {{code}} carefully", 1)
+		promptService, opened, out, err := run(t, edited)
+		if err != nil {
+			t.Fatalf("edit failed: %v", err)
+		}
+		if opened[0] != editTestContent {
+			t.Errorf("Expected the editor to open the prompt content, got %q", opened[0])
+		}
+		if len(promptService.saved) != 1 || promptService.saved[0] != edited {
+			t.Errorf("Expected the edit to be saved, got %v", promptService.saved)
+		}
+		if !strings.Contains(out, "已保存提示词 'Review'") {
+			t.Errorf("Expected a success message, got %q", out)
+		}
+	})
+
+	t.Run("reopens the editor until the content is valid", func(t *testing.T) {
+		renamed := strings.Replace(editTestContent, "name: Review", "name: Code Review", 1)
+		promptService, opened, out, err := run(t, "invalid\n", renamed)
+		if err != nil {
+			t.Fatalf("edit failed: %v", err)
+		}
+		if len(opened) != 2 || !strings.HasPrefix(opened[1], editorNoticePrefix+"验证失败: name is required\n") || !strings.HasSuffix(opened[1], "\ninvalid\n") {
+			t.Errorf("Expected the editor to reopen with the error above the content, got %q", opened)
+		}
+		if len(promptService.saved) != 1 || promptService.saved[0] != renamed {
+			t.Errorf("Expected the corrected content to be saved, got %v", promptService.saved)
+		}
+		if !strings.Contains(out, "重命名为 'Code Review'") {
+			t.Errorf("Expected the rename to be reported, got %q", out)
+		}
+	})
+
+	t.Run("gives up when an invalid edit is left unchanged", func(t *testing.T) {
+		promptService, opened, _, err := run(t, "invalid\n", editorNotice(errors.NewAppError(errors.ErrValidation, "name is required", nil))+"invalid\n")
+		if err == nil || !strings.Contains(err.Error(), "已放弃编辑") {
+			t.Errorf("Expected the edit to be abandoned, got %v", err)
+		}
+		if len(opened) != 2 || len(promptService.saved) != 0 {
+			t.Errorf("Expected nothing to be saved, got %v", promptService.saved)
+		}
+	})
+
+	t.Run("unchanged or emptied", func(t *testing.T) {
+		for _, edit := range []string{editTestContent, ""} {
+			promptService, _, _, err := run(t, edit)
+			if err != nil || len(promptService.saved) != 0 {
+				t.Errorf("Expected %q not to be saved, got %v, %v", edit, promptService.saved, err)
+			}
+		}
+	})
+}
@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	stderrors "errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/grigri/pv/internal/errors"
+)
+
+// editorNoticePrefix 标记 pv 写在临时文件开头的提示行，保存后会被去掉
+const editorNoticePrefix = "# pv: "
+
+// editOutcome 表示一次编辑器会话的结果
+type editOutcome int
+
+const (
+	// editSaved 表示编辑后的内容已经保存
+	editSaved editOutcome = iota
+	// editEmptied 表示文件被清空，放弃编辑
+	editEmptied
+	// editUnchanged 表示内容没有修改，无需保存
+	editUnchanged
+)
+
+// editUntilValid 在编辑器中编辑 content，直到 save 接受编辑后的内容
+// content 写入以 pattern 命名的临时文件；save 返回验证错误时，编辑器带着错误说明重新打开。
+// 验证失败后不做修改就退出编辑器视为放弃，返回该验证错误
+func editUntilValid(runEditor func(path string) error, errOut io.Writer, pattern, content string, save func(edited string) error) (editOutcome, error) {
+	file, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return 0, fmt.Errorf("创建临时文件失败: %w", err)
+	}
+	path := file.Name()
+	file.Close()
+	defer os.Remove(path)
+
+	edited := content
+	var invalid error
+	for {
+		if err := os.WriteFile(path, []byte(editorNotice(invalid)+edited), 0600); err != nil {
+			return 0, fmt.Errorf("写入临时文件失败: %w", err)
+		}
+		if err := runEditor(path); err != nil {
+			return 0, fmt.Errorf("运行编辑器失败: %w", err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return 0, fmt.Errorf("读取临时文件失败: %w", err)
+		}
+
+		previous := edited
+		edited = stripEditorNotice(string(data))
+		switch {
+		case strings.TrimSpace(edited) == "":
+			return editEmptied, nil
+		case edited == content:
+			return editUnchanged, nil
+		case invalid != nil && edited == previous:
+			// 验证失败后没有修改就退出了编辑器，视为放弃
+			return 0, fmt.Errorf("提示词内容无效，已放弃编辑: %w", invalid)
+		}
+
+		err = save(edited)
+		if err == nil {
+			return editSaved, nil
+		}
+		if !isValidationError(err) {
+			return 0, fmt.Errorf("保存提示词失败: %w", err)
+		}
+
+		invalid = err
+		fmt.Fprintf(errOut, "❌ 内容验证失败: %v\n重新打开编辑器...\n", err)
+	}
+}
+
+// isValidationError 判断错误是否由提示词内容无效引起，可以通过修改内容解决
+func isValidationError(err error) bool {
+	var validationErr errors.ValidationError
+	return errors.TypeOf(err, errors.ErrUnknown) == errors.ErrValidation || stderrors.As(err, &validationErr)
+}
+
+// editorNotice 返回写在临时文件开头的验证错误说明；没有错误时为空
+func editorNotice(invalid error) string {
+	if invalid == nil {
+		return ""
+	}
+
+	var notice strings.Builder
+	for _, line := range strings.Split("验证失败: "+invalid.Error(), "\n") {
+		notice.WriteString(editorNoticePrefix + line + "\n")
+	}
+	notice.WriteString(editorNoticePrefix + "修正后保存并退出；清空文件可放弃编辑。这几行会在保存时自动去掉\n")
+	return notice.String()
+}
+
+// stripEditorNotice 去掉文件开头由 editorNotice 写入的说明行
+func stripEditorNotice(content string) string {
+	for strings.HasPrefix(content, editorNoticePrefix) {
+		end := strings.Index(content, "\n")
+		if end < 0 {
+			return ""
+		}
+		content = content[end+1:]
+	}
+	return content
+}
+
+// editorCommand 返回用户配置的编辑器及其参数，依次读取 $VISUAL 和 $EDITOR
+func editorCommand() []string {
+	for _, name := range []string{"VISUAL", "EDITOR"} {
+		if fields := strings.Fields(os.Getenv(name)); len(fields) > 0 {
+			return fields
+		}
+	}
+	if runtime.GOOS == "windows" {
+		return []string{"notepad"}
+	}
+	return []string{"vi"}
+}
+
+// runEditor 在当前终端中运行编辑器编辑 path，等待其退出
+func runEditor(path string) error {
+	editor := editorCommand()
+	editorCmd := exec.Command(editor[0], append(editor[1:], path)...)
+	editorCmd.Stdin = os.Stdin
+	editorCmd.Stdout = os.Stdout
+	editorCmd.Stderr = os.Stderr
+	return editorCmd.Run()
+}
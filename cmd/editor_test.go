@@ -0,0 +1,19 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEditorCommand(t *testing.T) {
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "code --wait")
+	if got := editorCommand(); strings.Join(got, " ") != "code --wait" {
+		t.Errorf("Expected $EDITOR with its arguments, got %v", got)
+	}
+
+	t.Setenv("VISUAL", "nano")
+	if got := editorCommand(); len(got) != 1 || got[0] != "nano" {
+		t.Errorf("Expected $VISUAL to take precedence, got %v", got)
+	}
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/grigri/pv/internal/archive"
+	"github.com/grigri/pv/internal/model"
+	"github.com/grigri/pv/internal/service"
+	"github.com/spf13/cobra"
+)
+
+// ExportCmd represents the export command
+type ExportCmd struct {
+	promptService service.PromptService
+
+	archive string
+}
+
+// NewExportCommand creates a new export command
+func NewExportCommand(promptService service.PromptService) *cobra.Command {
+	exportCmd := &ExportCmd{
+		promptService: promptService,
+	}
+	return exportCmd.command()
+}
+
+// command builds the cobra command running this export command
+func (e *ExportCmd) command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export --archive <file>",
+		Short: "把整个 vault 导出为可移植的归档文件",
+		Long: `把整个 vault 备份为一个 .tar.gz 归档文件。
+
+归档包含完整的索引（提示词和分享记录）、每个提示词和公开副本的内容，
+以及记录每个文件校验和的 manifest.json。使用 pv restore 可以把归档恢复到
+任意存储后端。`,
+		Example: `  pv export --archive vault.tar.gz`,
+		Args:    cobra.NoArgs,
+		RunE:    e.run,
+	}
+
+	cmd.Flags().StringVar(&e.archive, "archive", "", "写入的归档文件路径（.tar.gz）")
+	_ = cmd.MarkFlagRequired("archive")
+	return cmd
+}
+
+// run 执行 export 命令的主要逻辑
+func (e *ExportCmd) run(cmd *cobra.Command, args []string) error {
+	ctx, cancel := commandContext(cmd)
+	defer cancel()
+	out := cmd.OutOrStdout()
+
+	a, err := e.promptService.ExportArchive(ctx, func(done, total int, prompt model.Prompt, err error) {
+		fmt.Fprintf(cmd.ErrOrStderr(), "⬇️  正在读取 %d/%d: %s\n", done, total, prompt.Name)
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := writeArchive(e.archive, a); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "✅ 已导出 %d 个提示词和 %d 个分享记录到 %s\n", len(a.Index.Prompts), len(a.Index.Exports), e.archive)
+	return nil
+}
+
+// writeArchive writes an archive to a temporary file next to path and renames it into place,
+// so a failed export never leaves a truncated archive behind
+func writeArchive(path string, a *archive.Archive) error {
+	file, err := os.CreateTemp(filepath.Dir(path), ".pv-export-*")
+	if err != nil {
+		return fmt.Errorf("无法创建归档文件: %w", err)
+	}
+	defer os.Remove(file.Name())
+
+	if err := archive.Write(file, a); err != nil {
+		file.Close()
+		return fmt.Errorf("写入归档失败: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("写入归档失败: %w", err)
+	}
+	if err := os.Rename(file.Name(), path); err != nil {
+		return fmt.Errorf("写入归档失败: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/grigri/pv/internal/archive"
+	"github.com/grigri/pv/internal/infra"
+	"github.com/grigri/pv/internal/service"
+	"github.com/grigri/pv/internal/validator"
+)
+
+// newVaultService returns a prompt service on a local vault holding the given prompt files
+func newVaultService(t *testing.T, contents ...string) service.PromptService {
+	t.Helper()
+	promptService := service.NewPromptService(infra.NewFileSystemStore(filepath.Join(t.TempDir(), "vault")), validator.NewYAMLValidator())
+	for _, content := range contents {
+		if _, err := promptService.AddFromContent(context.Background(), content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return promptService
+}
+
+func TestExportCommand(t *testing.T) {
+	promptService := newVaultService(t, "name: Review\nauthor: alice\n---\nReview {code}\n")
+	path := filepath.Join(t.TempDir(), "vault.tar.gz")
+
+	cmd := NewExportCommand(promptService)
+	var out, progress bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&progress)
+	cmd.SetArgs([]string{"--archive", path})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "已导出 1 个提示词和 0 个分享记录") || !strings.Contains(progress.String(), "1/1: Review") {
+		t.Errorf("Unexpected output %q, progress %q", out.String(), progress.String())
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	a, err := archive.Read(file)
+	if err != nil || len(a.Index.Prompts) != 1 || a.Index.Prompts[0].Name != "Review" {
+		t.Errorf("Expected an archive holding the prompt, got %+v, %v", a, err)
+	}
+
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("Expected no temporary file to be left behind, got %v", entries)
+	}
+}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/spf13/cobra"
@@ -9,6 +10,7 @@ import (
 	"github.com/grigri/pv/internal/model"
 	"github.com/grigri/pv/internal/service"
 	"github.com/grigri/pv/internal/tui"
+	"github.com/grigri/pv/internal/utils"
 	"github.com/grigri/pv/internal/variable"
 )
 
@@ -35,15 +37,18 @@ func (g *get) execute(cmd *cobra.Command, args []string) {
 		fmt.Println("Examples:")
 		fmt.Println("  pv get                       # 显示所有提示供选择")
 		fmt.Println("  pv get golang                # 筛选包含 'golang' 的提示")
-		fmt.Println("  pv get https://gist.github.com/user/abc123")
+		fmt.Printf("  pv get %s/user/abc123\n", utils.CurrentGitHubEndpoints().GistBaseURL())
 		return
 	}
 
+	ctx, cancel := commandContext(cmd)
+	defer cancel()
+
 	// Route to appropriate mode based on arguments
 	switch len(args) {
 	case 0:
 		// 交互式模式 - 显示所有提示供用户选择
-		g.handleInteractiveMode()
+		g.handleInteractiveMode(ctx)
 	case 1:
 		arg := args[0]
 		// Check if it looks like a URL first
@@ -51,14 +56,14 @@ func (g *get) execute(cmd *cobra.Command, args []string) {
 			// It's a URL-like string, check if it's a valid Gist URL
 			if g.isGistURL(arg) {
 				// Valid Gist URL - direct get mode
-				g.handleDirectMode(arg)
+				g.handleDirectMode(ctx, arg)
 			} else {
 				// Invalid Gist URL - show error
 				g.handleInvalidURL(arg)
 			}
 		} else {
 			// Not URL-like - keyword filtering mode
-			g.handleFilterMode(arg)
+			g.handleFilterMode(ctx, arg)
 		}
 	}
 }
@@ -70,8 +75,8 @@ func (g *get) isGistURL(input string) bool {
 		return false
 	}
 	
-	// Check if it contains gist.github.com
-	if !contains(input, "gist.github.com") {
+	// Check if it points at the configured gist host (gist.github.com by default)
+	if !utils.IsGistURLHost(input) {
 		return false
 	}
 	
@@ -90,11 +95,11 @@ func (g *get) looksLikeURL(input string) bool {
 }
 
 // handleInteractiveMode handles the interactive get mode (no arguments)
-func (g *get) handleInteractiveMode() {
+func (g *get) handleInteractiveMode(ctx context.Context) {
 	fmt.Println("🔄 Interactive mode - loading all prompts...")
 	
 	// Step 1: Call promptService.ListPrompts() to get all prompts
-	prompts, err := g.promptService.ListPrompts()
+	prompts, err := g.promptService.ListPrompts(ctx)
 	if err != nil {
 		fmt.Printf("❌ Error loading prompts: %v\n", err)
 		return
@@ -132,15 +137,15 @@ func (g *get) handleInteractiveMode() {
 	fmt.Println()
 	
 	// Step 4: Process the selected prompt (get content, handle variables, copy to clipboard)
-	g.processSelectedPrompt(selectedPrompt)
+	g.processSelectedPrompt(ctx, selectedPrompt)
 }
 
 // handleFilterMode handles the keyword filtering get mode
-func (g *get) handleFilterMode(keyword string) {
+func (g *get) handleFilterMode(ctx context.Context, keyword string) {
 	fmt.Printf("🔄 Filter mode - searching for prompts matching '%s'...\n", keyword)
 	
 	// Step 1: Call promptService.FilterPrompts(keyword) to filter prompts
-	filteredPrompts, err := g.promptService.FilterPrompts(keyword)
+	filteredPrompts, err := g.promptService.FilterPrompts(ctx, keyword)
 	if err != nil {
 		fmt.Printf("❌ Error filtering prompts: %v\n", err)
 		fmt.Println()
@@ -201,28 +206,29 @@ func (g *get) handleFilterMode(keyword string) {
 	fmt.Println()
 	
 	// Step 5: Process the selected prompt
-	g.processSelectedPrompt(selectedPrompt)
+	g.processSelectedPrompt(ctx, selectedPrompt)
 }
 
 // handleDirectMode handles the direct URL get mode
-func (g *get) handleDirectMode(gistURL string) {
+func (g *get) handleDirectMode(ctx context.Context, gistURL string) {
 	fmt.Printf("🔄 Direct mode - processing URL: %s\n", gistURL)
 	
 	// Step 1: Validate and parse the GitHub Gist URL
 	if !g.isGistURL(gistURL) {
+		gistBase := utils.CurrentGitHubEndpoints().GistBaseURL()
 		fmt.Println("❌ Error: Invalid GitHub Gist URL format")
 		fmt.Println()
 		fmt.Println("Valid URL formats:")
-		fmt.Println("  https://gist.github.com/username/gist-id")
-		fmt.Println("  https://gist.github.com/gist-id")
+		fmt.Printf("  %s/username/gist-id\n", gistBase)
+		fmt.Printf("  %s/gist-id\n", gistBase)
 		fmt.Println()
 		fmt.Println("Example:")
-		fmt.Println("  pv get https://gist.github.com/user/1234567890abcdef")
+		fmt.Printf("  pv get %s/user/1234567890abcdef\n", gistBase)
 		return
 	}
 	
 	// Step 2: Get the prompt directly by URL
-	prompt, err := g.promptService.GetPromptByURL(gistURL)
+	prompt, err := g.promptService.GetPromptByURL(ctx, gistURL)
 	if err != nil {
 		fmt.Printf("❌ Error getting prompt: %v\n", err)
 		fmt.Println()
@@ -242,7 +248,7 @@ func (g *get) handleDirectMode(gistURL string) {
 	fmt.Println()
 	
 	// Step 3: Process the prompt directly
-	g.processSelectedPrompt(*prompt)
+	g.processSelectedPrompt(ctx, *prompt)
 }
 
 // handleInvalidURL handles invalid URL input and shows helpful error messages
@@ -250,19 +256,22 @@ func (g *get) handleInvalidURL(invalidURL string) {
 	fmt.Printf("❌ Error: Invalid GitHub Gist URL format: %s\n", invalidURL)
 	fmt.Println()
 	
+	// Examples use the configured gist host (gist.github.com or GitHub Enterprise)
+	gistBase := utils.CurrentGitHubEndpoints().GistBaseURL()
+	
 	// Provide specific guidance based on the URL pattern
-	if contains(invalidURL, "gist.github.com") {
-		fmt.Println("The URL contains 'gist.github.com' but doesn't match the expected format.")
+	if utils.IsGistURLHost(invalidURL) {
+		fmt.Println("The URL points at the gist host but doesn't match the expected format.")
 		fmt.Println()
 		fmt.Println("Valid GitHub Gist URL formats:")
-		fmt.Println("  https://gist.github.com/username/gist-id")
-		fmt.Println("  https://gist.github.com/gist-id")
+		fmt.Printf("  %s/username/gist-id\n", gistBase)
+		fmt.Printf("  %s/gist-id\n", gistBase)
 		fmt.Println()
 		fmt.Println("Where gist-id is a 20 or 32 character hexadecimal string.")
 		fmt.Println()
 		fmt.Println("Examples:")
-		fmt.Println("  https://gist.github.com/user/1234567890abcdef1234567890abcdef")
-		fmt.Println("  https://gist.github.com/abcdef1234567890abcd")
+		fmt.Printf("  %s/user/1234567890abcdef1234567890abcdef\n", gistBase)
+		fmt.Printf("  %s/abcdef1234567890abcd\n", gistBase)
 	} else {
 		fmt.Println("This appears to be a URL but not a GitHub Gist URL.")
 		fmt.Println()
@@ -270,16 +279,16 @@ func (g *get) handleInvalidURL(invalidURL string) {
 		fmt.Printf("  pv get \"%s\"\n", invalidURL)
 		fmt.Println()
 		fmt.Println("For GitHub Gist URLs, use the format:")
-		fmt.Println("  https://gist.github.com/username/gist-id")
+		fmt.Printf("  %s/username/gist-id\n", gistBase)
 	}
 }
 
 // processSelectedPrompt handles the core processing workflow
-func (g *get) processSelectedPrompt(prompt model.Prompt) {
+func (g *get) processSelectedPrompt(ctx context.Context, prompt model.Prompt) {
 	fmt.Printf("🔄 Processing prompt: %s...\n", prompt.Name)
 	
 	// Step 1: Get prompt content
-	content, err := g.promptService.GetPromptContent(&prompt)
+	content, err := g.promptService.GetPromptContent(ctx, &prompt)
 	if err != nil {
 		fmt.Printf("❌ Failed to get prompt content: %v\n", err)
 		fmt.Println()
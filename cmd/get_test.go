@@ -2,14 +2,18 @@ package cmd
 
 import (
 	"bytes"
+	"context"
 	"io"
 	"os"
 	"strings"
 	"testing"
 
+	"github.com/grigri/pv/internal/archive"
 	"github.com/grigri/pv/internal/errors"
+	"github.com/grigri/pv/internal/infra"
 	"github.com/grigri/pv/internal/model"
 	"github.com/grigri/pv/internal/service"
+	"github.com/grigri/pv/internal/utils"
 )
 
 func TestGet_LooksLikeURL(t *testing.T) {
@@ -112,6 +116,49 @@ func TestGet_IsGistURL(t *testing.T) {
 	}
 }
 
+func TestGet_IsGistURL_Enterprise(t *testing.T) {
+	endpoints, err := utils.NewGitHubEndpoints("https://github.example.com", "", "")
+	if err != nil {
+		t.Fatalf("NewGitHubEndpoints failed: %v", err)
+	}
+	utils.SetGitHubEndpoints(endpoints)
+	t.Cleanup(func() { utils.SetGitHubEndpoints(utils.GitHubEndpoints{GistHosts: []string{utils.DefaultGistHost}}) })
+
+	g := &get{}
+
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"https://github.example.com/gist/user/1234567890abcdef1234567890abcdef", true},
+		{"https://gist.github.example.com/user/1234567890abcdef1234567890abcdef", true},
+		{"https://gist.github.com/user/1234567890abcdef1234567890abcdef", false},
+		{"https://other.example.com/gist/user/1234567890abcdef1234567890abcdef", false},
+	}
+
+	for _, tt := range tests {
+		if result := g.isGistURL(tt.input); result != tt.expected {
+			t.Errorf("isGistURL(%q) = %v, want %v", tt.input, result, tt.expected)
+		}
+	}
+
+	gistID, err := utils.ExtractGistID("https://github.example.com/gist/user/1234567890abcdef1234567890abcdef")
+	if err != nil || gistID != "1234567890abcdef1234567890abcdef" {
+		t.Errorf("ExtractGistID = %q, %v", gistID, err)
+	}
+	if _, err := utils.ExtractGistID("https://github.com/user/1234567890abcdef1234567890abcdef"); err == nil {
+		t.Error("Expected github.com gist URL to be rejected on an enterprise host")
+	}
+
+	// The help shown for invalid URLs uses the enterprise gist host
+	for _, invalid := range []string{"https://github.example.com/gist/user/not-a-gist!", "https://other.example.com/page"} {
+		output := captureOutput(func() { g.handleInvalidURL(invalid) })
+		if !strings.Contains(output, "https://github.example.com/gist/username/gist-id") || strings.Contains(output, "gist.github.com") {
+			t.Errorf("Expected the enterprise gist host in the help for %s, got %q", invalid, output)
+		}
+	}
+}
+
 func TestContains(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -358,64 +405,166 @@ type MockPromptServiceForGet struct {
 	getPromptContentCalls []*model.Prompt
 }
 
-func (m *MockPromptServiceForGet) AddFromFile(filePath string) (*model.Prompt, error) {
+func (m *MockPromptServiceForGet) AddFromFile(ctx context.Context, filePath string) (*model.Prompt, error) {
 	return nil, errors.NewAppError(errors.ErrValidation, "not implemented", nil)
 }
 
-func (m *MockPromptServiceForGet) DeleteByKeyword(keyword string) error {
+func (m *MockPromptServiceForGet) DeleteByKeyword(ctx context.Context, keyword string) error {
 	return errors.NewAppError(errors.ErrValidation, "not implemented", nil)
 }
 
-func (m *MockPromptServiceForGet) DeleteByURL(gistURL string) error {
+func (m *MockPromptServiceForGet) DeleteByURL(ctx context.Context, gistURL string) error {
 	return errors.NewAppError(errors.ErrValidation, "not implemented", nil)
 }
 
-func (m *MockPromptServiceForGet) ListPrompts() ([]model.Prompt, error) {
+func (m *MockPromptServiceForGet) ListPrompts(ctx context.Context) ([]model.Prompt, error) {
 	m.listPromptsCalls++
 	return m.listPromptsResult, m.listPromptsError
 }
 
-func (m *MockPromptServiceForGet) FilterPrompts(keyword string) ([]model.Prompt, error) {
+func (m *MockPromptServiceForGet) FilterPrompts(ctx context.Context, keyword string) ([]model.Prompt, error) {
 	m.filterPromptsCalls = append(m.filterPromptsCalls, keyword)
 	return m.filterPromptsResult, m.filterPromptsError
 }
 
-func (m *MockPromptServiceForGet) GetPromptByURL(gistURL string) (*model.Prompt, error) {
+func (m *MockPromptServiceForGet) GetPromptByURL(ctx context.Context, gistURL string) (*model.Prompt, error) {
 	m.getPromptByURLCalls = append(m.getPromptByURLCalls, gistURL)
 	return m.getPromptByURLResult, m.getPromptByURLError
 }
 
-func (m *MockPromptServiceForGet) GetPromptContent(prompt *model.Prompt) (string, error) {
+func (m *MockPromptServiceForGet) GetPromptContent(ctx context.Context, prompt *model.Prompt) (string, error) {
 	m.getPromptContentCalls = append(m.getPromptContentCalls, prompt)
 	return m.getPromptContentResult, m.getPromptContentError
 }
 
-func (m *MockPromptServiceForGet) AddFromURL(gistURL string) (*model.Prompt, error) {
+func (m *MockPromptServiceForGet) AddFromURL(ctx context.Context, gistURL string) (*model.Prompt, error) {
 	return nil, errors.NewAppError(errors.ErrValidation, "not implemented", nil)
 }
 
-func (m *MockPromptServiceForGet) FilterPrivatePrompts(keyword string) ([]model.Prompt, error) {
+func (m *MockPromptServiceForGet) FilterPrivatePrompts(ctx context.Context, keyword string) ([]model.Prompt, error) {
 	return nil, errors.NewAppError(errors.ErrValidation, "not implemented", nil)
 }
 
-func (m *MockPromptServiceForGet) SharePrompt(prompt *model.Prompt) (*model.Prompt, error) {
+func (m *MockPromptServiceForGet) SharePrompt(ctx context.Context, prompt *model.Prompt) (*model.Prompt, error) {
 	return nil, errors.NewAppError(errors.ErrValidation, "not implemented", nil)
 }
 
-func (m *MockPromptServiceForGet) ValidateGistAccess(gistURL string) (*service.GistInfo, error) {
+func (m *MockPromptServiceForGet) ValidateGistAccess(ctx context.Context, gistURL string) (*service.GistInfo, error) {
 	return &service.GistInfo{}, nil
 }
 
-func (m *MockPromptServiceForGet) ListPrivatePrompts() ([]model.Prompt, error) {
+func (m *MockPromptServiceForGet) ListPrivatePrompts(ctx context.Context) ([]model.Prompt, error) {
 	return nil, errors.NewAppError(errors.ErrValidation, "not implemented", nil)
 }
 
 // Sync implements the PromptService interface for testing
-func (m *MockPromptServiceForGet) Sync() error {
+func (m *MockPromptServiceForGet) Sync(ctx context.Context) (*infra.SyncPlan, error) {
+	// This method is not used by get command but required by interface
+	return &infra.SyncPlan{}, nil
+}
+
+// FetchPromptContents implements the PromptService interface for testing
+func (m *MockPromptServiceForGet) FetchPromptContents(ctx context.Context, prompts []model.Prompt, progress service.ContentProgress) error {
 	// This method is not used by get command but required by interface
 	return nil
 }
 
+// ReplayOfflineChanges implements the PromptService interface for testing
+func (m *MockPromptServiceForGet) ReplayOfflineChanges(ctx context.Context) (*infra.ReplayReport, error) {
+	// This method is not used by get command but required by interface
+	return &infra.ReplayReport{}, nil
+}
+
+// GetPromptHistory implements the PromptService interface for testing
+func (m *MockPromptServiceForGet) GetPromptHistory(ctx context.Context, prompt *model.Prompt) ([]model.Revision, error) {
+	// This method is not used by get command but required by interface
+	return nil, nil
+}
+
+// GetPromptRevision implements the PromptService interface for testing
+func (m *MockPromptServiceForGet) GetPromptRevision(ctx context.Context, prompt *model.Prompt, sha string) (string, error) {
+	// This method is not used by get command but required by interface
+	return "", nil
+}
+
+// DiffRevisions implements the PromptService interface for testing
+func (m *MockPromptServiceForGet) DiffRevisions(ctx context.Context, prompt *model.Prompt, from, to string) (*service.PromptDiff, error) {
+	// This method is not used by get command but required by interface
+	return nil, nil
+}
+
+// DiffWithFile implements the PromptService interface for testing
+func (m *MockPromptServiceForGet) DiffWithFile(ctx context.Context, prompt *model.Prompt, filePath string) (*service.PromptDiff, error) {
+	// This method is not used by get command but required by interface
+	return nil, nil
+}
+
+// DiffWithExport implements the PromptService interface for testing
+func (m *MockPromptServiceForGet) DiffWithExport(ctx context.Context, prompt *model.Prompt) (*service.PromptDiff, error) {
+	// This method is not used by get command but required by interface
+	return nil, nil
+}
+
+// AddFromContent implements the PromptService interface for testing
+func (m *MockPromptServiceForGet) AddFromContent(ctx context.Context, content string) (*model.Prompt, error) {
+	// This method is not used by get command but required by interface
+	return nil, nil
+}
+
+// ValidatePromptContent implements the PromptService interface for testing
+func (m *MockPromptServiceForGet) ValidatePromptContent(content string) (*model.Prompt, error) {
+	// This method is not used by get command but required by interface
+	return nil, nil
+}
+
+// PlanAddFromFiles implements the PromptService interface for testing
+func (m *MockPromptServiceForGet) PlanAddFromFiles(ctx context.Context, paths []string, recursive bool) (*service.AddPlan, error) {
+	// This method is not used by get command but required by interface
+	return &service.AddPlan{}, nil
+}
+
+// PlanAddFromContents implements the PromptService interface for testing
+func (m *MockPromptServiceForGet) PlanAddFromContents(ctx context.Context, sources []service.PromptSource) (*service.AddPlan, error) {
+	// This method is not used by get command but required by interface
+	return &service.AddPlan{}, nil
+}
+
+// ExportArchive implements the PromptService interface for testing
+func (m *MockPromptServiceForGet) ExportArchive(ctx context.Context, progress service.ContentProgress) (*archive.Archive, error) {
+	// This method is not used by get command but required by interface
+	return &archive.Archive{}, nil
+}
+
+// RestoreArchive implements the PromptService interface for testing
+func (m *MockPromptServiceForGet) RestoreArchive(ctx context.Context, a *archive.Archive) (*service.RestoreReport, error) {
+	// This method is not used by get command but required by interface
+	return &service.RestoreReport{}, nil
+}
+
+// ApplyAddPlan implements the PromptService interface for testing
+func (m *MockPromptServiceForGet) ApplyAddPlan(ctx context.Context, plan *service.AddPlan) ([]model.Prompt, error) {
+	// This method is not used by get command but required by interface
+	return nil, nil
+}
+
+// UpdatePromptContent implements the PromptService interface for testing
+func (m *MockPromptServiceForGet) UpdatePromptContent(ctx context.Context, prompt *model.Prompt, content string) (*model.Prompt, error) {
+	// This method is not used by get command but required by interface
+	return nil, nil
+}
+
+// PreviewRollback implements the PromptService interface for testing
+func (m *MockPromptServiceForGet) PreviewRollback(ctx context.Context, prompt *model.Prompt, sha string) (*service.PromptDiff, error) {
+	// This method is not used by get command but required by interface
+	return nil, nil
+}
+
+// RollbackPrompt implements the PromptService interface for testing
+func (m *MockPromptServiceForGet) RollbackPrompt(ctx context.Context, prompt *model.Prompt, sha string) (*service.RollbackResult, error) {
+	// This method is not used by get command but required by interface
+	return nil, nil
+}
+
 // MockClipboardUtil implements clipboard.Util for testing
 type MockClipboardUtil struct {
 	isAvailable bool
@@ -491,6 +640,10 @@ func (m *MockTUIInterface) ShowVariableForm(variables []string) (map[string]stri
 	return m.showVariableFormResult, m.showVariableFormError
 }
 
+func (m *MockTUIInterface) ShowPromptForm(defaults model.Prompt) (model.Prompt, error) {
+	return defaults, nil
+}
+
 func (m *MockTUIInterface) ShowConfirm(prompt model.Prompt) (bool, error) {
 	m.showConfirmCalls = append(m.showConfirmCalls, prompt.Name)
 	return m.showConfirmResult, m.showConfirmError
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/grigri/pv/internal/model"
+	"github.com/grigri/pv/internal/service"
+	"github.com/grigri/pv/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+// HistoryCmd represents the history command
+type HistoryCmd struct {
+	promptService service.PromptService
+	tui           tui.TUIInterface
+}
+
+// NewHistoryCommand creates a new history command
+func NewHistoryCommand(promptService service.PromptService, tui tui.TUIInterface) *cobra.Command {
+	historyCmd := &HistoryCmd{
+		promptService: promptService,
+		tui:           tui,
+	}
+
+	return &cobra.Command{
+		Use:   "history <keyword|gist_url>",
+		Short: "查看提示词的修订历史",
+		Long: `列出提示词所在 Gist 的修订历史，包括修订时间、YAML 中的 version 字段和变更摘要。
+
+git 存储后端以修改过该提示词目录的提交作为修订；其他不保存历史的后端会报错。
+修订内容会缓存在本地，离线时仍可浏览已查看过的历史。
+使用 pv show <gist_url> --rev <sha> 查看某个修订的完整内容。`,
+		Example: `  pv history "code review"
+  pv history https://gist.github.com/user/gist_id`,
+		Args: cobra.ExactArgs(1),
+		RunE: historyCmd.run,
+	}
+}
+
+// run 执行 history 命令的主要逻辑
+func (h *HistoryCmd) run(cmd *cobra.Command, args []string) error {
+	ctx, cancel := commandContext(cmd)
+	defer cancel()
+
+	prompt, err := selectPrompt(ctx, h.promptService, h.tui, args[0])
+	if err != nil {
+		return err
+	}
+	if prompt == nil {
+		fmt.Fprintln(cmd.OutOrStdout(), "取消查看历史")
+		return nil
+	}
+
+	revisions, err := h.promptService.GetPromptHistory(ctx, prompt)
+	if err != nil {
+		return fmt.Errorf("获取修订历史失败: %w", err)
+	}
+
+	printRevisions(cmd.OutOrStdout(), prompt, revisions)
+	return nil
+}
+
+// printRevisions 按从新到旧的顺序打印修订列表
+func printRevisions(out io.Writer, prompt *model.Prompt, revisions []model.Revision) {
+	fmt.Fprintf(out, "📜 '%s' 的修订历史（%d 个修订）:\n\n", prompt.Name, len(revisions))
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	for i, revision := range revisions {
+		version := "-"
+		if revision.Version != "" {
+			version = revision.Version
+		}
+
+		// revisions 从新到旧排列，下一个元素是上一个修订
+		var previous *model.Revision
+		if i+1 < len(revisions) {
+			previous = &revisions[i+1]
+		}
+
+		fmt.Fprintf(w, "  %s\t%s\t%s\t%s\n",
+			revision.ShortSHA(),
+			revision.CommittedAt.Local().Format("2006-01-02 15:04"),
+			version,
+			formatRevisionChange(revision, previous))
+	}
+	w.Flush()
+
+	fmt.Fprintf(out, "\n使用 pv show %s --rev <sha> 查看某个修订的内容\n", prompt.GistURL)
+}
+
+// formatRevisionChange 生成修订的变更摘要，previous 为 nil 表示这是最早的修订
+func formatRevisionChange(revision model.Revision, previous *model.Revision) string {
+	if previous == nil {
+		return "创建"
+	}
+
+	summary := fmt.Sprintf("+%d -%d", revision.Additions, revision.Deletions)
+	if revision.Version != "" && previous.Version != "" && revision.Version != previous.Version {
+		summary += fmt.Sprintf("  version %s → %s", previous.Version, revision.Version)
+	}
+	return summary
+}
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grigri/pv/internal/model"
+)
+
+func TestPrintRevisions(t *testing.T) {
+	prompt := &model.Prompt{Name: "Review", GistURL: "https://gist.github.com/alice/0123456789abcdef0123456789abcdef"}
+	revisions := []model.Revision{
+		{SHA: "b2c3d4e5f60718293a4b5c6d7e8f9a0b1c2d3e4f", CommittedAt: time.Now(), Additions: 3, Deletions: 1, Version: "1.1"},
+		{SHA: "a1b2c3d4e5f60718293a4b5c6d7e8f9a0b1c2d3e", CommittedAt: time.Now().Add(-time.Hour), Additions: 5, Version: "1.0"},
+	}
+
+	var out bytes.Buffer
+	printRevisions(&out, prompt, revisions)
+	lines := strings.Split(out.String(), "\n")
+
+	if !strings.Contains(lines[0], "2 个修订") {
+		t.Errorf("Expected a header with the revision count, got %q", lines[0])
+	}
+	if got := lines[2]; !strings.Contains(got, "b2c3d4e ") || !strings.Contains(got, "+3 -1") || !strings.Contains(got, "version 1.0 → 1.1") {
+		t.Errorf("Expected the newest revision with its change summary, got %q", got)
+	}
+	if got := lines[3]; !strings.Contains(got, "a1b2c3d ") || !strings.Contains(got, "创建") {
+		t.Errorf("Expected the oldest revision to be the creation, got %q", got)
+	}
+	if !strings.Contains(out.String(), "pv show "+prompt.GistURL+" --rev") {
+		t.Errorf("Expected a hint to show a revision, got %q", out.String())
+	}
+}
@@ -0,0 +1,193 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	apperrors "github.com/grigri/pv/internal/errors"
+	"github.com/grigri/pv/internal/importer"
+	"github.com/grigri/pv/internal/scaffold"
+	"github.com/grigri/pv/internal/service"
+	"github.com/spf13/cobra"
+)
+
+// ImportCmd represents the import command
+type ImportCmd struct {
+	promptService service.PromptService
+	authService   service.AuthService
+
+	from   string
+	author string
+	dryRun bool
+}
+
+// NewImportCommand creates a new import command
+func NewImportCommand(promptService service.PromptService, authService service.AuthService) *cobra.Command {
+	importCmd := &ImportCmd{
+		promptService: promptService,
+		authService:   authService,
+	}
+	return importCmd.command()
+}
+
+// command builds the cobra command running this import command
+func (i *ImportCmd) command() *cobra.Command {
+	var formats strings.Builder
+	for _, converter := range importer.Converters() {
+		fmt.Fprintf(&formats, "  %-10s %s\n", converter.Name(), converter.Description())
+	}
+
+	cmd := &cobra.Command{
+		Use:   "import --from <format> <file>...",
+		Short: "从其他提示词工具的文件导入提示词",
+		Long: `把其他提示词工具的文件转换为 pv 提示词并添加到 vault。
+
+支持的格式:
+` + formats.String() + `
+{{ 变量 }} 等占位符会转换为 pv 的 {变量}；无法转换的模板语法（如 {% if %}）会被报告。
+转换结果与 pv add 一样经过验证，并逐项报告结果；只要有一项失败就不会导入任何提示词。
+来源文件没有作者信息时，author 使用 --author，默认为当前登录的 GitHub 用户名。
+name 和 author 都与已有提示词相同时更新该提示词。`,
+		Example: `  pv import --from openai chat.json
+  pv import --from langchain templates/*.json --dry-run
+  pv import --from promptfoo promptfooconfig.yaml --author alice
+  pv import --from csv prompts.csv`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: i.run,
+	}
+
+	cmd.Flags().StringVar(&i.from, "from", "", "来源格式: "+strings.Join(importer.Names(), ", "))
+	cmd.Flags().StringVar(&i.author, "author", "", "来源文件没有作者时使用的 author，默认为当前登录用户")
+	cmd.Flags().BoolVar(&i.dryRun, "dry-run", false, "只转换、验证并显示报告，不导入任何提示词")
+	_ = cmd.MarkFlagRequired("from")
+	return cmd
+}
+
+// run 执行 import 命令的主要逻辑
+func (i *ImportCmd) run(cmd *cobra.Command, args []string) error {
+	ctx, cancel := commandContext(cmd)
+	defer cancel()
+	out := cmd.OutOrStdout()
+
+	converter, ok := importer.Find(i.from)
+	if !ok {
+		return fmt.Errorf("不支持的格式 '%s'，可用的格式: %s", i.from, strings.Join(importer.Names(), ", "))
+	}
+
+	author := i.author
+	if author == "" {
+		if status, err := i.authService.GetStatus(ctx); err == nil && status.IsAuthenticated {
+			author = status.Username
+		}
+	}
+
+	// order 按文件和条目的顺序记录来源，报告按此顺序输出
+	var order []string
+	var sources []service.PromptSource
+	var failures []service.AddFailure
+	for _, path := range args {
+		imported, err := convertFile(converter, path)
+		if err != nil {
+			order = append(order, path)
+			failures = append(failures, service.AddFailure{Path: path, Err: err})
+			continue
+		}
+		for _, entry := range imported {
+			order = append(order, entry.Source)
+			source, err := promptSource(entry, author)
+			if err != nil {
+				failures = append(failures, service.AddFailure{Path: entry.Source, Err: err})
+				continue
+			}
+			sources = append(sources, source)
+		}
+	}
+
+	plan, err := i.promptService.PlanAddFromContents(ctx, sources)
+	if err != nil {
+		return err
+	}
+	plan.Failures = append(failures, plan.Failures...)
+
+	printImportReport(out, order, plan)
+	if len(plan.Failures) > 0 {
+		return apperrors.ErrAddPlanHasFailures
+	}
+	if len(plan.Adds) == 0 {
+		return nil
+	}
+	fmt.Fprintf(out, "\n将新建 %d 个、更新 %d 个提示词\n", plan.Count(service.AddCreate), plan.Count(service.AddUpdate))
+	if i.dryRun {
+		fmt.Fprintln(out, "dry run：没有做任何修改")
+		return nil
+	}
+
+	stored, err := i.promptService.ApplyAddPlan(ctx, plan)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "✅ 已导入 %d 个提示词\n", len(stored))
+	return nil
+}
+
+// convertFile 读取并转换一个文件
+func convertFile(converter importer.Converter, path string) ([]importer.Imported, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	imported, err := converter.Convert(path, content)
+	if err == nil && len(imported) == 0 {
+		err = fmt.Errorf("没有找到提示词")
+	}
+	return imported, err
+}
+
+// promptSource 把转换出的提示词写成提示词文件，交给 PlanAddFromContents 验证
+func promptSource(entry importer.Imported, author string) (service.PromptSource, error) {
+	if entry.Err != nil {
+		return service.PromptSource{}, entry.Err
+	}
+
+	prompt := entry.Prompt
+	if prompt.Author == "" {
+		prompt.Author = author
+	}
+	body := prompt.Content
+	prompt.Content = ""
+
+	content, err := scaffold.Compose(prompt, body+"\n")
+	if err != nil {
+		return service.PromptSource{}, err
+	}
+	return service.PromptSource{Path: entry.Source, Content: content}, nil
+}
+
+// printImportReport 按来源顺序逐项输出转换和验证的结果
+func printImportReport(out io.Writer, order []string, plan *service.AddPlan) {
+	adds := map[string]service.PlannedAdd{}
+	for _, add := range plan.Adds {
+		adds[add.Path] = add
+	}
+	failed := map[string]error{}
+	for _, failure := range plan.Failures {
+		failed[failure.Path] = failure.Err
+	}
+
+	fmt.Fprintln(out, "导入报告:")
+	for _, source := range order {
+		if err, ok := failed[source]; ok {
+			fmt.Fprintf(out, "  ✗ %s: %v\n", source, err)
+			continue
+		}
+		if add, ok := adds[source]; ok {
+			marker := "+"
+			if add.Action == service.AddUpdate {
+				marker = "~"
+			}
+			fmt.Fprintf(out, "  ✓ %s → %s %s (%s)\n", source, marker, add.Prompt.Name, add.Prompt.Author)
+		}
+	}
+}
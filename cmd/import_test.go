@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/grigri/pv/internal/service"
+	"github.com/grigri/pv/internal/validator"
+)
+
+func TestImportCommand(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	run := func(t *testing.T, args ...string) (*MockStore, string, error) {
+		t.Helper()
+		store := NewMockStore()
+		importCmd := &ImportCmd{
+			promptService: service.NewPromptService(store, validator.NewYAMLValidator()),
+			authService:   &newAuthService{status: &service.AuthStatus{IsAuthenticated: true, Username: "alice"}},
+		}
+		cmd := importCmd.command()
+
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetErr(&out)
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+		return store, out.String(), err
+	}
+
+	t.Run("csv", func(t *testing.T) {
+		path := write("prompts.csv", "name,author,body\nReview,,Review {{code}}\nSummary,bob,Summarize {{ text }}\n")
+		store, out, err := run(t, "--from", "csv", path)
+		if err != nil {
+			t.Fatalf("import failed: %v\n%s", err, out)
+		}
+		if !strings.Contains(out, "✓ "+path+":2 → + Review (alice)") || !strings.Contains(out, "✓ "+path+":3 → + Summary (bob)") {
+			t.Errorf("Expected a line per row in the report, got %q", out)
+		}
+		if len(store.prompts) != 2 || !strings.HasSuffix(store.prompts[0].Content, "---\nReview {code}\n") {
+			t.Errorf("Expected the converted prompts to be stored, got %+v", store.prompts)
+		}
+		if !strings.Contains(out, "已导入 2 个提示词") {
+			t.Errorf("Expected a summary, got %q", out)
+		}
+	})
+
+	t.Run("failures import nothing", func(t *testing.T) {
+		good := write("good.j2", "Review {{ code }}")
+		bad := write("loop.j2", "{% for x in xs %}{{ x }}{% endfor %}")
+		store, out, err := run(t, "--from", "jinja", good, bad, filepath.Join(dir, "missing.j2"), "--author", "bob")
+		if err == nil {
+			t.Fatal("Expected the import to fail")
+		}
+		if !strings.Contains(out, "✓ "+good+" → + good (bob)") || !strings.Contains(out, "✗ "+bad+": 无法转换模板语法") || !strings.Contains(out, "missing.j2: open") {
+			t.Errorf("Expected every file in the report, got %q", out)
+		}
+		if len(store.prompts) != 0 {
+			t.Errorf("Expected nothing to be stored, got %+v", store.prompts)
+		}
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		path := write("unnamed.csv", "name,body\n,Review {{code}}\n")
+		store, out, err := run(t, "--from", "csv", path)
+		if err == nil || !strings.Contains(out, "✗ "+path+":2: invalid prompt metadata") || len(store.prompts) != 0 {
+			t.Errorf("Expected the row to fail validation, got %q, %v", out, err)
+		}
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		path := write("chat.json", `[{"role": "user", "content": "Explain {{topic}}"}]`)
+		store, out, err := run(t, "--from", "openai", path, "--dry-run")
+		if err != nil || len(store.prompts) != 0 || !strings.Contains(out, "dry run") {
+			t.Errorf("Expected nothing to be stored, got %q, %v", out, err)
+		}
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		_, _, err := run(t, "--from", "markdown", "notes.md")
+		if err == nil || !strings.Contains(err.Error(), "csv, jinja, langchain, openai, promptfoo") {
+			t.Errorf("Expected the supported formats to be listed, got %v", err)
+		}
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -66,6 +67,24 @@ func formatPromptWithExport(prompt model.Prompt, exportMap map[string]ExportStat
 		prompt.Name, prompt.Author, prompt.GistURL, exportInfo)
 }
 
+// formatPromptDetails 格式化索引中记录的描述、标签和版本，没有元数据时返回空字符串
+func formatPromptDetails(prompt model.Prompt) string {
+	var details []string
+	if prompt.Description != "" {
+		details = append(details, prompt.Description)
+	}
+	if len(prompt.Tags) > 0 {
+		details = append(details, fmt.Sprintf("[tags: %s]", strings.Join(prompt.Tags, ", ")))
+	}
+	if prompt.Version != "" {
+		details = append(details, "v"+strings.TrimPrefix(prompt.Version, "v"))
+	}
+	if len(details) == 0 {
+		return ""
+	}
+	return "    " + strings.Join(details, " ")
+}
+
 func (lc *list) execute(cmd *cobra.Command, args []string) {
 	// Create appropriate store based on --remote flag
 	var store infra.Store
@@ -87,7 +106,10 @@ func (lc *list) execute(cmd *cobra.Command, args []string) {
 		}
 	}
 
-	var prompts, err = store.List()
+	ctx, cancel := commandContext(cmd)
+	defer cancel()
+
+	var prompts, err = store.List(ctx)
 	if err != nil {
 		// Handle friendly error messages for empty/missing index
 		if errors.Is(err, infra.ErrNoIndex) {
@@ -126,7 +148,7 @@ func (lc *list) execute(cmd *cobra.Command, args []string) {
 	var exports []model.IndexedPrompt
 	var exportMap map[string]ExportStatus
 
-	if exports, err = store.GetExports(); err == nil {
+	if exports, err = store.GetExports(ctx); err == nil {
 		exportMap = buildExportMap(exports)
 	} else {
 		// 优雅降级：继续显示但不包含导出信息
@@ -137,6 +159,9 @@ func (lc *list) execute(cmd *cobra.Command, args []string) {
 	for i := range prompts {
 		var prompt = prompts[i]
 		fmt.Printf("%s\n", formatPromptWithExport(prompt, exportMap))
+		if details := formatPromptDetails(prompt); details != "" {
+			fmt.Printf("%s\n", details)
+		}
 	}
 
 	// Display cache information when using cached data (requirement 5.5)
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"strings"
 	"testing"
 	"time"
@@ -34,14 +35,14 @@ func NewMockStoreForList(prompts []model.Prompt, listError error) *MockStoreForL
 	}
 }
 
-func (m *LocalMockStore) List() ([]model.Prompt, error) {
+func (m *LocalMockStore) List(ctx context.Context) ([]model.Prompt, error) {
 	if m.listError != nil {
 		return nil, m.listError
 	}
 	return m.prompts, nil
 }
 
-func (m *LocalMockStore) GetExports() ([]model.IndexedPrompt, error) {
+func (m *LocalMockStore) GetExports(ctx context.Context) ([]model.IndexedPrompt, error) {
 	if m.getExportsError != nil {
 		return nil, m.getExportsError
 	}
@@ -49,21 +50,21 @@ func (m *LocalMockStore) GetExports() ([]model.IndexedPrompt, error) {
 }
 
 // Implement other Store interface methods (stubs for testing)
-func (m *LocalMockStore) Add(prompt model.Prompt) error { return nil }
-func (m *LocalMockStore) Delete(keyword string) error { return nil }
-func (m *LocalMockStore) Update(prompt model.Prompt) error { return nil }
-func (m *LocalMockStore) Get(keyword string) ([]model.Prompt, error) { return nil, nil }
-func (m *LocalMockStore) GetContent(gistID string) (string, error) { return "", nil }
-func (m *LocalMockStore) CreatePublicGist(prompt model.Prompt) (string, error) { return "", nil }
-func (m *LocalMockStore) UpdateGist(gistURL string, prompt model.Prompt) error { return nil }
-func (m *LocalMockStore) GetGistInfo(gistURL string) (*infra.GistInfo, error) { return nil, nil }
-func (m *LocalMockStore) AddExport(prompt model.IndexedPrompt) error { return nil }
-func (m *LocalMockStore) UpdateExport(prompt model.IndexedPrompt) error { return nil }
-func (m *LocalMockStore) FindExistingPromptByURL(gistURL string) (*model.Prompt, error) { return nil, nil }
-
-func (m *MockStoreForList) List() ([]model.Prompt, error) {
+func (m *LocalMockStore) Add(ctx context.Context, prompt model.Prompt) error { return nil }
+func (m *LocalMockStore) Delete(ctx context.Context, keyword string) error { return nil }
+func (m *LocalMockStore) Update(ctx context.Context, prompt model.Prompt) error { return nil }
+func (m *LocalMockStore) Get(ctx context.Context, keyword string) ([]model.Prompt, error) { return nil, nil }
+func (m *LocalMockStore) GetContent(ctx context.Context, gistID string) (string, error) { return "", nil }
+func (m *LocalMockStore) CreatePublicGist(ctx context.Context, prompt model.Prompt) (string, error) { return "", nil }
+func (m *LocalMockStore) UpdateGist(ctx context.Context, gistURL string, prompt model.Prompt) error { return nil }
+func (m *LocalMockStore) GetGistInfo(ctx context.Context, gistURL string) (*infra.GistInfo, error) { return nil, nil }
+func (m *LocalMockStore) AddExport(ctx context.Context, prompt model.IndexedPrompt) error { return nil }
+func (m *LocalMockStore) UpdateExport(ctx context.Context, prompt model.IndexedPrompt) error { return nil }
+func (m *LocalMockStore) FindExistingPromptByURL(ctx context.Context, gistURL string) (*model.Prompt, error) { return nil, nil }
+
+func (m *MockStoreForList) List(ctx context.Context) ([]model.Prompt, error) {
 	m.listCalls++
-	return m.LocalMockStore.List()
+	return m.LocalMockStore.List(ctx)
 }
 
 // MockCacheManager implements cache management for testing
@@ -427,7 +428,7 @@ func TestListCommand_BackwardCompatibility(t *testing.T) {
 func TestMockStoreDebug(t *testing.T) {
 	// Test with error
 	mockWithError := NewMockStoreForList(nil, errors.NewAppError(errors.ErrNetwork, "test error", nil))
-	prompts, err := mockWithError.List()
+	prompts, err := mockWithError.List(context.Background())
 	
 	if err == nil {
 		t.Errorf("Expected error but got nil")
@@ -441,7 +442,7 @@ func TestMockStoreDebug(t *testing.T) {
 		{ID: "test", Name: "Test", Author: "author"},
 	}
 	mockWithPrompts := NewMockStoreForList(testPrompts, nil)
-	prompts2, err2 := mockWithPrompts.List()
+	prompts2, err2 := mockWithPrompts.List(context.Background())
 	
 	if err2 != nil {
 		t.Errorf("Expected no error but got %v", err2)
@@ -484,4 +485,27 @@ func TestListCommand_Structure(t *testing.T) {
 	if remoteFlag.DefValue != "false" {
 		t.Errorf("Expected --remote flag default to be 'false', got %q", remoteFlag.DefValue)
 	}
-}
\ No newline at end of file
+}
+func TestFormatPromptDetails(t *testing.T) {
+	tests := []struct {
+		name   string
+		prompt model.Prompt
+		want   string
+	}{
+		{"no metadata", model.Prompt{Name: "Plain"}, ""},
+		{"description only", model.Prompt{Description: "Reviews code"}, "    Reviews code"},
+		{
+			"all metadata",
+			model.Prompt{Description: "Reviews code", Tags: []string{"go", "review"}, Version: "v1.2"},
+			"    Reviews code [tags: go, review] v1.2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatPromptDetails(tt.prompt); got != tt.want {
+				t.Errorf("formatPromptDetails() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,225 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/grigri/pv/internal/config"
+	"github.com/grigri/pv/internal/model"
+	"github.com/grigri/pv/internal/scaffold"
+	"github.com/grigri/pv/internal/service"
+	"github.com/grigri/pv/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+// NewCmd represents the new command
+type NewCmd struct {
+	promptService service.PromptService
+	authService   service.AuthService
+	tui           tui.TUIInterface
+	// runEditor 打开编辑器编辑 path，编辑器退出后返回；测试中替换为直接改写文件
+	runEditor func(path string) error
+	// templatesDir 返回用户模板所在目录；测试中替换为临时目录
+	templatesDir func() (string, error)
+
+	template string
+	form     bool
+	out      string
+	list     bool
+}
+
+// NewNewCommand creates a new new command
+func NewNewCommand(promptService service.PromptService, authService service.AuthService, tui tui.TUIInterface) *cobra.Command {
+	newCmd := &NewCmd{
+		promptService: promptService,
+		authService:   authService,
+		tui:           tui,
+		runEditor:     runEditor,
+		templatesDir:  config.GetTemplatesDir,
+	}
+	return newCmd.command()
+}
+
+// command builds the cobra command running this new command
+func (n *NewCmd) command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "new [name]",
+		Short: "从模板创建新的提示词",
+		Long: `从模板生成提示词 YAML，在 $VISUAL 或 $EDITOR 指定的编辑器中完成后添加到 vault。
+
+author 默认为当前登录的 GitHub 用户名；给出 name 参数时会预先填入。
+使用 --form 在终端表单中填写元数据，正文沿用模板内容。
+使用 --out 把结果写入文件而不添加到 vault，之后可以用 pv add 添加。
+
+内置模板有 basic、code-review 和 role；在配置目录的 templates 子目录
+（如 ~/.config/pv/templates）中放入 .yaml 文件即可添加自己的模板，
+与内置模板同名时覆盖内置模板。`,
+		Example: `  pv new
+  pv new "代码审查" --template code-review
+  pv new --form
+  pv new "翻译助手" --out translator.yaml
+  pv new --list`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: n.run,
+	}
+
+	cmd.Flags().StringVarP(&n.template, "template", "t", scaffold.DefaultTemplate, "使用的模板名称")
+	cmd.Flags().BoolVar(&n.form, "form", false, "在终端表单中填写元数据，不打开编辑器")
+	cmd.Flags().StringVarP(&n.out, "out", "o", "", "写入指定文件而不添加到 vault")
+	cmd.Flags().BoolVar(&n.list, "list", false, "列出可用的模板")
+	return cmd
+}
+
+// run 执行 new 命令的主要逻辑
+func (n *NewCmd) run(cmd *cobra.Command, args []string) error {
+	ctx, cancel := commandContext(cmd)
+	defer cancel()
+	out := cmd.OutOrStdout()
+
+	templates, err := n.loadTemplates()
+	if err != nil {
+		return err
+	}
+	if n.list {
+		printTemplates(out, templates)
+		return nil
+	}
+
+	template, ok := scaffold.Find(templates, n.template)
+	if !ok {
+		return fmt.Errorf("模板 '%s' 不存在，使用 pv new --list 查看可用的模板", n.template)
+	}
+
+	// 模板中的值之上叠加参数给出的 name 和当前登录用户
+	prompt := model.Prompt{}
+	if len(args) > 0 {
+		prompt.Name = args[0]
+	}
+	if status, err := n.authService.GetStatus(ctx); err == nil && status.IsAuthenticated {
+		prompt.Author = status.Username
+	}
+
+	var created *model.Prompt
+	save := func(content string) error {
+		var err error
+		if n.out != "" {
+			created, err = n.writeFile(content)
+		} else {
+			created, err = n.promptService.AddFromContent(ctx, content)
+		}
+		return err
+	}
+
+	if n.form {
+		saved, err := n.fillForm(template, prompt, save)
+		if err != nil {
+			return err
+		}
+		if !saved {
+			fmt.Fprintln(out, "取消创建")
+			return nil
+		}
+	} else {
+		content, err := scaffold.Render(template, prompt)
+		if err != nil {
+			return err
+		}
+		outcome, err := editUntilValid(n.runEditor, cmd.ErrOrStderr(), "pv-new-*.yaml", content, save)
+		if err != nil {
+			return err
+		}
+		switch outcome {
+		case editEmptied:
+			fmt.Fprintln(out, "文件为空，取消创建")
+			return nil
+		case editUnchanged:
+			fmt.Fprintln(out, "模板没有修改，取消创建")
+			return nil
+		}
+	}
+
+	if n.out != "" {
+		fmt.Fprintf(out, "✅ 已将提示词 '%s' 写入 %s\n", created.Name, n.out)
+		fmt.Fprintf(out, "使用 pv add %s 添加到 vault\n", n.out)
+		return nil
+	}
+	fmt.Fprintf(out, "✅ 已创建提示词 '%s'\n", created.Name)
+	if created.GistURL != "" {
+		fmt.Fprintf(out, "Gist URL: %s\n", created.GistURL)
+	}
+	return nil
+}
+
+// fillForm 在终端表单中填写元数据并保存渲染结果；用户取消时返回 false
+func (n *NewCmd) fillForm(template scaffold.Template, prompt model.Prompt, save func(content string) error) (bool, error) {
+	defaults, err := scaffold.Defaults(template)
+	if err != nil {
+		return false, err
+	}
+	if prompt.Name != "" {
+		defaults.Name = prompt.Name
+	}
+	if prompt.Author != "" {
+		defaults.Author = prompt.Author
+	}
+
+	filled, err := n.tui.ShowPromptForm(defaults)
+	if err != nil {
+		if err.Error() == tui.ErrMsgUserCancelled {
+			return false, nil
+		}
+		return false, fmt.Errorf("表单输入失败: %w", err)
+	}
+
+	content, err := scaffold.Render(template, filled)
+	if err != nil {
+		return false, err
+	}
+	if err := save(content); err != nil {
+		return false, fmt.Errorf("保存提示词失败: %w", err)
+	}
+	return true, nil
+}
+
+// writeFile 验证 content 后写入 --out 指定的文件
+func (n *NewCmd) writeFile(content string) (*model.Prompt, error) {
+	prompt, err := n.promptService.ValidatePromptContent(content)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(n.out, []byte(content), 0644); err != nil {
+		return nil, fmt.Errorf("写入文件 %s 失败: %w", n.out, err)
+	}
+	return prompt, nil
+}
+
+// loadTemplates 读取内置模板和用户模板
+func (n *NewCmd) loadTemplates() ([]scaffold.Template, error) {
+	dir, err := n.templatesDir()
+	if err != nil {
+		// 没有配置目录时只使用内置模板
+		return scaffold.Builtin(), nil
+	}
+	templates, err := scaffold.Load(dir)
+	if err != nil {
+		return nil, fmt.Errorf("读取模板失败: %w", err)
+	}
+	return templates, nil
+}
+
+// printTemplates 列出可用的模板及其来源
+func printTemplates(out io.Writer, templates []scaffold.Template) {
+	fmt.Fprintln(out, "可用的模板:")
+	for _, template := range templates {
+		source := "内置"
+		if !template.Builtin() {
+			source = template.Path
+		}
+		marker := "  "
+		if template.Name == scaffold.DefaultTemplate {
+			marker = "* "
+		}
+		fmt.Fprintf(out, "%s%-15s %s\n", marker, template.Name, source)
+	}
+}
@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/grigri/pv/internal/errors"
+	"github.com/grigri/pv/internal/model"
+	"github.com/grigri/pv/internal/service"
+	"github.com/grigri/pv/internal/tui"
+)
+
+// newAuthService reports a fixed login status
+type newAuthService struct {
+	status *service.AuthStatus
+}
+
+func (m *newAuthService) Login(ctx context.Context, token string) error { return nil }
+
+func (m *newAuthService) GetStatus(ctx context.Context) (*service.AuthStatus, error) {
+	return m.status, nil
+}
+
+func (m *newAuthService) Logout(ctx context.Context) error { return nil }
+
+// newPromptService accepts prompts whose content names an author
+type newPromptService struct {
+	*MockPromptService
+	added []string
+}
+
+func (m *newPromptService) ValidatePromptContent(content string) (*model.Prompt, error) {
+	if strings.Contains(content, `author: ""`) {
+		return nil, errors.NewAppError(errors.ErrValidation, "author is required", nil)
+	}
+	return &model.Prompt{Name: "Review", Author: "alice"}, nil
+}
+
+func (m *newPromptService) AddFromContent(ctx context.Context, content string) (*model.Prompt, error) {
+	prompt, err := m.ValidatePromptContent(content)
+	if err != nil {
+		return nil, err
+	}
+	m.added = append(m.added, content)
+	prompt.GistURL = "https://gist.github.com/alice/0123456789abcdef0123456789abcdef"
+	return prompt, nil
+}
+
+func TestNewCommand(t *testing.T) {
+	// run creates a prompt with an editor that writes the given contents in turn,
+	// recording what the editor was opened with each time
+	run := func(t *testing.T, mockTUI *tui.MockTUI, username string, args []string, edits ...func(string) string) (*newPromptService, []string, string, error) {
+		t.Helper()
+		promptService := &newPromptService{MockPromptService: NewMockPromptService()}
+		status := &service.AuthStatus{IsAuthenticated: username != "", Username: username}
+		templatesDir := t.TempDir()
+
+		var opened []string
+		newCmd := &NewCmd{
+			promptService: promptService,
+			authService:   &newAuthService{status: status},
+			tui:           mockTUI,
+			templatesDir:  func() (string, error) { return templatesDir, nil },
+			runEditor: func(path string) error {
+				data, err := os.ReadFile(path)
+				if err != nil {
+					return err
+				}
+				opened = append(opened, string(data))
+				if len(opened) > len(edits) {
+					t.Fatalf("Editor opened %d times, expected at most %d", len(opened), len(edits))
+				}
+				return os.WriteFile(path, []byte(edits[len(opened)-1](string(data))), 0600)
+			},
+		}
+		cmd := newCmd.command()
+
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetErr(&out)
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+		return promptService, opened, out.String(), err
+	}
+	appendBody := func(content string) string { return content + "More detail\n" }
+
+	t.Run("pre-fills the name and author", func(t *testing.T) {
+		promptService, opened, out, err := run(t, tui.NewMockTUI(), "alice", []string{"Review"}, appendBody)
+		if err != nil {
+			t.Fatalf("new failed: %v", err)
+		}
+		if !strings.Contains(opened[0], `name: "Review"`) || !strings.Contains(opened[0], `author: "alice"`) {
+			t.Errorf("Expected the name and author to be filled in, got %q", opened[0])
+		}
+		if len(promptService.added) != 1 || !strings.HasSuffix(promptService.added[0], "More detail\n") {
+			t.Errorf("Expected the edited prompt to be added, got %v", promptService.added)
+		}
+		if !strings.Contains(out, "已创建提示词 'Review'") {
+			t.Errorf("Expected a success message, got %q", out)
+		}
+	})
+
+	t.Run("reopens the editor until the content is valid", func(t *testing.T) {
+		fillAuthor := func(content string) string {
+			return strings.Replace(stripEditorNotice(content), `author: ""`, `author: "bob"`, 1)
+		}
+		promptService, opened, _, err := run(t, tui.NewMockTUI(), "", []string{"Review"}, appendBody, fillAuthor)
+		if err != nil {
+			t.Fatalf("new failed: %v", err)
+		}
+		if len(opened) != 2 || !strings.Contains(opened[1], "author is required") {
+			t.Errorf("Expected the editor to reopen with the error, got %q", opened)
+		}
+		if len(promptService.added) != 1 || !strings.Contains(promptService.added[0], `author: "bob"`) {
+			t.Errorf("Expected the corrected prompt to be added, got %v", promptService.added)
+		}
+	})
+
+	t.Run("unchanged template", func(t *testing.T) {
+		unchanged := func(content string) string { return content }
+		promptService, _, out, err := run(t, tui.NewMockTUI(), "alice", []string{"Review"}, unchanged)
+		if err != nil || len(promptService.added) != 0 || !strings.Contains(out, "取消创建") {
+			t.Errorf("Expected nothing to be created, got %v, %q, %v", promptService.added, out, err)
+		}
+	})
+
+	t.Run("writes to a file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "review.yaml")
+		promptService, _, out, err := run(t, tui.NewMockTUI(), "alice", []string{"Review", "--out", path}, appendBody)
+		if err != nil {
+			t.Fatalf("new failed: %v", err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil || !strings.HasSuffix(string(data), "More detail\n") {
+			t.Errorf("Expected the prompt to be written to %s, got %q, %v", path, data, err)
+		}
+		if len(promptService.added) != 0 || !strings.Contains(out, "pv add "+path) {
+			t.Errorf("Expected the prompt not to be added, got %v, %q", promptService.added, out)
+		}
+	})
+
+	t.Run("form", func(t *testing.T) {
+		mockTUI := tui.NewMockTUI()
+		mockTUI.PromptFormResult = &model.Prompt{Name: "Review", Author: "alice", Tags: []string{"code"}}
+		promptService, opened, _, err := run(t, mockTUI, "alice", []string{"--form", "--template", "code-review"})
+		if err != nil {
+			t.Fatalf("new failed: %v", err)
+		}
+		if len(opened) != 0 {
+			t.Errorf("Expected no editor in form mode, got %q", opened)
+		}
+		if len(mockTUI.ShowPromptFormArgs) != 1 || mockTUI.ShowPromptFormArgs[0].Author != "alice" {
+			t.Errorf("Expected the form to be pre-filled with the author, got %v", mockTUI.ShowPromptFormArgs)
+		}
+		if len(promptService.added) != 1 || !strings.Contains(promptService.added[0], `- "code"`) {
+			t.Errorf("Expected the form values to be added, got %v", promptService.added)
+		}
+	})
+
+	t.Run("unknown template", func(t *testing.T) {
+		_, _, _, err := run(t, tui.NewMockTUI(), "alice", []string{"--template", "missing"})
+		if err == nil || !strings.Contains(err.Error(), "模板 'missing' 不存在") {
+			t.Errorf("Expected an unknown template error, got %v", err)
+		}
+	})
+
+	t.Run("lists templates", func(t *testing.T) {
+		_, _, out, err := run(t, tui.NewMockTUI(), "alice", []string{"--list"})
+		if err != nil || !strings.Contains(out, "* basic") || !strings.Contains(out, "code-review") {
+			t.Errorf("Expected the built-in templates to be listed, got %q, %v", out, err)
+		}
+	})
+}
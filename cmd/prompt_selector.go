@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/grigri/pv/internal/model"
+	"github.com/grigri/pv/internal/service"
+	"github.com/grigri/pv/internal/tui"
+	"github.com/grigri/pv/internal/utils"
+)
+
+// isGistURLArg 判断命令参数是否为配置的 Gist 主机上的 Gist URL
+func isGistURLArg(arg string) bool {
+	if !strings.HasPrefix(arg, "http://") && !strings.HasPrefix(arg, "https://") {
+		return false
+	}
+	return utils.IsGistURLHost(arg) && containsGistID(arg)
+}
+
+// selectPrompt 将 keyword 或 gist URL 参数解析为一个提示词
+// URL 直接查找；关键字匹配到多个提示词时显示选择界面；参数为空时列出全部提示词供选择。
+// 用户取消选择时返回 nil, nil
+func selectPrompt(ctx context.Context, promptService service.PromptService, tuiInterface tui.TUIInterface, arg string) (*model.Prompt, error) {
+	arg = strings.TrimSpace(arg)
+
+	if isGistURLArg(arg) {
+		prompt, err := promptService.GetPromptByURL(ctx, arg)
+		if err != nil {
+			return nil, fmt.Errorf("获取提示词失败: %w", err)
+		}
+		return prompt, nil
+	}
+
+	var prompts []model.Prompt
+	if arg == "" {
+		allPrompts, err := promptService.ListPrompts(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("获取提示词列表失败: %w", err)
+		}
+		if len(allPrompts) == 0 {
+			return nil, fmt.Errorf("vault 中还没有提示词，请先使用 pv add 添加")
+		}
+		prompts = allPrompts
+	} else {
+		filteredPrompts, err := promptService.FilterPrompts(ctx, arg)
+		if err != nil {
+			return nil, fmt.Errorf("筛选提示词失败: %w", err)
+		}
+
+		switch len(filteredPrompts) {
+		case 0:
+			return nil, fmt.Errorf("没有找到匹配关键字 '%s' 的提示词", arg)
+		case 1:
+			return &filteredPrompts[0], nil
+		}
+		prompts = filteredPrompts
+	}
+
+	selected, err := tuiInterface.ShowPromptList(prompts)
+	if err != nil {
+		if err.Error() == tui.ErrMsgUserCancelled {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("显示选择界面失败: %w", err)
+	}
+	return &selected, nil
+}
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/grigri/pv/internal/archive"
+	"github.com/grigri/pv/internal/service"
+	"github.com/spf13/cobra"
+)
+
+// RestoreCmd represents the restore command
+type RestoreCmd struct {
+	promptService service.PromptService
+}
+
+// NewRestoreCommand creates a new restore command
+func NewRestoreCommand(promptService service.PromptService) *cobra.Command {
+	restoreCmd := &RestoreCmd{
+		promptService: promptService,
+	}
+	return restoreCmd.command()
+}
+
+// command builds the cobra command running this restore command
+func (r *RestoreCmd) command() *cobra.Command {
+	return &cobra.Command{
+		Use:   "restore <archive>",
+		Short: "从 pv export 导出的归档恢复 vault",
+		Long: `从 pv export --archive 导出的归档文件恢复提示词和分享记录。
+
+恢复前校验归档中每个文件的校验和，归档损坏时不做任何修改。
+提示词通过当前的存储后端重新创建，分享记录的公开副本也会重新创建并关联到
+父级提示词的新地址。地址相同或 name 和 author 都相同的提示词、已分享过的
+提示词视为已存在并跳过，因此中断后可以重新运行。`,
+		Example: `  pv restore vault.tar.gz`,
+		Args:    cobra.ExactArgs(1),
+		RunE:    r.run,
+	}
+}
+
+// run 执行 restore 命令的主要逻辑
+func (r *RestoreCmd) run(cmd *cobra.Command, args []string) error {
+	ctx, cancel := commandContext(cmd)
+	defer cancel()
+	out := cmd.OutOrStdout()
+
+	file, err := os.Open(args[0])
+	if err != nil {
+		return fmt.Errorf("无法打开归档文件: %w", err)
+	}
+	defer file.Close()
+
+	a, err := archive.Read(file)
+	if err != nil {
+		return fmt.Errorf("无法读取归档 %s: %w", args[0], err)
+	}
+
+	report, err := r.promptService.RestoreArchive(ctx, a)
+	printRestoreReport(out, report)
+	if err != nil {
+		fmt.Fprintln(out, "恢复已中断，重新运行 pv restore 会跳过已恢复的条目")
+		return err
+	}
+
+	fmt.Fprintf(out, "\n✅ 已恢复 %d 个条目，跳过 %d 个已存在的条目\n", report.Count(service.RestoreCreated), report.Count(service.RestoreSkipped))
+	return nil
+}
+
+// printRestoreReport 逐项输出恢复的提示词和分享记录
+func printRestoreReport(out io.Writer, report *service.RestoreReport) {
+	if report == nil {
+		return
+	}
+	section := func(title string, entries []service.RestoredEntry) {
+		if len(entries) == 0 {
+			return
+		}
+		fmt.Fprintln(out, title)
+		for _, entry := range entries {
+			if entry.Action == service.RestoreSkipped {
+				fmt.Fprintf(out, "  = %s (%s) 已存在: %s\n", entry.Entry.Name, entry.Entry.Author, entry.URL)
+				continue
+			}
+			fmt.Fprintf(out, "  + %s (%s) → %s\n", entry.Entry.Name, entry.Entry.Author, entry.URL)
+		}
+	}
+	section("提示词:", report.Prompts)
+	section("分享记录:", report.Exports)
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRestoreCommand(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vault.tar.gz")
+	export := NewExportCommand(newVaultService(t,
+		"name: Review\nauthor: alice\n---\nReview {code}\n",
+		"name: Summary\nauthor: bob\n---\nSummarize {text}\n",
+	))
+	export.SetOut(&bytes.Buffer{})
+	export.SetErr(&bytes.Buffer{})
+	export.SetArgs([]string{"--archive", path})
+	if err := export.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(t *testing.T, cmdArgs ...string) (string, error) {
+		t.Helper()
+		cmd := NewRestoreCommand(newVaultService(t, "name: Summary\nauthor: bob\n---\nSummarize {text}\n"))
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetErr(&out)
+		cmd.SetArgs(cmdArgs)
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	t.Run("restore", func(t *testing.T) {
+		out, err := run(t, path)
+		if err != nil {
+			t.Fatalf("restore failed: %v\n%s", err, out)
+		}
+		if !strings.Contains(out, "  + Review (alice) → vault://") || !strings.Contains(out, "  = Summary (bob) 已存在") {
+			t.Errorf("Expected each prompt in the report, got %q", out)
+		}
+		if !strings.Contains(out, "已恢复 1 个条目，跳过 1 个已存在的条目") {
+			t.Errorf("Expected a summary, got %q", out)
+		}
+	})
+
+	t.Run("corrupted archive", func(t *testing.T) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		corrupted := filepath.Join(t.TempDir(), "corrupted.tar.gz")
+		if err := os.WriteFile(corrupted, data[:len(data)/2], 0600); err != nil {
+			t.Fatal(err)
+		}
+		if out, err := run(t, corrupted); err == nil || strings.Contains(out, "Review") {
+			t.Errorf("Expected a truncated archive to restore nothing, got %q, %v", out, err)
+		}
+	})
+}
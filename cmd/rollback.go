@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/grigri/pv/internal/model"
+	"github.com/grigri/pv/internal/service"
+	"github.com/grigri/pv/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+// RollbackCmd represents the rollback command
+type RollbackCmd struct {
+	promptService service.PromptService
+	tui           tui.TUIInterface
+	rev           string
+}
+
+// NewRollbackCommand creates a new rollback command
+func NewRollbackCommand(promptService service.PromptService, tui tui.TUIInterface) *cobra.Command {
+	rollbackCmd := &RollbackCmd{
+		promptService: promptService,
+		tui:           tui,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "rollback <keyword|gist_url> --rev <sha>",
+		Short: "将提示词恢复到某个历史修订",
+		Long: `将提示词的内容（包括元数据）恢复为 pv history 列出的某个历史修订。
+
+回滚前会显示当前内容与目标修订之间的差异并要求确认。回滚会作为一个新的修订写入
+Gist，因此回滚本身也可以再次回滚。
+
+如果该提示词已经分享过公开副本，回滚完成后会询问是否同时更新公开副本。`,
+		Example: `  pv rollback "my prompt" --rev a1b2c3d
+  pv rollback https://gist.github.com/user/gist_id --rev a1b2c3d`,
+		Args: cobra.ExactArgs(1),
+		RunE: rollbackCmd.run,
+	}
+
+	cmd.Flags().StringVar(&rollbackCmd.rev, "rev", "", "要恢复的修订 SHA（可使用缩写）")
+	cmd.MarkFlagRequired("rev")
+
+	return cmd
+}
+
+// run 执行 rollback 命令的主要逻辑
+func (r *RollbackCmd) run(cmd *cobra.Command, args []string) error {
+	ctx, cancel := commandContext(cmd)
+	defer cancel()
+	out := cmd.OutOrStdout()
+
+	prompt, err := selectPrompt(ctx, r.promptService, r.tui, args[0])
+	if err != nil {
+		return err
+	}
+	if prompt == nil {
+		fmt.Fprintln(out, "取消回滚")
+		return nil
+	}
+
+	preview, err := r.promptService.PreviewRollback(ctx, prompt, r.rev)
+	if err != nil {
+		return fmt.Errorf("获取修订 %s 失败: %w", r.rev, err)
+	}
+	if !preview.HasChanges() {
+		fmt.Fprintf(out, "✅ 提示词 '%s' 的当前内容与修订 %s 相同，无需回滚\n", prompt.Name, preview.To)
+		return nil
+	}
+
+	fmt.Fprintf(out, "将提示词 '%s' 回滚到修订 %s，变化如下:\n\n", prompt.Name, preview.To)
+	printPromptDiff(out, preview)
+	fmt.Fprintln(out)
+
+	confirmed, err := r.tui.ShowConfirm(*prompt)
+	if err != nil {
+		return fmt.Errorf("显示确认界面失败: %w", err)
+	}
+	if !confirmed {
+		fmt.Fprintln(out, "取消回滚")
+		return nil
+	}
+
+	result, err := r.promptService.RollbackPrompt(ctx, prompt, r.rev)
+	if err != nil {
+		return fmt.Errorf("回滚提示词失败: %w", err)
+	}
+	fmt.Fprintf(out, "✅ 已将提示词 '%s' 回滚到修订 %s\n", result.Prompt.Name, result.Revision.ShortSHA())
+
+	if result.Export != nil {
+		return r.propagateToExport(cmd, result)
+	}
+	return nil
+}
+
+// propagateToExport 询问是否将回滚同步到分享出的公开副本
+func (r *RollbackCmd) propagateToExport(cmd *cobra.Command, result *service.RollbackResult) error {
+	ctx, cancel := commandContext(cmd)
+	defer cancel()
+	out := cmd.OutOrStdout()
+
+	fmt.Fprintf(out, "该提示词已分享为公开 Gist: %s\n", result.Export.GistURL)
+	confirmed, err := r.tui.ShowConfirm(model.Prompt{
+		Name:        result.Export.Name,
+		Author:      result.Export.Author,
+		Description: result.Export.Description,
+		GistURL:     result.Export.GistURL,
+	})
+	if err != nil {
+		return fmt.Errorf("显示确认界面失败: %w", err)
+	}
+	if !confirmed {
+		fmt.Fprintln(out, "公开副本保持不变，之后可以使用 pv share 更新")
+		return nil
+	}
+
+	if _, err := r.promptService.SharePrompt(ctx, result.Prompt); err != nil {
+		return fmt.Errorf("更新公开副本失败: %w", err)
+	}
+	fmt.Fprintf(out, "✅ 公开副本已更新: %s\n", result.Export.GistURL)
+	return nil
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/grigri/pv/internal/diff"
+	"github.com/grigri/pv/internal/model"
+	"github.com/grigri/pv/internal/service"
+	"github.com/grigri/pv/internal/tui"
+)
+
+// rollbackPromptService serves a fixed rollback preview and records the rollback
+type rollbackPromptService struct {
+	*MockPromptService
+	preview       *service.PromptDiff
+	export        *model.IndexedPrompt
+	rolledBack    []string
+	sharedPrompts []*model.Prompt
+}
+
+func (m *rollbackPromptService) PreviewRollback(ctx context.Context, prompt *model.Prompt, sha string) (*service.PromptDiff, error) {
+	return m.preview, nil
+}
+
+func (m *rollbackPromptService) RollbackPrompt(ctx context.Context, prompt *model.Prompt, sha string) (*service.RollbackResult, error) {
+	m.rolledBack = append(m.rolledBack, sha)
+	restored := *prompt
+	return &service.RollbackResult{
+		Prompt:   &restored,
+		Revision: model.Revision{SHA: "a1b2c3d4e5f60718293a4b5c6d7e8f9a0b1c2d3e"},
+		Export:   m.export,
+	}, nil
+}
+
+func (m *rollbackPromptService) SharePrompt(ctx context.Context, prompt *model.Prompt) (*model.Prompt, error) {
+	m.sharedPrompts = append(m.sharedPrompts, prompt)
+	return prompt, nil
+}
+
+func TestRollbackCommand(t *testing.T) {
+	prompt := model.Prompt{ID: "0123456789abcdef0123456789abcdef", Name: "Review", GistURL: "https://gist.github.com/alice/0123456789abcdef0123456789abcdef"}
+	preview := &service.PromptDiff{
+		From: "b2c3d4e",
+		To:   "a1b2c3d",
+		Body: diff.Hunks(diff.Lines("Review {{code}} thoroughly\n", "Review {{code}}\n"), diff.DefaultContext),
+	}
+
+	run := func(t *testing.T, promptService *rollbackPromptService, confirm bool) string {
+		t.Helper()
+		promptService.filterPromptsResult = []model.Prompt{prompt}
+		mockTUI := tui.NewMockTUI()
+		mockTUI.ConfirmResult = confirm
+
+		cmd := NewRollbackCommand(promptService, mockTUI)
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetArgs([]string{"review", "--rev", "a1b2c3d"})
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("rollback failed: %v", err)
+		}
+		return out.String()
+	}
+
+	t.Run("confirmed", func(t *testing.T) {
+		promptService := &rollbackPromptService{MockPromptService: NewMockPromptService(), preview: preview}
+		out := run(t, promptService, true)
+
+		if !strings.Contains(out, "-Review {{code}} thoroughly") || !strings.Contains(out, "+Review {{code}}") {
+			t.Errorf("Expected a diff preview, got %q", out)
+		}
+		if len(promptService.rolledBack) != 1 || promptService.rolledBack[0] != "a1b2c3d" {
+			t.Errorf("Expected the revision to be restored, got %v", promptService.rolledBack)
+		}
+		if !strings.Contains(out, "已将提示词 'Review' 回滚到修订 a1b2c3d") {
+			t.Errorf("Expected a success message, got %q", out)
+		}
+		if len(promptService.sharedPrompts) != 0 {
+			t.Errorf("Expected nothing to be shared without a public copy, got %v", promptService.sharedPrompts)
+		}
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		promptService := &rollbackPromptService{MockPromptService: NewMockPromptService(), preview: preview}
+		out := run(t, promptService, false)
+
+		if len(promptService.rolledBack) != 0 || !strings.Contains(out, "取消回滚") {
+			t.Errorf("Expected the rollback to be cancelled, got %v, %q", promptService.rolledBack, out)
+		}
+	})
+
+	t.Run("propagates to the public copy", func(t *testing.T) {
+		promptService := &rollbackPromptService{
+			MockPromptService: NewMockPromptService(),
+			preview:           preview,
+			export:            &model.IndexedPrompt{Name: "Review", GistURL: "https://gist.github.com/alice/fedcba9876543210fedcba9876543210"},
+		}
+		out := run(t, promptService, true)
+
+		if len(promptService.sharedPrompts) != 1 || promptService.sharedPrompts[0].ID != prompt.ID {
+			t.Errorf("Expected the restored prompt to be shared again, got %v", promptService.sharedPrompts)
+		}
+		if !strings.Contains(out, "公开副本已更新") {
+			t.Errorf("Expected the public copy to be reported, got %q", out)
+		}
+	})
+
+	t.Run("nothing to roll back", func(t *testing.T) {
+		promptService := &rollbackPromptService{MockPromptService: NewMockPromptService(), preview: &service.PromptDiff{From: "a1b2c3d", To: "a1b2c3d"}}
+		out := run(t, promptService, true)
+
+		if len(promptService.rolledBack) != 0 || !strings.Contains(out, "无需回滚") {
+			t.Errorf("Expected no rollback, got %v, %q", promptService.rolledBack, out)
+		}
+	})
+}
@@ -8,7 +8,7 @@ import (
 
 type RootCmd = *cobra.Command
 
-func NewRootCommand(lc ListCmd, addCmd AddCmd, deleteCmd DeleteCmd, getCmd GetCmd, syncCmd SyncCmd, authCmd AuthCmd, shareCmd *cobra.Command) RootCmd {
+func NewRootCommand(lc ListCmd, addCmd AddCmd, deleteCmd DeleteCmd, getCmd GetCmd, syncCmd SyncCmd, authCmd AuthCmd, shareCmd *cobra.Command, historyCmd *cobra.Command, showCmd *cobra.Command, diffCmd *cobra.Command, rollbackCmd *cobra.Command, editCmd *cobra.Command, newCmd *cobra.Command, importCmd *cobra.Command, exportCmd *cobra.Command, restoreCmd *cobra.Command) RootCmd {
 	root := &cobra.Command{
 		Use:   "pv",
 		Short: "Prompt Vault CLI",
@@ -16,7 +16,8 @@ func NewRootCommand(lc ListCmd, addCmd AddCmd, deleteCmd DeleteCmd, getCmd GetCm
 			fmt.Println("Hello, pv!")
 		},
 	}
-	root.AddCommand(lc, addCmd, deleteCmd, getCmd, syncCmd, authCmd, shareCmd)
+	root.PersistentFlags().Duration(timeoutFlag, 0, "远程调用的超时时间，例如 30s 或 2m（0 表示不限制）；超时后读取命令回退到本地缓存")
+	root.AddCommand(lc, addCmd, deleteCmd, getCmd, syncCmd, authCmd, shareCmd, historyCmd, showCmd, diffCmd, rollbackCmd, editCmd, newCmd, importCmd, exportCmd, restoreCmd)
 	
 	// Create 'del' alias for delete command
 	delCmd := &cobra.Command{
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"net/url"
 	"strings"
@@ -9,6 +10,7 @@ import (
 	"github.com/grigri/pv/internal/model"
 	"github.com/grigri/pv/internal/service"
 	"github.com/grigri/pv/internal/tui"
+	"github.com/grigri/pv/internal/utils"
 	"github.com/spf13/cobra"
 )
 
@@ -51,24 +53,27 @@ func NewShareCommand(promptService service.PromptService, tui tui.TUIInterface)
 
 // run 执行 share 命令的主要逻辑
 func (s *ShareCmd) run(cmd *cobra.Command, args []string) error {
+	ctx, cancel := commandContext(cmd)
+	defer cancel()
+
 	switch len(args) {
 	case 0:
-		return s.handleInteractiveMode()
+		return s.handleInteractiveMode(ctx)
 	case 1:
 		arg := strings.TrimSpace(args[0])
 		if s.isGistURL(arg) {
-			return s.handleDirectMode(arg)
+			return s.handleDirectMode(ctx, arg)
 		}
-		return s.handleFilterMode(arg)
+		return s.handleFilterMode(ctx, arg)
 	default:
 		return fmt.Errorf("share 命令只接受 0 或 1 个参数")
 	}
 }
 
 // handleInteractiveMode 处理交互式模式
-func (s *ShareCmd) handleInteractiveMode() error {
+func (s *ShareCmd) handleInteractiveMode(ctx context.Context) error {
 	// 获取所有私有提示词
-	privatePrompts, err := s.promptService.ListPrivatePrompts()
+	privatePrompts, err := s.promptService.ListPrivatePrompts(ctx)
 	if err != nil {
 		return fmt.Errorf("获取私有提示词列表失败: %w", err)
 	}
@@ -88,13 +93,13 @@ func (s *ShareCmd) handleInteractiveMode() error {
 		return fmt.Errorf("显示选择界面失败: %w", err)
 	}
 
-	return s.executeShare(&selectedPrompt)
+	return s.executeShare(ctx, &selectedPrompt)
 }
 
 // handleFilterMode 处理关键字筛选模式
-func (s *ShareCmd) handleFilterMode(keyword string) error {
+func (s *ShareCmd) handleFilterMode(ctx context.Context, keyword string) error {
 	// 根据关键字筛选私有提示词
-	filteredPrompts, err := s.promptService.FilterPrivatePrompts(keyword)
+	filteredPrompts, err := s.promptService.FilterPrivatePrompts(ctx, keyword)
 	if err != nil {
 		return fmt.Errorf("筛选私有提示词失败: %w", err)
 	}
@@ -114,24 +119,24 @@ func (s *ShareCmd) handleFilterMode(keyword string) error {
 		return fmt.Errorf("显示选择界面失败: %w", err)
 	}
 
-	return s.executeShare(&selectedPrompt)
+	return s.executeShare(ctx, &selectedPrompt)
 }
 
 // handleDirectMode 处理直接 URL 模式
-func (s *ShareCmd) handleDirectMode(gistURL string) error {
+func (s *ShareCmd) handleDirectMode(ctx context.Context, gistURL string) error {
 	// 验证 URL 格式
 	if !s.isValidGistURL(gistURL) {
 		return errors.ErrInvalidGistURL
 	}
 
 	// 获取指定 URL 的提示词
-	prompt, err := s.promptService.GetPromptByURL(gistURL)
+	prompt, err := s.promptService.GetPromptByURL(ctx, gistURL)
 	if err != nil {
 		return fmt.Errorf("获取提示词失败: %w", err)
 	}
 
 	// 验证是否为私有 gist
-	gistInfo, err := s.promptService.ValidateGistAccess(gistURL)
+	gistInfo, err := s.promptService.ValidateGistAccess(ctx, gistURL)
 	if err != nil {
 		return fmt.Errorf("验证 gist 访问权限失败: %w", err)
 	}
@@ -156,14 +161,14 @@ func (s *ShareCmd) handleDirectMode(gistURL string) error {
 		return nil
 	}
 
-	return s.executeShare(prompt)
+	return s.executeShare(ctx, prompt)
 }
 
 // executeShare 执行实际的分享操作
-func (s *ShareCmd) executeShare(prompt *model.Prompt) error {
+func (s *ShareCmd) executeShare(ctx context.Context, prompt *model.Prompt) error {
 	fmt.Printf("正在分享提示词 '%s'...\n", prompt.Name)
 	fmt.Println(prompt)
-	sharedPrompt, err := s.promptService.SharePrompt(prompt)
+	sharedPrompt, err := s.promptService.SharePrompt(ctx, prompt)
 	if err != nil {
 		return fmt.Errorf("分享提示词失败: %w", err)
 	}
@@ -195,8 +200,8 @@ func (s *ShareCmd) isValidGistURL(gistURL string) bool {
 		return false
 	}
 
-	// 检查是否为 GitHub Gist URL
-	if parsedURL.Host != "gist.github.com" {
+	// 检查是否为配置的 Gist 主机（默认 gist.github.com）
+	if !utils.IsGistHost(parsedURL.Host) {
 		return false
 	}
 
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/grigri/pv/internal/service"
+	"github.com/grigri/pv/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+// ShowCmd represents the show command
+type ShowCmd struct {
+	promptService service.PromptService
+	tui           tui.TUIInterface
+	rev           string
+}
+
+// NewShowCommand creates a new show command
+func NewShowCommand(promptService service.PromptService, tui tui.TUIInterface) *cobra.Command {
+	showCmd := &ShowCmd{
+		promptService: promptService,
+		tui:           tui,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "show <gist_url|keyword>",
+		Short: "打印提示词的内容或某个历史修订",
+		Long: `将提示词的 YAML 内容打印到标准输出。
+
+不带 --rev 时打印当前内容；使用 --rev 打印 pv history 列出的某个历史修订，
+SHA 可以是 pv history 中显示的缩写。`,
+		Example: `  pv show https://gist.github.com/user/gist_id
+  pv show https://gist.github.com/user/gist_id --rev a1b2c3d`,
+		Args: cobra.ExactArgs(1),
+		RunE: showCmd.run,
+	}
+
+	cmd.Flags().StringVar(&showCmd.rev, "rev", "", "要打印的修订 SHA（可使用缩写）")
+
+	return cmd
+}
+
+// run 执行 show 命令的主要逻辑
+func (s *ShowCmd) run(cmd *cobra.Command, args []string) error {
+	ctx, cancel := commandContext(cmd)
+	defer cancel()
+
+	prompt, err := selectPrompt(ctx, s.promptService, s.tui, args[0])
+	if err != nil {
+		return err
+	}
+	if prompt == nil {
+		return nil
+	}
+
+	var content string
+	if s.rev != "" {
+		content, err = s.promptService.GetPromptRevision(ctx, prompt, s.rev)
+		if err != nil {
+			return fmt.Errorf("获取修订 %s 失败: %w", s.rev, err)
+		}
+	} else {
+		content, err = s.promptService.GetPromptContent(ctx, prompt)
+		if err != nil {
+			return fmt.Errorf("获取提示词内容失败: %w", err)
+		}
+	}
+
+	// 只输出内容本身，便于重定向到文件或管道
+	fmt.Fprint(cmd.OutOrStdout(), content)
+	if !strings.HasSuffix(content, "\n") {
+		fmt.Fprintln(cmd.OutOrStdout())
+	}
+	return nil
+}
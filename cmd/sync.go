@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log"
@@ -8,6 +9,7 @@ import (
 	"github.com/spf13/cobra"
 
 	apperrors "github.com/grigri/pv/internal/errors"
+	"github.com/grigri/pv/internal/model"
 	"github.com/grigri/pv/internal/service"
 )
 
@@ -22,7 +24,8 @@ type SyncStats struct {
 	Total     int
 	Success   int
 	Failed    int
-	Skipped   int
+	Skipped   int // 缓存内容已是最新，无需下载
+	Evicted   int // 远程已删除，从缓存中移除
 	Errors    []string
 }
 
@@ -38,12 +41,21 @@ func (sc *sync) execute(cmd *cobra.Command, args []string) {
 	// Get verbose flag for detailed output
 	verbose, _ := cmd.Flags().GetBool("verbose")
 
+	// Ctrl-C cancels the command context: remaining downloads are skipped and
+	// the statistics so far are still shown
+	ctx, cancel := commandContext(cmd)
+	defer cancel()
+
+	// Step 0: Push writes made while offline before pulling remote data
+	sc.replayOfflineChanges(ctx)
+
 	// Step 1: Sync raw index.json first
 	fmt.Println("📥 正在同步索引文件...")
 	
-	if err := sc.promptService.Sync(); err != nil {
+	plan, err := sc.promptService.Sync(ctx)
+	if err != nil {
 		// Handle different types of errors with user-friendly messages
-		var appErr *apperrors.AppError
+		var appErr apperrors.AppError
 		if errors.As(err, &appErr) {
 			switch appErr.Type {
 			case apperrors.ErrAuth:
@@ -56,6 +68,11 @@ func (sc *sync) execute(cmd *cobra.Command, args []string) {
 				fmt.Println()
 				fmt.Println("请检查网络连接后重试。")
 				return
+			case apperrors.ErrPermission:
+				fmt.Printf("❌ 权限错误: %s\n", appErr.Message)
+				fmt.Println()
+				fmt.Println("请确认 GitHub token 具有 'gist' 权限，必要时运行 'pv auth login' 重新登录。")
+				return
 			default:
 				fmt.Printf("❌ 索引同步失败: %s\n", appErr.Message)
 				fmt.Println()
@@ -72,13 +89,17 @@ func (sc *sync) execute(cmd *cobra.Command, args []string) {
 	}
 	
 	fmt.Println("  ✅ 索引同步成功")
+	if plan != nil && plan.Evicted > 0 {
+		stats.Evicted = plan.Evicted
+		fmt.Printf("  🗑️  已移除 %d 个远程已删除的提示词缓存\n", plan.Evicted)
+	}
 	fmt.Println()
 
 	// Step 2: Get list of all prompts to sync
-	prompts, err := sc.promptService.ListPrompts()
+	prompts, err := sc.promptService.ListPrompts(ctx)
 	if err != nil {
 		// Handle different types of errors with user-friendly messages
-		var appErr *apperrors.AppError
+		var appErr apperrors.AppError
 		if errors.As(err, &appErr) {
 			switch appErr.Type {
 			case apperrors.ErrAuth:
@@ -91,6 +112,11 @@ func (sc *sync) execute(cmd *cobra.Command, args []string) {
 				fmt.Println()
 				fmt.Println("请检查网络连接后重试。")
 				return
+			case apperrors.ErrPermission:
+				fmt.Printf("❌ 权限错误: %s\n", appErr.Message)
+				fmt.Println()
+				fmt.Println("请确认 GitHub token 具有 'gist' 权限，必要时运行 'pv auth login' 重新登录。")
+				return
 			default:
 				fmt.Printf("❌ 错误: %s\n", appErr.Message)
 				return
@@ -112,24 +138,40 @@ func (sc *sync) execute(cmd *cobra.Command, args []string) {
 		return
 	}
 
-	fmt.Printf("📋 发现 %d 个提示词需要同步\n", stats.Total)
+	// Step 3: Only prompts whose content changed since the last sync are downloaded
+	var changed []model.Prompt
+	for _, prompt := range prompts {
+		if plan.IsUpToDate(prompt.ID) {
+			stats.Skipped++
+			if verbose {
+				fmt.Printf("⏭️  已是最新: %s (%s)\n", prompt.Name, prompt.ID)
+			}
+			continue
+		}
+		changed = append(changed, prompt)
+	}
+
+	if len(changed) == 0 {
+		fmt.Printf("📋 %d 个提示词的缓存均已是最新\n", stats.Total)
+		fmt.Println()
+		sc.displaySyncResults(stats, verbose)
+		return
+	}
+
+	fmt.Printf("📋 发现 %d 个提示词需要同步\n", len(changed))
 	fmt.Println()
 
-	// Step 3: Serial download of prompt content with progress display
-	for i, prompt := range prompts {
+	// Step 4: Download prompt contents in parallel, reporting each as it finishes.
+	// The content goes through the CachedStore which fetches it from the remote
+	// (GitHub Gist) and caches it locally on success
+	err = sc.promptService.FetchPromptContents(ctx, changed, func(done, total int, prompt model.Prompt, err error) {
 		// Display progress in the exact format specified: "正在下载 X/Y"
-		fmt.Printf("⬇️  正在下载 %d/%d: %s", i+1, stats.Total, prompt.Name)
+		fmt.Printf("⬇️  正在下载 %d/%d: %s", done, total, prompt.Name)
 		if verbose {
 			fmt.Printf(" (%s)", prompt.ID)
 		}
 		fmt.Println()
 
-		// Try to get content for this prompt
-		// This call will go through the CachedStore which will:
-		// 1. Try to fetch from remote (GitHub Gist)
-		// 2. Cache the content locally on success
-		// 3. Return the content
-		_, err := sc.promptService.GetPromptContent(&prompt)
 		if err != nil {
 			stats.Failed++
 			errorMsg := fmt.Sprintf("下载 '%s' 失败: %v", prompt.Name, err)
@@ -141,7 +183,7 @@ func (sc *sync) execute(cmd *cobra.Command, args []string) {
 				fmt.Printf("  ❌ 失败\n")
 			}
 			// Continue processing other prompts even if this one fails
-			continue
+			return
 		}
 		
 		// Success: content has been downloaded and cached
@@ -149,12 +191,42 @@ func (sc *sync) execute(cmd *cobra.Command, args []string) {
 		if verbose {
 			fmt.Printf("  ✅ 成功缓存\n")
 		}
+	})
+	if err != nil {
+		fmt.Println()
+		fmt.Printf("⚠️  同步已中断，%d 个提示词未下载: %v\n", len(changed)-stats.Success-stats.Failed, err)
 	}
 
 	fmt.Println()
 	sc.displaySyncResults(stats, verbose)
 }
 
+// replayOfflineChanges 推送离线时排队的修改，并报告与远程冲突的修改
+func (sc *sync) replayOfflineChanges(ctx context.Context) {
+	report, err := sc.promptService.ReplayOfflineChanges(ctx)
+	if err != nil {
+		fmt.Printf("⚠️  离线修改推送失败，将在下次同步时重试: %v\n", err)
+		fmt.Println()
+		return
+	}
+
+	if report.Applied == 0 && report.Pending == 0 && len(report.Conflicts) == 0 {
+		return
+	}
+
+	fmt.Println("⏫ 正在推送离线修改...")
+	if report.Applied > 0 {
+		fmt.Printf("  ✅ 已推送 %d 个离线修改\n", report.Applied)
+	}
+	if report.Pending > 0 {
+		fmt.Printf("  ⏳ %d 个离线修改仍在排队，远程暂时不可用\n", report.Pending)
+	}
+	for _, conflict := range report.Conflicts {
+		fmt.Printf("  ⚠️  与远程冲突，已放弃: %s\n", conflict)
+	}
+	fmt.Println()
+}
+
 func (sc *sync) displaySyncResults(stats *SyncStats, verbose bool) {
 	fmt.Println("📊 同步完成统计:")
 	fmt.Println()
@@ -162,9 +234,12 @@ func (sc *sync) displaySyncResults(stats *SyncStats, verbose bool) {
 	fmt.Printf("  成功: %d\n", stats.Success)
 	fmt.Printf("  失败: %d\n", stats.Failed)
 	fmt.Printf("  跳过: %d\n", stats.Skipped)
+	if stats.Evicted > 0 {
+		fmt.Printf("  移除: %d\n", stats.Evicted)
+	}
 	fmt.Println()
 
-	if stats.Success == stats.Total {
+	if stats.Failed == 0 && stats.Success+stats.Skipped == stats.Total {
 		fmt.Println("🎉 所有提示词同步并缓存成功!")
 	} else if stats.Success > 0 {
 		fmt.Printf("✅ %d 个提示词同步并缓存成功", stats.Success)
@@ -188,7 +263,7 @@ func (sc *sync) displaySyncResults(stats *SyncStats, verbose bool) {
 		fmt.Println("使用 --verbose 查看详细错误信息")
 	}
 
-	if stats.Success > 0 {
+	if stats.Success+stats.Skipped > 0 {
 		fmt.Println()
 		fmt.Println("提示词已缓存到本地，现在可以离线使用。")
 		fmt.Println("运行 'pv list' 查看同步的提示词。")
@@ -204,8 +279,9 @@ func NewSyncCommand(promptService service.PromptService) SyncCmd {
 		Long: `同步命令实现完整的缓存同步流程，从 GitHub Gist 下载最新的提示词数据到本地缓存。
 
 完整同步流程：
-  • 获取远程提示词索引列表
-  • 串行下载所有提示词内容到本地缓存
+  • 推送离线时排队的修改，报告与远程冲突的修改
+  • 获取远程提示词索引列表，移除远程已删除的提示词缓存
+  • 只下载自上次同步以来有变化的提示词内容（按内容哈希或更新时间判断）
   • 显示 "正在下载 X/Y" 进度信息
   • 单个提示词失败时继续处理其他提示词
   • 显示最终同步统计信息（成功/失败数量）
@@ -1,12 +1,15 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"testing"
 
 	"github.com/spf13/cobra"
+	"github.com/grigri/pv/internal/archive"
 	"github.com/grigri/pv/internal/errors"
+	"github.com/grigri/pv/internal/infra"
 	"github.com/grigri/pv/internal/model"
 	"github.com/grigri/pv/internal/service"
 )
@@ -32,6 +35,9 @@ type MockSyncPromptService struct {
 	
 	// Per-prompt error simulation for partial failure testing
 	promptContentErrors map[string]error
+
+	// Incremental sync plan returned by Sync
+	syncPlan *infra.SyncPlan
 }
 
 func NewMockSyncPromptService() *MockSyncPromptService {
@@ -42,7 +48,7 @@ func NewMockSyncPromptService() *MockSyncPromptService {
 	}
 }
 
-func (m *MockSyncPromptService) GetPromptContent(prompt *model.Prompt) (string, error) {
+func (m *MockSyncPromptService) GetPromptContent(ctx context.Context, prompt *model.Prompt) (string, error) {
 	m.getPromptContentCalls = append(m.getPromptContentCalls, prompt.ID)
 	
 	if m.getPromptContentFunc != nil {
@@ -61,7 +67,86 @@ func (m *MockSyncPromptService) GetPromptContent(prompt *model.Prompt) (string,
 	return m.getPromptContentResult, nil
 }
 
-func (m *MockSyncPromptService) ListPrompts() ([]model.Prompt, error) {
+func (m *MockSyncPromptService) Sync(ctx context.Context) (*infra.SyncPlan, error) {
+	if m.syncPlan != nil {
+		return m.syncPlan, nil
+	}
+	return &infra.SyncPlan{}, nil
+}
+
+// FetchPromptContents downloads the prompts one at a time through GetPromptContent
+func (m *MockSyncPromptService) FetchPromptContents(ctx context.Context, prompts []model.Prompt, progress service.ContentProgress) error {
+	for i := range prompts {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		_, err := m.GetPromptContent(ctx, &prompts[i])
+		progress(i+1, len(prompts), prompts[i], err)
+	}
+	return nil
+}
+
+func (m *MockSyncPromptService) GetPromptHistory(ctx context.Context, prompt *model.Prompt) ([]model.Revision, error) {
+	return nil, nil
+}
+
+func (m *MockSyncPromptService) GetPromptRevision(ctx context.Context, prompt *model.Prompt, sha string) (string, error) {
+	return "", nil
+}
+
+func (m *MockSyncPromptService) DiffRevisions(ctx context.Context, prompt *model.Prompt, from, to string) (*service.PromptDiff, error) {
+	return nil, nil
+}
+
+func (m *MockSyncPromptService) DiffWithFile(ctx context.Context, prompt *model.Prompt, filePath string) (*service.PromptDiff, error) {
+	return nil, nil
+}
+
+func (m *MockSyncPromptService) DiffWithExport(ctx context.Context, prompt *model.Prompt) (*service.PromptDiff, error) {
+	return nil, nil
+}
+
+func (m *MockSyncPromptService) AddFromContent(ctx context.Context, content string) (*model.Prompt, error) {
+	return nil, nil
+}
+
+func (m *MockSyncPromptService) ValidatePromptContent(content string) (*model.Prompt, error) {
+	return nil, nil
+}
+
+func (m *MockSyncPromptService) PlanAddFromFiles(ctx context.Context, paths []string, recursive bool) (*service.AddPlan, error) {
+	return &service.AddPlan{}, nil
+}
+
+func (m *MockSyncPromptService) PlanAddFromContents(ctx context.Context, sources []service.PromptSource) (*service.AddPlan, error) {
+	return &service.AddPlan{}, nil
+}
+
+func (m *MockSyncPromptService) ExportArchive(ctx context.Context, progress service.ContentProgress) (*archive.Archive, error) {
+	return &archive.Archive{}, nil
+}
+
+func (m *MockSyncPromptService) RestoreArchive(ctx context.Context, a *archive.Archive) (*service.RestoreReport, error) {
+	return &service.RestoreReport{}, nil
+}
+
+func (m *MockSyncPromptService) ApplyAddPlan(ctx context.Context, plan *service.AddPlan) ([]model.Prompt, error) {
+	return nil, nil
+}
+
+func (m *MockSyncPromptService) UpdatePromptContent(ctx context.Context, prompt *model.Prompt, content string) (*model.Prompt, error) {
+	return nil, nil
+}
+
+func (m *MockSyncPromptService) PreviewRollback(ctx context.Context, prompt *model.Prompt, sha string) (*service.PromptDiff, error) {
+	return nil, nil
+}
+
+func (m *MockSyncPromptService) RollbackPrompt(ctx context.Context, prompt *model.Prompt, sha string) (*service.RollbackResult, error) {
+	return nil, nil
+}
+
+func (m *MockSyncPromptService) ListPrompts(ctx context.Context) ([]model.Prompt, error) {
 	m.listPromptsCalls++
 	if m.listPromptsFunc != nil {
 		return m.listPromptsFunc()
@@ -206,7 +291,7 @@ func TestSyncCommand_CommandConfiguration(t *testing.T) {
 	longDescriptionKeywords := []string{
 		"同步命令实现完整的缓存同步流程",
 		"获取远程提示词索引列表",
-		"串行下载所有提示词内容到本地缓存",
+		"只下载自上次同步以来有变化的提示词内容",
 		"正在下载 X/Y",
 		"单个提示词失败时继续处理其他提示词",
 		"显示最终同步统计信息",
@@ -384,6 +469,44 @@ func TestSyncCommand_EmptyPromptList(t *testing.T) {
 	}
 }
 
+// TestSyncCommand_IncrementalSync tests that up-to-date prompts are skipped
+func TestSyncCommand_IncrementalSync(t *testing.T) {
+	mockService := NewMockSyncPromptService()
+	prompts := createSyncTestPrompts()
+	mockService.listPromptsResult = prompts
+	mockService.getPromptContentResult = "content"
+	mockService.syncPlan = &infra.SyncPlan{
+		UpToDate: map[string]bool{prompts[0].ID: true, prompts[2].ID: true},
+		Evicted:  2,
+	}
+
+	cmd := NewSyncCommand(mockService)
+	cobraCmd := (*cobra.Command)(cmd)
+
+	output := captureOutput(func() {
+		cobraCmd.Run(cobraCmd, []string{})
+	})
+
+	if len(mockService.getPromptContentCalls) != 1 || mockService.getPromptContentCalls[0] != prompts[1].ID {
+		t.Errorf("Expected only %s to be downloaded, got %v", prompts[1].ID, mockService.getPromptContentCalls)
+	}
+
+	expectedMessages := []string{
+		"已移除 2 个远程已删除的提示词缓存",
+		"发现 1 个提示词需要同步",
+		"正在下载 1/1: Python Data Analysis",
+		"成功: 1",
+		"跳过: 2",
+		"移除: 2",
+		"🎉 所有提示词同步并缓存成功!",
+	}
+	for _, msg := range expectedMessages {
+		if !strings.Contains(output, msg) {
+			t.Errorf("Expected message %q, got:\n%s", msg, output)
+		}
+	}
+}
+
 // TestSyncCommand_AuthenticationFailure tests authentication error scenarios (需求 3.4)
 func TestSyncCommand_AuthenticationFailure(t *testing.T) {
 	testCases := []struct {
@@ -1,6 +1,7 @@
 package integration
 
 import (
+	"context"
 	"bytes"
 	"errors"
 	"fmt"
@@ -42,14 +43,14 @@ func testServiceLayerIntegration(t *testing.T) {
 
 	// Add prompts to store
 	for _, prompt := range prompts {
-		err := testEnv.store.Add(prompt)
+		err := testEnv.store.Add(context.Background(), prompt)
 		if err != nil {
 			t.Fatalf("Failed to add prompt: %v", err)
 		}
 	}
 
 	// Test ListPrompts
-	listedPrompts, err := testEnv.service.ListPrompts()
+	listedPrompts, err := testEnv.service.ListPrompts(context.Background())
 	if err != nil {
 		t.Errorf("ListPrompts failed: %v", err)
 	}
@@ -58,7 +59,7 @@ func testServiceLayerIntegration(t *testing.T) {
 	}
 
 	// Test FilterPrompts
-	filteredPrompts, err := testEnv.service.FilterPrompts("AI")
+	filteredPrompts, err := testEnv.service.FilterPrompts(context.Background(), "AI")
 	if err != nil {
 		t.Errorf("FilterPrompts failed: %v", err)
 	}
@@ -82,7 +83,7 @@ func testCommandParameterParsing(t *testing.T) {
 	}
 
 	for _, prompt := range prompts {
-		err := testEnv.store.Add(prompt)
+		err := testEnv.store.Add(context.Background(), prompt)
 		if err != nil {
 			t.Fatalf("Failed to add prompt: %v", err)
 		}
@@ -109,7 +110,7 @@ func testCommandParameterParsing(t *testing.T) {
 			t.Logf("Command with args %v completed with result: %v", tt.args, err)
 			
 			// Verify store integrity is maintained
-			remaining, storeErr := testEnv.store.List()
+			remaining, storeErr := testEnv.store.List(context.Background())
 			if storeErr != nil {
 				t.Errorf("Store integrity compromised: %v", storeErr)
 			}
@@ -130,13 +131,13 @@ func testStoreIntegration(t *testing.T) {
 	testPrompt := createTestPrompt("test1", "Store Test", "tester", createValidGistURL("1234567890abcdef1234"))
 
 	// Test Add operation
-	err := testEnv.store.Add(testPrompt)
+	err := testEnv.store.Add(context.Background(), testPrompt)
 	if err != nil {
 		t.Fatalf("Store Add failed: %v", err)
 	}
 
 	// Test List operation (used by ListPrompts)
-	prompts, err := testEnv.store.List()
+	prompts, err := testEnv.store.List(context.Background())
 	if err != nil {
 		t.Errorf("Store List failed: %v", err)
 	}
@@ -145,7 +146,7 @@ func testStoreIntegration(t *testing.T) {
 	}
 
 	// Test Get operation (used by FilterPrompts and URL search)
-	foundPrompts, err := testEnv.store.Get("Store")
+	foundPrompts, err := testEnv.store.Get(context.Background(), "Store")
 	if err != nil {
 		t.Errorf("Store Get failed: %v", err)
 	}
@@ -154,7 +155,7 @@ func testStoreIntegration(t *testing.T) {
 	}
 
 	// Test Get by URL
-	urlPrompts, err := testEnv.store.Get(testPrompt.GistURL)
+	urlPrompts, err := testEnv.store.Get(context.Background(), testPrompt.GistURL)
 	if err != nil {
 		t.Errorf("Store Get by URL failed: %v", err)
 	}
@@ -163,13 +164,13 @@ func testStoreIntegration(t *testing.T) {
 	}
 
 	// Test Delete operation (core of delete functionality)
-	err = testEnv.store.Delete(testPrompt.ID)
+	err = testEnv.store.Delete(context.Background(), testPrompt.ID)
 	if err != nil {
 		t.Errorf("Store Delete failed: %v", err)
 	}
 
 	// Verify deletion
-	remainingPrompts, err := testEnv.store.List()
+	remainingPrompts, err := testEnv.store.List(context.Background())
 	if err != nil {
 		t.Errorf("Store List after Delete failed: %v", err)
 	}
@@ -200,10 +201,10 @@ func testErrorHandlingIntegration(t *testing.T) {
 	
 	// Add a test prompt and try to delete it
 	testPrompt := createTestPrompt("perm1", "Permission Test", "tester", createValidGistURL("1234567890abcdef1234"))
-	testEnv.store.Add(testPrompt)
+	testEnv.store.Add(context.Background(), testPrompt)
 	
 	// Try to delete with permission error
-	err = testEnv.store.Delete(testPrompt.ID)
+	err = testEnv.store.Delete(context.Background(), testPrompt.ID)
 	if err == nil {
 		t.Error("Expected permission error, but got none")
 	}
@@ -246,7 +247,7 @@ func testURLValidationIntegration(t *testing.T) {
 		t.Logf("Invalid URL %s processing result: %v", invalidURL, err)
 		
 		// Verify store remains unchanged
-		prompts, _ := testEnv.store.List()
+		prompts, _ := testEnv.store.List(context.Background())
 		if len(prompts) != 0 {
 			t.Errorf("Store should remain empty for invalid URL processing")
 		}
@@ -352,7 +353,7 @@ func NewMockGitHubStore() *MockGitHubStore {
 }
 
 // List implements infra.Store.List
-func (m *MockGitHubStore) List() ([]model.Prompt, error) {
+func (m *MockGitHubStore) List(ctx context.Context) ([]model.Prompt, error) {
 	if m.ShouldSimulateNetworkError {
 		return nil, apperrors.NewAppError(apperrors.ErrNetwork, "simulated network error", nil)
 	}
@@ -365,7 +366,7 @@ func (m *MockGitHubStore) List() ([]model.Prompt, error) {
 }
 
 // Add implements infra.Store.Add
-func (m *MockGitHubStore) Add(prompt model.Prompt) error {
+func (m *MockGitHubStore) Add(ctx context.Context, prompt model.Prompt) error {
 	if m.ShouldSimulateNetworkError {
 		return apperrors.NewAppError(apperrors.ErrNetwork, "simulated network error", nil)
 	}
@@ -375,7 +376,7 @@ func (m *MockGitHubStore) Add(prompt model.Prompt) error {
 }
 
 // Delete implements infra.Store.Delete
-func (m *MockGitHubStore) Delete(id string) error {
+func (m *MockGitHubStore) Delete(ctx context.Context, id string) error {
 	m.DeleteMethodCalled = true
 	
 	if m.ShouldSimulateNetworkError {
@@ -391,7 +392,7 @@ func (m *MockGitHubStore) Delete(id string) error {
 }
 
 // Update implements infra.Store.Update
-func (m *MockGitHubStore) Update(prompt model.Prompt) error {
+func (m *MockGitHubStore) Update(ctx context.Context, prompt model.Prompt) error {
 	if m.ShouldSimulateNetworkError {
 		return apperrors.NewAppError(apperrors.ErrNetwork, "simulated network error", nil)
 	}
@@ -401,7 +402,7 @@ func (m *MockGitHubStore) Update(prompt model.Prompt) error {
 }
 
 // Get implements infra.Store.Get
-func (m *MockGitHubStore) Get(query string) ([]model.Prompt, error) {
+func (m *MockGitHubStore) Get(ctx context.Context, query string) ([]model.Prompt, error) {
 	if m.ShouldSimulateNetworkError {
 		return nil, apperrors.NewAppError(apperrors.ErrNetwork, "simulated network error", nil)
 	}
@@ -421,20 +422,20 @@ func (m *MockGitHubStore) Get(query string) ([]model.Prompt, error) {
 }
 
 // GetContent implements the missing Store method for testing
-func (m *MockGitHubStore) GetContent(gistID string) (string, error) {
+func (m *MockGitHubStore) GetContent(ctx context.Context, gistID string) (string, error) {
 	// This method is not used by integration tests but required by interface
 	return "", nil
 }
 
-func (m *MockGitHubStore) CreatePublicGist(prompt model.Prompt) (string, error) {
+func (m *MockGitHubStore) CreatePublicGist(ctx context.Context, prompt model.Prompt) (string, error) {
 	return "https://gist.github.com/test/123", nil
 }
 
-func (m *MockGitHubStore) UpdateGist(gistURL string, prompt model.Prompt) error {
+func (m *MockGitHubStore) UpdateGist(ctx context.Context, gistURL string, prompt model.Prompt) error {
 	return nil
 }
 
-func (m *MockGitHubStore) GetGistInfo(gistURL string) (*infra.GistInfo, error) {
+func (m *MockGitHubStore) GetGistInfo(ctx context.Context, gistURL string) (*infra.GistInfo, error) {
 	return &infra.GistInfo{
 		ID:        "123",
 		URL:       gistURL,
@@ -443,19 +444,19 @@ func (m *MockGitHubStore) GetGistInfo(gistURL string) (*infra.GistInfo, error) {
 	}, nil
 }
 
-func (m *MockGitHubStore) AddExport(prompt model.IndexedPrompt) error {
+func (m *MockGitHubStore) AddExport(ctx context.Context, prompt model.IndexedPrompt) error {
 	return nil
 }
 
-func (m *MockGitHubStore) UpdateExport(prompt model.IndexedPrompt) error {
+func (m *MockGitHubStore) UpdateExport(ctx context.Context, prompt model.IndexedPrompt) error {
 	return nil
 }
 
-func (m *MockGitHubStore) GetExports() ([]model.IndexedPrompt, error) {
+func (m *MockGitHubStore) GetExports(ctx context.Context) ([]model.IndexedPrompt, error) {
 	return []model.IndexedPrompt{}, nil
 }
 
-func (m *MockGitHubStore) FindExistingPromptByURL(gistURL string) (*model.Prompt, error) {
+func (m *MockGitHubStore) FindExistingPromptByURL(ctx context.Context, gistURL string) (*model.Prompt, error) {
 	return nil, nil
 }
 
@@ -514,12 +515,12 @@ func TestIntegrationTestSetup(t *testing.T) {
 	// Test basic store operations
 	testPrompt := createTestPrompt("test1", "Setup Test", "tester", createValidGistURL("1234567890abcdef1234"))
 	
-	err := env.store.Add(testPrompt)
+	err := env.store.Add(context.Background(), testPrompt)
 	if err != nil {
 		t.Fatalf("Failed to add test prompt: %v", err)
 	}
 	
-	prompts, err := env.store.List()
+	prompts, err := env.store.List(context.Background())
 	if err != nil {
 		t.Fatalf("Failed to list prompts: %v", err)
 	}
@@ -547,14 +548,14 @@ func BenchmarkDeleteWorkflow(b *testing.B) {
 			"benchuser",
 			createValidGistURL(fmt.Sprintf("benchmark%012d", i)),
 		)
-		env.store.Add(prompt)
+		env.store.Add(context.Background(), prompt)
 	}
 	
 	// Run benchmark
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		// Test service operations that are core to delete functionality
-		env.service.ListPrompts()
-		env.service.FilterPrompts("benchmark")
+		env.service.ListPrompts(context.Background())
+		env.service.FilterPrompts(context.Background(), "benchmark")
 	}
 }
\ No newline at end of file
@@ -0,0 +1,577 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grigri/pv/cmd"
+	"github.com/grigri/pv/internal/auth"
+	apperrors "github.com/grigri/pv/internal/errors"
+	"github.com/grigri/pv/internal/fakegist"
+	"github.com/grigri/pv/internal/infra"
+	"github.com/grigri/pv/internal/model"
+	"github.com/grigri/pv/internal/service"
+	"github.com/grigri/pv/internal/utils"
+	"github.com/grigri/pv/internal/validator"
+	"github.com/spf13/cobra"
+)
+
+// fakeToken is the token accepted by the fake GitHub API
+const fakeToken = "fake-token"
+
+const workflowPromptYAML = `name: "Go Code Review"
+author: "octocat"
+description: "Review Go code"
+tags:
+  - "go"
+version: "1.0"
+---
+Review the following Go code: {{code}}
+`
+
+// memoryConfigStore keeps the token in memory
+type memoryConfigStore struct {
+	token string
+}
+
+func (m *memoryConfigStore) SaveToken(token string) error {
+	m.token = token
+	return nil
+}
+
+func (m *memoryConfigStore) GetToken() (string, error) { return m.token, nil }
+
+func (m *memoryConfigStore) DeleteToken() error {
+	m.token = ""
+	return nil
+}
+
+func (m *memoryConfigStore) GetConfigPath() string { return "" }
+
+// WorkflowEnvironment wires the real stores and services to a fake GitHub API
+type WorkflowEnvironment struct {
+	api     *fakegist.Server
+	config  *memoryConfigStore
+	remote  infra.Store
+	store   infra.Store
+	service service.PromptService
+}
+
+func setupWorkflowEnvironment(t *testing.T) *WorkflowEnvironment {
+	t.Helper()
+
+	api := fakegist.New(t, fakeToken)
+	endpoints := api.Endpoints()
+	utils.SetGitHubEndpoints(endpoints)
+	t.Cleanup(func() {
+		utils.SetGitHubEndpoints(utils.GitHubEndpoints{GistHosts: []string{utils.DefaultGistHost}})
+	})
+	t.Setenv("PV_CACHE_DIR", t.TempDir())
+
+	configStore := &memoryConfigStore{token: fakeToken}
+	remote := infra.NewGitHubEnterpriseStore(configStore, endpoints)
+	cacheManager, err := infra.NewCacheManager()
+	if err != nil {
+		t.Fatalf("NewCacheManager failed: %v", err)
+	}
+	store := infra.NewCachedStore(remote, cacheManager, configStore, false)
+
+	return &WorkflowEnvironment{
+		api:     api,
+		config:  configStore,
+		remote:  remote,
+		store:   store,
+		service: service.NewPromptService(store, validator.NewYAMLValidator()),
+	}
+}
+
+// addPrompt adds the workflow prompt from a YAML file
+func (env *WorkflowEnvironment) addPrompt(t *testing.T) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "review.yaml")
+	if err := os.WriteFile(path, []byte(workflowPromptYAML), 0600); err != nil {
+		t.Fatalf("Failed to write prompt file: %v", err)
+	}
+
+	prompt, err := env.service.AddFromFile(context.Background(), path)
+	if err != nil {
+		t.Fatalf("AddFromFile failed: %v", err)
+	}
+
+	prompts, err := env.service.ListPrompts(context.Background())
+	if err != nil || len(prompts) != 1 {
+		t.Fatalf("ListPrompts failed: %v, %v", prompts, err)
+	}
+	if prompts[0].Name != prompt.Name {
+		t.Fatalf("Expected %q, got %q", prompt.Name, prompts[0].Name)
+	}
+	if prompt.GistURL != prompts[0].GistURL {
+		t.Fatalf("Expected the added prompt to carry its gist URL %q, got %q", prompts[0].GistURL, prompt.GistURL)
+	}
+	return prompts[0].ID
+}
+
+// TestGistWorkflowIntegration runs the add → list → get → share → delete workflow against the fake API
+func TestGistWorkflowIntegration(t *testing.T) {
+	env := setupWorkflowEnvironment(t)
+	ctx := context.Background()
+
+	// add → list
+	gistID := env.addPrompt(t)
+	files, public, ok := env.api.Gist(gistID)
+	if !ok || public || files["Go Code Review.yaml"] != workflowPromptYAML {
+		t.Fatalf("Expected a private gist holding the prompt file, got %v (public %v, exists %v)", files, public, ok)
+	}
+
+	// get
+	prompts, err := env.service.ListPrompts(ctx)
+	if err != nil {
+		t.Fatalf("ListPrompts failed: %v", err)
+	}
+	prompt, err := env.service.GetPromptByURL(ctx, prompts[0].GistURL)
+	if err != nil {
+		t.Fatalf("GetPromptByURL failed: %v", err)
+	}
+	content, err := env.service.GetPromptContent(ctx, prompt)
+	if err != nil || !strings.Contains(content, "Review the following Go code") {
+		t.Fatalf("GetPromptContent failed: %q, %v", content, err)
+	}
+
+	// share
+	shared, err := env.service.SharePrompt(ctx, prompt)
+	if err != nil {
+		t.Fatalf("SharePrompt failed: %v", err)
+	}
+	sharedID := utils.ExtractGistIDFromURL(shared.GistURL)
+	if _, public, ok := env.api.Gist(sharedID); !ok || !public {
+		t.Errorf("Expected a public gist at %s", shared.GistURL)
+	}
+	exports, err := env.store.GetExports(ctx)
+	if err != nil || len(exports) != 1 {
+		t.Errorf("Expected the share to be recorded as an export, got %v, %v", exports, err)
+	}
+	if promptDiff, err := env.service.DiffWithExport(ctx, prompt); err != nil || promptDiff.HasChanges() {
+		t.Errorf("Expected the public copy to match the prompt, got %+v, %v", promptDiff, err)
+	}
+
+	// delete
+	if err := env.service.DeleteByURL(ctx, prompt.GistURL); err != nil {
+		t.Fatalf("DeleteByURL failed: %v", err)
+	}
+	if _, _, ok := env.api.Gist(gistID); ok {
+		t.Error("Expected the prompt gist to be deleted")
+	}
+	if prompts, err := env.service.ListPrompts(ctx); err != nil || len(prompts) != 0 {
+		t.Errorf("Expected no prompts to be left, got %v, %v", prompts, err)
+	}
+}
+
+// TestGistHistoryIntegration browses the revision history of an edited prompt
+func TestGistHistoryIntegration(t *testing.T) {
+	env := setupWorkflowEnvironment(t)
+	ctx := context.Background()
+
+	gistID := env.addPrompt(t)
+	prompts, err := env.service.ListPrompts(ctx)
+	if err != nil {
+		t.Fatalf("ListPrompts failed: %v", err)
+	}
+	prompt := prompts[0]
+	prompt.Version = "1.1"
+	prompt.Content = strings.Replace(workflowPromptYAML, `version: "1.0"`, `version: "1.1"`, 1) + "Point out data races.\n"
+	if err := env.store.Update(ctx, prompt); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	revisions, err := env.service.GetPromptHistory(ctx, &prompt)
+	if err != nil || len(revisions) != 2 {
+		t.Fatalf("Expected 2 revisions, got %v, %v", revisions, err)
+	}
+	if revisions[0].Version != "1.1" || revisions[1].Version != "1.0" {
+		t.Errorf("Expected the versions newest first, got %+v", revisions)
+	}
+	if revisions[0].Additions != 2 || revisions[0].Deletions != 1 {
+		t.Errorf("Expected +2 -1 for the edit, got +%d -%d", revisions[0].Additions, revisions[0].Deletions)
+	}
+
+	content, err := env.service.GetPromptRevision(ctx, &prompt, revisions[1].SHA[:7])
+	if err != nil || content != workflowPromptYAML {
+		t.Errorf("Expected the first revision, got %q, %v", content, err)
+	}
+
+	promptDiff, err := env.service.DiffRevisions(ctx, &prompt, "", "")
+	if err != nil {
+		t.Fatalf("DiffRevisions failed: %v", err)
+	}
+	if len(promptDiff.Metadata) != 1 || promptDiff.Metadata[0].Field != "version" || len(promptDiff.Body) != 1 {
+		t.Errorf("Expected the version change and one body hunk, got %+v", promptDiff)
+	}
+
+	// The history read above stays available when GitHub can't be reached
+	env.api.Inject(fakegist.Fault{Path: "/gists/" + gistID + "/*", Status: http.StatusNotFound})
+	if cached, err := env.service.GetPromptHistory(ctx, &prompt); err != nil || len(cached) != 2 || cached[1].Version != "1.0" {
+		t.Errorf("Expected the cached history, got %v, %v", cached, err)
+	}
+}
+
+// TestGistEditIntegration saves an edit that renames the prompt
+func TestGistEditIntegration(t *testing.T) {
+	env := setupWorkflowEnvironment(t)
+	ctx := context.Background()
+
+	gistID := env.addPrompt(t)
+	prompts, err := env.service.ListPrompts(ctx)
+	if err != nil {
+		t.Fatalf("ListPrompts failed: %v", err)
+	}
+
+	edited := strings.Replace(workflowPromptYAML, `name: "Go Code Review"`, `name: "Go Review"`, 1)
+	if _, err := env.service.UpdatePromptContent(ctx, &prompts[0], edited); err != nil {
+		t.Fatalf("UpdatePromptContent failed: %v", err)
+	}
+	if files, _, _ := env.api.Gist(gistID); len(files) != 1 || files["Go Review.yaml"] != edited {
+		t.Errorf("Expected the prompt file to be renamed, got %v", files)
+	}
+	if entry := indexedPrompt(t, env, gistID); entry.Name != "Go Review" || entry.FilePath != "Go Review.yaml" {
+		t.Errorf("Expected the index entry to follow the rename, got %+v", entry)
+	}
+	// The rename keeps the history of the prompt
+	if revisions, err := env.service.GetPromptHistory(ctx, &prompts[0]); err != nil || len(revisions) != 2 {
+		t.Errorf("Expected 2 revisions, got %v, %v", revisions, err)
+	}
+}
+
+// TestGistMarkdownIntegration adds, reads and edits a Markdown prompt stored as .md
+func TestGistMarkdownIntegration(t *testing.T) {
+	env := setupWorkflowEnvironment(t)
+	ctx := context.Background()
+
+	content := "---\nname: Notes\nauthor: octocat\n---\n# Notes\n\nTake notes on {{topic}}\n"
+	path := filepath.Join(t.TempDir(), "notes.md")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := env.service.AddFromFile(ctx, path); err != nil {
+		t.Fatalf("AddFromFile failed: %v", err)
+	}
+
+	prompts, err := env.service.ListPrompts(ctx)
+	if err != nil || len(prompts) != 1 {
+		t.Fatalf("ListPrompts failed: %v, %v", prompts, err)
+	}
+	gistID := prompts[0].ID
+	if files, _, _ := env.api.Gist(gistID); len(files) != 1 || files["Notes.md"] != content {
+		t.Errorf("Expected the prompt to be stored as Notes.md, got %v", files)
+	}
+	if got, err := env.service.GetPromptContent(ctx, &prompts[0]); err != nil || got != content {
+		t.Errorf("Expected the Markdown content, got %q, %v", got, err)
+	}
+
+	edited := strings.Replace(content, "# Notes", "# Meeting notes", 1)
+	if _, err := env.service.UpdatePromptContent(ctx, &prompts[0], edited); err != nil {
+		t.Fatalf("UpdatePromptContent failed: %v", err)
+	}
+	if files, _, _ := env.api.Gist(gistID); len(files) != 1 || files["Notes.md"] != edited {
+		t.Errorf("Expected the edit to keep Notes.md, got %v", files)
+	}
+	if entry := indexedPrompt(t, env, gistID); entry.FilePath != "Notes.md" {
+		t.Errorf("Expected the index to record Notes.md, got %+v", entry)
+	}
+}
+
+// TestGistBulkAddIntegration adds a directory of prompts with a single index write
+func TestGistBulkAddIntegration(t *testing.T) {
+	env := setupWorkflowEnvironment(t)
+	ctx := context.Background()
+
+	gistID := env.addPrompt(t)
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"review.yaml":         strings.Replace(workflowPromptYAML, `version: "1.0"`, `version: "1.1"`, 1),
+		"summary.yaml":        "name: Summary\nauthor: octocat\n---\nSummarize {{text}}\n",
+		"nested/explain.yaml": "name: Explain\nauthor: octocat\n---\nExplain {{code}}\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	plan, err := env.service.PlanAddFromFiles(ctx, []string{dir}, true)
+	if err != nil || len(plan.Failures) != 0 {
+		t.Fatalf("PlanAddFromFiles failed: %v, %+v", err, plan.Failures)
+	}
+	if plan.Count(service.AddCreate) != 2 || plan.Count(service.AddUpdate) != 1 {
+		t.Fatalf("Expected 2 creates and 1 update, got %+v", plan.Adds)
+	}
+
+	before := len(env.api.Requests())
+	if _, err := env.service.ApplyAddPlan(ctx, plan); err != nil {
+		t.Fatalf("ApplyAddPlan failed: %v", err)
+	}
+	// One edit of the updated prompt gist and one of the index
+	edits := 0
+	for _, request := range env.api.Requests()[before:] {
+		if strings.HasPrefix(request, "PATCH ") {
+			edits++
+		}
+	}
+	if edits != 2 {
+		t.Errorf("Expected 2 gist edits, got %d", edits)
+	}
+
+	prompts, err := env.service.ListPrompts(ctx)
+	if err != nil || len(prompts) != 3 {
+		t.Fatalf("Expected 3 prompts, got %v, %v", prompts, err)
+	}
+	if entry := indexedPrompt(t, env, gistID); entry.Version != "1.1" {
+		t.Errorf("Expected the existing prompt to be updated, got %+v", entry)
+	}
+}
+
+// TestGistAddJSONIntegration checks that pv add --json prints nothing but the JSON on stdout
+func TestGistAddJSONIntegration(t *testing.T) {
+	env := setupWorkflowEnvironment(t)
+
+	// 存储层直接写 os.Stdout 的输出也会破坏 JSON，所以捕获整个标准输出
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = writer
+	defer func() { os.Stdout = stdout }()
+
+	addCmd := (*cobra.Command)(cmd.NewAddCommand(env.service))
+	addCmd.SetIn(strings.NewReader(workflowPromptYAML))
+	addCmd.SetArgs([]string{"-", "--json"})
+	err = addCmd.Execute()
+	os.Stdout = stdout
+	writer.Close()
+	if err != nil {
+		t.Fatalf("add failed: %v", err)
+	}
+
+	out, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var added struct {
+		Name    string `json:"name"`
+		GistURL string `json:"gist_url"`
+	}
+	if err := json.Unmarshal(out, &added); err != nil {
+		t.Fatalf("Expected stdout to hold only JSON, got %q: %v", out, err)
+	}
+
+	prompts, err := env.service.ListPrompts(context.Background())
+	if err != nil || len(prompts) != 1 || added.GistURL != prompts[0].GistURL || added.Name != "Go Code Review" {
+		t.Errorf("Expected the added prompt with its gist URL, got %+v (prompts %v, %v)", added, prompts, err)
+	}
+}
+
+// TestGistRollbackIntegration restores an earlier revision and carries it over to the public copy
+func TestGistRollbackIntegration(t *testing.T) {
+	env := setupWorkflowEnvironment(t)
+	ctx := context.Background()
+
+	gistID := env.addPrompt(t)
+	prompts, err := env.service.ListPrompts(ctx)
+	if err != nil {
+		t.Fatalf("ListPrompts failed: %v", err)
+	}
+	prompt := prompts[0]
+	prompt.Version = "1.1"
+	prompt.Content = strings.Replace(workflowPromptYAML, `version: "1.0"`, `version: "1.1"`, 1) + "Point out data races.\n"
+	if err := env.store.Update(ctx, prompt); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if _, err := env.service.SharePrompt(ctx, &prompt); err != nil {
+		t.Fatalf("SharePrompt failed: %v", err)
+	}
+	updatedAt := indexedPrompt(t, env, gistID).LastUpdated
+
+	revisions, err := env.service.GetPromptHistory(ctx, &prompt)
+	if err != nil || len(revisions) != 2 {
+		t.Fatalf("Expected 2 revisions, got %v, %v", revisions, err)
+	}
+	first := revisions[1].ShortSHA()
+
+	preview, err := env.service.PreviewRollback(ctx, &prompt, first)
+	if err != nil || len(preview.Metadata) != 1 || len(preview.Body) != 1 {
+		t.Fatalf("Expected the version change and one body hunk, got %+v, %v", preview, err)
+	}
+
+	result, err := env.service.RollbackPrompt(ctx, &prompt, first)
+	if err != nil {
+		t.Fatalf("RollbackPrompt failed: %v", err)
+	}
+	if files, _, _ := env.api.Gist(gistID); files["Go Code Review.yaml"] != workflowPromptYAML {
+		t.Errorf("Expected the gist to hold the first revision again, got %v", files)
+	}
+	if entry := indexedPrompt(t, env, gistID); entry.Version != "1.0" || !entry.LastUpdated.After(updatedAt) {
+		t.Errorf("Expected the index entry to be updated, got %+v", entry)
+	}
+	// The rollback is a revision of its own
+	if revisions, err := env.service.GetPromptHistory(ctx, &prompt); err != nil || len(revisions) != 3 || revisions[0].Version != "1.0" {
+		t.Errorf("Expected the rollback on top of the history, got %+v, %v", revisions, err)
+	}
+	if _, err := env.service.RollbackPrompt(ctx, &prompt, first); err != apperrors.ErrNothingToRollback {
+		t.Errorf("Expected ErrNothingToRollback, got %v", err)
+	}
+
+	// The public copy still has the edit until the rollback is shared
+	if result.Export == nil {
+		t.Fatal("Expected the rollback to report the public copy")
+	}
+	if promptDiff, err := env.service.DiffWithExport(ctx, &prompt); err != nil || !promptDiff.HasChanges() {
+		t.Errorf("Expected the public copy to differ, got %+v, %v", promptDiff, err)
+	}
+	if _, err := env.service.SharePrompt(ctx, result.Prompt); err != nil {
+		t.Fatalf("SharePrompt failed: %v", err)
+	}
+	if promptDiff, err := env.service.DiffWithExport(ctx, &prompt); err != nil || promptDiff.HasChanges() {
+		t.Errorf("Expected the public copy to match the rollback, got %+v, %v", promptDiff, err)
+	}
+}
+
+// indexedPrompt reads the entry of a prompt from the index gist
+func indexedPrompt(t *testing.T, env *WorkflowEnvironment, gistID string) model.IndexedPrompt {
+	t.Helper()
+
+	raw, err := env.remote.(infra.RawIndexProvider).GetRawIndexContent(context.Background())
+	if err != nil {
+		t.Fatalf("GetRawIndexContent failed: %v", err)
+	}
+	var index model.Index
+	if err := json.Unmarshal([]byte(raw), &index); err != nil {
+		t.Fatalf("Failed to parse the index: %v", err)
+	}
+	for _, entry := range index.Prompts {
+		if utils.ExtractGistIDFromURL(entry.GistURL) == gistID {
+			return entry
+		}
+	}
+	t.Fatalf("Prompt %s is not in the index", gistID)
+	return model.IndexedPrompt{}
+}
+
+// TestGistAuthIntegration logs in against the fake API's user and scopes endpoints
+func TestGistAuthIntegration(t *testing.T) {
+	env := setupWorkflowEnvironment(t)
+	ctx := context.Background()
+
+	newAuthService := func() service.AuthService {
+		client := auth.NewGitHubClientWithBaseURL(env.api.Endpoints().APIURL)
+		return service.NewAuthService(env.config, client, auth.NewTokenValidator(client))
+	}
+
+	env.config.token = ""
+	authService := newAuthService()
+	if err := authService.Login(ctx, fakeToken); err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+	status, err := authService.GetStatus(ctx)
+	if err != nil || !status.IsAuthenticated || status.Username != env.api.Login {
+		t.Fatalf("Expected to be logged in as %s, got %+v, %v", env.api.Login, status, err)
+	}
+
+	if err := authService.Login(ctx, "wrong-token"); err == nil {
+		t.Error("Expected an invalid token to be rejected")
+	}
+
+	env.api.Scopes = []string{"repo"}
+	if err := newAuthService().Login(ctx, fakeToken); err == nil {
+		t.Error("Expected a token without the gist scope to be rejected")
+	}
+}
+
+// TestGistFaultIntegration checks how pv reacts to failures injected into the fake API
+func TestGistFaultIntegration(t *testing.T) {
+	t.Run("not found", func(t *testing.T) {
+		env := setupWorkflowEnvironment(t)
+		gistID := env.addPrompt(t)
+		if _, err := env.store.GetContent(context.Background(), gistID); err != nil {
+			t.Fatalf("GetContent failed: %v", err)
+		}
+
+		env.api.Inject(fakegist.Fault{Method: http.MethodGet, Path: "/gists/" + gistID, Status: http.StatusNotFound})
+
+		if _, err := env.remote.GetContent(context.Background(), gistID); err == nil || !strings.Contains(err.Error(), "404") {
+			t.Errorf("Expected a 404 from the remote store, got %v", err)
+		}
+		// The cached store serves the last known content instead
+		if content, err := env.store.GetContent(context.Background(), gistID); err != nil || content != workflowPromptYAML {
+			t.Errorf("Expected the cached content, got %q, %v", content, err)
+		}
+	})
+
+	t.Run("forbidden", func(t *testing.T) {
+		env := setupWorkflowEnvironment(t)
+		env.addPrompt(t)
+
+		env.api.Inject(fakegist.Fault{Status: http.StatusForbidden})
+
+		_, err := env.service.Sync(context.Background())
+		if apperrors.TypeOf(err, apperrors.ErrUnknown) != apperrors.ErrPermission {
+			t.Errorf("Expected a permission error, got %v", err)
+		}
+	})
+
+	t.Run("rate limited", func(t *testing.T) {
+		env := setupWorkflowEnvironment(t)
+		gistID := env.addPrompt(t)
+
+		env.api.Inject(fakegist.Fault{Method: http.MethodGet, Path: "/gists/*", RateLimit: true})
+
+		_, err := env.remote.GetContent(context.Background(), gistID)
+		if apperrors.TypeOf(err, apperrors.ErrUnknown) != apperrors.ErrNetwork {
+			t.Errorf("Expected a rate limit to be reported as a network error, got %v", err)
+		}
+	})
+
+	t.Run("secondary rate limit is retried", func(t *testing.T) {
+		env := setupWorkflowEnvironment(t)
+		gistID := env.addPrompt(t)
+
+		env.api.Inject(fakegist.Fault{Method: http.MethodGet, Path: "/gists/*", SecondaryRateLimit: true, Times: 1})
+
+		if content, err := env.remote.GetContent(context.Background(), gistID); err != nil || content != workflowPromptYAML {
+			t.Errorf("Expected the request to succeed after Retry-After, got %q, %v", content, err)
+		}
+	})
+
+	t.Run("slow responses time out to the cache", func(t *testing.T) {
+		env := setupWorkflowEnvironment(t)
+		gistID := env.addPrompt(t)
+		if _, err := env.store.GetContent(context.Background(), gistID); err != nil {
+			t.Fatalf("GetContent failed: %v", err)
+		}
+
+		env.api.Inject(fakegist.Fault{Method: http.MethodGet, Path: "/gists/*", Delay: 5 * time.Second})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		content, err := env.store.GetContent(ctx, gistID)
+		if err != nil || content != workflowPromptYAML {
+			t.Errorf("Expected the cached content after the timeout, got %q, %v", content, err)
+		}
+		if elapsed := time.Since(start); elapsed > 2*time.Second {
+			t.Errorf("Expected the timeout to cut the request short, took %v", elapsed)
+		}
+	})
+}
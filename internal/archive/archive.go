@@ -0,0 +1,222 @@
+// Package archive reads and writes portable vault archives
+//
+// An archive is a gzip-compressed tar file holding:
+//
+//	manifest.json                 format version, creation time and the checksum of every file
+//	index.json                    the model.Index of the vault, prompts and exports
+//	prompts/<n>/<name>.yaml|.md   the content of each prompt
+//	exports/<n>/<name>.yaml|.md   the content of each shared public copy
+package archive
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/grigri/pv/internal/model"
+)
+
+// FormatVersion is the archive format version written by this build
+const FormatVersion = 1
+
+const (
+	manifestPath = "manifest.json"
+	indexPath    = "index.json"
+)
+
+// maxFileSize limits the size of a single file read from an archive
+const maxFileSize = 64 << 20
+
+// Archive is the content of a vault archive
+type Archive struct {
+	CreatedAt time.Time
+	Index     model.Index
+	// Contents maps the gist URL of every prompt and export to the content of its prompt file
+	Contents map[string]string
+}
+
+// Manifest describes the files of an archive
+type Manifest struct {
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	Files     []File    `json:"files"`
+}
+
+// File is a file of an archive and its checksum
+type File struct {
+	Path string `json:"path"`
+	// GistURL is the index entry the file holds the content of; empty for index.json
+	GistURL string `json:"gist_url,omitempty"`
+	// SHA256 is the digest of the file in the form of model.ContentHash
+	SHA256 string `json:"sha256"`
+	Size   int    `json:"size"`
+}
+
+// Write writes a as a gzip-compressed tar archive
+// Every index entry must have its content in a.Contents.
+func Write(w io.Writer, a *Archive) error {
+	indexData, err := json.MarshalIndent(a.Index, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode index: %w", err)
+	}
+
+	type entry struct {
+		path string
+		data []byte
+	}
+	entries := []entry{{indexPath, indexData}}
+	manifest := Manifest{
+		Version:   FormatVersion,
+		CreatedAt: a.CreatedAt,
+		Files:     []File{fileOf(indexPath, "", indexData)},
+	}
+
+	add := func(dir string, prompts []model.IndexedPrompt) error {
+		for i, prompt := range prompts {
+			content, ok := a.Contents[prompt.GistURL]
+			if !ok {
+				return fmt.Errorf("missing content of %s (%s)", prompt.Name, prompt.GistURL)
+			}
+			filePath := path.Join(dir, fmt.Sprint(i+1), fileName(prompt))
+			entries = append(entries, entry{filePath, []byte(content)})
+			manifest.Files = append(manifest.Files, fileOf(filePath, prompt.GistURL, []byte(content)))
+		}
+		return nil
+	}
+	if err := add("prompts", a.Index.Prompts); err != nil {
+		return err
+	}
+	if err := add("exports", a.Index.Exports); err != nil {
+		return err
+	}
+
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	// manifest 放在最前面，读取时先知道格式版本
+	entries = append([]entry{{manifestPath, manifestData}}, entries...)
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	for _, e := range entries {
+		header := &tar.Header{
+			Name:    e.path,
+			Mode:    0600,
+			Size:    int64(len(e.data)),
+			ModTime: a.CreatedAt,
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return fmt.Errorf("failed to write %s: %w", e.path, err)
+		}
+		if _, err := tw.Write(e.data); err != nil {
+			return fmt.Errorf("failed to write %s: %w", e.path, err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	return gz.Close()
+}
+
+// Read reads an archive written by Write and verifies the checksums of its files
+// Returns an error if a file is missing, altered or not listed in the manifest.
+func Read(r io.Reader) (*Archive, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("not a vault archive: %w", err)
+	}
+	defer gz.Close()
+
+	files := map[string][]byte{}
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read archive: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		if header.Size > maxFileSize {
+			return nil, fmt.Errorf("%s is too large", header.Name)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", header.Name, err)
+		}
+		files[header.Name] = data
+	}
+
+	manifestData, ok := files[manifestPath]
+	if !ok {
+		return nil, fmt.Errorf("not a vault archive: missing %s", manifestPath)
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(manifestData, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", manifestPath, err)
+	}
+	if manifest.Version < 1 || manifest.Version > FormatVersion {
+		return nil, fmt.Errorf("unsupported archive version %d, this pv reads version %d", manifest.Version, FormatVersion)
+	}
+
+	a := &Archive{CreatedAt: manifest.CreatedAt, Contents: map[string]string{}}
+	listed := map[string]bool{manifestPath: true}
+	for _, file := range manifest.Files {
+		data, ok := files[file.Path]
+		if !ok {
+			return nil, fmt.Errorf("archive is incomplete: missing %s", file.Path)
+		}
+		if model.ContentHash(string(data)) != file.SHA256 || len(data) != file.Size {
+			return nil, fmt.Errorf("checksum mismatch for %s, the archive is corrupted", file.Path)
+		}
+		listed[file.Path] = true
+		if file.GistURL != "" {
+			a.Contents[file.GistURL] = string(data)
+		}
+	}
+	for name := range files {
+		if !listed[name] {
+			return nil, fmt.Errorf("%s is not listed in the manifest", name)
+		}
+	}
+
+	indexData, ok := files[indexPath]
+	if !ok || !listed[indexPath] {
+		return nil, fmt.Errorf("archive is incomplete: missing %s", indexPath)
+	}
+	if err := json.Unmarshal(indexData, &a.Index); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", indexPath, err)
+	}
+	for _, prompt := range append(append([]model.IndexedPrompt{}, a.Index.Prompts...), a.Index.Exports...) {
+		if _, ok := a.Contents[prompt.GistURL]; !ok {
+			return nil, fmt.Errorf("archive is incomplete: missing content of %s (%s)", prompt.Name, prompt.GistURL)
+		}
+	}
+
+	return a, nil
+}
+
+// fileOf describes a file of the archive
+func fileOf(filePath, gistURL string, data []byte) File {
+	return File{Path: filePath, GistURL: gistURL, SHA256: model.ContentHash(string(data)), Size: len(data)}
+}
+
+// fileName returns the name the content of an index entry is archived under
+// Exports record no file path, so their name comes from the prompt name.
+func fileName(prompt model.IndexedPrompt) string {
+	name := path.Base(strings.ReplaceAll(prompt.FilePath, "\\", "/"))
+	if prompt.FilePath == "" || name == "." || name == "/" {
+		name = strings.ReplaceAll(prompt.Name, "/", "_") + ".yaml"
+	}
+	return name
+}
@@ -0,0 +1,166 @@
+package archive
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grigri/pv/internal/model"
+)
+
+func testArchive() *Archive {
+	parent := "https://gist.github.com/alice/private1"
+	return &Archive{
+		CreatedAt: time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC),
+		Index: model.Index{
+			SchemaVersion: model.CurrentIndexSchemaVersion,
+			Prompts: []model.IndexedPrompt{
+				{GistURL: parent, FilePath: "Review.yaml", Name: "Review", Author: "alice"},
+				{GistURL: "https://gist.github.com/alice/private2", FilePath: "Notes.md", Name: "Notes", Author: "alice"},
+			},
+			Exports: []model.IndexedPrompt{
+				{GistURL: "https://gist.github.com/alice/public1", Name: "Review", Author: "alice", Parent: &parent},
+			},
+		},
+		Contents: map[string]string{
+			parent:                                   "name: Review\nauthor: alice\n---\nReview {code}\n",
+			"https://gist.github.com/alice/private2": "---\nname: Notes\nauthor: alice\n---\n# Notes\n",
+			"https://gist.github.com/alice/public1":  "name: Review\nauthor: alice\n---\nReview {code}\n",
+		},
+	}
+}
+
+func TestWriteRead(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, testArchive()); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	names := tarNames(t, buf.Bytes())
+	if want := []string{"manifest.json", "index.json", "prompts/1/Review.yaml", "prompts/2/Notes.md", "exports/1/Review.yaml"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("Expected files %v, got %v", want, names)
+	}
+
+	a, err := Read(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	want := testArchive()
+	if !a.CreatedAt.Equal(want.CreatedAt) || !reflect.DeepEqual(a.Contents, want.Contents) {
+		t.Errorf("Expected %+v, got %+v", want, a)
+	}
+	if len(a.Index.Prompts) != 2 || len(a.Index.Exports) != 1 || *a.Index.Exports[0].Parent != want.Index.Prompts[0].GistURL {
+		t.Errorf("Expected the index to round-trip, got %+v", a.Index)
+	}
+}
+
+func TestWrite_MissingContent(t *testing.T) {
+	a := testArchive()
+	delete(a.Contents, "https://gist.github.com/alice/public1")
+	if err := Write(io.Discard, a); err == nil {
+		t.Error("Expected an archive without the content of an export not to be written")
+	}
+}
+
+func TestRead_Invalid(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, testArchive()); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(files map[string]string)
+		want   string
+	}{
+		{"altered content", func(files map[string]string) { files["prompts/1/Review.yaml"] += "extra\n" }, "checksum mismatch"},
+		{"missing file", func(files map[string]string) { delete(files, "exports/1/Review.yaml") }, "missing exports/1/Review.yaml"},
+		{"unlisted file", func(files map[string]string) { files["prompts/3/Other.yaml"] = "x" }, "not listed"},
+		{"newer version", func(files map[string]string) {
+			files["manifest.json"] = strings.Replace(files["manifest.json"], `"version": 1`, `"version": 99`, 1)
+		}, "unsupported archive version"},
+		{"no manifest", func(files map[string]string) { delete(files, "manifest.json") }, "not a vault archive"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := tarFiles(t, buf.Bytes())
+			tt.modify(files)
+			_, err := Read(bytes.NewReader(buildTar(t, files)))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected an error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+
+	if _, err := Read(strings.NewReader("not gzip")); err == nil {
+		t.Error("Expected a file that isn't an archive not to be read")
+	}
+}
+
+// tarNames lists the names of the files of an archive in order
+func tarNames(t *testing.T, data []byte) []string {
+	t.Helper()
+	var names []string
+	walkTar(t, data, func(name string, _ []byte) { names = append(names, name) })
+	return names
+}
+
+// tarFiles returns the files of an archive by name
+func tarFiles(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+	files := map[string]string{}
+	walkTar(t, data, func(name string, content []byte) { files[name] = string(content) })
+	return files
+}
+
+func walkTar(t *testing.T, data []byte, visit func(name string, content []byte)) {
+	t.Helper()
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		visit(header.Name, content)
+	}
+}
+
+// buildTar writes files into a gzip-compressed tar archive
+func buildTar(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: int64(len(content))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,112 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/grigri/pv/internal/errors"
+)
+
+// GiteaRepositoryScope is the token scope required by the Gitea backend
+const GiteaRepositoryScope = "write:repository"
+
+// GiteaClient defines the Gitea API operations used by the auth flow
+// It mirrors GitHubClient so the same TokenValidator and AuthService can be reused
+type GiteaClient interface {
+	// GetAuthenticatedUser retrieves the authenticated user information
+	GetAuthenticatedUser(ctx context.Context, token string) (*User, error)
+
+	// ValidateScopes returns the scopes granted to the access token
+	ValidateScopes(ctx context.Context, token string) ([]string, error)
+}
+
+// giteaClient implements the GiteaClient interface
+type giteaClient struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewGiteaClient creates a new Gitea API client for the instance at baseURL
+func NewGiteaClient(baseURL string) GiteaClient {
+	return &giteaClient{
+		baseURL: strings.TrimRight(baseURL, "/") + "/api/v1",
+		client: &http.Client{
+			Timeout: defaultTimeout,
+		},
+	}
+}
+
+// giteaUser is the subset of the Gitea user payload used by pv
+type giteaUser struct {
+	Login    string `json:"login"`
+	Email    string `json:"email"`
+	FullName string `json:"full_name"`
+}
+
+// GetAuthenticatedUser retrieves the authenticated user information
+func (c *giteaClient) GetAuthenticatedUser(ctx context.Context, token string) (*User, error) {
+	var user giteaUser
+	if err := c.get(ctx, "/user", token, &user); err != nil {
+		return nil, err
+	}
+
+	return &User{
+		Login: user.Login,
+		Email: user.Email,
+		Name:  user.FullName,
+	}, nil
+}
+
+// ValidateScopes checks the token against the instance
+// Gitea does not report the scopes of the calling token, so a token that can
+// read its user is treated as having repository access; a token without write
+// access is rejected by the first store operation instead
+func (c *giteaClient) ValidateScopes(ctx context.Context, token string) ([]string, error) {
+	var user giteaUser
+	if err := c.get(ctx, "/user", token, &user); err != nil {
+		return nil, err
+	}
+
+	return []string{GiteaRepositoryScope}, nil
+}
+
+// get performs an authenticated GET request and decodes the JSON response
+func (c *giteaClient) get(ctx context.Context, path, token string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+path, nil)
+	if err != nil {
+		return errors.NewAppError(errors.ErrNetwork, "failed to create request", err)
+	}
+
+	req.Header.Set("Authorization", "token "+token)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return errors.NewAppError(errors.ErrNetwork, "Unable to connect to Gitea API. Please check your internet connection", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return errors.ErrInvalidToken
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return errors.NewAppError(
+			errors.ErrNetwork,
+			fmt.Sprintf("Gitea API returned status %d", resp.StatusCode),
+			nil,
+		)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return errors.NewAppError(errors.ErrNetwork, "failed to decode response", err)
+	}
+
+	return nil
+}
+
+// Verify interface compliance: a GiteaClient can drive the shared TokenValidator
+var _ GitHubClient = (GiteaClient)(nil)
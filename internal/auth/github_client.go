@@ -1,12 +1,14 @@
 package auth
 
+import "context"
+
 // GitHubClient defines the interface for GitHub API operations
 type GitHubClient interface {
 	// GetAuthenticatedUser retrieves the authenticated user information
-	GetAuthenticatedUser(token string) (*User, error)
+	GetAuthenticatedUser(ctx context.Context, token string) (*User, error)
 
 	// ValidateScopes checks if the token has the required scopes
-	ValidateScopes(token string) ([]string, error)
+	ValidateScopes(ctx context.Context, token string) ([]string, error)
 }
 
 // User represents a GitHub user
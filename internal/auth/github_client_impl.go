@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -8,6 +9,7 @@ import (
 	"time"
 
 	"github.com/grigri/pv/internal/errors"
+	"github.com/grigri/pv/internal/utils"
 )
 
 const (
@@ -26,17 +28,24 @@ type githubClient struct {
 
 // NewGitHubClient creates a new GitHub API client
 func NewGitHubClient() GitHubClient {
+	return NewGitHubClientWithBaseURL(GitHubAPIBaseURL)
+}
+
+// NewGitHubClientWithBaseURL creates a GitHub API client for another API base URL,
+// such as https://github.example.com/api/v3 on GitHub Enterprise Server
+func NewGitHubClientWithBaseURL(baseURL string) GitHubClient {
 	return &githubClient{
-		baseURL: GitHubAPIBaseURL,
+		baseURL: strings.TrimRight(baseURL, "/"),
 		client: &http.Client{
-			Timeout: defaultTimeout,
+			Timeout:   defaultTimeout,
+			Transport: utils.NewRetryTransport(nil),
 		},
 	}
 }
 
 // GetAuthenticatedUser retrieves the authenticated user information
-func (c *githubClient) GetAuthenticatedUser(token string) (*User, error) {
-	req, err := http.NewRequest("GET", c.baseURL+"/user", nil)
+func (c *githubClient) GetAuthenticatedUser(ctx context.Context, token string) (*User, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+"/user", nil)
 	if err != nil {
 		return nil, errors.NewAppError(errors.ErrNetwork, "failed to create request", err)
 	}
@@ -54,6 +63,10 @@ func (c *githubClient) GetAuthenticatedUser(token string) (*User, error) {
 		return nil, errors.ErrInvalidToken
 	}
 
+	if resp.StatusCode == http.StatusForbidden {
+		return nil, errors.NewAppError(errors.ErrPermission, "GitHub 拒绝了此请求，请检查 token 权限", nil)
+	}
+
 	if resp.StatusCode != http.StatusOK {
 		return nil, errors.NewAppError(
 			errors.ErrNetwork,
@@ -71,9 +84,9 @@ func (c *githubClient) GetAuthenticatedUser(token string) (*User, error) {
 }
 
 // ValidateScopes checks if the token has the required scopes
-func (c *githubClient) ValidateScopes(token string) ([]string, error) {
+func (c *githubClient) ValidateScopes(ctx context.Context, token string) ([]string, error) {
 	// Use HEAD request to minimize data transfer
-	req, err := http.NewRequest("HEAD", c.baseURL+"/", nil)
+	req, err := http.NewRequestWithContext(ctx, "HEAD", c.baseURL+"/", nil)
 	if err != nil {
 		return nil, errors.NewAppError(errors.ErrNetwork, "failed to create request", err)
 	}
@@ -90,6 +103,10 @@ func (c *githubClient) ValidateScopes(token string) ([]string, error) {
 		return nil, errors.ErrInvalidToken
 	}
 
+	if resp.StatusCode == http.StatusForbidden {
+		return nil, errors.NewAppError(errors.ErrPermission, "GitHub 拒绝了此请求，请检查 token 权限", nil)
+	}
+
 	if resp.StatusCode != http.StatusOK {
 		return nil, errors.NewAppError(
 			errors.ErrNetwork,
@@ -1,5 +1,7 @@
 package auth
 
+import "context"
+
 // MockGitHubClient is a mock implementation of GitHubClient for testing
 type MockGitHubClient struct {
 	// GetAuthenticatedUserFunc allows customizing the behavior of GetAuthenticatedUser
@@ -40,7 +42,7 @@ func NewMockGitHubClient() *MockGitHubClient {
 }
 
 // GetAuthenticatedUser implements GitHubClient
-func (m *MockGitHubClient) GetAuthenticatedUser(token string) (*User, error) {
+func (m *MockGitHubClient) GetAuthenticatedUser(ctx context.Context, token string) (*User, error) {
 	m.Calls.GetAuthenticatedUser = append(m.Calls.GetAuthenticatedUser, token)
 	if m.GetAuthenticatedUserFunc != nil {
 		return m.GetAuthenticatedUserFunc(token)
@@ -49,7 +51,7 @@ func (m *MockGitHubClient) GetAuthenticatedUser(token string) (*User, error) {
 }
 
 // ValidateScopes implements GitHubClient
-func (m *MockGitHubClient) ValidateScopes(token string) ([]string, error) {
+func (m *MockGitHubClient) ValidateScopes(ctx context.Context, token string) ([]string, error) {
 	m.Calls.ValidateScopes = append(m.Calls.ValidateScopes, token)
 	if m.ValidateScopesFunc != nil {
 		return m.ValidateScopesFunc(token)
@@ -0,0 +1,123 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/grigri/pv/internal/errors"
+)
+
+const (
+	// GitLabBaseURL is the default GitLab instance
+	GitLabBaseURL = "https://gitlab.com"
+
+	// gitLabAPIPath is the REST API prefix of a GitLab instance
+	gitLabAPIPath = "/api/v4"
+)
+
+// GitLabClient defines the GitLab API operations used by the auth flow
+// It mirrors GitHubClient so the same TokenValidator and AuthService can be reused
+type GitLabClient interface {
+	// GetAuthenticatedUser retrieves the authenticated user information
+	GetAuthenticatedUser(ctx context.Context, token string) (*User, error)
+
+	// ValidateScopes returns the scopes granted to the personal access token
+	ValidateScopes(ctx context.Context, token string) ([]string, error)
+}
+
+// gitlabClient implements the GitLabClient interface
+type gitlabClient struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewGitLabClient creates a new GitLab API client for the instance at baseURL
+// An empty baseURL selects gitlab.com
+func NewGitLabClient(baseURL string) GitLabClient {
+	if baseURL == "" {
+		baseURL = GitLabBaseURL
+	}
+	return &gitlabClient{
+		baseURL: strings.TrimRight(baseURL, "/") + gitLabAPIPath,
+		client: &http.Client{
+			Timeout: defaultTimeout,
+		},
+	}
+}
+
+// gitLabUser is the subset of the GitLab user payload used by pv
+type gitLabUser struct {
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	Name     string `json:"name"`
+}
+
+// GetAuthenticatedUser retrieves the authenticated user information
+func (c *gitlabClient) GetAuthenticatedUser(ctx context.Context, token string) (*User, error) {
+	var user gitLabUser
+	if err := c.get(ctx, "/user", token, &user); err != nil {
+		return nil, err
+	}
+
+	return &User{
+		Login: user.Username,
+		Email: user.Email,
+		Name:  user.Name,
+	}, nil
+}
+
+// ValidateScopes returns the scopes granted to the personal access token
+func (c *gitlabClient) ValidateScopes(ctx context.Context, token string) ([]string, error) {
+	var tokenInfo struct {
+		Scopes []string `json:"scopes"`
+	}
+	if err := c.get(ctx, "/personal_access_tokens/self", token, &tokenInfo); err != nil {
+		return nil, err
+	}
+
+	if tokenInfo.Scopes == nil {
+		return []string{}, nil
+	}
+	return tokenInfo.Scopes, nil
+}
+
+// get performs an authenticated GET request and decodes the JSON response
+func (c *gitlabClient) get(ctx context.Context, path, token string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+path, nil)
+	if err != nil {
+		return errors.NewAppError(errors.ErrNetwork, "failed to create request", err)
+	}
+
+	req.Header.Set("PRIVATE-TOKEN", token)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return errors.NewAppError(errors.ErrNetwork, "Unable to connect to GitLab API. Please check your internet connection", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return errors.ErrInvalidToken
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return errors.NewAppError(
+			errors.ErrNetwork,
+			fmt.Sprintf("GitLab API returned status %d", resp.StatusCode),
+			nil,
+		)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return errors.NewAppError(errors.ErrNetwork, "failed to decode response", err)
+	}
+
+	return nil
+}
+
+// Verify interface compliance: a GitLabClient can drive the shared TokenValidator
+var _ GitHubClient = (GitLabClient)(nil)
@@ -1,13 +1,24 @@
 package auth
 
 import (
+	"context"
+	"fmt"
+
 	"github.com/grigri/pv/internal/errors"
 )
 
+const (
+	// GistScope is the token scope required by the GitHub gist backend
+	GistScope = "gist"
+
+	// GitLabAPIScope is the token scope required by the GitLab snippets backend
+	GitLabAPIScope = "api"
+)
+
 // TokenValidator defines the interface for validating GitHub tokens
 type TokenValidator interface {
 	// Validate checks if the token is valid and has required permissions
-	Validate(token string) (*ValidationResult, error)
+	Validate(ctx context.Context, token string) (*ValidationResult, error)
 }
 
 // ValidationResult contains the result of token validation
@@ -15,7 +26,8 @@ type ValidationResult struct {
 	// IsValid indicates if the token is valid
 	IsValid bool
 
-	// HasGistScope indicates if the token has the required 'gist' scope
+	// HasGistScope indicates if the token has the required scope
+	// ('gist' for GitHub, or the scope the validator was created with)
 	HasGistScope bool
 
 	// User contains the authenticated user information
@@ -27,25 +39,33 @@ type ValidationResult struct {
 
 // tokenValidator implements the TokenValidator interface
 type tokenValidator struct {
-	githubClient GitHubClient
+	githubClient  GitHubClient
+	requiredScope string
 }
 
-// NewTokenValidator creates a new token validator
+// NewTokenValidator creates a new token validator that requires the 'gist' scope
 func NewTokenValidator(githubClient GitHubClient) TokenValidator {
+	return NewTokenValidatorWithScope(githubClient, GistScope)
+}
+
+// NewTokenValidatorWithScope creates a token validator that requires the given scope
+// It is used by backends whose client implements the GitHubClient-style interface
+func NewTokenValidatorWithScope(githubClient GitHubClient, requiredScope string) TokenValidator {
 	return &tokenValidator{
-		githubClient: githubClient,
+		githubClient:  githubClient,
+		requiredScope: requiredScope,
 	}
 }
 
 // Validate checks if the token is valid and has required permissions
-func (v *tokenValidator) Validate(token string) (*ValidationResult, error) {
+func (v *tokenValidator) Validate(ctx context.Context, token string) (*ValidationResult, error) {
 	result := &ValidationResult{
 		IsValid:      false,
 		HasGistScope: false,
 	}
 
 	// Validate token by checking scopes
-	scopes, err := v.githubClient.ValidateScopes(token)
+	scopes, err := v.githubClient.ValidateScopes(ctx, token)
 	if err != nil {
 		if err == errors.ErrInvalidToken {
 			result.Error = "Invalid token"
@@ -54,21 +74,21 @@ func (v *tokenValidator) Validate(token string) (*ValidationResult, error) {
 		return nil, err
 	}
 
-	// Check if token has gist scope
+	// Check if token has the required scope
 	for _, scope := range scopes {
-		if scope == "gist" {
+		if scope == v.requiredScope {
 			result.HasGistScope = true
 			break
 		}
 	}
 
 	if !result.HasGistScope {
-		result.Error = "Token lacks required 'gist' scope"
+		result.Error = fmt.Sprintf("Token lacks required '%s' scope", v.requiredScope)
 		return result, nil
 	}
 
 	// Get user information
-	user, err := v.githubClient.GetAuthenticatedUser(token)
+	user, err := v.githubClient.GetAuthenticatedUser(ctx, token)
 	if err != nil {
 		if err == errors.ErrInvalidToken {
 			result.Error = "Invalid token"
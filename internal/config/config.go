@@ -1,5 +1,23 @@
 package config
 
+// Backend names accepted by the "backend" configuration key
+const (
+	// BackendGitHub stores prompts as GitHub Gists (default)
+	BackendGitHub = "github"
+
+	// BackendFileSystem stores prompts as YAML files in a local directory
+	BackendFileSystem = "filesystem"
+
+	// BackendGit stores prompts in a local git repository, one commit per change
+	BackendGit = "git"
+
+	// BackendGitLab stores prompts as GitLab snippets (gitlab.com or self-hosted)
+	BackendGitLab = "gitlab"
+
+	// BackendGitea stores prompts in repositories on a Gitea or Forgejo instance
+	BackendGitea = "gitea"
+)
+
 // Store defines the interface for configuration storage
 type Store interface {
 	// SaveToken saves the GitHub authentication token
@@ -19,4 +37,33 @@ type Store interface {
 type Config struct {
 	// GitHubToken is the encrypted/obfuscated GitHub personal access token
 	GitHubToken string `json:"github_token,omitempty"`
+
+	// GitHubEnterpriseURL is the web URL of a GitHub Enterprise Server (e.g. https://github.example.com)
+	// The API, upload and gist locations are derived from it; empty means github.com
+	GitHubEnterpriseURL string `json:"github_enterprise_url,omitempty"`
+
+	// GitHubAPIURL overrides the derived GitHub Enterprise API URL
+	GitHubAPIURL string `json:"github_api_url,omitempty"`
+
+	// GitHubUploadURL overrides the derived GitHub Enterprise upload URL
+	GitHubUploadURL string `json:"github_upload_url,omitempty"`
+
+	// Backend selects the prompt storage backend ("github", "gitlab", "gitea", "filesystem" or "git")
+	// An empty value means the default GitHub backend
+	Backend string `json:"backend,omitempty"`
+
+	// VaultDir is the vault directory used by the filesystem and git backends
+	VaultDir string `json:"vault_dir,omitempty"`
+
+	// GitRemote is the optional remote the git backend pulls from and pushes to
+	GitRemote string `json:"git_remote,omitempty"`
+
+	// GitBranch is the branch used by the git backend (defaults to "main")
+	GitBranch string `json:"git_branch,omitempty"`
+
+	// GitLabURL is the GitLab instance used by the gitlab backend (defaults to https://gitlab.com)
+	GitLabURL string `json:"gitlab_url,omitempty"`
+
+	// GiteaURL is the Gitea or Forgejo instance used by the gitea backend
+	GiteaURL string `json:"gitea_url,omitempty"`
 }
@@ -125,6 +125,26 @@ func (s *fileStore) GetConfigPath() string {
 	return s.configPath
 }
 
+// LoadConfig reads the configuration file at configPath
+// A missing file yields an empty Config so callers fall back to defaults
+func LoadConfig(configPath string) (*Config, error) {
+	config := &Config{}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return config, nil
+		}
+		return nil, errors.NewAppError(errors.ErrStorage, "failed to read config file", err)
+	}
+
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, errors.NewAppError(errors.ErrStorage, "failed to parse config file", err)
+	}
+
+	return config, nil
+}
+
 // getConfigDir is a function variable that returns the configuration directory path based on the OS
 // It's a variable so it can be overridden in tests
 var getConfigDir = func() (string, error) {
@@ -155,6 +175,15 @@ var getConfigDir = func() (string, error) {
 	return configDir, nil
 }
 
+// GetTemplatesDir returns the directory holding the user's prompt templates for pv new
+func GetTemplatesDir() (string, error) {
+	configDir, err := getConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "templates"), nil
+}
+
 // writeFileWithPermissions writes data to a file with appropriate permissions
 func writeFileWithPermissions(path string, data []byte) error {
 	// Write to a temporary file first
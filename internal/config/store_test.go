@@ -196,3 +196,61 @@ func TestConfigDirCreation(t *testing.T) {
 		t.Errorf("Failed to save token in newly created directory: %v", err)
 	}
 }
+
+func TestLoadConfig(t *testing.T) {
+	tempDir := t.TempDir()
+
+	t.Run("missing file returns defaults", func(t *testing.T) {
+		cfg, err := LoadConfig(filepath.Join(tempDir, "missing.json"))
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if cfg.Backend != "" || cfg.VaultDir != "" {
+			t.Errorf("Expected empty config, got: %+v", cfg)
+		}
+	})
+
+	t.Run("backend settings survive token save", func(t *testing.T) {
+		originalGetConfigDir := getConfigDir
+		getConfigDir = func() (string, error) {
+			return tempDir, nil
+		}
+		defer func() {
+			getConfigDir = originalGetConfigDir
+		}()
+
+		store, err := NewFileStore()
+		if err != nil {
+			t.Fatalf("Failed to create file store: %v", err)
+		}
+
+		data := []byte(`{"backend": "filesystem", "vault_dir": "/srv/prompts"}`)
+		if err := os.WriteFile(store.GetConfigPath(), data, 0600); err != nil {
+			t.Fatalf("Failed to write config: %v", err)
+		}
+		if err := store.SaveToken("test_token"); err != nil {
+			t.Fatalf("Failed to save token: %v", err)
+		}
+
+		cfg, err := LoadConfig(store.GetConfigPath())
+		if err != nil {
+			t.Fatalf("Failed to load config: %v", err)
+		}
+		if cfg.Backend != BackendFileSystem {
+			t.Errorf("Backend = %q, want %q", cfg.Backend, BackendFileSystem)
+		}
+		if cfg.VaultDir != "/srv/prompts" {
+			t.Errorf("VaultDir = %q, want %q", cfg.VaultDir, "/srv/prompts")
+		}
+	})
+
+	t.Run("invalid JSON", func(t *testing.T) {
+		path := filepath.Join(tempDir, "broken.json")
+		if err := os.WriteFile(path, []byte("{not json"), 0600); err != nil {
+			t.Fatalf("Failed to write config: %v", err)
+		}
+		if _, err := LoadConfig(path); err == nil {
+			t.Error("Expected error for invalid JSON")
+		}
+	})
+}
@@ -1,14 +1,19 @@
 package di
 
 import (
+	"fmt"
+	"path/filepath"
+
 	"github.com/spf13/cobra"
 
 	"github.com/grigri/pv/cmd"
+	"github.com/grigri/pv/internal/auth"
 	"github.com/grigri/pv/internal/clipboard"
 	"github.com/grigri/pv/internal/config"
 	"github.com/grigri/pv/internal/infra"
 	"github.com/grigri/pv/internal/service"
 	"github.com/grigri/pv/internal/tui"
+	"github.com/grigri/pv/internal/utils"
 	"github.com/grigri/pv/internal/validator"
 	"github.com/grigri/pv/internal/variable"
 )
@@ -24,20 +29,29 @@ func ProvideAuthCommands(authService service.AuthService) *cobra.Command {
 
 // Commands holds all the subcommands
 type Commands struct {
-	ListCmd   *cobra.Command
-	AddCmd    *cobra.Command
-	DeleteCmd *cobra.Command
-	GetCmd    *cobra.Command
-	SyncCmd   *cobra.Command
-	AuthCmd   *cobra.Command
-	ShareCmd  *cobra.Command
+	ListCmd     *cobra.Command
+	AddCmd      *cobra.Command
+	DeleteCmd   *cobra.Command
+	GetCmd      *cobra.Command
+	SyncCmd     *cobra.Command
+	AuthCmd     *cobra.Command
+	ShareCmd    *cobra.Command
+	HistoryCmd  *cobra.Command
+	ShowCmd     *cobra.Command
+	DiffCmd     *cobra.Command
+	RollbackCmd *cobra.Command
+	EditCmd     *cobra.Command
+	NewCmd      *cobra.Command
+	ImportCmd   *cobra.Command
+	ExportCmd   *cobra.Command
+	RestoreCmd  *cobra.Command
 }
 
 // ProvideCommands provides all commands
 func ProvideCommands(
-	store infra.Store, 
+	store infra.Store,
 	configStore config.Store,
-	authService service.AuthService, 
+	authService service.AuthService,
 	promptService service.PromptService,
 	clipboardUtil clipboard.Util,
 	variableParser variable.Parser,
@@ -50,14 +64,32 @@ func ProvideCommands(
 	syncCmd := cmd.NewSyncCommand(promptService)
 	authCmd := ProvideAuthCommands(authService)
 	shareCmd := cmd.NewShareCommand(promptService, tuiInterface)
+	historyCmd := cmd.NewHistoryCommand(promptService, tuiInterface)
+	showCmd := cmd.NewShowCommand(promptService, tuiInterface)
+	diffCmd := cmd.NewDiffCommand(promptService, tuiInterface)
+	rollbackCmd := cmd.NewRollbackCommand(promptService, tuiInterface)
+	editCmd := cmd.NewEditCommand(promptService, tuiInterface)
+	newCmd := cmd.NewNewCommand(promptService, authService, tuiInterface)
+	importCmd := cmd.NewImportCommand(promptService, authService)
+	exportCmd := cmd.NewExportCommand(promptService)
+	restoreCmd := cmd.NewRestoreCommand(promptService)
 	return Commands{
-		ListCmd:   listCmd,
-		AddCmd:    addCmd,
-		DeleteCmd: deleteCmd,
-		GetCmd:    getCmd,
-		SyncCmd:   syncCmd,
-		AuthCmd:   authCmd,
-		ShareCmd:  shareCmd,
+		ListCmd:     listCmd,
+		AddCmd:      addCmd,
+		DeleteCmd:   deleteCmd,
+		GetCmd:      getCmd,
+		SyncCmd:     syncCmd,
+		AuthCmd:     authCmd,
+		ShareCmd:    shareCmd,
+		HistoryCmd:  historyCmd,
+		ShowCmd:     showCmd,
+		DiffCmd:     diffCmd,
+		RollbackCmd: rollbackCmd,
+		EditCmd:     editCmd,
+		NewCmd:      newCmd,
+		ImportCmd:   importCmd,
+		ExportCmd:   exportCmd,
+		RestoreCmd:  restoreCmd,
 	}
 }
 
@@ -91,21 +123,96 @@ func ProvideCacheManager() (*infra.CacheManager, error) {
 	return infra.NewCacheManager()
 }
 
-// ProvideGitHubStore provides a GitHubStore instance (not bound to Store interface)
-func ProvideGitHubStore(configStore config.Store) *infra.GitHubStore {
-	// Use the existing NewGitHubStore and perform type assertion
-	// This is safe because we know NewGitHubStore returns *GitHubStore wrapped in Store interface
-	return infra.NewGitHubStore(configStore).(*infra.GitHubStore)
+// RemoteStore is the backing Store wrapped by CachedStore
+// It is a distinct type so wire can tell it apart from the cached infra.Store
+type RemoteStore infra.Store
+
+// ProvideConfig provides the application configuration from the config file
+func ProvideConfig(configStore config.Store) (*config.Config, error) {
+	return config.LoadConfig(configStore.GetConfigPath())
+}
+
+// ProvideGitHubEndpoints provides the github.com or GitHub Enterprise endpoints from the configuration
+// They are also registered with utils so gist URL detection and parsing accept the configured host
+func ProvideGitHubEndpoints(cfg *config.Config) (utils.GitHubEndpoints, error) {
+	endpoints, err := utils.NewGitHubEndpoints(cfg.GitHubEnterpriseURL, cfg.GitHubAPIURL, cfg.GitHubUploadURL)
+	if err != nil {
+		return utils.GitHubEndpoints{}, err
+	}
+
+	utils.SetGitHubEndpoints(endpoints)
+	return endpoints, nil
+}
+
+// ProvideRemoteStore provides the backing Store selected by the "backend" config key
+// The GitHub gist backend is used unless the configuration asks for another one
+func ProvideRemoteStore(configStore config.Store, cfg *config.Config, endpoints utils.GitHubEndpoints) (RemoteStore, error) {
+	switch cfg.Backend {
+	case "", config.BackendGitHub:
+		if endpoints.IsEnterprise() {
+			return infra.NewGitHubEnterpriseStore(configStore, endpoints), nil
+		}
+		return infra.NewGitHubStore(configStore), nil
+	case config.BackendGitLab:
+		return infra.NewGitLabStore(configStore, cfg.GitLabURL), nil
+	case config.BackendGitea:
+		if cfg.GiteaURL == "" {
+			return nil, fmt.Errorf("the gitea backend requires gitea_url to be set")
+		}
+		return infra.NewGiteaStore(configStore, cfg.GiteaURL), nil
+	case config.BackendFileSystem:
+		return infra.NewFileSystemStore(vaultDir(cfg, configStore)), nil
+	case config.BackendGit:
+		return infra.NewGitStore(vaultDir(cfg, configStore), cfg.GitRemote, cfg.GitBranch), nil
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", cfg.Backend)
+	}
+}
+
+// ProvideGitHubClient provides the API client used by the auth flow
+// The gitlab and gitea backends authenticate against their own instance instead of GitHub
+func ProvideGitHubClient(cfg *config.Config, endpoints utils.GitHubEndpoints) auth.GitHubClient {
+	switch cfg.Backend {
+	case config.BackendGitLab:
+		return auth.NewGitLabClient(cfg.GitLabURL)
+	case config.BackendGitea:
+		return auth.NewGiteaClient(cfg.GiteaURL)
+	}
+
+	if endpoints.IsEnterprise() {
+		return auth.NewGitHubClientWithBaseURL(endpoints.APIURL)
+	}
+	return auth.NewGitHubClient()
+}
+
+// ProvideTokenValidator provides a token validator requiring the scope of the configured backend
+func ProvideTokenValidator(cfg *config.Config, client auth.GitHubClient) auth.TokenValidator {
+	switch cfg.Backend {
+	case config.BackendGitLab:
+		return auth.NewTokenValidatorWithScope(client, auth.GitLabAPIScope)
+	case config.BackendGitea:
+		return auth.NewTokenValidatorWithScope(client, auth.GiteaRepositoryScope)
+	default:
+		return auth.NewTokenValidator(client)
+	}
+}
+
+// vaultDir returns the configured vault directory, defaulting to "vault" next to the config file
+func vaultDir(cfg *config.Config, configStore config.Store) string {
+	if cfg.VaultDir != "" {
+		return cfg.VaultDir
+	}
+	return filepath.Join(filepath.Dir(configStore.GetConfigPath()), "vault")
 }
 
-// ProvideCachedStore provides a CachedStore instance that wraps GitHubStore with caching
-func ProvideCachedStore(gitHubStore *infra.GitHubStore, cacheManager *infra.CacheManager, configStore config.Store) infra.Store {
+// ProvideCachedStore provides a CachedStore instance that wraps the remote store with caching
+func ProvideCachedStore(remoteStore RemoteStore, cacheManager *infra.CacheManager, configStore config.Store) infra.Store {
 	// Default to forceRemote = false for normal operations
 	// Commands can override this behavior as needed
-	return infra.NewCachedStore(gitHubStore, cacheManager, configStore, false)
+	return infra.NewCachedStore(remoteStore, cacheManager, configStore, false)
 }
 
 // ProvideRootCommand provides the root command with all subcommands
 func ProvideRootCommand(commands Commands) *cobra.Command {
-	return cmd.NewRootCommand(commands.ListCmd, commands.AddCmd, commands.DeleteCmd, commands.GetCmd, commands.SyncCmd, commands.AuthCmd, commands.ShareCmd)
+	return cmd.NewRootCommand(commands.ListCmd, commands.AddCmd, commands.DeleteCmd, commands.GetCmd, commands.SyncCmd, commands.AuthCmd, commands.ShareCmd, commands.HistoryCmd, commands.ShowCmd, commands.DiffCmd, commands.RollbackCmd, commands.EditCmd, commands.NewCmd, commands.ImportCmd, commands.ExportCmd, commands.RestoreCmd)
 }
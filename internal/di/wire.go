@@ -6,7 +6,6 @@ package di
 import (
 	"github.com/spf13/cobra"
 
-	"github.com/grigri/pv/internal/auth"
 	"github.com/grigri/pv/internal/config"
 	"github.com/grigri/pv/internal/service"
 	"github.com/grigri/pv/internal/validator"
@@ -16,16 +15,18 @@ import (
 
 // InfraSet provides infrastructure components
 var InfraSet = wire.NewSet(
-	ProvideGitHubStore,
+	ProvideRemoteStore,
 	config.NewFileStore,
+	ProvideConfig,
+	ProvideGitHubEndpoints,
 	ProvideCacheManager,
 	ProvideCachedStore,
 )
 
 // AuthSet provides authentication related components
 var AuthSet = wire.NewSet(
-	auth.NewGitHubClient,
-	auth.NewTokenValidator,
+	ProvideGitHubClient,
+	ProvideTokenValidator,
 	service.NewAuthService,
 )
 
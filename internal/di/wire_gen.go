@@ -8,7 +8,6 @@ package di
 
 import (
 	"github.com/google/wire"
-	"github.com/grigri/pv/internal/auth"
 	"github.com/grigri/pv/internal/config"
 	"github.com/grigri/pv/internal/service"
 	"github.com/grigri/pv/internal/validator"
@@ -22,14 +21,25 @@ func BuildCLI() (*cobra.Command, error) {
 	if err != nil {
 		return nil, err
 	}
-	gitHubStore := ProvideGitHubStore(store)
+	configConfig, err := ProvideConfig(store)
+	if err != nil {
+		return nil, err
+	}
+	gitHubEndpoints, err := ProvideGitHubEndpoints(configConfig)
+	if err != nil {
+		return nil, err
+	}
+	remoteStore, err := ProvideRemoteStore(store, configConfig, gitHubEndpoints)
+	if err != nil {
+		return nil, err
+	}
 	cacheManager, err := ProvideCacheManager()
 	if err != nil {
 		return nil, err
 	}
-	infraStore := ProvideCachedStore(gitHubStore, cacheManager, store)
-	gitHubClient := auth.NewGitHubClient()
-	tokenValidator := auth.NewTokenValidator(gitHubClient)
+	infraStore := ProvideCachedStore(remoteStore, cacheManager, store)
+	gitHubClient := ProvideGitHubClient(configConfig, gitHubEndpoints)
+	tokenValidator := ProvideTokenValidator(configConfig, gitHubClient)
 	authService := service.NewAuthService(store, gitHubClient, tokenValidator)
 	yamlValidator := validator.NewYAMLValidator()
 	promptService := service.NewPromptService(infraStore, yamlValidator)
@@ -45,12 +55,14 @@ func BuildCLI() (*cobra.Command, error) {
 
 // InfraSet provides infrastructure components
 var InfraSet = wire.NewSet(
-	ProvideGitHubStore, config.NewFileStore, ProvideCacheManager,
+	ProvideRemoteStore, config.NewFileStore, ProvideConfig,
+	ProvideGitHubEndpoints,
+	ProvideCacheManager,
 	ProvideCachedStore,
 )
 
 // AuthSet provides authentication related components
-var AuthSet = wire.NewSet(auth.NewGitHubClient, auth.NewTokenValidator, service.NewAuthService)
+var AuthSet = wire.NewSet(ProvideGitHubClient, ProvideTokenValidator, service.NewAuthService)
 
 // ServiceSet provides service layer components
 var ServiceSet = wire.NewSet(validator.NewYAMLValidator, service.NewPromptService)
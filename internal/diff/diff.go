@@ -0,0 +1,183 @@
+// Package diff computes line-based diffs of prompt text and groups them into
+// unified diff hunks.
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Op is the kind of a diff line, written as the prefix of the line in a unified diff
+type Op string
+
+const (
+	Equal  Op = " "
+	Insert Op = "+"
+	Delete Op = "-"
+)
+
+// Line is one line of a diff
+type Line struct {
+	Op   Op     `json:"op"`
+	Text string `json:"text"`
+}
+
+// Hunk is a run of changes with the unchanged lines around them, as in a unified diff
+// Line numbers start at 1; a side without lines has its start at the line before.
+type Hunk struct {
+	OldStart int    `json:"old_start"`
+	OldLines int    `json:"old_lines"`
+	NewStart int    `json:"new_start"`
+	NewLines int    `json:"new_lines"`
+	Lines    []Line `json:"lines"`
+}
+
+// Header returns the range line of the hunk, e.g. "@@ -1,3 +1,4 @@"
+func (h Hunk) Header() string {
+	return fmt.Sprintf("@@ -%s +%s @@", formatRange(h.OldStart, h.OldLines), formatRange(h.NewStart, h.NewLines))
+}
+
+func formatRange(start, lines int) string {
+	if lines == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, lines)
+}
+
+// DefaultContext is the number of unchanged lines shown around each change
+const DefaultContext = 3
+
+// Lines returns the line-by-line diff that turns a into b
+// It keeps the longest common subsequence of lines unchanged, which is exact but
+// quadratic; prompts are small enough for that not to matter.
+func Lines(a, b string) []Line {
+	oldLines, newLines := splitLines(a), splitLines(b)
+	n, m := len(oldLines), len(newLines)
+
+	// lcs[i][j] is the length of the longest common subsequence of oldLines[i:] and newLines[j:]
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if oldLines[i] == newLines[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	lines := make([]Line, 0, max(n, m))
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case oldLines[i] == newLines[j]:
+			lines = append(lines, Line{Op: Equal, Text: oldLines[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, Line{Op: Delete, Text: oldLines[i]})
+			i++
+		default:
+			lines = append(lines, Line{Op: Insert, Text: newLines[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		lines = append(lines, Line{Op: Delete, Text: oldLines[i]})
+	}
+	for ; j < m; j++ {
+		lines = append(lines, Line{Op: Insert, Text: newLines[j]})
+	}
+	return lines
+}
+
+// splitLines splits text into lines; a trailing newline does not start another line
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// Hunks groups a diff into hunks showing context unchanged lines around the changes
+// Changes separated by at most 2*context unchanged lines share a hunk. It returns
+// nil when nothing changed.
+func Hunks(lines []Line, context int) []Hunk {
+	var hunks []Hunk
+	var current *Hunk
+	oldLine, newLine := 1, 1
+	trailing := 0 // unchanged lines at the end of the current hunk
+
+	for i, line := range lines {
+		if line.Op == Equal {
+			if current != nil {
+				// Unchanged lines between two changes join them into one hunk when
+				// the gap is short enough for their contexts to meet
+				if trailing < context || trailing+1+nextChange(lines, i) <= 2*context {
+					current.Lines = append(current.Lines, line)
+					current.OldLines++
+					current.NewLines++
+					trailing++
+				} else {
+					hunks = append(hunks, *current)
+					current = nil
+				}
+			}
+			oldLine++
+			newLine++
+			continue
+		}
+
+		if current == nil {
+			// Open a hunk with up to context unchanged lines before the change
+			start := i
+			for start > 0 && i-start < context && lines[start-1].Op == Equal {
+				start--
+			}
+			current = &Hunk{OldStart: oldLine - (i - start), NewStart: newLine - (i - start)}
+			for _, before := range lines[start:i] {
+				current.Lines = append(current.Lines, before)
+				current.OldLines++
+				current.NewLines++
+			}
+		}
+		trailing = 0
+
+		current.Lines = append(current.Lines, line)
+		if line.Op == Delete {
+			current.OldLines++
+			oldLine++
+		} else {
+			current.NewLines++
+			newLine++
+		}
+	}
+	if current != nil {
+		hunks = append(hunks, *current)
+	}
+
+	// A side without lines starts at the line before it, as diff -u prints it
+	for i := range hunks {
+		if hunks[i].OldLines == 0 {
+			hunks[i].OldStart--
+		}
+		if hunks[i].NewLines == 0 {
+			hunks[i].NewStart--
+		}
+	}
+	return hunks
+}
+
+// nextChange returns how many unchanged lines follow lines[i] before the next change,
+// or len(lines) when no change follows
+func nextChange(lines []Line, i int) int {
+	for j := i + 1; j < len(lines); j++ {
+		if lines[j].Op != Equal {
+			return j - i - 1
+		}
+	}
+	return len(lines)
+}
@@ -0,0 +1,81 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+)
+
+// render writes hunks as the body of a unified diff
+func render(hunks []Hunk) string {
+	var b strings.Builder
+	for _, hunk := range hunks {
+		b.WriteString(hunk.Header() + "\n")
+		for _, line := range hunk.Lines {
+			b.WriteString(string(line.Op) + line.Text + "\n")
+		}
+	}
+	return b.String()
+}
+
+func numbered(from, to int) string {
+	var lines []string
+	for i := from; i <= to; i++ {
+		lines = append(lines, strings.Repeat("x", i))
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func TestHunks(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want string
+	}{
+		{
+			name: "identical",
+			a:    "a\nb\n",
+			b:    "a\nb\n",
+			want: "",
+		},
+		{
+			name: "changed line",
+			a:    "a\nb\nc\n",
+			b:    "a\nB\nc\n",
+			want: "@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n",
+		},
+		{
+			name: "added to empty",
+			a:    "",
+			b:    "a\nb\n",
+			want: "@@ -0,0 +1,2 @@\n+a\n+b\n",
+		},
+		{
+			name: "removed line",
+			a:    "a\nb\n",
+			b:    "a\n",
+			want: "@@ -1,2 +1 @@\n a\n-b\n",
+		},
+		{
+			name: "distant changes get their own hunks",
+			a:    numbered(1, 12),
+			b:    strings.Replace(strings.Replace(numbered(1, 12), "x\n", "y\n", 1), "xxxxxxxxxxxx\n", "z\n", 1),
+			want: "@@ -1,4 +1,4 @@\n-x\n+y\n xx\n xxx\n xxxx\n" +
+				"@@ -9,4 +9,4 @@\n xxxxxxxxx\n xxxxxxxxxx\n xxxxxxxxxxx\n-xxxxxxxxxxxx\n+z\n",
+		},
+		{
+			name: "close changes share a hunk",
+			a:    numbered(1, 8),
+			b:    strings.Replace(strings.Replace(numbered(1, 8), "x\n", "y\n", 1), "xxxxxxxx\n", "z\n", 1),
+			want: "@@ -1,8 +1,8 @@\n-x\n+y\n xx\n xxx\n xxxx\n xxxxx\n xxxxxx\n xxxxxxx\n-xxxxxxxx\n+z\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := render(Hunks(Lines(tt.a, tt.b), DefaultContext))
+			if got != tt.want {
+				t.Errorf("Unexpected diff:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,7 @@
+package errors
+
+var (
+	// 批量添加相关错误
+	ErrNoPromptFiles      = NewAppError(ErrValidation, "没有匹配的提示词文件", nil)
+	ErrAddPlanHasFailures = NewAppError(ErrValidation, "部分文件未通过验证，没有添加任何提示词", nil)
+)
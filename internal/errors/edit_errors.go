@@ -0,0 +1,6 @@
+package errors
+
+var (
+	// Edit 相关错误
+	ErrPromptNameTaken = NewAppError(ErrValidation, "已有同名且同作者的提示词，请换一个名称", nil)
+)
@@ -1,6 +1,9 @@
 package errors
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+)
 
 // ErrorType represents the type of error
 type ErrorType int
@@ -59,3 +62,13 @@ func NewAppError(errType ErrorType, message string, err error) AppError {
 		Err:     err,
 	}
 }
+
+// TypeOf returns the type of the first AppError in err's chain, or fallback when there is none
+// It lets a wrapping AppError keep the classification of the failure it wraps.
+func TypeOf(err error, fallback ErrorType) ErrorType {
+	var appErr AppError
+	if errors.As(err, &appErr) {
+		return appErr.Type
+	}
+	return fallback
+}
@@ -0,0 +1,10 @@
+package errors
+
+var (
+	// History 相关错误
+	ErrRevisionNotFound   = NewAppError(ErrNotFound, "找不到指定的修订版本", nil)
+	ErrAmbiguousRevision  = NewAppError(ErrValidation, "修订版本前缀匹配到多个修订，请提供更长的 SHA", nil)
+	ErrNoPreviousRevision = NewAppError(ErrValidation, "提示词只有一个修订，没有可比较的上一个修订", nil)
+	ErrHistoryUnsupported = NewAppError(ErrValidation, "当前存储后端不保存提示词的修订历史", nil)
+	ErrNothingToRollback  = NewAppError(ErrValidation, "提示词的当前内容与该修订相同，无需回滚", nil)
+)
@@ -7,6 +7,7 @@ var (
 	ErrGistAlreadyPublic = NewAppError(ErrValidation, "Gist 已经是公开的", nil)
 	ErrGistAccessDenied  = NewAppError(ErrPermission, "没有访问 Gist 的权限", nil)
 	ErrGistNotFound      = NewAppError(ErrNotFound, "找不到指定的 Gist", nil)
+	ErrExportNotFound    = NewAppError(ErrNotFound, "该提示词还没有分享过公开副本", nil)
 	
 	// Add URL 相关错误
 	ErrGistNotPublic     = NewAppError(ErrValidation, "只能导入公开的 Gist", nil)
@@ -14,9 +15,10 @@ var (
 )
 
 // NewShareError 创建分享操作相关的错误
+// 原因已经分类（如 ErrAuth、ErrNetwork）时沿用其类型，否则为 ErrStorage
 func NewShareError(operation string, gistURL string, cause error) AppError {
 	message := fmt.Sprintf("分享操作失败: %s (%s)", operation, gistURL)
-	return NewAppError(ErrStorage, message, cause)
+	return NewAppError(TypeOf(cause, ErrStorage), message, cause)
 }
 
 // NewAddFromURLError 创建从 URL 添加相关的错误
@@ -0,0 +1,527 @@
+// Package fakegist is an in-process fake of the GitHub REST endpoints used by pv:
+// gists (list, get, create, edit, delete, commits and revisions), the authenticated
+// user and the token scopes header. It runs on httptest and is served under /api/v3,
+// so pv can point at it as a GitHub Enterprise URL.
+//
+// Faults can be injected per method and path to simulate 404s, 403s, rate limits
+// and slow responses.
+package fakegist
+
+import (
+	"crypto/sha1"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grigri/pv/internal/utils"
+)
+
+// apiPrefix is where the REST API is served, as on GitHub Enterprise Server
+const apiPrefix = "/api/v3"
+
+// Fault makes matching requests fail or slow down
+type Fault struct {
+	// Method matches the request method; empty matches every method
+	Method string
+	// Path is a path.Match pattern for the path below /api/v3, e.g. "/gists/*";
+	// empty matches every path
+	Path string
+
+	// Status is the status to answer with, e.g. http.StatusNotFound
+	Status int
+	// RateLimit answers as an exhausted primary rate limit
+	RateLimit bool
+	// SecondaryRateLimit answers as a secondary rate limit asking to wait RetryAfter
+	SecondaryRateLimit bool
+	RetryAfter         time.Duration
+	// Delay holds the response back; without a failure the request is then served normally
+	Delay time.Duration
+
+	// Times is how many matching requests are affected; 0 means all of them
+	Times int
+}
+
+// revision is one version of a gist's files
+type revision struct {
+	version     string
+	files       map[string]string
+	committedAt time.Time
+}
+
+// gist is a stored gist, newest revision first
+type gist struct {
+	id          string
+	description string
+	public      bool
+	createdAt   time.Time
+	history     []revision
+}
+
+// Server is a running fake GitHub API
+type Server struct {
+	// URL is the server's base URL, to be used as the GitHub Enterprise URL
+	URL string
+
+	// Login is the login of the authenticated user and the owner of every gist
+	Login string
+	// Scopes are reported in the X-OAuth-Scopes header
+	Scopes []string
+
+	token  string
+	server *httptest.Server
+
+	mu       sync.Mutex
+	gists    map[string]*gist
+	nextID   int
+	nextRev  int
+	faults   []*Fault
+	requests []string
+}
+
+// New starts a fake accepting token and stops it when the test ends
+func New(t testing.TB, token string) *Server {
+	t.Helper()
+
+	s := &Server{
+		Login:  "octocat",
+		Scopes: []string{"gist"},
+		token:  token,
+		gists:  map[string]*gist{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+apiPrefix+"/{$}", s.root)
+	mux.HandleFunc("GET "+apiPrefix+"/user", s.user)
+	mux.HandleFunc("GET "+apiPrefix+"/gists", s.list)
+	mux.HandleFunc("POST "+apiPrefix+"/gists", s.create)
+	mux.HandleFunc("GET "+apiPrefix+"/gists/{id}", s.get)
+	mux.HandleFunc("PATCH "+apiPrefix+"/gists/{id}", s.edit)
+	mux.HandleFunc("DELETE "+apiPrefix+"/gists/{id}", s.delete)
+	mux.HandleFunc("GET "+apiPrefix+"/gists/{id}/commits", s.commits)
+	mux.HandleFunc("GET "+apiPrefix+"/gists/{id}/{sha}", s.revision)
+
+	s.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.serve(mux, w, r)
+	}))
+	s.URL = s.server.URL
+	t.Cleanup(s.server.Close)
+	return s
+}
+
+// Endpoints returns the GitHub Enterprise endpoints pointing at the fake
+func (s *Server) Endpoints() utils.GitHubEndpoints {
+	endpoints, err := utils.NewGitHubEndpoints(s.URL, "", "")
+	if err != nil {
+		panic(err) // httptest URLs are always valid
+	}
+	return endpoints
+}
+
+// Inject adds a fault; faults are matched in the order they were added
+func (s *Server) Inject(fault Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, &fault)
+}
+
+// ClearFaults removes every injected fault
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = nil
+}
+
+// Requests returns the requests served so far as "METHOD /path", without the /api/v3 prefix
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+// GistCount returns the number of stored gists
+func (s *Server) GistCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.gists)
+}
+
+// Gist returns whether the gist exists, whether it is public and its current files
+func (s *Server) Gist(id string) (files map[string]string, public bool, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	g, ok := s.gists[id]
+	if !ok {
+		return nil, false, false
+	}
+	files = make(map[string]string, len(g.history[0].files))
+	for name, content := range g.history[0].files {
+		files[name] = content
+	}
+	return files, g.public, true
+}
+
+// serve authenticates the request, applies faults and dispatches it
+func (s *Server) serve(mux *http.ServeMux, w http.ResponseWriter, r *http.Request) {
+	apiPath := strings.TrimPrefix(r.URL.Path, apiPrefix)
+
+	s.mu.Lock()
+	s.requests = append(s.requests, r.Method+" "+apiPath)
+	fault := s.matchFault(r.Method, apiPath)
+	s.mu.Unlock()
+
+	if fault != nil && fault.Delay > 0 {
+		select {
+		case <-time.After(fault.Delay):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	auth := r.Header.Get("Authorization")
+	if auth != "Bearer "+s.token && auth != "token "+s.token {
+		writeError(w, http.StatusUnauthorized, "Bad credentials", "")
+		return
+	}
+
+	w.Header().Set("X-OAuth-Scopes", strings.Join(s.Scopes, ", "))
+	w.Header().Set("X-RateLimit-Limit", "5000")
+	w.Header().Set("X-RateLimit-Remaining", "4999")
+	w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))
+
+	switch {
+	case fault == nil:
+	case fault.RateLimit:
+		// The reset lies in the past so clients may try again right away
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Unix(), 10))
+		writeError(w, http.StatusForbidden, "API rate limit exceeded for user", "https://docs.github.com/rest/overview/rate-limits-for-the-rest-api")
+		return
+	case fault.SecondaryRateLimit:
+		w.Header().Set("Retry-After", strconv.Itoa(int(fault.RetryAfter/time.Second)))
+		writeError(w, http.StatusForbidden, "You have exceeded a secondary rate limit", "https://docs.github.com/rest/overview/rate-limits-for-the-rest-api#about-secondary-rate-limits")
+		return
+	case fault.Status != 0:
+		writeError(w, fault.Status, http.StatusText(fault.Status), "")
+		return
+	}
+
+	mux.ServeHTTP(w, r)
+}
+
+// matchFault returns the first fault matching the request and uses it up
+func (s *Server) matchFault(method, apiPath string) *Fault {
+	for i, fault := range s.faults {
+		if fault.Method != "" && fault.Method != method {
+			continue
+		}
+		if fault.Path != "" {
+			if ok, _ := path.Match(fault.Path, apiPath); !ok {
+				continue
+			}
+		}
+
+		if fault.Times > 0 {
+			fault.Times--
+			if fault.Times == 0 {
+				s.faults = append(s.faults[:i:i], s.faults[i+1:]...)
+			}
+		}
+		return fault
+	}
+	return nil
+}
+
+func writeError(w http.ResponseWriter, status int, message, documentationURL string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"message": message, "documentation_url": documentationURL})
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
+
+func (s *Server) root(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"current_user_url": s.URL + apiPrefix + "/user"})
+}
+
+func (s *Server) user(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"login": s.Login,
+		"name":  s.Login,
+		"email": s.Login + "@example.com",
+	})
+}
+
+// newRevision records files as the next revision; the lock must be held
+func (s *Server) newRevision(files map[string]string) revision {
+	s.nextRev++
+	// Hash the counter so abbreviated SHAs are as distinct as GitHub's
+	version := fmt.Sprintf("%x", sha1.Sum([]byte(strconv.Itoa(s.nextRev))))
+	return revision{version: version, files: files, committedAt: time.Now().UTC()}
+}
+
+// payload renders a gist at the given revision; the lock must be held
+func (s *Server) payload(g *gist, rev revision) map[string]interface{} {
+	files := map[string]interface{}{}
+	for name, content := range rev.files {
+		files[name] = map[string]interface{}{
+			"filename": name,
+			"content":  content,
+			"size":     len(content),
+			"raw_url":  fmt.Sprintf("%s/gist/%s/%s/raw/%s/%s", s.URL, s.Login, g.id, rev.version, name),
+		}
+	}
+	return map[string]interface{}{
+		"id":          g.id,
+		"description": g.description,
+		"public":      g.public,
+		"html_url":    fmt.Sprintf("%s/gist/%s/%s", s.URL, s.Login, g.id),
+		"files":       files,
+		"owner":       map[string]string{"login": s.Login},
+		"created_at":  g.createdAt.Format(time.RFC3339),
+		"updated_at":  rev.committedAt.Format(time.RFC3339),
+	}
+}
+
+func (s *Server) list(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var since time.Time
+	if value := r.URL.Query().Get("since"); value != "" {
+		since, _ = time.Parse(time.RFC3339, value)
+	}
+
+	ids := make([]string, 0, len(s.gists))
+	for id := range s.gists {
+		ids = append(ids, id)
+	}
+	// Newest first, like GitHub
+	sort.Sort(sort.Reverse(sort.StringSlice(ids)))
+
+	gists := []map[string]interface{}{}
+	for _, id := range ids {
+		g := s.gists[id]
+		if g.history[0].committedAt.Before(since.Truncate(time.Second)) {
+			continue
+		}
+		gists = append(gists, s.payload(g, g.history[0]))
+	}
+	writeJSON(w, http.StatusOK, gists)
+}
+
+// gistRequest is the body of gist create and edit requests
+// On edit a nil file deletes it and a filename renames it.
+type gistRequest struct {
+	Description *string `json:"description"`
+	Public      bool    `json:"public"`
+	Files       map[string]*struct {
+		Filename *string `json:"filename"`
+		Content  *string `json:"content"`
+	} `json:"files"`
+}
+
+func (s *Server) create(w http.ResponseWriter, r *http.Request) {
+	var req gistRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.Files) == 0 {
+		writeError(w, http.StatusUnprocessableEntity, "Validation Failed", "")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	files := map[string]string{}
+	for name, file := range req.Files {
+		if file != nil && file.Content != nil {
+			files[name] = *file.Content
+		}
+	}
+
+	s.nextID++
+	g := &gist{id: fmt.Sprintf("%032x", s.nextID), public: req.Public, createdAt: time.Now().UTC()}
+	if req.Description != nil {
+		g.description = *req.Description
+	}
+	g.history = []revision{s.newRevision(files)}
+	s.gists[g.id] = g
+
+	writeJSON(w, http.StatusCreated, s.payload(g, g.history[0]))
+}
+
+func (s *Server) get(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	g, ok := s.gists[r.PathValue("id")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Not Found", "")
+		return
+	}
+
+	etag := fmt.Sprintf(`W/"%s"`, g.history[0].version)
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("ETag", etag)
+	writeJSON(w, http.StatusOK, s.payload(g, g.history[0]))
+}
+
+func (s *Server) edit(w http.ResponseWriter, r *http.Request) {
+	var req gistRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, "Validation Failed", "")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	g, ok := s.gists[r.PathValue("id")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Not Found", "")
+		return
+	}
+
+	files := map[string]string{}
+	for name, content := range g.history[0].files {
+		files[name] = content
+	}
+	for name, file := range req.Files {
+		if file == nil {
+			delete(files, name)
+			continue
+		}
+		content, exists := files[name]
+		if file.Content != nil {
+			content = *file.Content
+		} else if !exists {
+			continue
+		}
+		if file.Filename != nil && *file.Filename != name {
+			delete(files, name)
+			name = *file.Filename
+		}
+		files[name] = content
+	}
+	if req.Description != nil {
+		g.description = *req.Description
+	}
+	g.history = append([]revision{s.newRevision(files)}, g.history...)
+
+	writeJSON(w, http.StatusOK, s.payload(g, g.history[0]))
+}
+
+func (s *Server) delete(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.gists[r.PathValue("id")]; !ok {
+		writeError(w, http.StatusNotFound, "Not Found", "")
+		return
+	}
+	delete(s.gists, r.PathValue("id"))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) commits(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	g, ok := s.gists[r.PathValue("id")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Not Found", "")
+		return
+	}
+
+	history := g.history
+	if perPage, err := strconv.Atoi(r.URL.Query().Get("per_page")); err == nil && perPage > 0 && perPage < len(history) {
+		history = history[:perPage]
+	}
+
+	commits := []map[string]interface{}{}
+	for i, rev := range history {
+		var previous map[string]string
+		if i+1 < len(g.history) {
+			previous = g.history[i+1].files
+		}
+		additions, deletions := lineChanges(previous, rev.files)
+
+		commits = append(commits, map[string]interface{}{
+			"version":      rev.version,
+			"committed_at": rev.committedAt.Format(time.RFC3339),
+			"change_status": map[string]int{
+				"additions": additions,
+				"deletions": deletions,
+				"total":     additions + deletions,
+			},
+			"url":  fmt.Sprintf("%s%s/gists/%s/%s", s.URL, apiPrefix, g.id, rev.version),
+			"user": map[string]string{"login": s.Login},
+		})
+	}
+	writeJSON(w, http.StatusOK, commits)
+}
+
+// lineChanges counts the lines added and deleted between two revisions of a gist's files
+func lineChanges(before, after map[string]string) (additions, deletions int) {
+	names := map[string]bool{}
+	for name := range before {
+		names[name] = true
+	}
+	for name := range after {
+		names[name] = true
+	}
+
+	for name := range names {
+		remaining := map[string]int{}
+		if content, ok := before[name]; ok {
+			for _, line := range strings.Split(content, "\n") {
+				remaining[line]++
+			}
+		}
+		if content, ok := after[name]; ok {
+			for _, line := range strings.Split(content, "\n") {
+				if remaining[line] > 0 {
+					remaining[line]--
+				} else {
+					additions++
+				}
+			}
+		}
+		for _, count := range remaining {
+			deletions += count
+		}
+	}
+	return additions, deletions
+}
+
+func (s *Server) revision(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	g, ok := s.gists[r.PathValue("id")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Not Found", "")
+		return
+	}
+	for _, rev := range g.history {
+		if rev.version == r.PathValue("sha") {
+			writeJSON(w, http.StatusOK, s.payload(g, rev))
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "Not Found", "")
+}
@@ -0,0 +1,109 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/grigri/pv/internal/model"
+)
+
+// csvConverter reads a CSV table of prompts, one prompt per row
+type csvConverter struct{}
+
+func (csvConverter) Name() string { return "csv" }
+
+func (csvConverter) Description() string {
+	return "CSV 表格，每行一个提示词；表头包含 name、body，可选 author、description、tags（以 ; 分隔）、version"
+}
+
+// csvColumns are the header names accepted for each prompt field
+var csvColumns = map[string][]string{
+	"name":        {"name", "title"},
+	"author":      {"author"},
+	"body":        {"body", "content", "prompt"},
+	"description": {"description"},
+	"tags":        {"tags"},
+	"version":     {"version"},
+}
+
+func (csvConverter) Convert(path string, content []byte) ([]Imported, error) {
+	// Excel 导出的 CSV 常带有 UTF-8 BOM
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(content, []byte("\xef\xbb\xbf"))))
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("无法读取 CSV 表头: %w", err)
+	}
+	columns := map[string]int{}
+	for i, title := range header {
+		title = strings.ToLower(strings.TrimSpace(title))
+		for field, names := range csvColumns {
+			for _, name := range names {
+				if _, ok := columns[field]; !ok && title == name {
+					columns[field] = i
+				}
+			}
+		}
+	}
+	for _, required := range []string{"name", "body"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("CSV 表头缺少 %s 列", required)
+		}
+	}
+
+	var imported []Imported
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		line, _ := reader.FieldPos(0)
+		source := fmt.Sprintf("%s:%d", path, line)
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				source = fmt.Sprintf("%s:%d", path, parseErr.StartLine)
+			}
+			// 引号不匹配等错误之后的内容无法可靠地读取
+			imported = append(imported, Imported{Source: source, Err: fmt.Errorf("无法解析 CSV: %w", err)})
+			break
+		}
+
+		cell := func(field string) string {
+			if i, ok := columns[field]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		body, err := convertDoubleBraces(cell("body"))
+		if err != nil {
+			imported = append(imported, Imported{Source: source, Err: err})
+			continue
+		}
+		imported = append(imported, Imported{Source: source, Prompt: model.Prompt{
+			Name:        cell("name"),
+			Author:      cell("author"),
+			Description: cell("description"),
+			Tags:        splitTags(cell("tags")),
+			Version:     cell("version"),
+			Content:     body,
+		}})
+	}
+	return imported, nil
+}
+
+// splitTags splits a tags cell on ; or ,
+func splitTags(cell string) []string {
+	var tags []string
+	for _, tag := range strings.FieldsFunc(cell, func(r rune) bool { return r == ';' || r == ',' }) {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
@@ -0,0 +1,108 @@
+// Package importer converts the prompt files of other tools into pv prompts.
+//
+// Each supported format has a Converter. Converters only map a file onto
+// model.Prompt and rewrite its placeholders into pv's {variable} syntax;
+// validation and storage are left to the prompt service, as for any prompt file.
+package importer
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/grigri/pv/internal/model"
+)
+
+// Imported is a prompt converted from the file of another tool
+type Imported struct {
+	// Source names where the prompt came from: the file, followed by the entry
+	// within it for files holding several prompts, such as prompts.csv:3
+	Source string
+	// Prompt holds the metadata found in the file and the prompt body in Content;
+	// fields without an equivalent in the file are left empty
+	Prompt model.Prompt
+	// Err is why the entry could not be converted
+	Err error
+}
+
+// Converter converts the prompt files of one tool
+type Converter interface {
+	// Name is the value of pv import --from that selects the converter
+	Name() string
+
+	// Description describes the files the converter reads
+	Description() string
+
+	// Convert returns the prompts in a file, given its path and content.
+	// An error means nothing in the file could be converted; entries that fail
+	// on their own are returned with Err set.
+	Convert(path string, content []byte) ([]Imported, error)
+}
+
+// Converters returns the supported formats, sorted by name
+func Converters() []Converter {
+	return []Converter{csvConverter{}, jinjaConverter{}, langChainConverter{}, openAIConverter{}, promptfooConverter{}}
+}
+
+// Find returns the converter of the named format
+func Find(name string) (Converter, bool) {
+	for _, converter := range Converters() {
+		if converter.Name() == strings.ToLower(name) {
+			return converter, true
+		}
+	}
+	return nil, false
+}
+
+// Names returns the names of the supported formats
+func Names() []string {
+	var names []string
+	for _, converter := range Converters() {
+		names = append(names, converter.Name())
+	}
+	return names
+}
+
+// doubleBraceVariable matches the {{ name }} and {{ name | filter }} placeholders of
+// Jinja, Nunjucks and Mustache templates
+var doubleBraceVariable = regexp.MustCompile(`\{\{-?\s*([A-Za-z_][\w.]*)\s*(?:\|[^{}]*)?-?\}\}`)
+
+// convertDoubleBraces rewrites the {{ name }} placeholders of text into pv's {name}
+// Filters are dropped. Statements, comments and expressions have no pv equivalent
+// and make the conversion fail.
+func convertDoubleBraces(text string) (string, error) {
+	converted := doubleBraceVariable.ReplaceAllString(text, "{$1}")
+	for _, open := range []string{"{{", "{%", "{#"} {
+		if i := strings.Index(converted, open); i >= 0 {
+			return "", fmt.Errorf("无法转换模板语法 %q，pv 只支持 {变量} 占位符", excerpt(converted[i:]))
+		}
+	}
+	return converted, nil
+}
+
+// convertFString converts a Python f-string template, whose {name} placeholders are
+// already pv's, by unescaping its literal {{ and }}
+func convertFString(text string) string {
+	return strings.NewReplacer("{{", "{", "}}", "}").Replace(text)
+}
+
+// excerpt returns the template tag at the start of text, shortened for error messages
+func excerpt(text string) string {
+	for _, end := range []string{"}}", "%}", "#}"} {
+		if i := strings.Index(text, end); i >= 0 && i < 40 {
+			return text[:i+len(end)]
+		}
+	}
+	if runes := []rune(text); len(runes) > 40 {
+		return string(runes[:40]) + "..."
+	}
+	return text
+}
+
+// baseName returns the file name of path without its extension, the name given to
+// prompts whose file does not name them
+func baseName(path string) string {
+	name := filepath.Base(path)
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
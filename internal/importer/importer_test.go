@@ -0,0 +1,221 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/grigri/pv/internal/model"
+)
+
+func TestConvertDoubleBraces(t *testing.T) {
+	tests := []struct {
+		text    string
+		want    string
+		wantErr bool
+	}{
+		{text: "Review {{code}} in {{ language }}", want: "Review {code} in {language}"},
+		{text: "Hi {{ user.name | title }}{{- suffix -}}", want: "Hi {user.name}{suffix}"},
+		{text: "No placeholders", want: "No placeholders"},
+		{text: "{% if code %}Review {{ code }}{% endif %}", wantErr: true},
+		{text: "{{ a + b }}", wantErr: true},
+		{text: "{{#items}}{{.}}{{/items}}", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := convertDoubleBraces(tt.text)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("Expected %q not to convert, got %q", tt.text, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("convertDoubleBraces(%q) = %q, %v, want %q", tt.text, got, err, tt.want)
+		}
+	}
+}
+
+func TestFind(t *testing.T) {
+	for _, name := range Names() {
+		if converter, ok := Find(strings.ToUpper(name)); !ok || converter.Name() != name {
+			t.Errorf("Expected to find the %s converter", name)
+		}
+	}
+	if _, ok := Find("unknown"); ok {
+		t.Error("Expected no converter for an unknown format")
+	}
+}
+
+func TestOpenAIConverter(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    model.Prompt
+	}{
+		{
+			name:    "message array",
+			content: `[{"role": "system", "content": "You review code."}, {"role": "user", "content": [{"type": "text", "text": "Review {{code}}"}]}]`,
+			want:    model.Prompt{Name: "review", Content: "[system]\nYou review code.\n\n[user]\nReview {code}"},
+		},
+		{
+			name:    "request with a single message",
+			content: `{"model": "gpt-4o", "name": "Summary", "messages": [{"role": "user", "content": "Summarize {{ text }}"}]}`,
+			want:    model.Prompt{Name: "Summary", Content: "Summarize {text}"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			imported, err := openAIConverter{}.Convert("chats/review.json", []byte(tt.content))
+			if err != nil {
+				t.Fatalf("Convert failed: %v", err)
+			}
+			if len(imported) != 1 || !reflect.DeepEqual(imported[0].Prompt, tt.want) || imported[0].Source != "chats/review.json" {
+				t.Errorf("Expected %+v, got %+v", tt.want, imported)
+			}
+		})
+	}
+
+	for _, content := range []string{`{"messages": []}`, `[{"role": "user", "content": [{"type": "image_url"}]}]`, `not json`} {
+		if _, err := (openAIConverter{}).Convert("chat.json", []byte(content)); err == nil {
+			t.Errorf("Expected %s not to convert", content)
+		}
+	}
+}
+
+func TestLangChainConverter(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "body.txt"), []byte("Explain {{ topic }}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path    string
+		content string
+		want    string
+	}{
+		{"summary.json", `{"_type": "prompt", "input_variables": ["text"], "template": "Summarize {text} as {{\"key\": 1}}"}`, `Summarize {text} as {"key": 1}`},
+		{"review.yaml", "_type: prompt\ntemplate_format: jinja2\ntemplate: Review {{ code }}\n", "Review {code}"},
+		{filepath.Join(dir, "explain.yaml"), "template_path: body.txt\ntemplate_format: jinja2\n", "Explain {topic}"},
+		{"translate.txt", "Translate {text} to {language}\n", "Translate {text} to {language}"},
+	}
+	for _, tt := range tests {
+		imported, err := langChainConverter{}.Convert(tt.path, []byte(tt.content))
+		if err != nil {
+			t.Errorf("Convert(%s) failed: %v", tt.path, err)
+			continue
+		}
+		if len(imported) != 1 || imported[0].Prompt.Content != tt.want || imported[0].Prompt.Name != baseName(tt.path) {
+			t.Errorf("Convert(%s) = %+v, want %q", tt.path, imported, tt.want)
+		}
+	}
+
+	if _, err := (langChainConverter{}).Convert("few_shot.yaml", []byte("_type: few_shot\ntemplate: x\n")); err == nil {
+		t.Error("Expected other LangChain templates not to convert")
+	}
+}
+
+func TestJinjaConverter(t *testing.T) {
+	imported, err := jinjaConverter{}.Convert("templates/review.j2", []byte("Review {{ code }}\n"))
+	if err != nil || len(imported) != 1 || imported[0].Prompt.Name != "review" || imported[0].Prompt.Content != "Review {code}" {
+		t.Errorf("Expected the template to convert, got %+v, %v", imported, err)
+	}
+	if _, err := (jinjaConverter{}).Convert("loop.j2", []byte("{% for x in xs %}{{ x }}{% endfor %}")); err == nil {
+		t.Error("Expected statements not to convert")
+	}
+}
+
+func TestPromptfooConverter(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"prompts/review.txt": "Review {{code}}\n---\nReview {{code}} strictly\n",
+		"prompts/chat.json":  `[{"role": "user", "content": "Explain {{topic}}"}]`,
+		"prompts/loop.txt":   "{% for x in xs %}{{ x }}{% endfor %}",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	config := filepath.Join(dir, "promptfooconfig.yaml")
+	content := `description: Code prompts
+prompts:
+  - "Summarize {{ text }}"
+  - file://prompts/review.txt
+  - id: file://prompts/chat.json
+    label: Explain
+  - raw: "Translate {{text}}"
+    id: translate
+  - file://prompts/loop.txt
+  - file://prompts/missing.txt
+`
+	imported, err := promptfooConverter{}.Convert(config, []byte(content))
+	if err != nil {
+		t.Fatalf("Convert failed: %v", err)
+	}
+
+	var names, bodies []string
+	var failed []string
+	for _, entry := range imported {
+		if entry.Err != nil {
+			failed = append(failed, filepath.Base(entry.Source))
+			continue
+		}
+		if entry.Prompt.Description != "Code prompts" {
+			t.Errorf("Expected the config description, got %+v", entry.Prompt)
+		}
+		names = append(names, entry.Prompt.Name)
+		bodies = append(bodies, entry.Prompt.Content)
+	}
+	if want := []string{"promptfooconfig-1", "review-1", "review-2", "Explain", "translate"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("Expected prompts %v, got %v", want, names)
+	}
+	if want := []string{"Summarize {text}", "Review {code}", "Review {code} strictly", "Explain {topic}", "Translate {text}"}; !reflect.DeepEqual(bodies, want) {
+		t.Errorf("Expected bodies %q, got %q", want, bodies)
+	}
+	if want := []string{"loop.txt", "missing.txt"}; !reflect.DeepEqual(failed, want) {
+		t.Errorf("Expected %v to fail, got %v", want, failed)
+	}
+
+	if _, err := (promptfooConverter{}).Convert("other.yaml", []byte("providers: [openai:gpt-4o]\n")); err == nil {
+		t.Error("Expected a configuration without prompts not to convert")
+	}
+}
+
+func TestCSVConverter(t *testing.T) {
+	content := "\xef\xbb\xbfName,Author,Body,Tags\n" +
+		"Review,alice,\"Review {{code}}\nCarefully\",code;review\n" +
+		"Summary,,Summarize {{ text }},\n" +
+		"Loop,bob,{% for x in xs %}{% endfor %},\n"
+
+	imported, err := csvConverter{}.Convert("prompts.csv", []byte(content))
+	if err != nil {
+		t.Fatalf("Convert failed: %v", err)
+	}
+	if len(imported) != 3 {
+		t.Fatalf("Expected 3 rows, got %+v", imported)
+	}
+
+	want := model.Prompt{Name: "Review", Author: "alice", Tags: []string{"code", "review"}, Content: "Review {code}\nCarefully"}
+	if imported[0].Source != "prompts.csv:2" || !reflect.DeepEqual(imported[0].Prompt, want) {
+		t.Errorf("Expected %+v from prompts.csv:2, got %+v", want, imported[0])
+	}
+	if imported[1].Source != "prompts.csv:4" || imported[1].Prompt.Author != "" || imported[1].Prompt.Content != "Summarize {text}" {
+		t.Errorf("Expected the second row from line 4, got %+v", imported[1])
+	}
+	if imported[2].Err == nil {
+		t.Errorf("Expected the third row to fail, got %+v", imported[2])
+	}
+
+	if _, err := (csvConverter{}).Convert("prompts.csv", []byte("title,author\nReview,alice\n")); err == nil {
+		t.Error("Expected a table without a body column not to convert")
+	}
+}
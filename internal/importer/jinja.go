@@ -0,0 +1,100 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/grigri/pv/internal/model"
+	"gopkg.in/yaml.v3"
+)
+
+// jinjaConverter reads Jinja2 template files
+type jinjaConverter struct{}
+
+func (jinjaConverter) Name() string { return "jinja" }
+
+func (jinjaConverter) Description() string {
+	return "Jinja2 模板文件，{{ 变量 }} 占位符"
+}
+
+func (jinjaConverter) Convert(path string, content []byte) ([]Imported, error) {
+	body, err := convertDoubleBraces(strings.TrimSpace(string(content)))
+	if err != nil {
+		return nil, err
+	}
+	return []Imported{{Source: path, Prompt: model.Prompt{Name: baseName(path), Content: body}}}, nil
+}
+
+// langChainConverter reads LangChain prompt templates
+type langChainConverter struct{}
+
+func (langChainConverter) Name() string { return "langchain" }
+
+func (langChainConverter) Description() string {
+	return "LangChain PromptTemplate：序列化的 JSON/YAML（f-string 或 jinja2），或 f-string 模板文本"
+}
+
+// langChainTemplate is a PromptTemplate serialized by LangChain
+type langChainTemplate struct {
+	Type           string `json:"_type" yaml:"_type"`
+	Template       string `json:"template" yaml:"template"`
+	TemplatePath   string `json:"template_path" yaml:"template_path"`
+	TemplateFormat string `json:"template_format" yaml:"template_format"`
+}
+
+func (langChainConverter) Convert(path string, content []byte) ([]Imported, error) {
+	prompt := model.Prompt{Name: baseName(path)}
+
+	// 序列化的模板是 JSON 或 YAML，其他扩展名的文件是 f-string 模板本身
+	var template langChainTemplate
+	var err error
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.Unmarshal(content, &template)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &template)
+	default:
+		prompt.Content = convertFString(strings.TrimSpace(string(content)))
+		return []Imported{{Source: path, Prompt: prompt}}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("无法解析 LangChain 模板: %w", err)
+	}
+	if template.Type != "" && template.Type != "prompt" {
+		return nil, fmt.Errorf("不支持 %s 类型的 LangChain 模板，只支持 PromptTemplate", template.Type)
+	}
+
+	text := template.Template
+	if text == "" && template.TemplatePath != "" {
+		templatePath := template.TemplatePath
+		if !filepath.IsAbs(templatePath) {
+			templatePath = filepath.Join(filepath.Dir(path), templatePath)
+		}
+		data, err := os.ReadFile(templatePath)
+		if err != nil {
+			return nil, fmt.Errorf("读取 template_path 失败: %w", err)
+		}
+		text = string(data)
+	}
+	if strings.TrimSpace(text) == "" {
+		return nil, fmt.Errorf("不是 LangChain PromptTemplate：缺少 template")
+	}
+	text = strings.TrimSpace(text)
+
+	switch template.TemplateFormat {
+	case "", "f-string":
+		prompt.Content = convertFString(text)
+	case "jinja2", "mustache":
+		body, err := convertDoubleBraces(text)
+		if err != nil {
+			return nil, err
+		}
+		prompt.Content = body
+	default:
+		return nil, fmt.Errorf("不支持的 template_format %q", template.TemplateFormat)
+	}
+	return []Imported{{Source: path, Prompt: prompt}}, nil
+}
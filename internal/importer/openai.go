@@ -0,0 +1,112 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/grigri/pv/internal/model"
+)
+
+// openAIConverter reads OpenAI-style chat messages
+type openAIConverter struct{}
+
+func (openAIConverter) Name() string { return "openai" }
+
+func (openAIConverter) Description() string {
+	return "OpenAI 聊天消息 JSON：消息数组，或带 messages 字段的对象"
+}
+
+// chatMessage is a message of an OpenAI chat
+type chatMessage struct {
+	Role string `json:"role"`
+	// Content is a string, or an array of content parts
+	Content json.RawMessage `json:"content"`
+}
+
+// contentPart is a part of the content of a chat message
+type contentPart struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// chatFile is a chat request, or a stored prompt holding its messages
+type chatFile struct {
+	Name        string        `json:"name"`
+	Description string        `json:"description"`
+	Messages    []chatMessage `json:"messages"`
+}
+
+func (c openAIConverter) Convert(path string, content []byte) ([]Imported, error) {
+	prompt, err := convertChat(content)
+	if err != nil {
+		return nil, err
+	}
+	if prompt.Name == "" {
+		prompt.Name = baseName(path)
+	}
+	return []Imported{{Source: path, Prompt: prompt}}, nil
+}
+
+// convertChat converts chat messages JSON into a prompt
+// A single message becomes the prompt body; several are written one after the
+// other, each under a [role] line.
+func convertChat(content []byte) (model.Prompt, error) {
+	var chat chatFile
+	trimmed := bytes.TrimSpace(content)
+	if bytes.HasPrefix(trimmed, []byte("[")) {
+		if err := json.Unmarshal(trimmed, &chat.Messages); err != nil {
+			return model.Prompt{}, fmt.Errorf("无法解析聊天消息 JSON: %w", err)
+		}
+	} else if err := json.Unmarshal(trimmed, &chat); err != nil {
+		return model.Prompt{}, fmt.Errorf("无法解析聊天消息 JSON: %w", err)
+	}
+	if len(chat.Messages) == 0 {
+		return model.Prompt{}, fmt.Errorf("没有聊天消息")
+	}
+
+	var sections []string
+	for i, message := range chat.Messages {
+		text, err := messageText(message.Content)
+		if err != nil {
+			return model.Prompt{}, fmt.Errorf("第 %d 条消息: %w", i+1, err)
+		}
+		if len(chat.Messages) == 1 {
+			sections = append(sections, text)
+			break
+		}
+		role := message.Role
+		if role == "" {
+			role = "user"
+		}
+		sections = append(sections, fmt.Sprintf("[%s]\n%s", role, text))
+	}
+
+	body, err := convertDoubleBraces(strings.Join(sections, "\n\n"))
+	if err != nil {
+		return model.Prompt{}, err
+	}
+	return model.Prompt{Name: chat.Name, Description: chat.Description, Content: body}, nil
+}
+
+// messageText returns the text of the content of a chat message
+func messageText(content json.RawMessage) (string, error) {
+	var text string
+	if err := json.Unmarshal(content, &text); err == nil {
+		return strings.TrimSpace(text), nil
+	}
+
+	var parts []contentPart
+	if err := json.Unmarshal(content, &parts); err != nil {
+		return "", fmt.Errorf("content 既不是字符串也不是内容数组")
+	}
+	var texts []string
+	for _, part := range parts {
+		if part.Type != "text" && part.Type != "input_text" {
+			return "", fmt.Errorf("不支持 %s 类型的内容", part.Type)
+		}
+		texts = append(texts, strings.TrimSpace(part.Text))
+	}
+	return strings.Join(texts, "\n\n"), nil
+}
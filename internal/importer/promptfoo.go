@@ -0,0 +1,171 @@
+package importer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/grigri/pv/internal/model"
+	"gopkg.in/yaml.v3"
+)
+
+// promptfooConverter reads the prompts of promptfoo configurations
+type promptfooConverter struct{}
+
+func (promptfooConverter) Name() string { return "promptfoo" }
+
+func (promptfooConverter) Description() string {
+	return "promptfoo 配置 YAML 中的 prompts，包括 file:// 引用的文本和聊天 JSON 文件"
+}
+
+// promptfooConfig is the part of a promptfoo configuration holding its prompts
+type promptfooConfig struct {
+	Description string `yaml:"description"`
+	// Prompts is a prompt, a list of prompts, or a map of prompts to their labels
+	Prompts interface{} `yaml:"prompts"`
+}
+
+// promptfooPrompt is an entry of the prompts of a promptfoo configuration
+type promptfooPrompt struct {
+	// Ref is the prompt text, or a file:// reference to the file holding it
+	Ref   string
+	Label string
+}
+
+// promptSeparator separates the prompts of a promptfoo text file
+var promptSeparator = regexp.MustCompile(`(?m)^---[ \t]*\r?$`)
+
+func (c promptfooConverter) Convert(path string, content []byte) ([]Imported, error) {
+	var config promptfooConfig
+	if err := yaml.Unmarshal(content, &config); err != nil {
+		return nil, fmt.Errorf("无法解析 promptfoo 配置: %w", err)
+	}
+	entries, err := promptfooPrompts(config.Prompts)
+	if err != nil {
+		return nil, err
+	}
+
+	var imported []Imported
+	for i, entry := range entries {
+		source := fmt.Sprintf("%s#%d", path, i+1)
+		ref, isFile := strings.CutPrefix(entry.Ref, "file://")
+		if !isFile {
+			name := entry.Label
+			if name == "" {
+				name = fmt.Sprintf("%s-%d", baseName(path), i+1)
+			}
+			imported = append(imported, convertPromptfooText(source, name, entry.Ref, config.Description))
+			continue
+		}
+
+		if !filepath.IsAbs(ref) {
+			ref = filepath.Join(filepath.Dir(path), ref)
+		}
+		imported = append(imported, c.convertFile(ref, entry.Label, config.Description)...)
+	}
+	return imported, nil
+}
+
+// convertFile converts a prompt file referenced by a promptfoo configuration
+// Chat JSON files hold one prompt; text files hold prompts separated by --- lines.
+func (c promptfooConverter) convertFile(path, label, description string) []Imported {
+	if strings.ContainsAny(path, "*?[") || strings.Contains(filepath.Base(path), ":") {
+		return []Imported{{Source: path, Err: fmt.Errorf("不支持 glob 和函数形式的 file:// 引用")}}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return []Imported{{Source: path, Err: fmt.Errorf("读取引用的文件失败: %w", err)}}
+	}
+
+	name := label
+	if name == "" {
+		name = baseName(path)
+	}
+
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		prompt, err := convertChat(data)
+		if err != nil {
+			return []Imported{{Source: path, Err: err}}
+		}
+		prompt.Name = name
+		if prompt.Description == "" {
+			prompt.Description = description
+		}
+		return []Imported{{Source: path, Prompt: prompt}}
+	}
+
+	parts := promptSeparator.Split(string(data), -1)
+	if len(parts) == 1 {
+		return []Imported{convertPromptfooText(path, name, parts[0], description)}
+	}
+	var imported []Imported
+	for i, part := range parts {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		source := fmt.Sprintf("%s#%d", path, i+1)
+		imported = append(imported, convertPromptfooText(source, fmt.Sprintf("%s-%d", name, i+1), part, description))
+	}
+	return imported
+}
+
+// convertPromptfooText converts the text of a promptfoo prompt, a Nunjucks template
+func convertPromptfooText(source, name, text, description string) Imported {
+	body, err := convertDoubleBraces(strings.TrimSpace(text))
+	if err != nil {
+		return Imported{Source: source, Err: err}
+	}
+	return Imported{Source: source, Prompt: model.Prompt{Name: name, Description: description, Content: body}}
+}
+
+// promptfooPrompts lists the prompts of a configuration in the forms promptfoo accepts
+func promptfooPrompts(prompts interface{}) ([]promptfooPrompt, error) {
+	switch prompts := prompts.(type) {
+	case nil:
+		return nil, fmt.Errorf("不是 promptfoo 配置：缺少 prompts")
+	case string:
+		return []promptfooPrompt{{Ref: prompts}}, nil
+	case map[string]interface{}:
+		// 旧格式：提示词或文件映射到标签
+		var entries []promptfooPrompt
+		for ref, label := range prompts {
+			entries = append(entries, promptfooPrompt{Ref: ref, Label: fmt.Sprint(label)})
+		}
+		// map 没有顺序，按引用排序保持结果稳定
+		sort.Slice(entries, func(i, j int) bool { return entries[i].Ref < entries[j].Ref })
+		return entries, nil
+	case []interface{}:
+		var entries []promptfooPrompt
+		for i, item := range prompts {
+			switch item := item.(type) {
+			case string:
+				entries = append(entries, promptfooPrompt{Ref: item})
+			case map[string]interface{}:
+				// 有 raw 时 id 只是标识，否则 id 是提示词或文件引用
+				entry := promptfooPrompt{Ref: stringField(item, "raw"), Label: stringField(item, "label")}
+				if entry.Ref == "" {
+					entry.Ref = stringField(item, "id")
+				} else if entry.Label == "" {
+					entry.Label = stringField(item, "id")
+				}
+				if entry.Ref == "" {
+					return nil, fmt.Errorf("第 %d 个提示词缺少 raw 或 id", i+1)
+				}
+				entries = append(entries, entry)
+			default:
+				return nil, fmt.Errorf("第 %d 个提示词的格式无法识别", i+1)
+			}
+		}
+		return entries, nil
+	}
+	return nil, fmt.Errorf("prompts 的格式无法识别")
+}
+
+// stringField returns a string field of a YAML mapping, or an empty string
+func stringField(mapping map[string]interface{}, key string) string {
+	value, _ := mapping[key].(string)
+	return value
+}
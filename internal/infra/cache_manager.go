@@ -75,12 +75,12 @@ func (c *CacheManager) LoadIndex() (*model.Index, error) {
 	}
 	
 	// Parse JSON data
-	var index model.Index
-	if err := json.Unmarshal(data, &index); err != nil {
+	index, err := DecodeIndex(data)
+	if err != nil {
 		return nil, errors.NewAppError(errors.ErrStorage, "failed to parse cache index", err)
 	}
 	
-	return &index, nil
+	return index, nil
 }
 
 // SaveIndex saves the model.Index to index.json using JSON serialization
@@ -92,7 +92,7 @@ func (c *CacheManager) SaveIndex(index *model.Index) error {
 	}
 	
 	// Marshal index to JSON with proper formatting
-	data, err := json.MarshalIndent(index, "", "  ")
+	data, err := encodeIndex(index)
 	if err != nil {
 		return errors.NewAppError(errors.ErrStorage, "failed to marshal cache index", err)
 	}
@@ -149,6 +149,214 @@ func (c *CacheManager) SaveContent(gistID, content string) error {
 	return nil
 }
 
+// DeleteContent removes prompts/{gist_id}.yaml from the cache
+// Returns whether a cached file was removed
+func (c *CacheManager) DeleteContent(gistID string) (bool, error) {
+	filename := fmt.Sprintf("%s.yaml", gistID)
+	contentPath := filepath.Join(c.cacheDir, "prompts", filename)
+
+	if err := os.Remove(contentPath); err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, errors.NewAppError(errors.ErrStorage, "failed to delete cached content", err)
+	}
+
+	return true, nil
+}
+
+// LoadResponse reads the remote response cached as responses/{key}.json
+// Returns nil when no response is cached for key
+func (c *CacheManager) LoadResponse(key string) (*CachedResponse, error) {
+	responsePath := filepath.Join(c.cacheDir, "responses", key+".json")
+
+	data, err := os.ReadFile(responsePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.NewAppError(errors.ErrStorage, "failed to read cached response", err)
+	}
+
+	var response CachedResponse
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, errors.NewAppError(errors.ErrStorage, "failed to parse cached response", err)
+	}
+
+	return &response, nil
+}
+
+// SaveResponse caches a remote response with its validators as responses/{key}.json
+func (c *CacheManager) SaveResponse(key string, response *CachedResponse) error {
+	responsesDir := filepath.Join(c.cacheDir, "responses")
+	if err := os.MkdirAll(responsesDir, 0700); err != nil {
+		return errors.NewAppError(errors.ErrStorage, "failed to create responses directory", err)
+	}
+
+	data, err := json.Marshal(response)
+	if err != nil {
+		return errors.NewAppError(errors.ErrStorage, "failed to marshal cached response", err)
+	}
+
+	if err := config.WriteFileWithPermissions(filepath.Join(responsesDir, key+".json"), data); err != nil {
+		return errors.NewAppError(errors.ErrStorage, "failed to save cached response", err)
+	}
+
+	return nil
+}
+
+// DeleteResponse removes the response cached for key, if any
+func (c *CacheManager) DeleteResponse(key string) error {
+	responsePath := filepath.Join(c.cacheDir, "responses", key+".json")
+	if err := os.Remove(responsePath); err != nil && !os.IsNotExist(err) {
+		return errors.NewAppError(errors.ErrStorage, "failed to delete cached response", err)
+	}
+	return nil
+}
+
+// revisionsDir returns revisions/{gist_id}, where the history of a prompt is cached
+func (c *CacheManager) revisionsDir(gistID string) string {
+	return filepath.Join(c.cacheDir, "revisions", gistID)
+}
+
+// LoadRevisions reads the revision list of a prompt from revisions/{gist_id}/history.json
+func (c *CacheManager) LoadRevisions(gistID string) ([]model.Revision, error) {
+	data, err := os.ReadFile(filepath.Join(c.revisionsDir(gistID), "history.json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, errors.NewAppError(errors.ErrStorage, "cached revisions not found", err)
+		}
+		return nil, errors.NewAppError(errors.ErrStorage, "failed to read cached revisions", err)
+	}
+
+	var revisions []model.Revision
+	if err := json.Unmarshal(data, &revisions); err != nil {
+		return nil, errors.NewAppError(errors.ErrStorage, "failed to parse cached revisions", err)
+	}
+
+	return revisions, nil
+}
+
+// SaveRevisions saves the revision list of a prompt to revisions/{gist_id}/history.json
+func (c *CacheManager) SaveRevisions(gistID string, revisions []model.Revision) error {
+	dir := c.revisionsDir(gistID)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return errors.NewAppError(errors.ErrStorage, "failed to create revisions directory", err)
+	}
+
+	data, err := json.MarshalIndent(revisions, "", "  ")
+	if err != nil {
+		return errors.NewAppError(errors.ErrStorage, "failed to marshal cached revisions", err)
+	}
+
+	if err := config.WriteFileWithPermissions(filepath.Join(dir, "history.json"), data); err != nil {
+		return errors.NewAppError(errors.ErrStorage, "failed to save cached revisions", err)
+	}
+
+	return nil
+}
+
+// LoadRevisionContent reads the content of a prompt revision from revisions/{gist_id}/{sha}.yaml
+func (c *CacheManager) LoadRevisionContent(gistID, sha string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(c.revisionsDir(gistID), sha+".yaml"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", errors.NewAppError(errors.ErrStorage, "cached revision not found", err)
+		}
+		return "", errors.NewAppError(errors.ErrStorage, "failed to read cached revision", err)
+	}
+
+	return string(data), nil
+}
+
+// SaveRevisionContent saves the content of a prompt revision to revisions/{gist_id}/{sha}.yaml
+// Revisions never change, so the saved content stays valid for good
+func (c *CacheManager) SaveRevisionContent(gistID, sha, content string) error {
+	dir := c.revisionsDir(gistID)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return errors.NewAppError(errors.ErrStorage, "failed to create revisions directory", err)
+	}
+
+	if err := config.WriteFileWithPermissions(filepath.Join(dir, sha+".yaml"), []byte(content)); err != nil {
+		return errors.NewAppError(errors.ErrStorage, "failed to save cached revision", err)
+	}
+
+	return nil
+}
+
+// DeleteRevisions removes the cached history of a prompt, if any
+func (c *CacheManager) DeleteRevisions(gistID string) error {
+	if err := os.RemoveAll(c.revisionsDir(gistID)); err != nil {
+		return errors.NewAppError(errors.ErrStorage, "failed to delete cached revisions", err)
+	}
+	return nil
+}
+
+// LoadJournal reads the offline operation journal from journal.json
+// Returns an empty journal when no operations are queued
+func (c *CacheManager) LoadJournal() ([]model.PendingOperation, error) {
+	journalPath := filepath.Join(c.cacheDir, JournalFileName)
+
+	data, err := os.ReadFile(journalPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.NewAppError(errors.ErrStorage, "failed to read offline journal", err)
+	}
+
+	var operations []model.PendingOperation
+	if err := json.Unmarshal(data, &operations); err != nil {
+		return nil, errors.NewAppError(errors.ErrStorage, "failed to parse offline journal", err)
+	}
+
+	return operations, nil
+}
+
+// SaveJournal writes the offline operation journal, removing the file once nothing is queued
+func (c *CacheManager) SaveJournal(operations []model.PendingOperation) error {
+	journalPath := filepath.Join(c.cacheDir, JournalFileName)
+
+	if len(operations) == 0 {
+		if err := os.Remove(journalPath); err != nil && !os.IsNotExist(err) {
+			return errors.NewAppError(errors.ErrStorage, "failed to clear offline journal", err)
+		}
+		return nil
+	}
+
+	if err := c.EnsureCacheDir(); err != nil {
+		return fmt.Errorf("failed to ensure cache directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(operations, "", "  ")
+	if err != nil {
+		return errors.NewAppError(errors.ErrStorage, "failed to marshal offline journal", err)
+	}
+
+	if err := config.WriteFileWithPermissions(journalPath, data); err != nil {
+		return errors.NewAppError(errors.ErrStorage, "failed to save offline journal", err)
+	}
+
+	return nil
+}
+
+// SaveConflictCopy keeps the local content of an operation that conflicted with remote changes
+// The copy is written to conflicts/{name}-{timestamp}.yaml and its path is returned
+func (c *CacheManager) SaveConflictCopy(name, content string) (string, error) {
+	conflictsDir := filepath.Join(c.cacheDir, "conflicts")
+	if err := os.MkdirAll(conflictsDir, 0700); err != nil {
+		return "", errors.NewAppError(errors.ErrStorage, "failed to create conflicts directory", err)
+	}
+
+	filename := fmt.Sprintf("%s-%s.yaml", pathSeparators.Replace(name), time.Now().Format("20060102-150405"))
+	conflictPath := filepath.Join(conflictsDir, filename)
+	if err := config.WriteFileWithPermissions(conflictPath, []byte(content)); err != nil {
+		return "", errors.NewAppError(errors.ErrStorage, "failed to save conflicting content", err)
+	}
+
+	return conflictPath, nil
+}
+
 // GetCacheInfo returns statistical information about the cache directory
 // including last update time, total prompts count, and total cache size in bytes
 func (c *CacheManager) GetCacheInfo() (*model.CacheInfo, error) {
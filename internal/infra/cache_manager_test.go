@@ -757,6 +757,26 @@ content: |
 	}
 }
 
+func TestCacheManager_SaveConflictCopy(t *testing.T) {
+	for _, name := range []string{"Code Review", "team/review", "../escape", `dir\review`} {
+		t.Run(name, func(t *testing.T) {
+			cacheDir := t.TempDir()
+			cm := &CacheManager{cacheDir: cacheDir}
+
+			path, err := cm.SaveConflictCopy(name, "local content")
+			if err != nil {
+				t.Fatalf("SaveConflictCopy failed: %v", err)
+			}
+			if filepath.Dir(path) != filepath.Join(cacheDir, "conflicts") {
+				t.Errorf("Expected the copy in the conflicts directory, got %s", path)
+			}
+			if data, err := os.ReadFile(path); err != nil || string(data) != "local content" {
+				t.Errorf("Expected the local content to be saved, got %q, %v", data, err)
+			}
+		})
+	}
+}
+
 func TestCacheManager_GetCacheInfo(t *testing.T) {
 	tests := []struct {
 		name      string
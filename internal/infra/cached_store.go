@@ -1,11 +1,10 @@
 package infra
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
-	"strings"
 	"time"
 	"github.com/grigri/pv/internal/config"
 	"github.com/grigri/pv/internal/model"
@@ -24,6 +23,11 @@ type CachedStore struct {
 // NewCachedStore creates a new CachedStore instance that wraps a remote Store with local caching
 // The forceRemote parameter controls whether to bypass cache and always use remote operations
 func NewCachedStore(remote Store, cacheManager *CacheManager, configStore config.Store, forceRemote bool) Store {
+	// Remote reads are revalidated against the responses kept next to the cached prompts
+	if cacher, ok := remote.(ResponseCacher); ok && cacheManager != nil {
+		cacher.SetResponseCache(cacheManager)
+	}
+
 	return &CachedStore{
 		remote:      remote,
 		cache:       cacheManager,
@@ -35,16 +39,17 @@ func NewCachedStore(remote Store, cacheManager *CacheManager, configStore config
 // List retrieves all prompts using the remote-first strategy
 // Per requirement 2.1: Try remote first, fallback to cache on failure
 // Per requirement 5.2: If forceRemote is true, skip cache fallback
-func (c *CachedStore) List() ([]model.Prompt, error) {
+func (c *CachedStore) List(ctx context.Context) ([]model.Prompt, error) {
 	// Always try remote first (remote-first strategy)
-	prompts, err := c.remote.List()
+	prompts, err := c.remote.List(ctx)
 	if err == nil {
 		// Remote success: update cache and return remote data
 		if cacheErr := c.updateCacheFromPrompts(prompts); cacheErr != nil {
 			// Log cache update failure but don't fail the operation
 			// The user still gets the correct data from remote
 		}
-		return prompts, nil
+		// Prompts added offline stay listed until the journal is replayed
+		return append(prompts, c.pendingPrompts()...), nil
 	}
 
 	// Remote failed: check if we should fallback to cache
@@ -53,17 +58,34 @@ func (c *CachedStore) List() ([]model.Prompt, error) {
 		return nil, fmt.Errorf("remote operation failed and forceRemote is enabled: %w", err)
 	}
 
+	// A command cancelled by the user stops here; timeouts and network errors fall back
+	if isInterrupted(ctx, err) {
+		return nil, err
+	}
+
 	// Try to fallback to cache
 	return c.listFromCache(err)
 }
 
 // Add creates a new prompt using the remote store and updates the cache
-func (c *CachedStore) Add(prompt model.Prompt) error {
+// When the remote is unreachable the prompt is added to the cache and queued in the offline journal
+func (c *CachedStore) Add(ctx context.Context, prompt model.Prompt) error {
+	c.replayPending(ctx)
+
 	// Add to remote store
-	if err := c.remote.Add(prompt); err != nil {
-		return err
+	if err := c.remote.Add(ctx, prompt); err != nil {
+		if !c.canQueue(ctx, err) {
+			return err
+		}
+		return c.addOffline(prompt)
 	}
 
+	c.addToCache(prompt)
+	return nil
+}
+
+// addToCache records a new prompt in the cache index and content cache
+func (c *CachedStore) addToCache(prompt model.Prompt) {
 	// Update cache with the new prompt
 	// Load current index, add the new prompt, and save
 	index, err := c.cache.LoadIndex()
@@ -78,11 +100,12 @@ func (c *CachedStore) Add(prompt model.Prompt) error {
 	// Create indexed prompt entry
 	indexedPrompt := model.IndexedPrompt{
 		GistURL:     prompt.GistURL,
-		FilePath:    fmt.Sprintf("%s.yaml", prompt.Name),
+		FilePath:    prompt.FileName(),
 		Author:      prompt.Author,
 		Name:        prompt.Name,
 		LastUpdated: time.Now(),
 	}
+	indexedPrompt.ApplyMetadata(prompt)
 
 	// Add to index
 	index.Prompts = append(index.Prompts, indexedPrompt)
@@ -95,30 +118,156 @@ func (c *CachedStore) Add(prompt model.Prompt) error {
 	if prompt.ID != "" {
 		c.cache.SaveContent(prompt.ID, prompt.Content)
 	}
+}
 
-	return nil
+// AddBatch adds several prompts, using a single index write when the remote store supports it
+// Otherwise the prompts are added one by one like Add. Either way the prompts the remote
+// could not store while offline are queued, like Add.
+func (c *CachedStore) AddBatch(ctx context.Context, prompts []model.Prompt) ([]model.Prompt, error) {
+	c.replayPending(ctx)
+
+	batcher, ok := c.remote.(BatchAdder)
+	if !ok {
+		return c.addOneByOne(ctx, prompts)
+	}
+
+	stored, err := batcher.AddBatch(ctx, prompts)
+	c.addBatchToCache(stored)
+	if err == nil || !c.canQueue(ctx, err) {
+		return stored, err
+	}
+
+	// 离线时排队剩下的提示词，它们在重放后才有地址
+	for _, prompt := range prompts[len(stored):] {
+		if err := c.addOffline(prompt); err != nil {
+			return stored, err
+		}
+		stored = append(stored, prompt)
+	}
+	return stored, nil
+}
+
+// addOneByOne adds prompts to a remote store that cannot add them in a batch
+// The remote Add does not return where a prompt is stored, so the prompts are
+// returned and cached without their IDs and gist URLs.
+func (c *CachedStore) addOneByOne(ctx context.Context, prompts []model.Prompt) ([]model.Prompt, error) {
+	var stored []model.Prompt
+	var added []int
+	var addErr error
+	for _, prompt := range prompts {
+		if err := c.remote.Add(ctx, prompt); err != nil {
+			if !c.canQueue(ctx, err) {
+				addErr = err
+				break
+			}
+			// 离线时与 Add 一样排队，提示词在重放后才有地址
+			if err := c.addOffline(prompt); err != nil {
+				addErr = err
+				break
+			}
+			stored = append(stored, prompt)
+			continue
+		}
+		added = append(added, len(stored))
+		stored = append(stored, prompt)
+	}
+	if len(added) == 0 {
+		return stored, addErr
+	}
+
+	remoteStored := make([]model.Prompt, 0, len(added))
+	for _, i := range added {
+		remoteStored = append(remoteStored, stored[i])
+	}
+	c.addBatchToCache(remoteStored)
+	return stored, addErr
+}
+
+// addBatchToCache records prompts added in a batch in the cache, writing the cache index once
+func (c *CachedStore) addBatchToCache(prompts []model.Prompt) {
+	if len(prompts) == 0 {
+		return
+	}
+
+	index, err := c.cache.LoadIndex()
+	if err != nil {
+		// If cache doesn't exist, create a new one
+		index = &model.Index{
+			Prompts:     []model.IndexedPrompt{},
+			LastUpdated: time.Now(),
+		}
+	}
+
+	for _, prompt := range prompts {
+		indexedPrompt := model.IndexedPrompt{
+			GistURL:     prompt.GistURL,
+			FilePath:    prompt.FileName(),
+			Author:      prompt.Author,
+			Name:        prompt.Name,
+			LastUpdated: time.Now(),
+		}
+		indexedPrompt.ApplyMetadata(prompt)
+
+		// 更新已有的条目，新提示词追加到末尾
+		updated := false
+		for i := range index.Prompts {
+			if index.Prompts[i].GistURL == prompt.GistURL {
+				index.Prompts[i] = indexedPrompt
+				updated = true
+				break
+			}
+		}
+		if !updated {
+			index.Prompts = append(index.Prompts, indexedPrompt)
+		}
+
+		if prompt.ID != "" {
+			c.cache.SaveContent(prompt.ID, prompt.Content)
+		}
+	}
+
+	// Save updated index (ignore cache errors to not fail the operation)
+	index.LastUpdated = time.Now()
+	c.cache.SaveIndex(index)
 }
 
 // Delete removes a prompt using the remote store and updates the cache
-func (c *CachedStore) Delete(keyword string) error {
+// When the remote is unreachable the prompt is removed from the cache and the deletion is queued
+func (c *CachedStore) Delete(ctx context.Context, keyword string) error {
+	c.replayPending(ctx)
+
 	// Delete from remote store
-	if err := c.remote.Delete(keyword); err != nil {
-		return err
+	if err := c.remote.Delete(ctx, keyword); err != nil {
+		if !c.canQueue(ctx, err) {
+			return err
+		}
+		return c.deleteOffline(keyword)
 	}
 
+	c.deleteFromCache(keyword)
+	return nil
+}
+
+// matchesDeleteKeyword reports whether a cached entry is removed by a deletion keyword
+func matchesDeleteKeyword(indexedPrompt model.IndexedPrompt, keyword string) bool {
+	gistID := utils.ExtractGistIDFromURL(indexedPrompt.GistURL)
+	return gistID == keyword || indexedPrompt.FilePath == keyword || indexedPrompt.Name == keyword
+}
+
+// deleteFromCache removes the prompts matching keyword from the cache index
+func (c *CachedStore) deleteFromCache(keyword string) {
 	// Update cache by removing the deleted prompt
 	index, err := c.cache.LoadIndex()
 	if err != nil {
 		// Cache doesn't exist or is corrupted, skip cache update
-		return nil
+		return
 	}
 
 	// Remove matching prompts from cache index
 	var updatedPrompts []model.IndexedPrompt
 	for _, indexedPrompt := range index.Prompts {
-		gistID := utils.ExtractGistIDFromURL(indexedPrompt.GistURL)
 		// Keep prompts that don't match the deletion keyword
-		if gistID != keyword && indexedPrompt.FilePath != keyword && indexedPrompt.Name != keyword {
+		if !matchesDeleteKeyword(indexedPrompt, keyword) {
 			updatedPrompts = append(updatedPrompts, indexedPrompt)
 		}
 	}
@@ -127,32 +276,47 @@ func (c *CachedStore) Delete(keyword string) error {
 	index.Prompts = updatedPrompts
 	index.LastUpdated = time.Now()
 	c.cache.SaveIndex(index)
-
-	return nil
 }
 
 // Update modifies an existing prompt using the remote store and updates the cache
-func (c *CachedStore) Update(prompt model.Prompt) error {
+// When the remote is unreachable the change is applied to the cache and queued in the offline journal
+func (c *CachedStore) Update(ctx context.Context, prompt model.Prompt) error {
+	c.replayPending(ctx)
+
 	// Update in remote store
-	if err := c.remote.Update(prompt); err != nil {
-		return err
+	if err := c.remote.Update(ctx, prompt); err != nil {
+		if !c.canQueue(ctx, err) {
+			return err
+		}
+		return c.updateOffline(prompt)
 	}
 
+	c.updateCache(prompt)
+	return nil
+}
+
+// updateCache applies a prompt change to the cache index and content cache
+func (c *CachedStore) updateCache(prompt model.Prompt) {
 	// Update cache
 	index, err := c.cache.LoadIndex()
 	if err != nil {
 		// Cache doesn't exist, skip cache update
-		return nil
+		return
 	}
 
 	// Update the prompt in cache index
 	for i, indexedPrompt := range index.Prompts {
 		gistID := utils.ExtractGistIDFromURL(indexedPrompt.GistURL)
 		if gistID == prompt.ID {
+			// 未指定格式时沿用原文件的格式
+			if prompt.Format == "" {
+				prompt.Format = model.FormatFromPath(indexedPrompt.FilePath)
+			}
 			index.Prompts[i].Author = prompt.Author
 			index.Prompts[i].Name = prompt.Name
-			index.Prompts[i].FilePath = fmt.Sprintf("%s.yaml", prompt.Name)
+			index.Prompts[i].FilePath = prompt.FileName()
 			index.Prompts[i].LastUpdated = time.Now()
+			index.Prompts[i].ApplyMetadata(prompt)
 			break
 		}
 	}
@@ -161,14 +325,12 @@ func (c *CachedStore) Update(prompt model.Prompt) error {
 	index.LastUpdated = time.Now()
 	c.cache.SaveIndex(index)
 	c.cache.SaveContent(prompt.ID, prompt.Content)
-
-	return nil
 }
 
 // Get searches for prompts using the remote-first strategy
-func (c *CachedStore) Get(keyword string) ([]model.Prompt, error) {
+func (c *CachedStore) Get(ctx context.Context, keyword string) ([]model.Prompt, error) {
 	// Try remote first
-	prompts, err := c.remote.Get(keyword)
+	prompts, err := c.remote.Get(ctx, keyword)
 	if err == nil {
 		// Remote success: update cache and return results
 		if cacheErr := c.updateCacheFromPrompts(prompts); cacheErr != nil {
@@ -181,6 +343,9 @@ func (c *CachedStore) Get(keyword string) ([]model.Prompt, error) {
 	if c.forceRemote {
 		return nil, fmt.Errorf("remote operation failed and forceRemote is enabled: %w", err)
 	}
+	if isInterrupted(ctx, err) {
+		return nil, err
+	}
 
 	// Fallback to cache search
 	return c.searchCache(keyword, err)
@@ -188,9 +353,9 @@ func (c *CachedStore) Get(keyword string) ([]model.Prompt, error) {
 
 // GetContent retrieves prompt content using the remote-first strategy
 // This is the core method for content retrieval with caching
-func (c *CachedStore) GetContent(gistID string) (string, error) {
+func (c *CachedStore) GetContent(ctx context.Context, gistID string) (string, error) {
 	// Try remote first (remote-first strategy)
-	content, err := c.remote.GetContent(gistID)
+	content, err := c.remote.GetContent(ctx, gistID)
 	if err == nil {
 		// Remote success: cache the content and return it
 		if cacheErr := c.cache.SaveContent(gistID, content); cacheErr != nil {
@@ -204,6 +369,9 @@ func (c *CachedStore) GetContent(gistID string) (string, error) {
 	if c.forceRemote {
 		return "", fmt.Errorf("remote operation failed and forceRemote is enabled: %w", err)
 	}
+	if isInterrupted(ctx, err) {
+		return "", err
+	}
 
 	// Try to load from cache
 	cachedContent, cacheErr := c.cache.LoadContent(gistID)
@@ -216,6 +384,88 @@ func (c *CachedStore) GetContent(gistID string) (string, error) {
 	return cachedContent, nil
 }
 
+// FetchContents fetches many prompt contents from the remote and caches them
+// Remotes that can't fetch in bulk are asked one prompt at a time. As with GetContent,
+// a prompt whose remote fetch fails falls back to its cached content.
+func (c *CachedStore) FetchContents(ctx context.Context, gistIDs []string, progress FetchProgress) (map[string]string, error) {
+	contents := make(map[string]string, len(gistIDs))
+	cacheResult := func(done, total int, result FetchResult) {
+		if result.Err == nil {
+			c.cache.SaveContent(result.GistID, result.Content)
+		} else if !c.forceRemote && !isInterrupted(ctx, result.Err) {
+			if cached, err := c.cache.LoadContent(result.GistID); err == nil {
+				result.Content, result.Err = cached, nil
+			}
+		}
+
+		if result.Err == nil {
+			contents[result.GistID] = result.Content
+		}
+		if progress != nil {
+			progress(done, total, result)
+		}
+	}
+
+	var err error
+	if fetcher, ok := c.remote.(ContentFetcher); ok {
+		_, err = fetcher.FetchContents(ctx, gistIDs, cacheResult)
+	} else {
+		_, err = fetchConcurrently(ctx, gistIDs, 1, func(ctx context.Context, gistID string) (string, error) {
+			return c.remote.GetContent(ctx, gistID)
+		}, cacheResult)
+	}
+
+	return contents, err
+}
+
+// ListRevisions returns the revision history of a prompt using the remote-first strategy
+// The history is cached so it can still be browsed when the remote is unreachable
+func (c *CachedStore) ListRevisions(ctx context.Context, gistID string) ([]model.Revision, error) {
+	provider, ok := c.remote.(RevisionProvider)
+	if !ok {
+		return nil, ErrRevisionsUnsupported
+	}
+
+	revisions, err := provider.ListRevisions(ctx, gistID)
+	if err == nil {
+		c.cache.SaveRevisions(gistID, revisions)
+		return revisions, nil
+	}
+
+	if c.forceRemote {
+		return nil, fmt.Errorf("remote operation failed and forceRemote is enabled: %w", err)
+	}
+	if isInterrupted(ctx, err) {
+		return nil, err
+	}
+
+	cached, cacheErr := c.cache.LoadRevisions(gistID)
+	if cacheErr != nil {
+		return nil, fmt.Errorf("remote failed and no cache available: remote error: %w, cache error: %v", err, cacheErr)
+	}
+	return cached, nil
+}
+
+// GetRevisionContent returns the prompt content as of a revision
+// Revisions never change, so a cached revision is served without asking the remote
+func (c *CachedStore) GetRevisionContent(ctx context.Context, gistID, sha string) (string, error) {
+	if content, err := c.cache.LoadRevisionContent(gistID, sha); err == nil {
+		return content, nil
+	}
+
+	provider, ok := c.remote.(RevisionProvider)
+	if !ok {
+		return "", ErrRevisionsUnsupported
+	}
+
+	content, err := provider.GetRevisionContent(ctx, gistID, sha)
+	if err != nil {
+		return "", err
+	}
+	c.cache.SaveRevisionContent(gistID, sha, content)
+	return content, nil
+}
+
 // Helper methods
 
 // listFromCache attempts to load prompts from local cache when remote fails
@@ -230,13 +480,7 @@ func (c *CachedStore) listFromCache(remoteErr error) ([]model.Prompt, error) {
 	var prompts []model.Prompt
 	for _, indexedPrompt := range index.Prompts {
 		gistID := utils.ExtractGistIDFromURL(indexedPrompt.GistURL)
-		prompt := model.Prompt{
-			ID:      gistID,
-			Name:    indexedPrompt.Name,
-			Author:  indexedPrompt.Author,
-			GistURL: indexedPrompt.GistURL,
-		}
-		prompts = append(prompts, prompt)
+		prompts = append(prompts, promptFromIndex(gistID, indexedPrompt))
 	}
 
 	return prompts, nil
@@ -250,13 +494,10 @@ func (c *CachedStore) searchCache(keyword string, remoteErr error) ([]model.Prom
 		return nil, err
 	}
 
-	// Filter by keyword (similar to GitHubStore.Get logic)
+	// Filter by keyword (same logic as GitHubStore.Get)
 	var matchingPrompts []model.Prompt
-	keywordLower := keyword
 	for _, prompt := range allPrompts {
-		if contains(prompt.Name, keywordLower) ||
-			contains(prompt.Author, keywordLower) ||
-			contains(prompt.ID, keywordLower) {
+		if matchesKeyword(prompt, keyword) {
 			matchingPrompts = append(matchingPrompts, prompt)
 		}
 	}
@@ -269,13 +510,18 @@ func (c *CachedStore) updateCacheFromPrompts(prompts []model.Prompt) error {
 	// Load existing cache to preserve timestamps where possible
 	existingIndex, err := c.cache.LoadIndex()
 	existingTimestamps := make(map[string]time.Time)
+	existingHashes := make(map[string]string)
 	if err == nil {
 		// Build a map of existing timestamps by GistURL
 		for _, existing := range existingIndex.Prompts {
 			existingTimestamps[existing.GistURL] = existing.LastUpdated
+			existingHashes[existing.GistURL] = existing.ContentHash
 		}
 	}
 
+	// Keep the entries of prompts added offline that are still queued in the journal
+	prompts = append(append([]model.Prompt{}, prompts...), c.pendingPrompts()...)
+
 	// Build new index from prompts, preserving existing timestamps where possible
 	var indexedPrompts []model.IndexedPrompt
 	for _, prompt := range prompts {
@@ -291,10 +537,15 @@ func (c *CachedStore) updateCacheFromPrompts(prompts []model.Prompt) error {
 
 		indexedPrompt := model.IndexedPrompt{
 			GistURL:     prompt.GistURL,
-			FilePath:    fmt.Sprintf("%s.yaml", prompt.Name),
+			FilePath:    prompt.FileName(),
 			Author:      prompt.Author,
 			Name:        prompt.Name,
 			LastUpdated: lastUpdated,
+			Description: prompt.Description,
+			Tags:        prompt.Tags,
+			Version:     prompt.Version,
+			// Listed prompts carry no content, so keep the hash already cached
+			ContentHash: existingHashes[prompt.GistURL],
 		}
 		indexedPrompts = append(indexedPrompts, indexedPrompt)
 	}
@@ -336,32 +587,23 @@ func (c *CachedStore) updateCacheFromPrompts(prompts []model.Prompt) error {
 }
 
 // SyncRawIndex downloads the raw index.json content from GitHub and saves it to cache
-func (c *CachedStore) SyncRawIndex() error {
-	// Cast remote store to GitHubStore to access GetRawIndexContent
-	githubStore, ok := c.remote.(*GitHubStore)
+func (c *CachedStore) SyncRawIndex(ctx context.Context) error {
+	// The remote store must be able to hand out its raw index
+	provider, ok := c.remote.(RawIndexProvider)
 	if !ok {
-		return fmt.Errorf("remote store is not a GitHubStore")
+		return fmt.Errorf("remote store does not provide a raw index")
 	}
 
-	// Get raw index content from GitHub
-	rawIndexContent, err := githubStore.GetRawIndexContent()
+	// Get raw index content from the remote store
+	rawIndexContent, err := provider.GetRawIndexContent(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to get raw index content: %w", err)
 	}
 
 	// Parse the raw content to validate it's valid JSON
-	var index model.Index
-	if err := json.Unmarshal([]byte(rawIndexContent), &index); err != nil {
+	if _, err := DecodeIndex([]byte(rawIndexContent)); err != nil {
 		return fmt.Errorf("invalid index JSON from GitHub: %w", err)
 	}
-	
-	// Debug output: show downloaded index content
-	fmt.Printf("📥 从 GitHub 下载的原始 index 内容:\n%s\n", rawIndexContent)
-	fmt.Printf("📋 解析后的 Index 结构:\n")
-	fmt.Printf("  - Prompts 数量: %d\n", len(index.Prompts))
-	fmt.Printf("  - Exports 数量: %d\n", len(index.Exports))
-	fmt.Printf("  - 最后更新: %s\n", index.LastUpdated.Format("2006-01-02 15:04:05"))
-	fmt.Println()
 
 	// Ensure cache directory exists before writing files
 	if err := c.cache.EnsureCacheDir(); err != nil {
@@ -373,43 +615,88 @@ func (c *CachedStore) SyncRawIndex() error {
 	if err := config.WriteFileWithPermissions(indexPath, []byte(rawIndexContent)); err != nil {
 		return fmt.Errorf("failed to save raw index to cache: %w", err)
 	}
-	
-	// Debug output: show cached index.json content
-	cachedContent, err := os.ReadFile(indexPath)
-	if err != nil {
-		fmt.Printf("⚠️  无法读取缓存的 index.json: %v\n", err)
-	} else {
-		fmt.Printf("💾 写入缓存的 index.json 内容:\n%s\n", string(cachedContent))
-	}
 
 	// Note: We don't call c.cache.SaveIndex() here to avoid overwriting the raw index.json
 	// The raw content has already been saved directly to maintain exact timestamp formatting.
+	// Only prompts added offline and still queued in the journal are added back to it.
+	for _, prompt := range c.pendingPrompts() {
+		c.addToCache(prompt)
+	}
 
 	return nil
 }
 
+// GetRawIndexContent returns the index.json of the remote store
+// When the remote is unreachable the index mirrored by SyncRawIndex is returned instead
+func (c *CachedStore) GetRawIndexContent(ctx context.Context) (string, error) {
+	provider, ok := c.remote.(RawIndexProvider)
+	if !ok {
+		return "", ErrRawIndexUnsupported
+	}
+
+	content, err := provider.GetRawIndexContent(ctx)
+	if err == nil {
+		return content, nil
+	}
+
+	if c.forceRemote {
+		return "", fmt.Errorf("remote operation failed and forceRemote is enabled: %w", err)
+	}
+	if isInterrupted(ctx, err) {
+		return "", err
+	}
+
+	cached, cacheErr := os.ReadFile(filepath.Join(c.cache.GetCacheDir(), "index.json"))
+	if cacheErr != nil {
+		return "", fmt.Errorf("remote failed and no cache available: remote error: %w, cache error: %v", err, cacheErr)
+	}
+	return string(cached), nil
+}
+
+// BackfillMetadata fills in the metadata of older index entries on the remote store
+// The cache picks up the filled-in entries on the next sync
+func (c *CachedStore) BackfillMetadata(ctx context.Context) (int, error) {
+	backfiller, ok := c.remote.(MetadataBackfiller)
+	if !ok {
+		return 0, nil
+	}
+	return backfiller.BackfillMetadata(ctx)
+}
+
 // CreatePublicGist creates a new public gist and delegates to remote store
-func (c *CachedStore) CreatePublicGist(prompt model.Prompt) (string, error) {
-	return c.remote.CreatePublicGist(prompt)
+func (c *CachedStore) CreatePublicGist(ctx context.Context, prompt model.Prompt) (string, error) {
+	return c.remote.CreatePublicGist(ctx, prompt)
 }
 
 // UpdateGist updates an existing gist and delegates to remote store
-func (c *CachedStore) UpdateGist(gistURL string, prompt model.Prompt) error {
-	return c.remote.UpdateGist(gistURL, prompt)
+func (c *CachedStore) UpdateGist(ctx context.Context, gistURL string, prompt model.Prompt) error {
+	return c.remote.UpdateGist(ctx, gistURL, prompt)
 }
 
 // GetGistInfo retrieves gist information and delegates to remote store
-func (c *CachedStore) GetGistInfo(gistURL string) (*GistInfo, error) {
-	return c.remote.GetGistInfo(gistURL)
+func (c *CachedStore) GetGistInfo(ctx context.Context, gistURL string) (*GistInfo, error) {
+	return c.remote.GetGistInfo(ctx, gistURL)
 }
 
 // AddExport adds a prompt to the export index and updates the cache
-func (c *CachedStore) AddExport(prompt model.IndexedPrompt) error {
+// When the remote is unreachable the export is recorded in the cache and queued in the offline journal
+func (c *CachedStore) AddExport(ctx context.Context, prompt model.IndexedPrompt) error {
+	c.replayPending(ctx)
+
 	// Add to remote store
-	if err := c.remote.AddExport(prompt); err != nil {
-		return err
+	if err := c.remote.AddExport(ctx, prompt); err != nil {
+		if !c.canQueue(ctx, err) {
+			return err
+		}
+		return c.addExportOffline(prompt)
 	}
 
+	c.addExportToCache(prompt)
+	return nil
+}
+
+// addExportToCache records a new export in the cache index
+func (c *CachedStore) addExportToCache(prompt model.IndexedPrompt) {
 	// Update cache with the new export
 	// Load current index, add the new export, and save
 	index, err := c.cache.LoadIndex()
@@ -433,14 +720,14 @@ func (c *CachedStore) AddExport(prompt model.IndexedPrompt) error {
 
 	// Save updated index (ignore cache errors to not fail the operation)
 	c.cache.SaveIndex(index)
-
-	return nil
 }
 
 // UpdateExport updates a prompt in the export index and updates the cache
-func (c *CachedStore) UpdateExport(prompt model.IndexedPrompt) error {
+func (c *CachedStore) UpdateExport(ctx context.Context, prompt model.IndexedPrompt) error {
+	c.replayPending(ctx)
+
 	// Update in remote store
-	if err := c.remote.UpdateExport(prompt); err != nil {
+	if err := c.remote.UpdateExport(ctx, prompt); err != nil {
 		return err
 	}
 
@@ -475,16 +762,11 @@ func (c *CachedStore) UpdateExport(prompt model.IndexedPrompt) error {
 }
 
 // GetExports retrieves all exported prompts and delegates to remote store
-func (c *CachedStore) GetExports() ([]model.IndexedPrompt, error) {
-	return c.remote.GetExports()
+func (c *CachedStore) GetExports(ctx context.Context) ([]model.IndexedPrompt, error) {
+	return c.remote.GetExports(ctx)
 }
 
 // FindExistingPromptByURL 根据 gist URL 查找已存在的提示词
-func (c *CachedStore) FindExistingPromptByURL(gistURL string) (*model.Prompt, error) {
-	return c.remote.FindExistingPromptByURL(gistURL)
-}
-
-// contains performs case-insensitive substring matching
-func contains(s, substr string) bool {
-	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+func (c *CachedStore) FindExistingPromptByURL(ctx context.Context, gistURL string) (*model.Prompt, error) {
+	return c.remote.FindExistingPromptByURL(ctx, gistURL)
 }
@@ -1,8 +1,11 @@
 package infra
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -28,91 +31,91 @@ type MockStore struct {
 	findExistingPromptByURLFunc func(string) (*model.Prompt, error)
 }
 
-func (m *MockStore) List() ([]model.Prompt, error) {
+func (m *MockStore) List(ctx context.Context) ([]model.Prompt, error) {
 	if m.listFunc != nil {
 		return m.listFunc()
 	}
 	return nil, errors.New("not implemented")
 }
 
-func (m *MockStore) Add(prompt model.Prompt) error {
+func (m *MockStore) Add(ctx context.Context, prompt model.Prompt) error {
 	if m.addFunc != nil {
 		return m.addFunc(prompt)
 	}
 	return errors.New("not implemented")
 }
 
-func (m *MockStore) Delete(keyword string) error {
+func (m *MockStore) Delete(ctx context.Context, keyword string) error {
 	if m.deleteFunc != nil {
 		return m.deleteFunc(keyword)
 	}
 	return errors.New("not implemented")
 }
 
-func (m *MockStore) Update(prompt model.Prompt) error {
+func (m *MockStore) Update(ctx context.Context, prompt model.Prompt) error {
 	if m.updateFunc != nil {
 		return m.updateFunc(prompt)
 	}
 	return errors.New("not implemented")
 }
 
-func (m *MockStore) Get(keyword string) ([]model.Prompt, error) {
+func (m *MockStore) Get(ctx context.Context, keyword string) ([]model.Prompt, error) {
 	if m.getFunc != nil {
 		return m.getFunc(keyword)
 	}
 	return nil, errors.New("not implemented")
 }
 
-func (m *MockStore) GetContent(gistID string) (string, error) {
+func (m *MockStore) GetContent(ctx context.Context, gistID string) (string, error) {
 	if m.getContentFunc != nil {
 		return m.getContentFunc(gistID)
 	}
 	return "", errors.New("not implemented")
 }
 
-func (m *MockStore) CreatePublicGist(prompt model.Prompt) (string, error) {
+func (m *MockStore) CreatePublicGist(ctx context.Context, prompt model.Prompt) (string, error) {
 	if m.createPublicGistFunc != nil {
 		return m.createPublicGistFunc(prompt)
 	}
 	return "", errors.New("not implemented")
 }
 
-func (m *MockStore) UpdateGist(gistURL string, prompt model.Prompt) error {
+func (m *MockStore) UpdateGist(ctx context.Context, gistURL string, prompt model.Prompt) error {
 	if m.updateGistFunc != nil {
 		return m.updateGistFunc(gistURL, prompt)
 	}
 	return errors.New("not implemented")
 }
 
-func (m *MockStore) GetGistInfo(gistURL string) (*GistInfo, error) {
+func (m *MockStore) GetGistInfo(ctx context.Context, gistURL string) (*GistInfo, error) {
 	if m.getGistInfoFunc != nil {
 		return m.getGistInfoFunc(gistURL)
 	}
 	return nil, errors.New("not implemented")
 }
 
-func (m *MockStore) AddExport(prompt model.IndexedPrompt) error {
+func (m *MockStore) AddExport(ctx context.Context, prompt model.IndexedPrompt) error {
 	if m.addExportFunc != nil {
 		return m.addExportFunc(prompt)
 	}
 	return errors.New("not implemented")
 }
 
-func (m *MockStore) UpdateExport(prompt model.IndexedPrompt) error {
+func (m *MockStore) UpdateExport(ctx context.Context, prompt model.IndexedPrompt) error {
 	if m.updateExportFunc != nil {
 		return m.updateExportFunc(prompt)
 	}
 	return errors.New("not implemented")
 }
 
-func (m *MockStore) GetExports() ([]model.IndexedPrompt, error) {
+func (m *MockStore) GetExports(ctx context.Context) ([]model.IndexedPrompt, error) {
 	if m.getExportsFunc != nil {
 		return m.getExportsFunc()
 	}
 	return nil, errors.New("not implemented")
 }
 
-func (m *MockStore) FindExistingPromptByURL(gistURL string) (*model.Prompt, error) {
+func (m *MockStore) FindExistingPromptByURL(ctx context.Context, gistURL string) (*model.Prompt, error) {
 	if m.findExistingPromptByURLFunc != nil {
 		return m.findExistingPromptByURLFunc(gistURL)
 	}
@@ -256,7 +259,7 @@ func TestCachedStore_List_RemoteFirst(t *testing.T) {
 			}
 
 			// Test List operation
-			prompts, err := store.List()
+			prompts, err := store.List(context.Background())
 
 			// Verify error expectation
 			if tt.expectError {
@@ -370,7 +373,7 @@ func TestCachedStore_GetContent_RemoteFirst(t *testing.T) {
 			store := NewCachedStore(mockRemote, cacheManager, mockConfig, tt.forceRemote)
 
 			// Test GetContent operation
-			content, err := store.GetContent(tt.gistID)
+			content, err := store.GetContent(context.Background(), tt.gistID)
 
 			// Verify error expectation
 			if tt.expectError {
@@ -457,7 +460,7 @@ func TestCachedStore_NetworkFailureScenarios(t *testing.T) {
 			store := NewCachedStore(mockRemote, cacheManager, mockConfig, false)
 
 			// Test List operation
-			prompts, err := store.List()
+			prompts, err := store.List(context.Background())
 
 			if tt.expectError {
 				if err == nil {
@@ -552,7 +555,7 @@ func TestCachedStore_CacheHitMissScenarios(t *testing.T) {
 			store := NewCachedStore(mockRemote, cacheManager, mockConfig, false)
 
 			// Test GetContent operation
-			content, err := store.GetContent(testGistID)
+			content, err := store.GetContent(context.Background(), testGistID)
 
 			if tt.expectError {
 				if err == nil {
@@ -592,7 +595,7 @@ func TestCachedStore_DataConsistency(t *testing.T) {
 		store := NewCachedStore(mockRemote, badCacheManager, mockConfig, false)
 
 		// Test List operation - should succeed despite cache failure
-		prompts, err := store.List()
+		prompts, err := store.List(context.Background())
 
 		// Should not return error even if cache update fails
 		if err != nil {
@@ -632,7 +635,7 @@ func TestCachedStore_DataConsistency(t *testing.T) {
 		store := NewCachedStore(mockRemote, cacheManager, mockConfig, false)
 
 		// Test GetContent operation
-		content, err := store.GetContent(testGistID)
+		content, err := store.GetContent(context.Background(), testGistID)
 
 		// Verify operation succeeded
 		if err != nil {
@@ -654,4 +657,231 @@ func TestCachedStore_DataConsistency(t *testing.T) {
 			t.Errorf("Expected cached content %q, got %q", testContent, cachedContent)
 		}
 	})
-}
\ No newline at end of file
+}
+func TestCachedStore_ContextFallback(t *testing.T) {
+	cacheManager := &CacheManager{cacheDir: t.TempDir()}
+	if err := cacheManager.SaveIndex(createTestIndex()); err != nil {
+		t.Fatalf("SaveIndex failed: %v", err)
+	}
+	if err := cacheManager.SaveContent("cached-gist", "cached content"); err != nil {
+		t.Fatalf("SaveContent failed: %v", err)
+	}
+
+	t.Run("timeout falls back to cache", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 0)
+		defer cancel()
+
+		mockRemote := &MockStore{
+			listFunc: func() ([]model.Prompt, error) {
+				return nil, fmt.Errorf("list gists: %w", context.DeadlineExceeded)
+			},
+			getContentFunc: func(gistID string) (string, error) {
+				return "", fmt.Errorf("get gist: %w", context.DeadlineExceeded)
+			},
+		}
+		store := NewCachedStore(mockRemote, cacheManager, &MockConfigStore{}, false)
+
+		prompts, err := store.List(ctx)
+		if err != nil || len(prompts) == 0 {
+			t.Errorf("Expected cached prompts after a timeout, got %v, %v", prompts, err)
+		}
+		content, err := store.GetContent(ctx, "cached-gist")
+		if err != nil || content != "cached content" {
+			t.Errorf("Expected cached content after a timeout, got %q, %v", content, err)
+		}
+	})
+
+	t.Run("cancellation is returned", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		mockRemote := &MockStore{
+			listFunc: func() ([]model.Prompt, error) {
+				return nil, fmt.Errorf("list gists: %w", context.Canceled)
+			},
+			getContentFunc: func(gistID string) (string, error) {
+				return "", fmt.Errorf("get gist: %w", context.Canceled)
+			},
+		}
+		store := NewCachedStore(mockRemote, cacheManager, &MockConfigStore{}, false)
+
+		if _, err := store.List(ctx); !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context.Canceled from List, got %v", err)
+		}
+		if _, err := store.GetContent(ctx, "cached-gist"); !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context.Canceled from GetContent, got %v", err)
+		}
+	})
+}
+
+// revisionMockStore is a MockStore that also serves a revision history
+type revisionMockStore struct {
+	*MockStore
+	revisions []model.Revision
+	contents  map[string]string
+	err       error
+	reads     int
+}
+
+func (m *revisionMockStore) ListRevisions(ctx context.Context, gistID string) ([]model.Revision, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return m.revisions, nil
+}
+
+func (m *revisionMockStore) GetRevisionContent(ctx context.Context, gistID, sha string) (string, error) {
+	m.reads++
+	if m.err != nil {
+		return "", m.err
+	}
+	return m.contents[sha], nil
+}
+
+func TestCachedStore_RevisionsOffline(t *testing.T) {
+	cacheManager := &CacheManager{cacheDir: t.TempDir()}
+	remote := &revisionMockStore{
+		MockStore: &MockStore{},
+		revisions: []model.Revision{{SHA: "bbb"}, {SHA: "aaa"}},
+		contents:  map[string]string{"aaa": "v1", "bbb": "v2"},
+	}
+	store := NewCachedStore(remote, cacheManager, &MockConfigStore{}, false).(*CachedStore)
+
+	if revisions, err := store.ListRevisions(context.Background(), "gist"); err != nil || len(revisions) != 2 {
+		t.Fatalf("ListRevisions failed: %v, %v", revisions, err)
+	}
+	if content, err := store.GetRevisionContent(context.Background(), "gist", "aaa"); err != nil || content != "v1" {
+		t.Fatalf("GetRevisionContent failed: %q, %v", content, err)
+	}
+
+	// Revisions never change, so a cached one is not read again
+	if _, err := store.GetRevisionContent(context.Background(), "gist", "aaa"); err != nil || remote.reads != 1 {
+		t.Errorf("Expected the cached revision to be served, got %d remote reads, %v", remote.reads, err)
+	}
+
+	// Offline the history seen before can still be browsed
+	remote.err = fmt.Errorf("dial tcp: connection refused")
+	revisions, err := store.ListRevisions(context.Background(), "gist")
+	if err != nil || len(revisions) != 2 || revisions[0].SHA != "bbb" {
+		t.Errorf("Expected the cached history, got %v, %v", revisions, err)
+	}
+	if content, err := store.GetRevisionContent(context.Background(), "gist", "aaa"); err != nil || content != "v1" {
+		t.Errorf("Expected the cached revision, got %q, %v", content, err)
+	}
+	if _, err := store.GetRevisionContent(context.Background(), "gist", "bbb"); err == nil {
+		t.Error("Expected a revision never read to be unavailable offline")
+	}
+
+	// Stores without a history say so
+	plain := NewCachedStore(&MockStore{}, cacheManager, &MockConfigStore{}, false).(*CachedStore)
+	if _, err := plain.ListRevisions(context.Background(), "gist"); !errors.Is(err, ErrRevisionsUnsupported) {
+		t.Errorf("Expected ErrRevisionsUnsupported, got %v", err)
+	}
+}
+
+// batchMockStore is a remote that adds prompts in batches
+type batchMockStore struct {
+	*MockStore
+	batches int
+}
+
+func (m *batchMockStore) AddBatch(ctx context.Context, prompts []model.Prompt) ([]model.Prompt, error) {
+	m.batches++
+	var stored []model.Prompt
+	for _, prompt := range prompts {
+		prompt.ID = strings.ToLower(prompt.Name)
+		prompt.GistURL = "https://gist.github.com/alice/" + prompt.ID
+		stored = append(stored, prompt)
+	}
+	return stored, nil
+}
+
+func TestCachedStore_AddBatch(t *testing.T) {
+	cacheManager := &CacheManager{cacheDir: t.TempDir()}
+	cacheManager.SaveIndex(&model.Index{Prompts: []model.IndexedPrompt{
+		{GistURL: "https://gist.github.com/alice/review", Name: "Review", Author: "alice", Version: "1.0"},
+	}})
+	remote := &batchMockStore{MockStore: &MockStore{}}
+	store := NewCachedStore(remote, cacheManager, &MockConfigStore{}, false).(*CachedStore)
+
+	prompts := []model.Prompt{
+		{Name: "Review", Author: "alice", Version: "2.0", Content: "v2"},
+		{Name: "Summary", Author: "alice", Content: "summary"},
+	}
+	if _, err := store.AddBatch(context.Background(), prompts); err != nil || remote.batches != 1 {
+		t.Fatalf("Expected a single remote batch, got %d, %v", remote.batches, err)
+	}
+
+	index, err := cacheManager.LoadIndex()
+	if err != nil || len(index.Prompts) != 2 {
+		t.Fatalf("Expected the cache index to hold both prompts, got %+v, %v", index, err)
+	}
+	if index.Prompts[0].Version != "2.0" {
+		t.Errorf("Expected the cached entry of the update to be replaced, got %+v", index.Prompts[0])
+	}
+	if content, err := cacheManager.LoadContent("summary"); err != nil || content != "summary" {
+		t.Errorf("Expected the content to be cached, got %q, %v", content, err)
+	}
+
+	// Remotes without batches get one Add per prompt
+	var added []string
+	plain := NewCachedStore(&MockStore{addFunc: func(prompt model.Prompt) error {
+		added = append(added, prompt.Name)
+		return nil
+	}}, cacheManager, &MockConfigStore{}, false).(*CachedStore)
+	if stored, err := plain.AddBatch(context.Background(), prompts); err != nil || len(stored) != 2 || len(added) != 2 {
+		t.Errorf("Expected the prompts to be added one by one, got %v, %v", added, err)
+	}
+}
+
+func TestCachedStore_AddBatch_FileSystemStore(t *testing.T) {
+	cacheManager := &CacheManager{cacheDir: t.TempDir()}
+	remote := NewFileSystemStore(filepath.Join(t.TempDir(), "vault"))
+	store := NewCachedStore(remote, cacheManager, &MockConfigStore{}, false).(*CachedStore)
+
+	prompts := []model.Prompt{
+		{Name: "Review", Author: "alice", Content: "name: Review\nauthor: alice\n---\nReview {code}\n"},
+		{Name: "Summary", Author: "alice", Content: "name: Summary\nauthor: alice\n---\nSummarize {text}\n"},
+	}
+	stored, err := store.AddBatch(context.Background(), prompts)
+	if err != nil || len(stored) != 2 {
+		t.Fatalf("AddBatch failed: %v, %v", stored, err)
+	}
+
+	listed, err := remote.List(context.Background())
+	if err != nil || len(listed) != 2 {
+		t.Fatalf("Expected both prompts in the vault, got %v, %v", listed, err)
+	}
+	for i, prompt := range stored {
+		if prompt.ID == "" || prompt.ID != listed[i].ID || prompt.GistURL != listed[i].GistURL {
+			t.Errorf("Expected %s to carry its ID and URL %s, got %+v", prompt.Name, listed[i].GistURL, prompt)
+		}
+	}
+
+	index, err := cacheManager.LoadIndex()
+	if err != nil || len(index.Prompts) != 2 || index.Prompts[0].GistURL != listed[0].GistURL {
+		t.Errorf("Expected the cache index to record the URLs, got %+v, %v", index, err)
+	}
+}
+
+func TestCachedStore_GetRawIndexContent(t *testing.T) {
+	cacheManager := &CacheManager{cacheDir: t.TempDir()}
+	remote := NewFileSystemStore(filepath.Join(t.TempDir(), "vault"))
+	store := NewCachedStore(remote, cacheManager, &MockConfigStore{}, false).(*CachedStore)
+	if err := store.Add(context.Background(), model.Prompt{Name: "Review", Author: "alice", Content: "name: Review\nauthor: alice\n---\nReview {code}\n"}); err != nil {
+		t.Fatal(err)
+	}
+
+	want, err := remote.(RawIndexProvider).GetRawIndexContent(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := store.GetRawIndexContent(context.Background()); err != nil || got != want {
+		t.Errorf("Expected the index of the remote, got %q, %v", got, err)
+	}
+
+	plain := NewCachedStore(&MockStore{}, cacheManager, &MockConfigStore{}, false).(*CachedStore)
+	if _, err := plain.GetRawIndexContent(context.Background()); !errors.Is(err, ErrRawIndexUnsupported) {
+		t.Errorf("Expected ErrRawIndexUnsupported, got %v", err)
+	}
+}
@@ -0,0 +1,103 @@
+package infra
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// CachedResponse is a remote response kept to revalidate it with a conditional request
+type CachedResponse struct {
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+	Body         string `json:"body"`
+}
+
+// ResponseCache persists responses for conditional requests
+type ResponseCache interface {
+	// LoadResponse returns the response cached under key, or nil when there is none
+	LoadResponse(key string) (*CachedResponse, error)
+	SaveResponse(key string, response *CachedResponse) error
+	DeleteResponse(key string) error
+}
+
+// ResponseCacher is implemented by stores that can revalidate remote reads against
+// cached responses instead of downloading them again
+type ResponseCacher interface {
+	SetResponseCache(cache ResponseCache)
+}
+
+// gistPathPattern matches the API path of a single gist, e.g. /gists/{id} or /api/v3/gists/{id}
+var gistPathPattern = regexp.MustCompile(`/gists/([0-9A-Za-z]+)$`)
+
+// conditionalTransport sends reads of single gists as conditional requests
+// The ETag and Last-Modified of each gist response are kept in the cache along with
+// its body. A 304 Not Modified, which doesn't count against the rate limit, is answered
+// with the cached body so callers see a normal 200 response.
+type conditionalTransport struct {
+	base  http.RoundTripper
+	cache ResponseCache
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *conditionalTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	match := gistPathPattern.FindStringSubmatch(req.URL.Path)
+	if match == nil {
+		return t.base.RoundTrip(req)
+	}
+	gistID := match[1]
+
+	if req.Method != http.MethodGet {
+		// The gist is about to change; its cached response must not be served again
+		t.cache.DeleteResponse(gistID)
+		return t.base.RoundTrip(req)
+	}
+
+	cached, _ := t.cache.LoadResponse(gistID)
+	if cached != nil && (cached.ETag != "" || cached.LastModified != "") {
+		req = req.Clone(req.Context())
+		if cached.ETag != "" {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && cached != nil:
+		// Keep the headers of the 304: they report the current rate limit
+		resp.Body.Close()
+		resp.StatusCode = http.StatusOK
+		resp.Status = "200 OK"
+		resp.Body = io.NopCloser(strings.NewReader(cached.Body))
+		resp.ContentLength = int64(len(cached.Body))
+		resp.Header.Set("Content-Length", strconv.Itoa(len(cached.Body)))
+
+	case resp.StatusCode == http.StatusOK:
+		etag, lastModified := resp.Header.Get("ETag"), resp.Header.Get("Last-Modified")
+		if etag == "" && lastModified == "" {
+			break
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+
+		// A failed cache write only costs a full download next time
+		t.cache.SaveResponse(gistID, &CachedResponse{ETag: etag, LastModified: lastModified, Body: string(body)})
+	}
+
+	return resp, nil
+}
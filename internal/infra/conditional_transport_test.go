@@ -0,0 +1,52 @@
+package infra
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grigri/pv/internal/model"
+)
+
+func TestGitHubStore_ConditionalRequests(t *testing.T) {
+	fake := newFakeGistAPI(t)
+	remote := fake.newStore(t)
+	cache := &CacheManager{cacheDir: t.TempDir()}
+
+	// CachedStore hands its cache to the remote for conditional requests
+	NewCachedStore(remote, cache, &MockConfigStore{}, false)
+	if remote.responses == nil {
+		t.Fatal("Expected NewCachedStore to set the response cache of the remote")
+	}
+
+	if err := remote.Add(context.Background(), model.Prompt{Name: "Cached", Author: "alice", Content: "v1"}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	prompts, err := remote.List(context.Background())
+	if err != nil || len(prompts) != 1 {
+		t.Fatalf("List failed: %v, %v", prompts, err)
+	}
+	gistID := prompts[0].ID
+
+	// Unchanged gists are revalidated and served from the cached response
+	before := fake.notModified
+	content, err := remote.GetContent(context.Background(), gistID)
+	if err != nil || content != "v1" {
+		t.Fatalf("Expected the cached content, got %q, %v", content, err)
+	}
+	if fake.notModified != before+1 {
+		t.Errorf("Expected the read to be answered with 304, got %d more", fake.notModified-before)
+	}
+	if cached, _ := cache.LoadResponse(gistID); cached == nil || cached.ETag == "" {
+		t.Errorf("Expected the gist response to be cached with its ETag, got %+v", cached)
+	}
+
+	// Changing the gist drops its cached response, so the new content is read
+	prompts[0].Content = "v2"
+	if err := remote.Update(context.Background(), prompts[0]); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	content, err = remote.GetContent(context.Background(), gistID)
+	if err != nil || content != "v2" {
+		t.Errorf("Expected the updated content, got %q, %v", content, err)
+	}
+}
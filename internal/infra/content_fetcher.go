@@ -0,0 +1,206 @@
+package infra
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v74/github"
+)
+
+const (
+	// DefaultFetchWorkers bounds the number of prompt contents fetched at the same time
+	DefaultFetchWorkers = 4
+
+	// lowRateLimitRemaining is the remaining API quota below which requests are spread
+	// out until the rate limit resets
+	lowRateLimitRemaining = 50
+
+	// defaultSecondaryRateLimitWait is used when a secondary rate limit gives no Retry-After
+	defaultSecondaryRateLimitWait = time.Minute
+)
+
+// FetchResult is the outcome of fetching the content of one prompt gist
+type FetchResult struct {
+	GistID  string
+	Content string
+	Err     error
+}
+
+// FetchProgress is called each time a content fetch finishes, successfully or not
+// done counts the finished fetches out of total. Calls never overlap.
+type FetchProgress func(done, total int, result FetchResult)
+
+// ContentFetcher is implemented by stores that can fetch many prompt contents at once
+type ContentFetcher interface {
+	// FetchContents fetches the content of the given gists, returning those that succeeded.
+	// Per-gist failures are reported through progress; the error is only set when the
+	// whole operation stops, e.g. because ctx was cancelled.
+	FetchContents(ctx context.Context, gistIDs []string, progress FetchProgress) (map[string]string, error)
+}
+
+// fetchConcurrently runs fetch for every ID on a bounded pool of workers
+// It stops handing out IDs once ctx is cancelled and returns ctx's error.
+func fetchConcurrently(ctx context.Context, ids []string, workers int, fetch func(ctx context.Context, id string) (string, error), progress FetchProgress) (map[string]string, error) {
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(ids) {
+		workers = len(ids)
+	}
+
+	var (
+		mu       sync.Mutex
+		done     int
+		contents = make(map[string]string, len(ids))
+		wg       sync.WaitGroup
+	)
+
+	jobs := make(chan string)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for id := range jobs {
+				content, err := fetch(ctx, id)
+
+				mu.Lock()
+				done++
+				if err == nil {
+					contents[id] = content
+				}
+				if progress != nil {
+					progress(done, len(ids), FetchResult{GistID: id, Content: content, Err: err})
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+feed:
+	for _, id := range ids {
+		select {
+		case jobs <- id:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return contents, err
+	}
+	return contents, nil
+}
+
+// FetchRevisionContents fetches the content of revisions of a prompt on a bounded pool of
+// workers, like the prompt contents of FetchContents, returning those that succeeded by SHA.
+// The GistID of the results passed to progress holds the revision SHA.
+func FetchRevisionContents(ctx context.Context, provider RevisionProvider, gistID string, shas []string, progress FetchProgress) (map[string]string, error) {
+	return fetchConcurrently(ctx, shas, DefaultFetchWorkers, func(ctx context.Context, sha string) (string, error) {
+		return provider.GetRevisionContent(ctx, gistID, sha)
+	}, progress)
+}
+
+// rateLimiter paces GitHub API requests using the quota reported by previous responses
+type rateLimiter struct {
+	mu        sync.Mutex
+	remaining int // -1 while no response reported a quota
+	reset     time.Time
+
+	now func() time.Time
+}
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{remaining: -1, now: time.Now}
+}
+
+// observe records the quota reported by a response
+func (r *rateLimiter) observe(resp *github.Response) {
+	if resp == nil || resp.Rate.Limit == 0 {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.remaining = resp.Rate.Remaining
+	r.reset = resp.Rate.Reset.Time
+}
+
+// throttled records a rate limit rejection so later requests wait for the reset,
+// reporting whether err was one
+func (r *rateLimiter) throttled(err error) bool {
+	var rateErr *github.RateLimitError
+	var abuseErr *github.AbuseRateLimitError
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	switch {
+	case stderrors.As(err, &rateErr):
+		r.remaining = 0
+		r.reset = rateErr.Rate.Reset.Time
+	case stderrors.As(err, &abuseErr):
+		wait := defaultSecondaryRateLimitWait
+		if retryAfter := abuseErr.GetRetryAfter(); retryAfter > 0 {
+			wait = retryAfter
+		}
+		r.remaining = 0
+		r.reset = r.now().Add(wait)
+	default:
+		return false
+	}
+	return true
+}
+
+// delay returns how long the next request should wait
+// Once the quota runs low the remaining requests are spread evenly until the reset;
+// once it is exhausted requests wait for the reset.
+func (r *rateLimiter) delay() time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.remaining < 0 || r.remaining >= lowRateLimitRemaining {
+		return 0
+	}
+
+	untilReset := r.reset.Sub(r.now())
+	if untilReset <= 0 {
+		// The quota has been reset; the next response reports the new one
+		r.remaining = -1
+		return 0
+	}
+	if r.remaining == 0 {
+		return untilReset
+	}
+
+	delay := untilReset / time.Duration(r.remaining+1)
+	// Account for this request before its response arrives, so concurrent workers spread out too
+	r.remaining--
+	return delay
+}
+
+// wait blocks until the next request may be sent or ctx is cancelled
+func (r *rateLimiter) wait(ctx context.Context) error {
+	delay := r.delay()
+	if delay <= 0 {
+		return ctx.Err()
+	}
+
+	if delay >= time.Second {
+		fmt.Fprintf(os.Stderr, "⏳ GitHub API 配额即将用尽，等待 %s 后继续\n", delay.Round(time.Second))
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
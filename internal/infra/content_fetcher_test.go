@@ -0,0 +1,219 @@
+package infra
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v74/github"
+	"github.com/grigri/pv/internal/model"
+	"github.com/grigri/pv/internal/utils"
+)
+
+func TestFetchConcurrently_BoundsWorkers(t *testing.T) {
+	ids := []string{"a", "b", "c", "d", "e", "f", "g", "h"}
+
+	var mu sync.Mutex
+	running, maxRunning := 0, 0
+	fetch := func(ctx context.Context, id string) (string, error) {
+		mu.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		mu.Unlock()
+
+		time.Sleep(5 * time.Millisecond)
+
+		mu.Lock()
+		running--
+		mu.Unlock()
+		if id == "c" {
+			return "", fmt.Errorf("boom")
+		}
+		return "content " + id, nil
+	}
+
+	var progressed []int
+	failed := ""
+	contents, err := fetchConcurrently(context.Background(), ids, 3, fetch, func(done, total int, result FetchResult) {
+		progressed = append(progressed, done)
+		if total != len(ids) {
+			t.Errorf("Expected total %d, got %d", len(ids), total)
+		}
+		if result.Err != nil {
+			failed = result.GistID
+		}
+	})
+	if err != nil {
+		t.Fatalf("fetchConcurrently failed: %v", err)
+	}
+
+	if maxRunning > 3 {
+		t.Errorf("Expected at most 3 concurrent fetches, got %d", maxRunning)
+	}
+	if len(contents) != len(ids)-1 || contents["a"] != "content a" {
+		t.Errorf("Expected every content but c, got %v", contents)
+	}
+	if failed != "c" {
+		t.Errorf("Expected the failure of c to be reported, got %q", failed)
+	}
+	for i, done := range progressed {
+		if done != i+1 {
+			t.Fatalf("Expected progress to count up, got %v", progressed)
+		}
+	}
+}
+
+func TestFetchConcurrently_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	ids := make([]string, 20)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("id%d", i)
+	}
+
+	fetched := 0
+	_, err := fetchConcurrently(ctx, ids, 1, func(ctx context.Context, id string) (string, error) {
+		fetched++
+		if fetched == 2 {
+			cancel()
+		}
+		return id, nil
+	}, nil)
+
+	if err != context.Canceled {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	if fetched >= len(ids) {
+		t.Errorf("Expected the remaining fetches to be skipped, got %d", fetched)
+	}
+}
+
+func TestRateLimiter_Delay(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	limiter := newRateLimiter()
+	limiter.now = func() time.Time { return now }
+
+	if d := limiter.delay(); d != 0 {
+		t.Errorf("Expected no delay without a known quota, got %v", d)
+	}
+
+	rate := func(remaining int, reset time.Time) *github.Response {
+		return &github.Response{Rate: github.Rate{Limit: 5000, Remaining: remaining, Reset: github.Timestamp{Time: reset}}}
+	}
+
+	limiter.observe(rate(4000, now.Add(time.Hour)))
+	if d := limiter.delay(); d != 0 {
+		t.Errorf("Expected no delay with plenty of quota, got %v", d)
+	}
+
+	// Low quota: the remaining requests are spread until the reset
+	limiter.observe(rate(9, now.Add(10*time.Minute)))
+	if d := limiter.delay(); d != time.Minute {
+		t.Errorf("Expected a one minute delay, got %v", d)
+	}
+
+	// Exhausted quota: wait for the reset
+	limiter.observe(rate(0, now.Add(30*time.Second)))
+	if d := limiter.delay(); d != 30*time.Second {
+		t.Errorf("Expected to wait for the reset, got %v", d)
+	}
+
+	// Once the reset has passed requests go through again
+	now = now.Add(time.Minute)
+	if d := limiter.delay(); d != 0 {
+		t.Errorf("Expected no delay after the reset, got %v", d)
+	}
+}
+
+func TestRateLimiter_Throttled(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	limiter := newRateLimiter()
+	limiter.now = func() time.Time { return now }
+
+	if limiter.throttled(fmt.Errorf("not found")) {
+		t.Error("Expected other errors not to count as throttling")
+	}
+
+	retryAfter := 20 * time.Second
+	err := fmt.Errorf("failed to get gist: %w", &github.AbuseRateLimitError{RetryAfter: &retryAfter})
+	if !limiter.throttled(err) {
+		t.Fatal("Expected a secondary rate limit to count as throttling")
+	}
+	if d := limiter.delay(); d != retryAfter {
+		t.Errorf("Expected to wait for Retry-After, got %v", d)
+	}
+}
+
+func TestGitHubStore_RateLimitRetriedByTransportOnly(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Retry-After", "1")
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"message":"You have exceeded a secondary rate limit","documentation_url":"https://docs.github.com/rest/overview/rate-limits-for-the-rest-api#about-secondary-rate-limits"}`)
+	}))
+	defer server.Close()
+
+	client, err := github.NewClient(&http.Client{Transport: utils.NewRetryTransport(nil)}).WithEnterpriseURLs(server.URL, server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := &GitHubStore{client: client, limiter: newRateLimiter()}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if _, err := store.getGist(ctx, "abc"); err == nil {
+		t.Fatal("Expected the rate limit to be reported")
+	}
+	// Only the transport retries; getGist doesn't repeat its attempts
+	if got := requests.Load(); got != utils.DefaultMaxRetries+1 {
+		t.Errorf("Expected %d requests, got %d", utils.DefaultMaxRetries+1, got)
+	}
+}
+
+func TestGitHubStore_FetchContents(t *testing.T) {
+	fake := newFakeGistAPI(t)
+	store := fake.newStore(t)
+
+	for i := 0; i < 6; i++ {
+		prompt := model.Prompt{Name: fmt.Sprintf("Prompt %d", i), Author: "alice", Content: fmt.Sprintf("content %d", i)}
+		if err := store.Add(context.Background(), prompt); err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+	}
+
+	prompts, err := store.List(context.Background())
+	if err != nil || len(prompts) != 6 {
+		t.Fatalf("List failed: %v, %v", prompts, err)
+	}
+	gistIDs := []string{"missing"}
+	for _, prompt := range prompts {
+		gistIDs = append(gistIDs, prompt.ID)
+	}
+
+	finished := 0
+	contents, err := store.FetchContents(context.Background(), gistIDs, func(done, total int, result FetchResult) {
+		finished = done
+	})
+	if err != nil {
+		t.Fatalf("FetchContents failed: %v", err)
+	}
+
+	if finished != len(gistIDs) {
+		t.Errorf("Expected progress for all %d gists, got %d", len(gistIDs), finished)
+	}
+	if len(contents) != len(prompts) {
+		t.Errorf("Expected %d contents, got %d", len(prompts), len(contents))
+	}
+	for _, prompt := range prompts {
+		if contents[prompt.ID] == "" {
+			t.Errorf("Expected content for %s", prompt.Name)
+		}
+	}
+}
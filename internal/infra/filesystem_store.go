@@ -0,0 +1,646 @@
+package infra
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/grigri/pv/internal/config"
+	"github.com/grigri/pv/internal/errors"
+	"github.com/grigri/pv/internal/model"
+	"github.com/grigri/pv/internal/utils"
+)
+
+// VaultURLPrefix prefixes the URLs of entries stored in a local vault
+const VaultURLPrefix = "vault://"
+
+// FileSystemStore implements the Store interface on top of a local directory
+// Each prompt lives in its own sub-directory (the equivalent of a gist) and
+// the vault root holds an index.json with the same shape as the index gist:
+//
+//	<root>/index.json
+//	<root>/<id>/<name>.yaml|.md
+type FileSystemStore struct {
+	rootDir string
+}
+
+// NewFileSystemStore creates a new FileSystemStore rooted at rootDir
+func NewFileSystemStore(rootDir string) Store {
+	return &FileSystemStore{
+		rootDir: rootDir,
+	}
+}
+
+// GetRootDir returns the vault directory
+func (f *FileSystemStore) GetRootDir() string {
+	return f.rootDir
+}
+
+// ensureInitialized creates the vault directory and an empty index if needed
+func (f *FileSystemStore) ensureInitialized() error {
+	if strings.TrimSpace(f.rootDir) == "" {
+		return errors.NewAppError(errors.ErrStorage, "vault directory is not configured", nil)
+	}
+
+	if err := os.MkdirAll(f.rootDir, 0700); err != nil {
+		return errors.NewAppError(errors.ErrStorage, "failed to create vault directory", err)
+	}
+
+	if _, err := os.Stat(f.indexPath()); os.IsNotExist(err) {
+		emptyIndex := &model.Index{
+			Prompts: []model.IndexedPrompt{},
+			Exports: []model.IndexedPrompt{},
+		}
+		return f.saveIndex(emptyIndex)
+	}
+
+	return nil
+}
+
+// indexPath returns the path of the vault index file
+func (f *FileSystemStore) indexPath() string {
+	return filepath.Join(f.rootDir, IndexFileName)
+}
+
+// entryDir returns the directory holding the files of a single entry
+func (f *FileSystemStore) entryDir(id string) string {
+	return filepath.Join(f.rootDir, id)
+}
+
+// entryURL builds the URL recorded in the index for an entry
+// It is location independent so a vault can be moved or cloned, and its last
+// path segment is the entry ID so utils.ExtractGistIDFromURL keeps working
+func (f *FileSystemStore) entryURL(id string) string {
+	return VaultURLPrefix + id
+}
+
+// loadIndex loads the current index from index.json
+func (f *FileSystemStore) loadIndex() (*model.Index, error) {
+	data, err := os.ReadFile(f.indexPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNoIndex
+		}
+		return nil, fmt.Errorf("failed to read index: %w", err)
+	}
+
+	index, err := DecodeIndex(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal index: %w", err)
+	}
+
+	return index, nil
+}
+
+// saveIndex writes the index to index.json
+func (f *FileSystemStore) saveIndex(index *model.Index) error {
+	index.LastUpdated = time.Now()
+
+	indexContent, err := encodeIndex(index)
+	if err != nil {
+		return fmt.Errorf("failed to marshal index: %w", err)
+	}
+
+	if err := config.WriteFileWithPermissions(f.indexPath(), indexContent); err != nil {
+		return fmt.Errorf("failed to write index: %w", err)
+	}
+
+	return nil
+}
+
+// GetRawIndexContent returns the raw index.json content of the vault
+func (f *FileSystemStore) GetRawIndexContent(ctx context.Context) (string, error) {
+	if err := f.ensureInitialized(); err != nil {
+		return "", err
+	}
+
+	data, err := os.ReadFile(f.indexPath())
+	if err != nil {
+		return "", fmt.Errorf("failed to read index: %w", err)
+	}
+
+	return string(data), nil
+}
+
+// writeEntry creates or replaces the prompt file of an entry
+// Any other prompt file in the entry directory is removed so renames don't leave stale files
+func (f *FileSystemStore) writeEntry(id, fileName, content string) error {
+	dir := f.entryDir(id)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create entry directory: %w", err)
+	}
+
+	if err := config.WriteFileWithPermissions(filepath.Join(dir, fileName), []byte(content)); err != nil {
+		return fmt.Errorf("failed to write prompt file: %w", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read entry directory: %w", err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || entry.Name() == fileName {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, entry.Name())); err != nil {
+			return fmt.Errorf("failed to remove old prompt file: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// entryFileName returns the name prompt is written under in an existing entry
+// A prompt without a format keeps the format of the file already in the entry
+func (f *FileSystemStore) entryFileName(id string, prompt model.Prompt) string {
+	if prompt.Format == "" {
+		if existing, err := f.findPromptFile(id); err == nil {
+			prompt.Format = model.FormatFromPath(existing)
+		}
+	}
+	return entryFile(prompt)
+}
+
+// pathSeparators replaces the path separators in a prompt name used as a file name
+var pathSeparators = strings.NewReplacer("/", "_", "\\", "_")
+
+// entryFile returns the name of the file prompt is stored under in its entry directory
+// Path separators in the prompt name are replaced so the file can't escape the entry
+func entryFile(prompt model.Prompt) string {
+	return pathSeparators.Replace(prompt.FileName())
+}
+
+// findPromptFile returns the name of the prompt file (.yaml or .md) stored in an entry directory
+func (f *FileSystemStore) findPromptFile(id string) (string, error) {
+	entries, err := os.ReadDir(f.entryDir(id))
+	if err != nil {
+		return "", err
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() && model.FormatFromPath(entry.Name()) != "" {
+			return entry.Name(), nil
+		}
+	}
+
+	return "", os.ErrNotExist
+}
+
+// List returns all prompts from the index
+func (f *FileSystemStore) List(ctx context.Context) ([]model.Prompt, error) {
+	if err := f.ensureInitialized(); err != nil {
+		return nil, err
+	}
+
+	index, err := f.loadIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(index.Prompts) == 0 {
+		return nil, ErrEmptyIndex
+	}
+
+	var prompts []model.Prompt
+	for _, indexedPrompt := range index.Prompts {
+		id := utils.ExtractGistIDFromURL(indexedPrompt.GistURL)
+		if id == "" {
+			continue
+		}
+
+		// Skip entries whose file has been removed from disk
+		if _, err := os.Stat(filepath.Join(f.entryDir(id), indexedPrompt.FilePath)); err != nil {
+			continue
+		}
+
+		prompts = append(prompts, promptFromIndex(id, indexedPrompt))
+	}
+
+	if len(prompts) == 0 {
+		return nil, ErrEmptyIndex
+	}
+
+	return prompts, nil
+}
+
+// findExistingPrompt searches for an existing prompt with the same name and author
+func (f *FileSystemStore) findExistingPrompt(ctx context.Context, name, author string) (*model.Prompt, error) {
+	allPrompts, err := f.List(ctx)
+	if err != nil {
+		if err == ErrNoIndex || err == ErrEmptyIndex {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	for _, prompt := range allPrompts {
+		if prompt.Name == name && prompt.Author == author {
+			return &prompt, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// FindExistingPromptByURL looks up a prompt by its entry URL
+func (f *FileSystemStore) FindExistingPromptByURL(ctx context.Context, gistURL string) (*model.Prompt, error) {
+	allPrompts, err := f.List(ctx)
+	if err != nil {
+		if err == ErrNoIndex || err == ErrEmptyIndex {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	for _, prompt := range allPrompts {
+		if prompt.GistURL == gistURL {
+			return &prompt, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// Add writes a new prompt entry and updates the index
+// A prompt with the same name and author is updated in place, like GitHubStore.Add
+func (f *FileSystemStore) Add(ctx context.Context, prompt model.Prompt) error {
+	if err := f.ensureInitialized(); err != nil {
+		return err
+	}
+
+	existingPrompt, err := f.findExistingPrompt(ctx, prompt.Name, prompt.Author)
+	if err != nil {
+		return fmt.Errorf("failed to check for existing prompt: %w", err)
+	}
+
+	if existingPrompt != nil {
+		existingPrompt.Content = prompt.Content
+		existingPrompt.Description = prompt.Description
+		existingPrompt.Tags = prompt.Tags
+		existingPrompt.Version = prompt.Version
+		existingPrompt.Format = prompt.Format
+
+		return f.Update(ctx, *existingPrompt)
+	}
+
+	indexedPrompt, err := f.createEntry(prompt)
+	if err != nil {
+		return err
+	}
+
+	index, err := f.loadIndex()
+	if err != nil {
+		return err
+	}
+
+	index.Prompts = append(index.Prompts, indexedPrompt)
+
+	return f.saveIndex(index)
+}
+
+// AddBatch adds several prompts like Add, writing the index once after all of them
+// A prompt with the name and author of an indexed prompt updates its entry. The returned
+// prompts carry the entry they are stored in; when a prompt fails, the ones stored before
+// it are returned and recorded in the index along with the error.
+func (f *FileSystemStore) AddBatch(ctx context.Context, prompts []model.Prompt) ([]model.Prompt, error) {
+	if err := f.ensureInitialized(); err != nil {
+		return nil, err
+	}
+
+	index, err := f.loadIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	var stored []model.Prompt
+	var addErr error
+	for _, prompt := range prompts {
+		existing := -1
+		for i, indexedPrompt := range index.Prompts {
+			if indexedPrompt.Name == prompt.Name && indexedPrompt.Author == prompt.Author {
+				existing = i
+				break
+			}
+		}
+
+		if existing >= 0 {
+			prompt.GistURL = index.Prompts[existing].GistURL
+			prompt.ID = utils.ExtractGistIDFromURL(prompt.GistURL)
+			fileName := f.entryFileName(prompt.ID, prompt)
+			if err := f.writeEntry(prompt.ID, fileName, prompt.Content); err != nil {
+				addErr = err
+				break
+			}
+			index.Prompts[existing].LastUpdated = time.Now()
+			index.Prompts[existing].FilePath = fileName
+			index.Prompts[existing].ApplyMetadata(prompt)
+		} else {
+			indexedPrompt, err := f.createEntry(prompt)
+			if err != nil {
+				addErr = err
+				break
+			}
+			index.Prompts = append(index.Prompts, indexedPrompt)
+			prompt.GistURL = indexedPrompt.GistURL
+			prompt.ID = utils.ExtractGistIDFromURL(prompt.GistURL)
+		}
+		stored = append(stored, prompt)
+	}
+
+	if len(stored) > 0 {
+		if err := f.saveIndex(index); err != nil {
+			return nil, err
+		}
+	}
+	return stored, addErr
+}
+
+// createEntry writes the entry of a new prompt and returns its index entry
+func (f *FileSystemStore) createEntry(prompt model.Prompt) (model.IndexedPrompt, error) {
+	id, err := newEntryID()
+	if err != nil {
+		return model.IndexedPrompt{}, err
+	}
+
+	fileName := entryFile(prompt)
+	if err := f.writeEntry(id, fileName, prompt.Content); err != nil {
+		return model.IndexedPrompt{}, err
+	}
+
+	indexedPrompt := model.IndexedPrompt{
+		GistURL:     f.entryURL(id),
+		FilePath:    fileName,
+		Author:      prompt.Author,
+		Name:        prompt.Name,
+		LastUpdated: time.Now(),
+	}
+	indexedPrompt.ApplyMetadata(prompt)
+	return indexedPrompt, nil
+}
+
+// Delete removes matching prompt entries and updates the index
+func (f *FileSystemStore) Delete(ctx context.Context, keyword string) error {
+	if err := f.ensureInitialized(); err != nil {
+		return err
+	}
+
+	index, err := f.loadIndex()
+	if err != nil {
+		return err
+	}
+
+	var remaining []model.IndexedPrompt
+	for _, indexedPrompt := range index.Prompts {
+		id := utils.ExtractGistIDFromURL(indexedPrompt.GistURL)
+		if id == keyword || strings.Contains(indexedPrompt.FilePath, keyword) {
+			if err := os.RemoveAll(f.entryDir(id)); err != nil {
+				return fmt.Errorf("failed to delete entry %s: %w", id, err)
+			}
+			continue
+		}
+		remaining = append(remaining, indexedPrompt)
+	}
+
+	// Leave the index untouched when no prompt matches
+	if len(remaining) == len(index.Prompts) {
+		return nil
+	}
+
+	index.Prompts = remaining
+	return f.saveIndex(index)
+}
+
+// Update modifies an existing prompt entry
+func (f *FileSystemStore) Update(ctx context.Context, prompt model.Prompt) error {
+	if err := f.ensureInitialized(); err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(f.entryDir(prompt.ID)); err != nil {
+		return fmt.Errorf("failed to get entry %s: %w", prompt.ID, err)
+	}
+
+	fileName := f.entryFileName(prompt.ID, prompt)
+	if err := f.writeEntry(prompt.ID, fileName, prompt.Content); err != nil {
+		return err
+	}
+
+	index, err := f.loadIndex()
+	if err != nil {
+		return err
+	}
+
+	for i, indexedPrompt := range index.Prompts {
+		if utils.ExtractGistIDFromURL(indexedPrompt.GistURL) == prompt.ID {
+			index.Prompts[i].LastUpdated = time.Now()
+			index.Prompts[i].Author = prompt.Author
+			index.Prompts[i].Name = prompt.Name
+			index.Prompts[i].FilePath = fileName
+			index.Prompts[i].ApplyMetadata(prompt)
+			break
+		}
+	}
+
+	return f.saveIndex(index)
+}
+
+// Get searches for prompts by keyword
+func (f *FileSystemStore) Get(ctx context.Context, keyword string) ([]model.Prompt, error) {
+	allPrompts, err := f.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var matchingPrompts []model.Prompt
+	for _, prompt := range allPrompts {
+		if matchesKeyword(prompt, keyword) {
+			matchingPrompts = append(matchingPrompts, prompt)
+		}
+	}
+
+	return matchingPrompts, nil
+}
+
+// BackfillMetadata records the metadata of index entries written by older pv versions
+func (f *FileSystemStore) BackfillMetadata(ctx context.Context) (int, error) {
+	if err := f.ensureInitialized(); err != nil {
+		return 0, err
+	}
+
+	index, err := f.loadIndex()
+	if err != nil {
+		return 0, err
+	}
+
+	filled := backfillIndexMetadata(index, func(entry model.IndexedPrompt) (string, error) {
+		return f.GetContent(ctx, utils.ExtractGistIDFromURL(entry.GistURL))
+	})
+	if filled == 0 {
+		return 0, nil
+	}
+
+	if err := f.saveIndex(index); err != nil {
+		return 0, err
+	}
+
+	return filled, nil
+}
+
+// GetContent reads the prompt file of an entry
+func (f *FileSystemStore) GetContent(ctx context.Context, gistID string) (string, error) {
+	if err := f.ensureInitialized(); err != nil {
+		return "", err
+	}
+
+	fileName, err := f.findPromptFile(gistID)
+	if err != nil {
+		return "", fmt.Errorf("no prompt file (.yaml or .md) found in entry %s: %w", gistID, err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(f.entryDir(gistID), fileName))
+	if err != nil {
+		return "", fmt.Errorf("failed to read entry %s: %w", gistID, err)
+	}
+
+	return string(data), nil
+}
+
+// CreatePublicGist creates a new entry for a shared prompt
+// A local vault has no visibility settings; entries become public by being recorded in Exports
+func (f *FileSystemStore) CreatePublicGist(ctx context.Context, prompt model.Prompt) (string, error) {
+	if err := f.ensureInitialized(); err != nil {
+		return "", err
+	}
+
+	id, err := newEntryID()
+	if err != nil {
+		return "", errors.NewShareError("创建公开条目", "", err)
+	}
+
+	if err := f.writeEntry(id, entryFile(prompt), buildYAMLContent(prompt)); err != nil {
+		return "", errors.NewShareError("创建公开条目", "", err)
+	}
+
+	return f.entryURL(id), nil
+}
+
+// UpdateGist replaces the content of an existing entry
+func (f *FileSystemStore) UpdateGist(ctx context.Context, gistURL string, prompt model.Prompt) error {
+	id := utils.ExtractGistIDFromURL(gistURL)
+
+	if _, err := os.Stat(f.entryDir(id)); err != nil {
+		return errors.NewShareError("获取现有条目", gistURL, err)
+	}
+
+	if err := f.writeEntry(id, f.entryFileName(id, prompt), buildYAMLContent(prompt)); err != nil {
+		return errors.NewShareError("更新条目", gistURL, err)
+	}
+
+	return nil
+}
+
+// GetGistInfo returns basic information about an entry
+func (f *FileSystemStore) GetGistInfo(ctx context.Context, gistURL string) (*GistInfo, error) {
+	if err := f.ensureInitialized(); err != nil {
+		return nil, err
+	}
+
+	id := utils.ExtractGistIDFromURL(gistURL)
+
+	if _, err := os.Stat(f.entryDir(id)); err != nil {
+		if os.IsNotExist(err) {
+			return &GistInfo{
+				ID:        id,
+				URL:       gistURL,
+				IsPublic:  false,
+				HasAccess: false,
+			}, nil
+		}
+		return nil, errors.NewShareError("获取条目信息", gistURL, err)
+	}
+
+	index, err := f.loadIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	isPublic := false
+	for _, export := range index.Exports {
+		if export.GistURL == gistURL {
+			isPublic = true
+			break
+		}
+	}
+
+	return &GistInfo{
+		ID:        id,
+		URL:       gistURL,
+		IsPublic:  isPublic,
+		HasAccess: true,
+	}, nil
+}
+
+// AddExport adds a new export record
+func (f *FileSystemStore) AddExport(ctx context.Context, prompt model.IndexedPrompt) error {
+	if err := f.ensureInitialized(); err != nil {
+		return err
+	}
+
+	index, err := f.loadIndex()
+	if err != nil {
+		return err
+	}
+
+	index.Exports = append(index.Exports, prompt)
+	return f.saveIndex(index)
+}
+
+// UpdateExport updates an existing export record or adds it if missing
+func (f *FileSystemStore) UpdateExport(ctx context.Context, prompt model.IndexedPrompt) error {
+	if err := f.ensureInitialized(); err != nil {
+		return err
+	}
+
+	index, err := f.loadIndex()
+	if err != nil {
+		return err
+	}
+
+	for i, export := range index.Exports {
+		if export.GistURL == prompt.GistURL {
+			index.Exports[i] = prompt
+			return f.saveIndex(index)
+		}
+	}
+
+	index.Exports = append(index.Exports, prompt)
+	return f.saveIndex(index)
+}
+
+// GetExports returns all export records
+func (f *FileSystemStore) GetExports(ctx context.Context) ([]model.IndexedPrompt, error) {
+	if err := f.ensureInitialized(); err != nil {
+		return nil, err
+	}
+
+	index, err := f.loadIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	return index.Exports, nil
+}
+
+// newEntryID generates a random 32 character hex ID, matching the shape of gist IDs
+func newEntryID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate entry ID: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,388 @@
+package infra
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/grigri/pv/internal/model"
+)
+
+const testPromptContent = `name: "Code Review"
+author: "alice"
+---
+Review the following code: {code}`
+
+func newTestFileSystemStore(t *testing.T) (*FileSystemStore, string) {
+	t.Helper()
+	rootDir := filepath.Join(t.TempDir(), "vault")
+	return NewFileSystemStore(rootDir).(*FileSystemStore), rootDir
+}
+
+func TestFileSystemStore_EmptyVault(t *testing.T) {
+	store, rootDir := newTestFileSystemStore(t)
+
+	_, err := store.List(context.Background())
+	if err != ErrEmptyIndex {
+		t.Fatalf("Expected ErrEmptyIndex, got: %v", err)
+	}
+
+	// The index should have been created in the same shape as the index gist
+	data, err := os.ReadFile(filepath.Join(rootDir, IndexFileName))
+	if err != nil {
+		t.Fatalf("Expected index.json to be created: %v", err)
+	}
+	var index model.Index
+	if err := json.Unmarshal(data, &index); err != nil {
+		t.Fatalf("index.json is not a valid model.Index: %v", err)
+	}
+}
+
+func TestFileSystemStore_AddListGetContent(t *testing.T) {
+	store, rootDir := newTestFileSystemStore(t)
+
+	prompt := model.Prompt{Name: "Code Review", Author: "alice", Content: testPromptContent}
+	if err := store.Add(context.Background(), prompt); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	prompts, err := store.List(context.Background())
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(prompts) != 1 {
+		t.Fatalf("Expected 1 prompt, got %d", len(prompts))
+	}
+
+	got := prompts[0]
+	if got.Name != "Code Review" || got.Author != "alice" {
+		t.Errorf("Unexpected prompt metadata: %+v", got)
+	}
+	if len(got.ID) != 32 {
+		t.Errorf("Expected 32 character ID, got %q", got.ID)
+	}
+	if got.GistURL != VaultURLPrefix+got.ID {
+		t.Errorf("Unexpected entry URL: %s", got.GistURL)
+	}
+
+	if _, err := os.Stat(filepath.Join(rootDir, got.ID, "Code Review.yaml")); err != nil {
+		t.Errorf("Expected prompt file on disk: %v", err)
+	}
+
+	content, err := store.GetContent(context.Background(), got.ID)
+	if err != nil {
+		t.Fatalf("GetContent failed: %v", err)
+	}
+	if content != testPromptContent {
+		t.Errorf("GetContent = %q, want %q", content, testPromptContent)
+	}
+
+	matches, err := store.Get(context.Background(), "review")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if len(matches) != 1 {
+		t.Errorf("Expected 1 match, got %d", len(matches))
+	}
+
+	found, err := store.FindExistingPromptByURL(context.Background(), got.GistURL)
+	if err != nil {
+		t.Fatalf("FindExistingPromptByURL failed: %v", err)
+	}
+	if found == nil || found.ID != got.ID {
+		t.Errorf("Expected to find prompt by URL, got %+v", found)
+	}
+}
+
+func TestFileSystemStore_AddSameNameAndAuthorUpdates(t *testing.T) {
+	store, _ := newTestFileSystemStore(t)
+
+	if err := store.Add(context.Background(), model.Prompt{Name: "Code Review", Author: "alice", Content: "v1"}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if err := store.Add(context.Background(), model.Prompt{Name: "Code Review", Author: "alice", Content: "v2"}); err != nil {
+		t.Fatalf("Second Add failed: %v", err)
+	}
+
+	prompts, err := store.List(context.Background())
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(prompts) != 1 {
+		t.Fatalf("Expected existing prompt to be updated, got %d prompts", len(prompts))
+	}
+
+	content, err := store.GetContent(context.Background(), prompts[0].ID)
+	if err != nil {
+		t.Fatalf("GetContent failed: %v", err)
+	}
+	if content != "v2" {
+		t.Errorf("Expected updated content, got %q", content)
+	}
+}
+
+func TestFileSystemStore_AddBatch(t *testing.T) {
+	store, _ := newTestFileSystemStore(t)
+
+	if err := store.Add(context.Background(), model.Prompt{Name: "Code Review", Author: "alice", Content: "v1"}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	stored, err := store.AddBatch(context.Background(), []model.Prompt{
+		{Name: "Code Review", Author: "alice", Content: "v2"},
+		{Name: "Summary", Author: "alice", Content: "summarize"},
+	})
+	if err != nil || len(stored) != 2 {
+		t.Fatalf("AddBatch failed: %+v, %v", stored, err)
+	}
+
+	prompts, err := store.List(context.Background())
+	if err != nil || len(prompts) != 2 {
+		t.Fatalf("Expected the existing prompt to be updated and one created, got %+v, %v", prompts, err)
+	}
+	for i, prompt := range stored {
+		if prompt.ID != prompts[i].ID || prompt.GistURL != prompts[i].GistURL {
+			t.Errorf("Expected %s to carry its entry %s, got %+v", prompt.Name, prompts[i].GistURL, prompt)
+		}
+	}
+	if content, err := store.GetContent(context.Background(), prompts[0].ID); err != nil || content != "v2" {
+		t.Errorf("Expected updated content, got %q, %v", content, err)
+	}
+}
+
+func TestFileSystemStore_UpdateRename(t *testing.T) {
+	store, rootDir := newTestFileSystemStore(t)
+
+	if err := store.Add(context.Background(), model.Prompt{Name: "Old Name", Author: "alice", Content: "body"}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	prompts, _ := store.List(context.Background())
+	id := prompts[0].ID
+
+	err := store.Update(context.Background(), model.Prompt{ID: id, Name: "New Name", Author: "alice", Content: "new body"})
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(rootDir, id, "Old Name.yaml")); !os.IsNotExist(err) {
+		t.Errorf("Expected old file to be removed, stat err: %v", err)
+	}
+
+	prompts, err = store.List(context.Background())
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if prompts[0].Name != "New Name" {
+		t.Errorf("Expected renamed prompt, got %q", prompts[0].Name)
+	}
+
+	if err := store.Update(context.Background(), model.Prompt{ID: "missing", Name: "x", Author: "y"}); err == nil {
+		t.Error("Expected error updating a missing entry")
+	}
+}
+
+func TestFileSystemStore_Markdown(t *testing.T) {
+	store, rootDir := newTestFileSystemStore(t)
+	content := "---\nname: Notes\nauthor: alice\n---\n# Notes\n"
+
+	if err := store.Add(context.Background(), model.Prompt{Name: "Notes", Author: "alice", Format: model.FormatMarkdown, Content: content}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	prompts, err := store.List(context.Background())
+	if err != nil || len(prompts) != 1 || prompts[0].Format != model.FormatMarkdown {
+		t.Fatalf("Expected the prompt to be listed as Markdown, got %+v, %v", prompts, err)
+	}
+	id := prompts[0].ID
+	if _, err := os.Stat(filepath.Join(rootDir, id, "Notes.md")); err != nil {
+		t.Errorf("Expected the prompt to be stored as Notes.md: %v", err)
+	}
+	if got, err := store.GetContent(context.Background(), id); err != nil || got != content {
+		t.Errorf("Expected the Markdown content, got %q, %v", got, err)
+	}
+
+	// An update without a format keeps the Markdown file
+	if err := store.Update(context.Background(), model.Prompt{ID: id, Name: "Notes", Author: "alice", Content: content + "more\n"}); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(rootDir, id, "Notes.md")); err != nil {
+		t.Errorf("Expected the update to keep Notes.md: %v", err)
+	}
+
+	// Adding a Markdown file over a YAML prompt switches the entry to Markdown
+	if err := store.Add(context.Background(), model.Prompt{Name: "Plan", Author: "alice", Content: "name: Plan\nauthor: alice\n---\nPlan\n"}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	planContent := "---\nname: Plan\nauthor: alice\n---\n# Plan\n"
+	if err := store.Add(context.Background(), model.Prompt{Name: "Plan", Author: "alice", Format: model.FormatMarkdown, Content: planContent}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	prompts, err = store.Get(context.Background(), "Plan")
+	if err != nil || len(prompts) != 1 || prompts[0].Format != model.FormatMarkdown {
+		t.Fatalf("Expected the updated prompt to be listed as Markdown, got %+v, %v", prompts, err)
+	}
+	if _, err := os.Stat(filepath.Join(rootDir, prompts[0].ID, "Plan.md")); err != nil {
+		t.Errorf("Expected the update to be stored as Plan.md: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(rootDir, prompts[0].ID, "Plan.yaml")); !os.IsNotExist(err) {
+		t.Errorf("Expected Plan.yaml to be replaced, stat err: %v", err)
+	}
+
+	url, err := store.CreatePublicGist(context.Background(), model.Prompt{Name: "Notes", Author: "alice", Format: model.FormatMarkdown, Content: content})
+	if err != nil {
+		t.Fatalf("CreatePublicGist failed: %v", err)
+	}
+	if got, err := store.GetContent(context.Background(), filepath.Base(url)); err != nil || got != content {
+		t.Errorf("Expected the shared copy to be readable, got %q, %v", got, err)
+	}
+}
+
+func TestFileSystemStore_NameWithPathSeparators(t *testing.T) {
+	store, rootDir := newTestFileSystemStore(t)
+
+	for _, name := range []string{"../../escape", "team/review", `..\\windows`} {
+		if err := store.Add(context.Background(), model.Prompt{Name: name, Author: "alice", Content: "body"}); err != nil {
+			t.Fatalf("Add %q failed: %v", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(rootDir, "..", "escape.yaml")); !os.IsNotExist(err) {
+		t.Errorf("Expected no file outside the vault, stat err: %v", err)
+	}
+
+	prompts, err := store.List(context.Background())
+	if err != nil || len(prompts) != 3 {
+		t.Fatalf("Expected 3 prompts, got %+v, %v", prompts, err)
+	}
+	for _, prompt := range prompts {
+		entries, err := os.ReadDir(filepath.Join(rootDir, prompt.ID))
+		if err != nil || len(entries) != 1 || entries[0].IsDir() {
+			t.Errorf("Expected %q to be a single file in its entry, got %v, %v", prompt.Name, entries, err)
+		}
+		if content, err := store.GetContent(context.Background(), prompt.ID); err != nil || content != "body" {
+			t.Errorf("Expected the content of %q, got %q, %v", prompt.Name, content, err)
+		}
+	}
+
+	if err := store.Update(context.Background(), model.Prompt{ID: prompts[0].ID, Name: "../renamed", Author: "alice", Content: "new body"}); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(rootDir, "renamed.yaml")); !os.IsNotExist(err) {
+		t.Errorf("Expected the renamed file to stay in its entry, stat err: %v", err)
+	}
+}
+
+func TestFileSystemStore_Delete(t *testing.T) {
+	store, rootDir := newTestFileSystemStore(t)
+
+	if err := store.Add(context.Background(), model.Prompt{Name: "Keep", Author: "alice", Content: "a"}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if err := store.Add(context.Background(), model.Prompt{Name: "Remove", Author: "alice", Content: "b"}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	matches, _ := store.Get(context.Background(), "remove")
+	id := matches[0].ID
+
+	if err := store.Delete(context.Background(), id); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(rootDir, id)); !os.IsNotExist(err) {
+		t.Errorf("Expected entry directory to be removed, stat err: %v", err)
+	}
+
+	prompts, err := store.List(context.Background())
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(prompts) != 1 || prompts[0].Name != "Keep" {
+		t.Errorf("Unexpected prompts after delete: %+v", prompts)
+	}
+}
+
+func TestFileSystemStore_Exports(t *testing.T) {
+	store, _ := newTestFileSystemStore(t)
+
+	if err := store.Add(context.Background(), model.Prompt{Name: "Shared", Author: "alice", Content: testPromptContent}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	prompts, _ := store.List(context.Background())
+	parentURL := prompts[0].GistURL
+
+	info, err := store.GetGistInfo(context.Background(), parentURL)
+	if err != nil {
+		t.Fatalf("GetGistInfo failed: %v", err)
+	}
+	if info.IsPublic || !info.HasAccess {
+		t.Errorf("Expected private accessible entry, got %+v", info)
+	}
+
+	publicURL, err := store.CreatePublicGist(context.Background(), model.Prompt{Name: "Shared", Author: "alice", Content: testPromptContent})
+	if err != nil {
+		t.Fatalf("CreatePublicGist failed: %v", err)
+	}
+
+	export := model.IndexedPrompt{GistURL: publicURL, Name: "Shared", Author: "alice", Parent: &parentURL}
+	if err := store.AddExport(context.Background(), export); err != nil {
+		t.Fatalf("AddExport failed: %v", err)
+	}
+
+	info, err = store.GetGistInfo(context.Background(), publicURL)
+	if err != nil {
+		t.Fatalf("GetGistInfo failed: %v", err)
+	}
+	if !info.IsPublic {
+		t.Error("Expected exported entry to be reported as public")
+	}
+
+	export.Name = "Shared v2"
+	if err := store.UpdateExport(context.Background(), export); err != nil {
+		t.Fatalf("UpdateExport failed: %v", err)
+	}
+	if err := store.UpdateGist(context.Background(), publicURL, model.Prompt{Name: "Shared v2", Content: "updated"}); err != nil {
+		t.Fatalf("UpdateGist failed: %v", err)
+	}
+
+	exports, err := store.GetExports(context.Background())
+	if err != nil {
+		t.Fatalf("GetExports failed: %v", err)
+	}
+	if len(exports) != 1 || exports[0].Name != "Shared v2" {
+		t.Errorf("Unexpected exports: %+v", exports)
+	}
+
+	content, err := store.GetContent(context.Background(), info.ID)
+	if err != nil {
+		t.Fatalf("GetContent failed: %v", err)
+	}
+	if content != "updated" {
+		t.Errorf("Expected updated export content, got %q", content)
+	}
+
+	missing, err := store.GetGistInfo(context.Background(), VaultURLPrefix+"0123456789abcdef0123456789abcdef")
+	if err != nil {
+		t.Fatalf("GetGistInfo failed: %v", err)
+	}
+	if missing.HasAccess {
+		t.Error("Expected missing entry to report no access")
+	}
+}
+
+func TestFileSystemStore_GetRawIndexContent(t *testing.T) {
+	store, _ := newTestFileSystemStore(t)
+
+	if err := store.Add(context.Background(), model.Prompt{Name: "Raw", Author: "alice", Content: "body"}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	var provider RawIndexProvider = store
+	raw, err := provider.GetRawIndexContent(context.Background())
+	if err != nil {
+		t.Fatalf("GetRawIndexContent failed: %v", err)
+	}
+	if !strings.Contains(raw, `"name": "Raw"`) {
+		t.Errorf("Expected raw index to contain the prompt, got: %s", raw)
+	}
+}
@@ -0,0 +1,451 @@
+package infra
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grigri/pv/internal/errors"
+	"github.com/grigri/pv/internal/model"
+)
+
+const (
+	// DefaultGitBranch is the branch used when none is configured
+	DefaultGitBranch = "main"
+	// gitRemoteName is the name given to the configured remote
+	gitRemoteName = "origin"
+)
+
+// GitStore implements the Store interface on top of a local git repository
+// The working tree uses the FileSystemStore layout (index.json at the root and
+// one YAML file per prompt); every write is recorded as a commit and, when a
+// remote is configured, pulled before the first operation and pushed after each commit.
+// The commits that changed an entry make up the revision history of its prompt.
+type GitStore struct {
+	*FileSystemStore
+	remote string
+	branch string
+	synced bool
+}
+
+// NewGitStore creates a new GitStore for the repository at repoDir
+// remote may be empty for a purely local repository
+func NewGitStore(repoDir, remote, branch string) Store {
+	if branch == "" {
+		branch = DefaultGitBranch
+	}
+	return &GitStore{
+		FileSystemStore: &FileSystemStore{rootDir: repoDir},
+		remote:          remote,
+		branch:          branch,
+	}
+}
+
+// ensureRepository initializes the repository and pulls the remote once per process
+func (g *GitStore) ensureRepository(ctx context.Context) error {
+	if g.synced {
+		return nil
+	}
+
+	if err := os.MkdirAll(g.rootDir, 0700); err != nil {
+		return errors.NewAppError(errors.ErrStorage, "failed to create repository directory", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(g.rootDir, ".git")); os.IsNotExist(err) {
+		if _, err := g.git(ctx, "init", "-q"); err != nil {
+			return err
+		}
+		if _, err := g.git(ctx, "symbolic-ref", "HEAD", "refs/heads/"+g.branch); err != nil {
+			return err
+		}
+	}
+
+	if g.remote != "" {
+		if err := g.ensureRemote(ctx); err != nil {
+			return err
+		}
+		if err := g.Pull(ctx); err != nil {
+			return err
+		}
+	}
+
+	g.synced = true
+	return nil
+}
+
+// ensureRemote points the origin remote at the configured URL
+func (g *GitStore) ensureRemote(ctx context.Context) error {
+	current, err := g.git(ctx, "remote", "get-url", gitRemoteName)
+	if err != nil {
+		_, err = g.git(ctx, "remote", "add", gitRemoteName, g.remote)
+		return err
+	}
+	if current != g.remote {
+		_, err = g.git(ctx, "remote", "set-url", gitRemoteName, g.remote)
+		return err
+	}
+	return nil
+}
+
+// Pull fetches the configured branch and rebases local commits on top of it
+// An empty remote (no branch yet) is not an error
+func (g *GitStore) Pull(ctx context.Context) error {
+	if g.remote == "" {
+		return nil
+	}
+
+	if _, err := g.git(ctx, "fetch", "-q", gitRemoteName); err != nil {
+		return errors.NewAppError(errors.ErrNetwork, "failed to fetch from git remote", err)
+	}
+
+	remoteRef := gitRemoteName + "/" + g.branch
+	if _, err := g.git(ctx, "rev-parse", "--verify", "-q", remoteRef); err != nil {
+		return nil
+	}
+
+	if _, err := g.git(ctx, "pull", "-q", "--rebase", gitRemoteName, g.branch); err != nil {
+		return errors.NewAppError(errors.ErrStorage, "failed to pull from git remote", err)
+	}
+
+	return nil
+}
+
+// Push publishes local commits to the configured remote
+func (g *GitStore) Push(ctx context.Context) error {
+	if g.remote == "" {
+		return nil
+	}
+
+	if _, err := g.git(ctx, "push", "-q", gitRemoteName, "HEAD:refs/heads/"+g.branch); err != nil {
+		return errors.NewAppError(errors.ErrNetwork, "failed to push to git remote", err)
+	}
+
+	return nil
+}
+
+// commit stages every change in the working tree and records it as one commit
+// Nothing is committed when the tree is clean
+func (g *GitStore) commit(ctx context.Context, message string) error {
+	if _, err := g.git(ctx, "add", "-A"); err != nil {
+		return err
+	}
+
+	status, err := g.git(ctx, "status", "--porcelain")
+	if err != nil {
+		return err
+	}
+	if status == "" {
+		return nil
+	}
+
+	args := []string{"commit", "-q", "-m", message}
+	if email, _ := g.git(ctx, "config", "user.email"); email == "" {
+		// Fall back to a neutral identity so commits work on fresh machines
+		args = append([]string{"-c", "user.name=pv", "-c", "user.email=pv@localhost"}, args...)
+	}
+	if _, err := g.git(ctx, args...); err != nil {
+		return err
+	}
+
+	return g.Push(ctx)
+}
+
+// git runs a git command inside the repository and returns its trimmed stdout
+func (g *GitStore) git(ctx context.Context, args ...string) (string, error) {
+	stdout, err := g.gitOutput(ctx, args...)
+	return strings.TrimSpace(stdout), err
+}
+
+// gitOutput runs a git command inside the repository and returns its stdout as is
+func (g *GitStore) gitOutput(ctx context.Context, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = g.rootDir
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s: %w: %s", gitSubcommand(args), err, strings.TrimSpace(stderr.String()))
+	}
+
+	return stdout.String(), nil
+}
+
+// gitSubcommand returns the subcommand of git arguments, skipping global options like -c name=value
+func gitSubcommand(args []string) string {
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "-c" || args[i] == "-C":
+			i++
+		case !strings.HasPrefix(args[i], "-"):
+			return args[i]
+		}
+	}
+	return strings.Join(args, " ")
+}
+
+// List returns all prompts from the index
+func (g *GitStore) List(ctx context.Context) ([]model.Prompt, error) {
+	if err := g.ensureRepository(ctx); err != nil {
+		return nil, err
+	}
+	return g.FileSystemStore.List(ctx)
+}
+
+// Get searches for prompts by keyword
+func (g *GitStore) Get(ctx context.Context, keyword string) ([]model.Prompt, error) {
+	if err := g.ensureRepository(ctx); err != nil {
+		return nil, err
+	}
+	return g.FileSystemStore.Get(ctx, keyword)
+}
+
+// GetContent reads the prompt file of an entry
+func (g *GitStore) GetContent(ctx context.Context, gistID string) (string, error) {
+	if err := g.ensureRepository(ctx); err != nil {
+		return "", err
+	}
+	return g.FileSystemStore.GetContent(ctx, gistID)
+}
+
+// GetRawIndexContent returns the raw index.json content of the repository
+func (g *GitStore) GetRawIndexContent(ctx context.Context) (string, error) {
+	if err := g.ensureRepository(ctx); err != nil {
+		return "", err
+	}
+	return g.FileSystemStore.GetRawIndexContent(ctx)
+}
+
+// GetGistInfo returns basic information about an entry
+func (g *GitStore) GetGistInfo(ctx context.Context, gistURL string) (*GistInfo, error) {
+	if err := g.ensureRepository(ctx); err != nil {
+		return nil, err
+	}
+	return g.FileSystemStore.GetGistInfo(ctx, gistURL)
+}
+
+// GetExports returns all export records
+func (g *GitStore) GetExports(ctx context.Context) ([]model.IndexedPrompt, error) {
+	if err := g.ensureRepository(ctx); err != nil {
+		return nil, err
+	}
+	return g.FileSystemStore.GetExports(ctx)
+}
+
+// FindExistingPromptByURL looks up a prompt by its entry URL
+func (g *GitStore) FindExistingPromptByURL(ctx context.Context, gistURL string) (*model.Prompt, error) {
+	if err := g.ensureRepository(ctx); err != nil {
+		return nil, err
+	}
+	return g.FileSystemStore.FindExistingPromptByURL(ctx, gistURL)
+}
+
+// Add writes a new prompt and commits it
+func (g *GitStore) Add(ctx context.Context, prompt model.Prompt) error {
+	if err := g.ensureRepository(ctx); err != nil {
+		return err
+	}
+	if err := g.FileSystemStore.Add(ctx, prompt); err != nil {
+		return err
+	}
+	return g.commit(ctx, fmt.Sprintf("Add prompt: %s", prompt.Name))
+}
+
+// AddBatch writes several prompts and commits them together
+func (g *GitStore) AddBatch(ctx context.Context, prompts []model.Prompt) ([]model.Prompt, error) {
+	if err := g.ensureRepository(ctx); err != nil {
+		return nil, err
+	}
+	stored, err := g.FileSystemStore.AddBatch(ctx, prompts)
+	if len(stored) == 0 {
+		return nil, err
+	}
+
+	message := fmt.Sprintf("Add %d prompts", len(stored))
+	if len(stored) == 1 {
+		message = fmt.Sprintf("Add prompt: %s", stored[0].Name)
+	}
+	if commitErr := g.commit(ctx, message); commitErr != nil {
+		return nil, commitErr
+	}
+	return stored, err
+}
+
+// Delete removes matching prompts and commits the removal
+// Nothing is committed or pushed when no prompt matches
+func (g *GitStore) Delete(ctx context.Context, keyword string) error {
+	if err := g.ensureRepository(ctx); err != nil {
+		return err
+	}
+	if err := g.FileSystemStore.Delete(ctx, keyword); err != nil {
+		return err
+	}
+	return g.commit(ctx, fmt.Sprintf("Delete prompt: %s", keyword))
+}
+
+// Update modifies an existing prompt and commits the change
+func (g *GitStore) Update(ctx context.Context, prompt model.Prompt) error {
+	if err := g.ensureRepository(ctx); err != nil {
+		return err
+	}
+	if err := g.FileSystemStore.Update(ctx, prompt); err != nil {
+		return err
+	}
+	return g.commit(ctx, fmt.Sprintf("Update prompt: %s", prompt.Name))
+}
+
+// CreatePublicGist creates an entry for a shared prompt and commits it
+func (g *GitStore) CreatePublicGist(ctx context.Context, prompt model.Prompt) (string, error) {
+	if err := g.ensureRepository(ctx); err != nil {
+		return "", err
+	}
+	url, err := g.FileSystemStore.CreatePublicGist(ctx, prompt)
+	if err != nil {
+		return "", err
+	}
+	if err := g.commit(ctx, fmt.Sprintf("Share prompt: %s", prompt.Name)); err != nil {
+		return "", err
+	}
+	return url, nil
+}
+
+// UpdateGist replaces the content of an entry and commits it
+func (g *GitStore) UpdateGist(ctx context.Context, gistURL string, prompt model.Prompt) error {
+	if err := g.ensureRepository(ctx); err != nil {
+		return err
+	}
+	if err := g.FileSystemStore.UpdateGist(ctx, gistURL, prompt); err != nil {
+		return err
+	}
+	return g.commit(ctx, fmt.Sprintf("Update shared prompt: %s", prompt.Name))
+}
+
+// AddExport records a new export and commits the index
+func (g *GitStore) AddExport(ctx context.Context, prompt model.IndexedPrompt) error {
+	if err := g.ensureRepository(ctx); err != nil {
+		return err
+	}
+	if err := g.FileSystemStore.AddExport(ctx, prompt); err != nil {
+		return err
+	}
+	return g.commit(ctx, fmt.Sprintf("Add export: %s", prompt.Name))
+}
+
+// UpdateExport updates an export record and commits the index
+func (g *GitStore) UpdateExport(ctx context.Context, prompt model.IndexedPrompt) error {
+	if err := g.ensureRepository(ctx); err != nil {
+		return err
+	}
+	if err := g.FileSystemStore.UpdateExport(ctx, prompt); err != nil {
+		return err
+	}
+	return g.commit(ctx, fmt.Sprintf("Update export: %s", prompt.Name))
+}
+
+// BackfillMetadata records the metadata of older index entries and commits the index
+func (g *GitStore) BackfillMetadata(ctx context.Context) (int, error) {
+	if err := g.ensureRepository(ctx); err != nil {
+		return 0, err
+	}
+	filled, err := g.FileSystemStore.BackfillMetadata(ctx)
+	if err != nil || filled == 0 {
+		return filled, err
+	}
+	if err := g.commit(ctx, "Backfill prompt metadata"); err != nil {
+		return 0, err
+	}
+	return filled, nil
+}
+
+// ListRevisions returns the commits that changed the entry of a prompt, newest first
+// Additions and deletions count the changed lines of the entry in each commit.
+func (g *GitStore) ListRevisions(ctx context.Context, gistID string) ([]model.Revision, error) {
+	if err := g.ensureRepository(ctx); err != nil {
+		return nil, err
+	}
+	if !isEntryID(gistID) {
+		return nil, errors.NewAppError(errors.ErrNotFound, fmt.Sprintf("no entry %s in the repository", gistID), nil)
+	}
+
+	out, err := g.git(ctx, "log", "--format=commit %H %ct", "--numstat", "--", gistID+"/")
+	if err != nil {
+		return nil, errors.NewAppError(errors.ErrStorage, fmt.Sprintf("failed to list revisions of entry %s", gistID), err)
+	}
+
+	var revisions []model.Revision
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 3 && fields[0] == "commit":
+			committedAt, err := strconv.ParseInt(fields[2], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("unexpected git log line %q: %w", line, err)
+			}
+			revisions = append(revisions, model.Revision{SHA: fields[1], CommittedAt: time.Unix(committedAt, 0)})
+		case len(fields) >= 3 && len(revisions) > 0:
+			// Binary files report "-" instead of line counts and are not counted
+			revision := &revisions[len(revisions)-1]
+			if additions, err := strconv.Atoi(fields[0]); err == nil {
+				revision.Additions += additions
+			}
+			if deletions, err := strconv.Atoi(fields[1]); err == nil {
+				revision.Deletions += deletions
+			}
+		}
+	}
+
+	return revisions, nil
+}
+
+// GetRevisionContent returns the prompt file of an entry as of commit sha
+func (g *GitStore) GetRevisionContent(ctx context.Context, gistID, sha string) (string, error) {
+	if err := g.ensureRepository(ctx); err != nil {
+		return "", err
+	}
+	if !isEntryID(gistID) || !isCommitSHA(sha) {
+		return "", errors.NewAppError(errors.ErrNotFound, fmt.Sprintf("no revision %s of entry %s", sha, gistID), nil)
+	}
+
+	files, err := g.git(ctx, "ls-tree", "--name-only", sha, "--", gistID+"/")
+	if err != nil {
+		return "", errors.NewAppError(errors.ErrNotFound, fmt.Sprintf("no revision %s of entry %s", sha, gistID), err)
+	}
+
+	for _, file := range strings.Split(files, "\n") {
+		if model.FormatFromPath(file) == "" {
+			continue
+		}
+		content, err := g.gitOutput(ctx, "show", sha+":"+file)
+		if err != nil {
+			return "", errors.NewAppError(errors.ErrStorage, fmt.Sprintf("failed to read revision %s of entry %s", sha, gistID), err)
+		}
+		return content, nil
+	}
+
+	return "", errors.NewAppError(errors.ErrNotFound, fmt.Sprintf("no prompt file found in revision %s of entry %s", sha, gistID), nil)
+}
+
+// isEntryID reports whether id names an entry directory at the repository root
+func isEntryID(id string) bool {
+	return id != "" && id != "." && id != ".." && !strings.ContainsAny(id, "/\\") && !strings.HasPrefix(id, "-")
+}
+
+// isCommitSHA reports whether sha is a full or abbreviated hexadecimal commit SHA
+func isCommitSHA(sha string) bool {
+	if len(sha) < 4 {
+		return false
+	}
+	for _, r := range sha {
+		if !strings.ContainsRune("0123456789abcdef", r) {
+			return false
+		}
+	}
+	return true
+}
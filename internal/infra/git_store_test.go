@@ -0,0 +1,235 @@
+package infra
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/grigri/pv/internal/model"
+)
+
+// newBareRepo creates a bare repository on local disk to act as the shared remote
+func newBareRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	bareDir := filepath.Join(t.TempDir(), "remote.git")
+	if out, err := exec.Command("git", "init", "-q", "--bare", bareDir).CombinedOutput(); err != nil {
+		t.Fatalf("Failed to create bare repo: %v: %s", err, out)
+	}
+	return bareDir
+}
+
+// gitLog returns the commit subjects of a branch in the given repository
+func gitLog(t *testing.T, gitDir, branch string) []string {
+	t.Helper()
+	out, err := exec.Command("git", "--git-dir", gitDir, "log", "--format=%s", branch).CombinedOutput()
+	if err != nil {
+		t.Fatalf("git log failed: %v: %s", err, out)
+	}
+	return strings.Split(strings.TrimSpace(string(out)), "\n")
+}
+
+func TestGitStore_CommitsEveryWrite(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	repoDir := filepath.Join(t.TempDir(), "prompts")
+	store := NewGitStore(repoDir, "", "")
+
+	if err := store.Add(context.Background(), model.Prompt{Name: "Review", Author: "alice", Content: "v1"}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	prompts, err := store.List(context.Background())
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+
+	prompt := prompts[0]
+	prompt.Content = "v2"
+	if err := store.Update(context.Background(), prompt); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if err := store.Delete(context.Background(), prompt.ID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+
+	subjects := gitLog(t, filepath.Join(repoDir, ".git"), DefaultGitBranch)
+	want := []string{"Delete prompt: " + prompt.ID, "Update prompt: Review", "Add prompt: Review"}
+	if len(subjects) != len(want) {
+		t.Fatalf("Expected %d commits, got %v", len(want), subjects)
+	}
+	for i := range want {
+		if subjects[i] != want[i] {
+			t.Errorf("Commit %d = %q, want %q", i, subjects[i], want[i])
+		}
+	}
+}
+
+func TestGitStore_AddBatchCommitsOnce(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	repoDir := filepath.Join(t.TempDir(), "prompts")
+	store := NewGitStore(repoDir, "", "").(*GitStore)
+
+	stored, err := store.AddBatch(context.Background(), []model.Prompt{
+		{Name: "Review", Author: "alice", Content: "v1"},
+		{Name: "Summary", Author: "alice", Content: "v1"},
+	})
+	if err != nil || len(stored) != 2 || stored[0].GistURL == "" {
+		t.Fatalf("AddBatch failed: %+v, %v", stored, err)
+	}
+
+	subjects := gitLog(t, filepath.Join(repoDir, ".git"), DefaultGitBranch)
+	if len(subjects) != 1 || subjects[0] != "Add 2 prompts" {
+		t.Errorf("Expected a single commit for the batch, got %v", subjects)
+	}
+}
+
+func TestGitStore_CommitErrorNamesSubcommand(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	// Without a configured identity commits use the fallback -c user.name=pv
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+
+	repoDir := filepath.Join(t.TempDir(), "prompts")
+	store := NewGitStore(repoDir, "", "")
+	if _, err := store.List(context.Background()); err != nil && err != ErrEmptyIndex {
+		t.Fatalf("List failed: %v", err)
+	}
+	hook := filepath.Join(repoDir, ".git", "hooks", "pre-commit")
+	if err := os.MkdirAll(filepath.Dir(hook), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(hook, []byte("#!/bin/sh\nexit 1\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	err := store.Add(context.Background(), model.Prompt{Name: "Review", Author: "alice", Content: "v1"})
+	if err == nil || !strings.HasPrefix(err.Error(), "git commit: ") {
+		t.Errorf("Expected the failing git subcommand in the error, got %v", err)
+	}
+}
+
+func TestGitStore_PushAndPullThroughBareRemote(t *testing.T) {
+	bareDir := newBareRepo(t)
+
+	// First teammate adds a prompt and pushes it
+	alice := NewGitStore(filepath.Join(t.TempDir(), "alice"), bareDir, "prompts")
+	if err := alice.Add(context.Background(), model.Prompt{Name: "Shared", Author: "alice", Content: "hello"}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	subjects := gitLog(t, bareDir, "prompts")
+	if subjects[0] != "Add prompt: Shared" {
+		t.Errorf("Expected commit to be pushed, got %v", subjects)
+	}
+
+	// Second teammate starts from an empty directory and sees the prompt
+	bob := NewGitStore(filepath.Join(t.TempDir(), "bob"), bareDir, "prompts")
+	prompts, err := bob.List(context.Background())
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(prompts) != 1 || prompts[0].Name != "Shared" {
+		t.Fatalf("Expected pulled prompt, got %+v", prompts)
+	}
+
+	content, err := bob.GetContent(context.Background(), prompts[0].ID)
+	if err != nil {
+		t.Fatalf("GetContent failed: %v", err)
+	}
+	if content != "hello" {
+		t.Errorf("GetContent = %q, want %q", content, "hello")
+	}
+
+	// Both clones agree on the entry URL, so lookups by URL work across machines
+	found, err := bob.FindExistingPromptByURL(context.Background(), prompts[0].GistURL)
+	if err != nil || found == nil {
+		t.Errorf("Expected to find prompt by URL, got %+v, %v", found, err)
+	}
+}
+
+func TestGitStore_ReadsDoNotCommit(t *testing.T) {
+	bareDir := newBareRepo(t)
+
+	store := NewGitStore(filepath.Join(t.TempDir(), "repo"), bareDir, "")
+	if _, err := store.List(context.Background()); err != ErrEmptyIndex {
+		t.Fatalf("Expected ErrEmptyIndex, got: %v", err)
+	}
+
+	out, err := exec.Command("git", "--git-dir", bareDir, "rev-parse", "--verify", "-q", DefaultGitBranch).CombinedOutput()
+	if err == nil {
+		t.Errorf("Expected no commits on remote after read-only use, got %s", out)
+	}
+}
+
+func TestGitStore_DeleteWithoutMatchDoesNotCommit(t *testing.T) {
+	bareDir := newBareRepo(t)
+
+	store := NewGitStore(filepath.Join(t.TempDir(), "repo"), bareDir, "")
+	if err := store.Add(context.Background(), model.Prompt{Name: "Review", Author: "alice", Content: "v1"}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if err := store.Delete(context.Background(), "no-such-prompt"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+
+	subjects := gitLog(t, bareDir, DefaultGitBranch)
+	if len(subjects) != 1 || subjects[0] != "Add prompt: Review" {
+		t.Errorf("Expected only the add to be pushed, got %v", subjects)
+	}
+}
+
+func TestGitStore_Revisions(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	store := NewGitStore(filepath.Join(t.TempDir(), "prompts"), "", "").(*GitStore)
+	if err := store.Add(context.Background(), model.Prompt{Name: "Review", Author: "alice", Content: "line 1\n"}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if err := store.Add(context.Background(), model.Prompt{Name: "Summary", Author: "alice", Content: "other\n"}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	prompts, err := store.Get(context.Background(), "Review")
+	if err != nil || len(prompts) != 1 {
+		t.Fatalf("Get failed: %+v, %v", prompts, err)
+	}
+	prompt := prompts[0]
+	prompt.Content = "line 1\nline 2\n"
+	if err := store.Update(context.Background(), prompt); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	// Only the commits that changed the entry are revisions of the prompt
+	revisions, err := store.ListRevisions(context.Background(), prompt.ID)
+	if err != nil || len(revisions) != 2 {
+		t.Fatalf("Expected 2 revisions, got %+v, %v", revisions, err)
+	}
+	if revisions[0].Additions != 1 || revisions[0].Deletions != 0 || revisions[0].CommittedAt.IsZero() {
+		t.Errorf("Expected the newest revision to add one line, got %+v", revisions[0])
+	}
+
+	for sha, want := range map[string]string{revisions[0].SHA: "line 1\nline 2\n", revisions[1].ShortSHA(): "line 1\n"} {
+		if got, err := store.GetRevisionContent(context.Background(), prompt.ID, sha); err != nil || got != want {
+			t.Errorf("Expected revision %s to hold %q, got %q, %v", sha, want, got, err)
+		}
+	}
+	if _, err := store.GetRevisionContent(context.Background(), prompt.ID, "--output=x"); err == nil {
+		t.Error("Expected an invalid SHA to be rejected")
+	}
+}
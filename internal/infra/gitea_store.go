@@ -0,0 +1,830 @@
+package infra
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/grigri/pv/internal/config"
+	"github.com/grigri/pv/internal/errors"
+	"github.com/grigri/pv/internal/model"
+	"github.com/grigri/pv/internal/utils"
+)
+
+const (
+	// GiteaPromptsRepo is the private repository holding prompts and the index
+	GiteaPromptsRepo = "pv-prompts"
+	// GiteaSharedRepo is the public repository holding shared prompts
+	GiteaSharedRepo = "pv-shared"
+)
+
+// giteaRepo is the subset of the Gitea repository payload used by pv
+type giteaRepo struct {
+	Name          string `json:"name"`
+	Private       bool   `json:"private"`
+	DefaultBranch string `json:"default_branch"`
+	Description   string `json:"description"`
+}
+
+// giteaContent is an entry returned by the Gitea contents API
+type giteaContent struct {
+	Name     string `json:"name"`
+	Path     string `json:"path"`
+	SHA      string `json:"sha"`
+	Type     string `json:"type"`
+	Content  string `json:"content"`
+	Encoding string `json:"encoding"`
+}
+
+// giteaFileRequest is the body of create/update/delete file requests
+type giteaFileRequest struct {
+	Content string `json:"content,omitempty"`
+	Message string `json:"message"`
+	Branch  string `json:"branch,omitempty"`
+	SHA     string `json:"sha,omitempty"`
+}
+
+// giteaAPIError is returned for non-2xx responses from the Gitea API
+type giteaAPIError struct {
+	StatusCode int
+	Method     string
+	Path       string
+}
+
+// Error implements the error interface
+func (e *giteaAPIError) Error() string {
+	return fmt.Sprintf("Gitea API %s %s returned status %d", e.Method, e.Path, e.StatusCode)
+}
+
+// isGiteaNotFound reports whether err is a 404 from the Gitea API
+func isGiteaNotFound(err error) bool {
+	var apiErr *giteaAPIError
+	return stderrors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// GiteaStore implements the Store interface on Gitea (and Forgejo) repositories
+// Gitea has no gists, so a private repository plays the role of the user's gists
+// and a public repository holds shared prompts. Both use the vault layout:
+//
+//	pv-prompts/index.json
+//	pv-prompts/<id>/<name>.yaml
+//	pv-shared/<id>/<name>.yaml
+type GiteaStore struct {
+	baseURL     string
+	httpClient  *http.Client
+	configStore config.Store
+	token       string
+	owner       string
+	branches    map[string]string
+}
+
+// NewGiteaStore creates a new GiteaStore for the instance at baseURL
+func NewGiteaStore(configStore config.Store, baseURL string) Store {
+	return &GiteaStore{
+		baseURL:     strings.TrimRight(baseURL, "/"),
+		httpClient:  &http.Client{Timeout: 30 * time.Second},
+		configStore: configStore,
+		branches:    map[string]string{},
+	}
+}
+
+// ensureInitialized loads the token and owner, and creates the prompts repository and index if needed
+func (g *GiteaStore) ensureInitialized(ctx context.Context) error {
+	if g.owner != "" {
+		return nil
+	}
+
+	if g.baseURL == "" {
+		return fmt.Errorf("Gitea URL is not configured")
+	}
+
+	token, err := g.configStore.GetToken()
+	if err != nil {
+		return fmt.Errorf("failed to get Gitea token: %w", err)
+	}
+
+	if token == "" {
+		return fmt.Errorf("Gitea token is not configured")
+	}
+	g.token = token
+
+	var user struct {
+		Login string `json:"login"`
+	}
+	if err := g.do(ctx, http.MethodGet, "/user", nil, &user); err != nil {
+		return fmt.Errorf("failed to get Gitea user: %w", err)
+	}
+
+	if err := g.ensureRepo(ctx, GiteaPromptsRepo, true, user.Login); err != nil {
+		return fmt.Errorf("failed to initialize prompts repository: %w", err)
+	}
+	g.owner = user.Login
+
+	if err := g.initializeIndex(ctx); err != nil {
+		g.owner = ""
+		return fmt.Errorf("failed to initialize index: %w", err)
+	}
+
+	return nil
+}
+
+// loadRepo fetches a repository of the owner and records its branch
+func (g *GiteaStore) loadRepo(ctx context.Context, name, owner string) (*giteaRepo, error) {
+	var repo giteaRepo
+	if err := g.do(ctx, http.MethodGet, "/repos/"+owner+"/"+name, nil, &repo); err != nil {
+		return nil, err
+	}
+
+	g.recordBranch(name, &repo)
+	return &repo, nil
+}
+
+// ensureRepo finds or creates a repository of the owner and records its branch
+func (g *GiteaStore) ensureRepo(ctx context.Context, name string, private bool, owner string) error {
+	if _, ok := g.branches[name]; ok {
+		return nil
+	}
+
+	_, err := g.loadRepo(ctx, name, owner)
+	if !isGiteaNotFound(err) {
+		return err
+	}
+
+	request := map[string]interface{}{
+		"name":           name,
+		"private":        private,
+		"auto_init":      true,
+		"default_branch": DefaultGitBranch,
+		"description":    "Prompt Vault storage",
+	}
+
+	var repo giteaRepo
+	if err := g.do(ctx, http.MethodPost, "/user/repos", request, &repo); err != nil {
+		return err
+	}
+
+	g.recordBranch(name, &repo)
+	return nil
+}
+
+// recordBranch remembers the default branch of a repository
+func (g *GiteaStore) recordBranch(name string, repo *giteaRepo) {
+	if repo.DefaultBranch == "" {
+		repo.DefaultBranch = DefaultGitBranch
+	}
+	g.branches[name] = repo.DefaultBranch
+}
+
+// initializeIndex creates an empty index.json if the prompts repository has none
+func (g *GiteaStore) initializeIndex(ctx context.Context) error {
+	_, _, err := g.readFile(ctx, GiteaPromptsRepo, IndexFileName)
+	if err == nil || !isGiteaNotFound(err) {
+		return err
+	}
+
+	emptyIndex := model.Index{
+		Prompts:     []model.IndexedPrompt{},
+		Exports:     []model.IndexedPrompt{},
+		LastUpdated: time.Now(),
+	}
+
+	indexContent, err := encodeIndex(&emptyIndex)
+	if err != nil {
+		return fmt.Errorf("failed to marshal empty index: %w", err)
+	}
+
+	return g.writeFile(ctx, GiteaPromptsRepo, IndexFileName, string(indexContent), "", "Create prompt index")
+}
+
+// do performs an authenticated JSON request against the Gitea API
+func (g *GiteaStore) do(ctx context.Context, method, apiPath string, body interface{}, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode Gitea request: %w", err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, g.baseURL+"/api/v1"+apiPath, reader)
+	if err != nil {
+		return errors.NewAppError(errors.ErrNetwork, "failed to create request", err)
+	}
+
+	req.Header.Set("Authorization", "token "+g.token)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := g.httpClient.Do(req)
+	if err != nil {
+		return errors.NewAppError(errors.ErrNetwork, "Unable to connect to Gitea API", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &giteaAPIError{StatusCode: resp.StatusCode, Method: method, Path: apiPath}
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode Gitea response: %w", err)
+	}
+
+	return nil
+}
+
+// contentsPath builds the contents API path of a file or directory in a repository
+func (g *GiteaStore) contentsPath(repo, filePath string) string {
+	segments := strings.Split(filePath, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return "/repos/" + g.owner + "/" + repo + "/contents/" + strings.Join(segments, "/")
+}
+
+// readFile returns the decoded content and blob SHA of a file
+func (g *GiteaStore) readFile(ctx context.Context, repo, filePath string) (string, string, error) {
+	var content giteaContent
+	apiPath := g.contentsPath(repo, filePath) + "?ref=" + url.QueryEscape(g.branches[repo])
+	if err := g.do(ctx, http.MethodGet, apiPath, nil, &content); err != nil {
+		return "", "", err
+	}
+
+	data, err := base64.StdEncoding.DecodeString(content.Content)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to decode %s: %w", filePath, err)
+	}
+
+	return string(data), content.SHA, nil
+}
+
+// listDir returns the entries of a directory
+func (g *GiteaStore) listDir(ctx context.Context, repo, dir string) ([]giteaContent, error) {
+	var entries []giteaContent
+	apiPath := g.contentsPath(repo, dir) + "?ref=" + url.QueryEscape(g.branches[repo])
+	if err := g.do(ctx, http.MethodGet, apiPath, nil, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// writeFile creates a file, or updates it when sha is the SHA of the existing blob
+func (g *GiteaStore) writeFile(ctx context.Context, repo, filePath, content, sha, message string) error {
+	method := http.MethodPost
+	if sha != "" {
+		method = http.MethodPut
+	}
+
+	request := giteaFileRequest{
+		Content: base64.StdEncoding.EncodeToString([]byte(content)),
+		Message: message,
+		Branch:  g.branches[repo],
+		SHA:     sha,
+	}
+
+	return g.do(ctx, method, g.contentsPath(repo, filePath), request, nil)
+}
+
+// deleteFile removes a file from a repository
+func (g *GiteaStore) deleteFile(ctx context.Context, repo, filePath, sha, message string) error {
+	request := giteaFileRequest{
+		Message: message,
+		Branch:  g.branches[repo],
+		SHA:     sha,
+	}
+
+	return g.do(ctx, http.MethodDelete, g.contentsPath(repo, filePath), request, nil)
+}
+
+// writeEntry creates or replaces the prompt file of an entry
+// Any other file in the entry directory is removed so renames don't leave stale files
+func (g *GiteaStore) writeEntry(ctx context.Context, repo, id, fileName, content, message string) error {
+	entries, err := g.listDir(ctx, repo, id)
+	if err != nil && !isGiteaNotFound(err) {
+		return fmt.Errorf("failed to list entry %s: %w", id, err)
+	}
+
+	var sha string
+	for _, entry := range entries {
+		if entry.Name == fileName {
+			sha = entry.SHA
+			continue
+		}
+		if err := g.deleteFile(ctx, repo, entry.Path, entry.SHA, message); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", entry.Path, err)
+		}
+	}
+
+	if err := g.writeFile(ctx, repo, path.Join(id, fileName), content, sha, message); err != nil {
+		return fmt.Errorf("failed to write prompt file: %w", err)
+	}
+
+	return nil
+}
+
+// deleteEntry removes every file of an entry
+func (g *GiteaStore) deleteEntry(ctx context.Context, repo, id, message string) error {
+	entries, err := g.listDir(ctx, repo, id)
+	if err != nil {
+		if isGiteaNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	for _, entry := range entries {
+		if err := g.deleteFile(ctx, repo, entry.Path, entry.SHA, message); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// findPromptFile returns the first .yaml file of an entry
+func (g *GiteaStore) findPromptFile(ctx context.Context, repo, id string) (*giteaContent, error) {
+	entries, err := g.listDir(ctx, repo, id)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		if entry.Type == "file" && strings.HasSuffix(entry.Name, ".yaml") {
+			return &entry, nil
+		}
+	}
+
+	return nil, &giteaAPIError{StatusCode: http.StatusNotFound, Method: http.MethodGet, Path: g.contentsPath(repo, id)}
+}
+
+// entryURL builds the web URL of an entry
+// The last path segment is the entry ID so utils.ExtractGistIDFromURL keeps working
+func (g *GiteaStore) entryURL(repo, id string) string {
+	return fmt.Sprintf("%s/%s/%s/src/branch/%s/%s", g.baseURL, g.owner, repo, g.branches[repo], id)
+}
+
+// repoFromURL returns the repository an entry URL points into
+func (g *GiteaStore) repoFromURL(entryURL string) string {
+	if strings.HasPrefix(entryURL, g.baseURL+"/"+g.owner+"/"+GiteaSharedRepo+"/") {
+		return GiteaSharedRepo
+	}
+	return GiteaPromptsRepo
+}
+
+// loadIndex loads the current index from index.json
+func (g *GiteaStore) loadIndex(ctx context.Context) (*model.Index, string, error) {
+	content, sha, err := g.readFile(ctx, GiteaPromptsRepo, IndexFileName)
+	if err != nil {
+		if isGiteaNotFound(err) {
+			return nil, "", ErrNoIndex
+		}
+		return nil, "", fmt.Errorf("failed to get index: %w", err)
+	}
+
+	index, err := DecodeIndex([]byte(content))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to unmarshal index: %w", err)
+	}
+
+	return index, sha, nil
+}
+
+// saveIndex writes the index back to index.json
+func (g *GiteaStore) saveIndex(ctx context.Context, index *model.Index, sha, message string) error {
+	index.LastUpdated = time.Now()
+
+	indexContent, err := encodeIndex(index)
+	if err != nil {
+		return fmt.Errorf("failed to marshal index: %w", err)
+	}
+
+	if err := g.writeFile(ctx, GiteaPromptsRepo, IndexFileName, string(indexContent), sha, message); err != nil {
+		return fmt.Errorf("failed to update index: %w", err)
+	}
+
+	return nil
+}
+
+// GetRawIndexContent retrieves the raw index.json content from Gitea
+func (g *GiteaStore) GetRawIndexContent(ctx context.Context) (string, error) {
+	if err := g.ensureInitialized(ctx); err != nil {
+		return "", err
+	}
+
+	content, _, err := g.readFile(ctx, GiteaPromptsRepo, IndexFileName)
+	if err != nil {
+		return "", fmt.Errorf("failed to get index: %w", err)
+	}
+
+	return content, nil
+}
+
+// List returns all prompts from the index
+func (g *GiteaStore) List(ctx context.Context) ([]model.Prompt, error) {
+	if err := g.ensureInitialized(ctx); err != nil {
+		return nil, err
+	}
+
+	index, _, err := g.loadIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(index.Prompts) == 0 {
+		return nil, ErrEmptyIndex
+	}
+
+	var prompts []model.Prompt
+	for _, indexedPrompt := range index.Prompts {
+		id := utils.ExtractGistIDFromURL(indexedPrompt.GistURL)
+		if id == "" {
+			continue
+		}
+
+		prompts = append(prompts, promptFromIndex(id, indexedPrompt))
+	}
+
+	if len(prompts) == 0 {
+		return nil, ErrEmptyIndex
+	}
+
+	return prompts, nil
+}
+
+// findExistingPrompt searches for an existing prompt with the same name and author
+func (g *GiteaStore) findExistingPrompt(ctx context.Context, name, author string) (*model.Prompt, error) {
+	allPrompts, err := g.List(ctx)
+	if err != nil {
+		if err == ErrNoIndex || err == ErrEmptyIndex {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	for _, prompt := range allPrompts {
+		if prompt.Name == name && prompt.Author == author {
+			return &prompt, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// FindExistingPromptByURL looks up a prompt by its entry URL
+func (g *GiteaStore) FindExistingPromptByURL(ctx context.Context, gistURL string) (*model.Prompt, error) {
+	allPrompts, err := g.List(ctx)
+	if err != nil {
+		if err == ErrNoIndex || err == ErrEmptyIndex {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	for _, prompt := range allPrompts {
+		if prompt.GistURL == gistURL {
+			return &prompt, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// Add writes a new prompt entry to the prompts repository and updates the index
+func (g *GiteaStore) Add(ctx context.Context, prompt model.Prompt) error {
+	if err := g.ensureInitialized(ctx); err != nil {
+		return err
+	}
+
+	existingPrompt, err := g.findExistingPrompt(ctx, prompt.Name, prompt.Author)
+	if err != nil {
+		return fmt.Errorf("failed to check for existing prompt: %w", err)
+	}
+
+	if existingPrompt != nil {
+		existingPrompt.Content = prompt.Content
+		existingPrompt.Description = prompt.Description
+		existingPrompt.Tags = prompt.Tags
+		existingPrompt.Version = prompt.Version
+
+		return g.Update(ctx, *existingPrompt)
+	}
+
+	id, err := newEntryID()
+	if err != nil {
+		return err
+	}
+
+	message := "Add prompt: " + prompt.Name
+	fileName := prompt.Name + ".yaml"
+	if err := g.writeEntry(ctx, GiteaPromptsRepo, id, fileName, prompt.Content, message); err != nil {
+		return err
+	}
+
+	index, sha, err := g.loadIndex(ctx)
+	if err != nil {
+		return err
+	}
+
+	indexedPrompt := model.IndexedPrompt{
+		GistURL:     g.entryURL(GiteaPromptsRepo, id),
+		FilePath:    fileName,
+		Author:      prompt.Author,
+		Name:        prompt.Name,
+		LastUpdated: time.Now(),
+	}
+	indexedPrompt.ApplyMetadata(prompt)
+	index.Prompts = append(index.Prompts, indexedPrompt)
+
+	return g.saveIndex(ctx, index, sha, message)
+}
+
+// Delete removes matching prompt entries and updates the index
+func (g *GiteaStore) Delete(ctx context.Context, keyword string) error {
+	if err := g.ensureInitialized(ctx); err != nil {
+		return err
+	}
+
+	index, sha, err := g.loadIndex(ctx)
+	if err != nil {
+		return err
+	}
+
+	message := "Delete prompt: " + keyword
+	var remaining []model.IndexedPrompt
+	for _, indexedPrompt := range index.Prompts {
+		id := utils.ExtractGistIDFromURL(indexedPrompt.GistURL)
+		if id == keyword || strings.Contains(indexedPrompt.FilePath, keyword) {
+			if err := g.deleteEntry(ctx, GiteaPromptsRepo, id, message); err != nil {
+				return fmt.Errorf("failed to delete entry %s: %w", id, err)
+			}
+			continue
+		}
+		remaining = append(remaining, indexedPrompt)
+	}
+
+	index.Prompts = remaining
+	return g.saveIndex(ctx, index, sha, message)
+}
+
+// Update modifies an existing prompt entry
+func (g *GiteaStore) Update(ctx context.Context, prompt model.Prompt) error {
+	if err := g.ensureInitialized(ctx); err != nil {
+		return err
+	}
+
+	if _, err := g.findPromptFile(ctx, GiteaPromptsRepo, prompt.ID); err != nil {
+		return fmt.Errorf("failed to get entry %s: %w", prompt.ID, err)
+	}
+
+	message := "Update prompt: " + prompt.Name
+	fileName := prompt.Name + ".yaml"
+	if err := g.writeEntry(ctx, GiteaPromptsRepo, prompt.ID, fileName, prompt.Content, message); err != nil {
+		return err
+	}
+
+	index, sha, err := g.loadIndex(ctx)
+	if err != nil {
+		return err
+	}
+
+	for i, indexedPrompt := range index.Prompts {
+		if utils.ExtractGistIDFromURL(indexedPrompt.GistURL) == prompt.ID {
+			index.Prompts[i].LastUpdated = time.Now()
+			index.Prompts[i].Author = prompt.Author
+			index.Prompts[i].Name = prompt.Name
+			index.Prompts[i].FilePath = fileName
+			index.Prompts[i].ApplyMetadata(prompt)
+			break
+		}
+	}
+
+	return g.saveIndex(ctx, index, sha, message)
+}
+
+// Get searches for prompts by keyword
+func (g *GiteaStore) Get(ctx context.Context, keyword string) ([]model.Prompt, error) {
+	allPrompts, err := g.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var matchingPrompts []model.Prompt
+	for _, prompt := range allPrompts {
+		if matchesKeyword(prompt, keyword) {
+			matchingPrompts = append(matchingPrompts, prompt)
+		}
+	}
+
+	return matchingPrompts, nil
+}
+
+// BackfillMetadata records the metadata of index entries written by older pv versions
+func (g *GiteaStore) BackfillMetadata(ctx context.Context) (int, error) {
+	if err := g.ensureInitialized(ctx); err != nil {
+		return 0, err
+	}
+
+	index, sha, err := g.loadIndex(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	filled := backfillIndexMetadata(index, func(entry model.IndexedPrompt) (string, error) {
+		return g.GetContent(ctx, utils.ExtractGistIDFromURL(entry.GistURL))
+	})
+	if filled == 0 {
+		return 0, nil
+	}
+
+	if err := g.saveIndex(ctx, index, sha, "Backfill prompt metadata"); err != nil {
+		return 0, err
+	}
+
+	return filled, nil
+}
+
+// GetContent reads the prompt file of an entry from the prompts or the shared repository
+func (g *GiteaStore) GetContent(ctx context.Context, gistID string) (string, error) {
+	if err := g.ensureInitialized(ctx); err != nil {
+		return "", err
+	}
+
+	for _, repo := range []string{GiteaPromptsRepo, GiteaSharedRepo} {
+		if _, ok := g.branches[repo]; !ok {
+			if _, err := g.loadRepo(ctx, repo, g.owner); err != nil {
+				if isGiteaNotFound(err) {
+					continue
+				}
+				return "", fmt.Errorf("failed to get entry %s: %w", gistID, err)
+			}
+		}
+
+		file, err := g.findPromptFile(ctx, repo, gistID)
+		if err != nil {
+			if isGiteaNotFound(err) {
+				continue
+			}
+			return "", fmt.Errorf("failed to get entry %s: %w", gistID, err)
+		}
+
+		content, _, err := g.readFile(ctx, repo, file.Path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read entry %s: %w", gistID, err)
+		}
+		return content, nil
+	}
+
+	return "", errors.NewAppError(errors.ErrNotFound, fmt.Sprintf("no prompt file found in entry %s", gistID), nil)
+}
+
+// CreatePublicGist creates an entry in the public shared repository
+func (g *GiteaStore) CreatePublicGist(ctx context.Context, prompt model.Prompt) (string, error) {
+	if err := g.ensureInitialized(ctx); err != nil {
+		return "", err
+	}
+
+	if err := g.ensureRepo(ctx, GiteaSharedRepo, false, g.owner); err != nil {
+		return "", errors.NewShareError("创建公开仓库", "", err)
+	}
+
+	id, err := newEntryID()
+	if err != nil {
+		return "", errors.NewShareError("创建公开条目", "", err)
+	}
+
+	if err := g.writeEntry(ctx, GiteaSharedRepo, id, prompt.Name+".yaml", buildYAMLContent(prompt), "Share prompt: "+prompt.Name); err != nil {
+		return "", errors.NewShareError("创建公开条目", "", err)
+	}
+
+	return g.entryURL(GiteaSharedRepo, id), nil
+}
+
+// UpdateGist replaces the content of an existing shared entry
+func (g *GiteaStore) UpdateGist(ctx context.Context, gistURL string, prompt model.Prompt) error {
+	if err := g.ensureInitialized(ctx); err != nil {
+		return err
+	}
+
+	repo := g.repoFromURL(gistURL)
+	if _, err := g.loadRepo(ctx, repo, g.owner); err != nil {
+		return errors.NewShareError("获取现有条目", gistURL, err)
+	}
+
+	id := utils.ExtractGistIDFromURL(gistURL)
+	if _, err := g.findPromptFile(ctx, repo, id); err != nil {
+		return errors.NewShareError("获取现有条目", gistURL, err)
+	}
+
+	if err := g.writeEntry(ctx, repo, id, prompt.Name+".yaml", buildYAMLContent(prompt), "Update shared prompt: "+prompt.Name); err != nil {
+		return errors.NewShareError("更新条目", gistURL, err)
+	}
+
+	return nil
+}
+
+// GetGistInfo returns basic information about an entry
+func (g *GiteaStore) GetGistInfo(ctx context.Context, gistURL string) (*GistInfo, error) {
+	if err := g.ensureInitialized(ctx); err != nil {
+		return nil, err
+	}
+
+	id := utils.ExtractGistIDFromURL(gistURL)
+	notFound := &GistInfo{
+		ID:        id,
+		URL:       gistURL,
+		IsPublic:  false,
+		HasAccess: false,
+	}
+
+	repoName := g.repoFromURL(gistURL)
+	repo, err := g.loadRepo(ctx, repoName, g.owner)
+	if err != nil {
+		if isGiteaNotFound(err) {
+			return notFound, nil
+		}
+		return nil, errors.NewShareError("获取条目信息", gistURL, err)
+	}
+
+	if _, err := g.findPromptFile(ctx, repoName, id); err != nil {
+		if isGiteaNotFound(err) {
+			return notFound, nil
+		}
+		return nil, errors.NewShareError("获取条目信息", gistURL, err)
+	}
+
+	return &GistInfo{
+		ID:          id,
+		URL:         gistURL,
+		IsPublic:    !repo.Private,
+		HasAccess:   true,
+		Description: repo.Description,
+		Owner:       g.owner,
+	}, nil
+}
+
+// AddExport adds a new export record
+func (g *GiteaStore) AddExport(ctx context.Context, prompt model.IndexedPrompt) error {
+	if err := g.ensureInitialized(ctx); err != nil {
+		return err
+	}
+
+	index, sha, err := g.loadIndex(ctx)
+	if err != nil {
+		return err
+	}
+
+	index.Exports = append(index.Exports, prompt)
+	return g.saveIndex(ctx, index, sha, "Add export: "+prompt.Name)
+}
+
+// UpdateExport updates an existing export record or adds it if missing
+func (g *GiteaStore) UpdateExport(ctx context.Context, prompt model.IndexedPrompt) error {
+	if err := g.ensureInitialized(ctx); err != nil {
+		return err
+	}
+
+	index, sha, err := g.loadIndex(ctx)
+	if err != nil {
+		return err
+	}
+
+	message := "Update export: " + prompt.Name
+	for i, export := range index.Exports {
+		if export.GistURL == prompt.GistURL {
+			index.Exports[i] = prompt
+			return g.saveIndex(ctx, index, sha, message)
+		}
+	}
+
+	index.Exports = append(index.Exports, prompt)
+	return g.saveIndex(ctx, index, sha, message)
+}
+
+// GetExports returns all export records
+func (g *GiteaStore) GetExports(ctx context.Context) ([]model.IndexedPrompt, error) {
+	if err := g.ensureInitialized(ctx); err != nil {
+		return nil, err
+	}
+
+	index, _, err := g.loadIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return index.Exports, nil
+}
@@ -0,0 +1,323 @@
+package infra
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/grigri/pv/internal/model"
+)
+
+// fakeGiteaRepo is a repository held by fakeGitea
+type fakeGiteaRepo struct {
+	private bool
+	files   map[string]string
+	commits []string
+}
+
+// fakeGitea is an in-memory stand-in for the Gitea repository and contents API
+type fakeGitea struct {
+	mu     sync.Mutex
+	server *httptest.Server
+	repos  map[string]*fakeGiteaRepo
+}
+
+func newFakeGitea(t *testing.T) *fakeGitea {
+	t.Helper()
+	f := &fakeGitea{repos: map[string]*fakeGiteaRepo{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/user", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{"login": "alice"})
+	})
+	mux.HandleFunc("GET /api/v1/repos/alice/{repo}", f.getRepo)
+	mux.HandleFunc("POST /api/v1/user/repos", f.createRepo)
+	mux.HandleFunc("GET /api/v1/repos/alice/{repo}/contents/{path...}", f.getContents)
+	mux.HandleFunc("POST /api/v1/repos/alice/{repo}/contents/{path...}", f.writeContents)
+	mux.HandleFunc("PUT /api/v1/repos/alice/{repo}/contents/{path...}", f.writeContents)
+	mux.HandleFunc("DELETE /api/v1/repos/alice/{repo}/contents/{path...}", f.deleteContents)
+
+	f.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "token test-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+func fakeSHA(content string) string {
+	sum := sha1.Sum([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+func (f *fakeGitea) repo(w http.ResponseWriter, r *http.Request) *fakeGiteaRepo {
+	repo, ok := f.repos[r.PathValue("repo")]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return nil
+	}
+	return repo
+}
+
+func (f *fakeGitea) getRepo(w http.ResponseWriter, r *http.Request) {
+	if repo := f.repo(w, r); repo != nil {
+		json.NewEncoder(w).Encode(giteaRepo{Name: r.PathValue("repo"), Private: repo.private, DefaultBranch: "main"})
+	}
+}
+
+func (f *fakeGitea) createRepo(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Name    string `json:"name"`
+		Private bool   `json:"private"`
+	}
+	json.NewDecoder(r.Body).Decode(&req)
+	if _, ok := f.repos[req.Name]; ok {
+		w.WriteHeader(http.StatusConflict)
+		return
+	}
+
+	f.repos[req.Name] = &fakeGiteaRepo{private: req.Private, files: map[string]string{"README.md": ""}}
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(giteaRepo{Name: req.Name, Private: req.Private, DefaultBranch: "main"})
+}
+
+func (f *fakeGitea) getContents(w http.ResponseWriter, r *http.Request) {
+	repo := f.repo(w, r)
+	if repo == nil {
+		return
+	}
+	filePath := r.PathValue("path")
+
+	if content, ok := repo.files[filePath]; ok {
+		json.NewEncoder(w).Encode(giteaContent{
+			Name:     path.Base(filePath),
+			Path:     filePath,
+			SHA:      fakeSHA(content),
+			Type:     "file",
+			Content:  base64.StdEncoding.EncodeToString([]byte(content)),
+			Encoding: "base64",
+		})
+		return
+	}
+
+	entries := []giteaContent{}
+	for name, content := range repo.files {
+		if strings.HasPrefix(name, filePath+"/") && !strings.Contains(strings.TrimPrefix(name, filePath+"/"), "/") {
+			entries = append(entries, giteaContent{Name: path.Base(name), Path: name, SHA: fakeSHA(content), Type: "file"})
+		}
+	}
+	if len(entries) == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	json.NewEncoder(w).Encode(entries)
+}
+
+func (f *fakeGitea) writeContents(w http.ResponseWriter, r *http.Request) {
+	repo := f.repo(w, r)
+	if repo == nil {
+		return
+	}
+	filePath := r.PathValue("path")
+
+	var req giteaFileRequest
+	json.NewDecoder(r.Body).Decode(&req)
+
+	existing, exists := repo.files[filePath]
+	switch {
+	case r.Method == http.MethodPost && exists:
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		return
+	case r.Method == http.MethodPut && (!exists || req.SHA != fakeSHA(existing)):
+		w.WriteHeader(http.StatusConflict)
+		return
+	}
+
+	data, _ := base64.StdEncoding.DecodeString(req.Content)
+	repo.files[filePath] = string(data)
+	repo.commits = append(repo.commits, req.Message)
+	w.WriteHeader(http.StatusCreated)
+	w.Write([]byte("{}"))
+}
+
+func (f *fakeGitea) deleteContents(w http.ResponseWriter, r *http.Request) {
+	repo := f.repo(w, r)
+	if repo == nil {
+		return
+	}
+	filePath := r.PathValue("path")
+
+	var req giteaFileRequest
+	json.NewDecoder(r.Body).Decode(&req)
+
+	existing, exists := repo.files[filePath]
+	if !exists || req.SHA != fakeSHA(existing) {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	delete(repo.files, filePath)
+	repo.commits = append(repo.commits, req.Message)
+	w.Write([]byte("{}"))
+}
+
+func TestGiteaStore_CreatesPrivateRepositoryAndIndex(t *testing.T) {
+	fake := newFakeGitea(t)
+	store := NewGiteaStore(&MockConfigStore{}, fake.server.URL)
+
+	if _, err := store.List(context.Background()); err != ErrEmptyIndex {
+		t.Fatalf("Expected ErrEmptyIndex, got: %v", err)
+	}
+
+	repo := fake.repos[GiteaPromptsRepo]
+	if repo == nil || !repo.private {
+		t.Fatalf("Expected private %s repository, got %+v", GiteaPromptsRepo, repo)
+	}
+	if _, ok := repo.files[IndexFileName]; !ok {
+		t.Error("Expected index.json to be created")
+	}
+	if _, ok := fake.repos[GiteaSharedRepo]; ok {
+		t.Error("Expected shared repository to be created only when sharing")
+	}
+}
+
+func TestGiteaStore_AddUpdateDelete(t *testing.T) {
+	fake := newFakeGitea(t)
+	store := NewGiteaStore(&MockConfigStore{}, fake.server.URL)
+
+	if err := store.Add(context.Background(), model.Prompt{Name: "Code Review", Author: "alice", Content: testPromptContent}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	prompts, err := store.List(context.Background())
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(prompts) != 1 {
+		t.Fatalf("Expected 1 prompt, got %d", len(prompts))
+	}
+
+	got := prompts[0]
+	wantURL := fake.server.URL + "/alice/" + GiteaPromptsRepo + "/src/branch/main/" + got.ID
+	if got.GistURL != wantURL {
+		t.Errorf("GistURL = %q, want %q", got.GistURL, wantURL)
+	}
+
+	content, err := store.GetContent(context.Background(), got.ID)
+	if err != nil {
+		t.Fatalf("GetContent failed: %v", err)
+	}
+	if content != testPromptContent {
+		t.Errorf("GetContent = %q, want %q", content, testPromptContent)
+	}
+
+	// Adding the same name and author again updates the existing entry
+	if err := store.Add(context.Background(), model.Prompt{Name: "Code Review", Author: "alice", Content: "v2"}); err != nil {
+		t.Fatalf("Second Add failed: %v", err)
+	}
+	if content, _ := store.GetContent(context.Background(), got.ID); content != "v2" {
+		t.Errorf("Expected updated content, got %q", content)
+	}
+
+	got.Name = "Renamed"
+	got.Content = "v3"
+	if err := store.Update(context.Background(), got); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	files := fake.repos[GiteaPromptsRepo].files
+	if _, ok := files[got.ID+"/Code Review.yaml"]; ok {
+		t.Error("Expected old prompt file to be removed on rename")
+	}
+	if files[got.ID+"/Renamed.yaml"] != "v3" {
+		t.Errorf("Expected renamed prompt file, got %v", files)
+	}
+
+	if err := store.Delete(context.Background(), got.ID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := store.List(context.Background()); err != ErrEmptyIndex {
+		t.Errorf("Expected ErrEmptyIndex after delete, got: %v", err)
+	}
+	if _, err := store.GetContent(context.Background(), got.ID); err == nil {
+		t.Error("Expected error reading a deleted entry")
+	}
+}
+
+func TestGiteaStore_ShareCreatesPublicEntry(t *testing.T) {
+	fake := newFakeGitea(t)
+	store := NewGiteaStore(&MockConfigStore{}, fake.server.URL)
+
+	if err := store.Add(context.Background(), model.Prompt{Name: "Shared", Author: "alice", Content: testPromptContent}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	prompts, _ := store.List(context.Background())
+
+	info, err := store.GetGistInfo(context.Background(), prompts[0].GistURL)
+	if err != nil {
+		t.Fatalf("GetGistInfo failed: %v", err)
+	}
+	if info.IsPublic || !info.HasAccess {
+		t.Errorf("Expected private accessible entry, got %+v", info)
+	}
+
+	publicURL, err := store.CreatePublicGist(context.Background(), model.Prompt{Name: "Shared", Author: "alice", Content: testPromptContent})
+	if err != nil {
+		t.Fatalf("CreatePublicGist failed: %v", err)
+	}
+	if shared := fake.repos[GiteaSharedRepo]; shared == nil || shared.private {
+		t.Fatalf("Expected public %s repository, got %+v", GiteaSharedRepo, shared)
+	}
+
+	info, err = store.GetGistInfo(context.Background(), publicURL)
+	if err != nil {
+		t.Fatalf("GetGistInfo failed: %v", err)
+	}
+	if !info.IsPublic || !info.HasAccess {
+		t.Errorf("Expected public accessible entry, got %+v", info)
+	}
+
+	if err := store.UpdateGist(context.Background(), publicURL, model.Prompt{Name: "Shared", Content: "updated"}); err != nil {
+		t.Fatalf("UpdateGist failed: %v", err)
+	}
+	content, err := store.GetContent(context.Background(), info.ID)
+	if err != nil {
+		t.Fatalf("GetContent failed: %v", err)
+	}
+	if !strings.Contains(content, "updated") {
+		t.Errorf("Expected updated shared content, got %q", content)
+	}
+
+	parentURL := prompts[0].GistURL
+	if err := store.AddExport(context.Background(), model.IndexedPrompt{GistURL: publicURL, Name: "Shared", Parent: &parentURL}); err != nil {
+		t.Fatalf("AddExport failed: %v", err)
+	}
+	exports, err := store.GetExports(context.Background())
+	if err != nil {
+		t.Fatalf("GetExports failed: %v", err)
+	}
+	if len(exports) != 1 || exports[0].GistURL != publicURL {
+		t.Errorf("Unexpected exports: %+v", exports)
+	}
+
+	missing, err := store.GetGistInfo(context.Background(), fake.server.URL+"/alice/"+GiteaSharedRepo+"/src/branch/main/missing")
+	if err != nil {
+		t.Fatalf("GetGistInfo failed: %v", err)
+	}
+	if missing.HasAccess {
+		t.Error("Expected missing entry to report no access")
+	}
+}
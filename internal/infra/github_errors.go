@@ -0,0 +1,58 @@
+package infra
+
+import (
+	"context"
+	stderrors "errors"
+	"net/http"
+
+	"github.com/google/go-github/v74/github"
+	"github.com/grigri/pv/internal/errors"
+	"github.com/grigri/pv/internal/utils"
+)
+
+// classifyGitHubError wraps a failed GitHub API call in an AppError of the matching type,
+// so commands can tell network, authentication and permission failures apart.
+// The original error stays in the chain; other failures, such as 404s, are returned unchanged.
+func classifyGitHubError(err error) error {
+	if err == nil || stderrors.Is(err, context.Canceled) {
+		return err
+	}
+
+	var appErr errors.AppError
+	if stderrors.As(err, &appErr) {
+		return err
+	}
+
+	var rateErr *github.RateLimitError
+	var abuseErr *github.AbuseRateLimitError
+	if stderrors.As(err, &rateErr) || stderrors.As(err, &abuseErr) {
+		return errors.NewAppError(errors.ErrNetwork, "GitHub API 请求过于频繁，请稍后重试", err)
+	}
+
+	var errResp *github.ErrorResponse
+	if stderrors.As(err, &errResp) && errResp.Response != nil {
+		switch code := errResp.Response.StatusCode; {
+		case code == http.StatusUnauthorized:
+			return errors.NewAppError(errors.ErrAuth, "GitHub token 无效或已过期", err)
+		case code == http.StatusForbidden:
+			return errors.NewAppError(errors.ErrPermission, "GitHub 拒绝了此操作，请检查 token 权限", err)
+		case code >= http.StatusInternalServerError:
+			return errors.NewAppError(errors.ErrNetwork, "GitHub API 暂时不可用，请稍后重试", err)
+		}
+		return err
+	}
+
+	if utils.IsTransientNetworkError(err) {
+		return errors.NewAppError(errors.ErrNetwork, errors.ErrGitHubAPIUnavailable.Message, err)
+	}
+	return err
+}
+
+// isTransientGitHubError reports whether a failed GitHub API call may succeed when repeated
+func isTransientGitHubError(err error) bool {
+	var errResp *github.ErrorResponse
+	if stderrors.As(err, &errResp) && errResp.Response != nil {
+		return utils.IsRetryableStatus(errResp.Response.StatusCode)
+	}
+	return utils.IsTransientNetworkError(err)
+}
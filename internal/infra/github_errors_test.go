@@ -0,0 +1,175 @@
+package infra
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"syscall"
+	"testing"
+
+	"github.com/google/go-github/v74/github"
+	"github.com/grigri/pv/internal/errors"
+	"github.com/grigri/pv/internal/model"
+)
+
+func TestClassifyGitHubError(t *testing.T) {
+	statusErr := func(code int) error {
+		return fmt.Errorf("failed to get gist: %w", &github.ErrorResponse{Response: &http.Response{StatusCode: code}})
+	}
+
+	tests := []struct {
+		name     string
+		err      error
+		wantType errors.ErrorType
+		wantApp  bool
+	}{
+		{"unauthorized", statusErr(http.StatusUnauthorized), errors.ErrAuth, true},
+		{"forbidden", statusErr(http.StatusForbidden), errors.ErrPermission, true},
+		{"server error", statusErr(http.StatusBadGateway), errors.ErrNetwork, true},
+		{"rate limited", &github.RateLimitError{}, errors.ErrNetwork, true},
+		{"connection reset", fmt.Errorf("read: %w", syscall.ECONNRESET), errors.ErrNetwork, true},
+		{"not found stays unchanged", statusErr(http.StatusNotFound), 0, false},
+		{"cancellation stays unchanged", context.Canceled, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := classifyGitHubError(tt.err)
+
+			var appErr errors.AppError
+			if got := stderrors.As(err, &appErr); got != tt.wantApp {
+				t.Fatalf("Expected AppError %v, got %v", tt.wantApp, err)
+			}
+			if tt.wantApp && appErr.Type != tt.wantType {
+				t.Errorf("Expected type %v, got %v", tt.wantType, appErr.Type)
+			}
+			if !stderrors.Is(err, tt.err) {
+				t.Errorf("Expected the original error to stay in the chain, got %v", err)
+			}
+		})
+	}
+}
+
+func TestGitHubStore_RetriesTransientFailures(t *testing.T) {
+	fake := newFakeGistAPI(t)
+	store := fake.newStore(t)
+
+	prompt := model.Prompt{Name: "Retried", Author: "alice", Content: "content"}
+	if err := store.Add(context.Background(), prompt); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	// The next read of a gist hits a 502 once
+	failed := false
+	fake.mu.Lock()
+	fake.fail = func(r *http.Request) (int, bool) {
+		if r.Method == http.MethodGet && !failed {
+			failed = true
+			return http.StatusBadGateway, false
+		}
+		return 0, false
+	}
+	fake.mu.Unlock()
+
+	prompts, err := store.List(context.Background())
+	if err != nil || len(prompts) != 1 {
+		t.Fatalf("Expected List to succeed after a retry, got %v, %v", prompts, err)
+	}
+	if !failed {
+		t.Error("Expected the injected failure to be hit")
+	}
+}
+
+func TestGitHubStore_CreateGistDeduplicates(t *testing.T) {
+	fake := newFakeGistAPI(t)
+	store := fake.newStore(t)
+
+	if err := store.ensureInitialized(context.Background()); err != nil {
+		t.Fatalf("ensureInitialized failed: %v", err)
+	}
+
+	// The gist gets created but the response is lost
+	creates := 0
+	fake.mu.Lock()
+	fake.fail = func(r *http.Request) (int, bool) {
+		if r.Method == http.MethodPost {
+			creates++
+			if creates == 1 {
+				return http.StatusBadGateway, true
+			}
+		}
+		return 0, false
+	}
+	fake.mu.Unlock()
+
+	url, err := store.CreatePublicGist(context.Background(), model.Prompt{Name: "Shared", Description: "shared prompt", Content: "content"})
+	if err != nil {
+		t.Fatalf("CreatePublicGist failed: %v", err)
+	}
+
+	if creates != 1 {
+		t.Errorf("Expected the creation not to be repeated, got %d POSTs", creates)
+	}
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	if len(fake.gists) != 2 {
+		t.Errorf("Expected the index gist and one shared gist, got %d gists", len(fake.gists))
+	}
+	if url == "" {
+		t.Error("Expected the URL of the gist created by the lost request")
+	}
+}
+
+func TestGitHubStore_ClassifiesFinalFailures(t *testing.T) {
+	fake := newFakeGistAPI(t)
+	store := fake.newStore(t)
+
+	fake.mu.Lock()
+	fake.fail = func(r *http.Request) (int, bool) {
+		return http.StatusForbidden, false
+	}
+	fake.mu.Unlock()
+
+	_, err := store.GetRawIndexContent(context.Background())
+	if errors.TypeOf(err, errors.ErrUnknown) != errors.ErrPermission {
+		t.Errorf("Expected a permission error, got %v", err)
+	}
+}
+
+func TestGitHubStore_ClassifiesEditFailures(t *testing.T) {
+	fake := newFakeGistAPI(t)
+	store := fake.newStore(t)
+
+	prompt := model.Prompt{Name: "Edited", Author: "alice", Content: "content"}
+	if err := store.Add(context.Background(), prompt); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	prompts, err := store.List(context.Background())
+	if err != nil || len(prompts) != 1 {
+		t.Fatalf("List failed: %v, %v", prompts, err)
+	}
+	url, err := store.CreatePublicGist(context.Background(), prompt)
+	if err != nil {
+		t.Fatalf("CreatePublicGist failed: %v", err)
+	}
+
+	// Every edit of a gist is rejected
+	fake.mu.Lock()
+	fake.fail = func(r *http.Request) (int, bool) {
+		if r.Method == http.MethodPatch {
+			return http.StatusUnauthorized, false
+		}
+		return 0, false
+	}
+	fake.mu.Unlock()
+
+	prompt.ID = prompts[0].ID
+	prompt.Content = "new content"
+	if err := store.Update(context.Background(), prompt); errors.TypeOf(err, errors.ErrUnknown) != errors.ErrAuth {
+		t.Errorf("Expected Update to fail with an auth error, got %v", err)
+	}
+	if err := store.UpdateGist(context.Background(), url, prompt); errors.TypeOf(err, errors.ErrUnknown) != errors.ErrAuth {
+		t.Errorf("Expected UpdateGist to fail with an auth error, got %v", err)
+	}
+}
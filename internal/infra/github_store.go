@@ -2,8 +2,8 @@ package infra
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"net/http"
 	"strings"
 	"time"
 
@@ -33,17 +33,51 @@ type GitHubStore struct {
 	client      *github.Client
 	configStore config.Store
 	indexGistID string
+	endpoints   utils.GitHubEndpoints
+
+	// indexBase snapshots the index as last read by loadIndex so saveIndex
+	// can detect and merge concurrent modifications
+	indexBase *model.Index
+
+	// limiter paces prompt gist requests according to the API rate limit
+	limiter *rateLimiter
+
+	// responses keeps gist responses for conditional requests; nil disables them
+	responses ResponseCache
 }
 
+// maxIndexWriteAttempts bounds the merge-and-retry loop of saveIndex
+const maxIndexWriteAttempts = 3
+
+// maxCreateGistRetries bounds how often a gist creation that failed transiently is retried
+const maxCreateGistRetries = 2
+
 // NewGitHubStore creates a new GitHubStore instance
 func NewGitHubStore(configStore config.Store) Store {
 	return &GitHubStore{
 		configStore: configStore,
+		limiter:     newRateLimiter(),
+	}
+}
+
+// NewGitHubEnterpriseStore creates a GitHubStore talking to the given endpoints
+// It is used for GitHub Enterprise Server; zero endpoints target github.com
+func NewGitHubEnterpriseStore(configStore config.Store, endpoints utils.GitHubEndpoints) Store {
+	return &GitHubStore{
+		configStore: configStore,
+		endpoints:   endpoints,
+		limiter:     newRateLimiter(),
 	}
 }
 
+// SetResponseCache makes gist reads conditional requests revalidated against cache
+// It has to be called before the first request.
+func (g *GitHubStore) SetResponseCache(cache ResponseCache) {
+	g.responses = cache
+}
+
 // ensureInitialized ensures the GitHub client is initialized
-func (g *GitHubStore) ensureInitialized() error {
+func (g *GitHubStore) ensureInitialized(ctx context.Context) error {
 	if g.client != nil {
 		return nil
 	}
@@ -61,11 +95,25 @@ func (g *GitHubStore) ensureInitialized() error {
 	ts := oauth2.StaticTokenSource(
 		&oauth2.Token{AccessToken: token},
 	)
-	tc := oauth2.NewClient(context.Background(), ts)
-	g.client = github.NewClient(tc)
+	// Transient failures of idempotent requests are retried with backoff, and
+	// unchanged gists are served from the response cache after a 304
+	transport := utils.NewRetryTransport(nil)
+	if g.responses != nil {
+		transport = &conditionalTransport{base: transport, cache: g.responses}
+	}
+	baseClient := &http.Client{Transport: transport}
+	tc := oauth2.NewClient(context.WithValue(context.Background(), oauth2.HTTPClient, baseClient), ts)
+	client := github.NewClient(tc)
+	if g.endpoints.IsEnterprise() {
+		client, err = client.WithEnterpriseURLs(g.endpoints.APIURL, g.endpoints.UploadURL)
+		if err != nil {
+			return fmt.Errorf("invalid GitHub Enterprise URLs: %w", err)
+		}
+	}
+	g.client = client
 
 	// Initialize index gist
-	if err := g.initializeIndex(); err != nil {
+	if err := g.initializeIndex(ctx); err != nil {
 		return fmt.Errorf("failed to initialize index: %w", err)
 	}
 
@@ -73,13 +121,11 @@ func (g *GitHubStore) ensureInitialized() error {
 }
 
 // initializeIndex finds or creates the index gist
-func (g *GitHubStore) initializeIndex() error {
-	ctx := context.Background()
-
+func (g *GitHubStore) initializeIndex(ctx context.Context) error {
 	// First, try to find existing index gist
 	gists, _, err := g.client.Gists.List(ctx, "", &github.GistListOptions{})
 	if err != nil {
-		return fmt.Errorf("failed to list gists: %w", err)
+		return fmt.Errorf("failed to list gists: %w", classifyGitHubError(err))
 	}
 
 	for _, gist := range gists {
@@ -96,7 +142,7 @@ func (g *GitHubStore) initializeIndex() error {
 		LastUpdated: time.Now(),
 	}
 
-	indexContent, err := json.MarshalIndent(emptyIndex, "", "  ")
+	indexContent, err := encodeIndex(&emptyIndex)
 	if err != nil {
 		return fmt.Errorf("failed to marshal empty index: %w", err)
 	}
@@ -111,9 +157,9 @@ func (g *GitHubStore) initializeIndex() error {
 		},
 	}
 
-	createdGist, _, err := g.client.Gists.Create(ctx, gist)
+	createdGist, err := g.createGist(ctx, gist)
 	if err != nil {
-		return fmt.Errorf("failed to create index gist: %w", err)
+		return fmt.Errorf("failed to create index gist: %w", classifyGitHubError(err))
 	}
 
 	g.indexGistID = createdGist.GetID()
@@ -121,38 +167,46 @@ func (g *GitHubStore) initializeIndex() error {
 }
 
 // loadIndex loads the current index from the index gist
-func (g *GitHubStore) loadIndex() (*model.Index, error) {
-	ctx := context.Background()
-
+// The index and its gist revision are remembered as the base for the next saveIndex
+func (g *GitHubStore) loadIndex(ctx context.Context) (*model.Index, error) {
 	gist, _, err := g.client.Gists.Get(ctx, g.indexGistID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get index gist: %w", err)
+		return nil, fmt.Errorf("failed to get index gist: %w", classifyGitHubError(err))
+	}
+
+	index, err := parseIndexGist(gist)
+	if err != nil {
+		return nil, err
 	}
 
+	g.indexBase = cloneIndex(index)
+	return index, nil
+}
+
+// parseIndexGist decodes index.json from an index gist
+func parseIndexGist(gist *github.Gist) (*model.Index, error) {
 	indexFile, exists := gist.Files[IndexFileName]
 	if !exists {
 		return nil, fmt.Errorf("index file not found in gist")
 	}
 
-	var index model.Index
-	if err := json.Unmarshal([]byte(indexFile.GetContent()), &index); err != nil {
+	index, err := DecodeIndex([]byte(indexFile.GetContent()))
+	if err != nil {
 		return nil, fmt.Errorf("failed to unmarshal index: %w", err)
 	}
 
-	return &index, nil
+	return index, nil
 }
 
 // GetRawIndexContent retrieves the raw index.json content from GitHub
-func (g *GitHubStore) GetRawIndexContent() (string, error) {
-	if err := g.ensureInitialized(); err != nil {
+func (g *GitHubStore) GetRawIndexContent(ctx context.Context) (string, error) {
+	if err := g.ensureInitialized(ctx); err != nil {
 		return "", err
 	}
 
-	ctx := context.Background()
-
 	gist, _, err := g.client.Gists.Get(ctx, g.indexGistID)
 	if err != nil {
-		return "", fmt.Errorf("failed to get index gist: %w", err)
+		return "", fmt.Errorf("failed to get index gist: %w", classifyGitHubError(err))
 	}
 
 	indexFile, exists := gist.Files[IndexFileName]
@@ -164,14 +218,101 @@ func (g *GitHubStore) GetRawIndexContent() (string, error) {
 }
 
 // saveIndex saves the index to the index gist
-func (g *GitHubStore) saveIndex(index *model.Index) error {
-	ctx := context.Background()
+// Gists have no conditional writes, so the index read by loadIndex is compared
+// with the remote one before writing, and the gist history is checked after
+// writing. When someone else changed the index in between, both versions are
+// merged by gist URL and the write is retried; ErrIndexConflict is returned
+// when the same entry was changed on both sides.
+func (g *GitHubStore) saveIndex(ctx context.Context, index *model.Index) error {
+	base := g.indexBase
+
+	for attempt := 0; attempt < maxIndexWriteAttempts; attempt++ {
+		baseRevision, err := g.indexRevisions(ctx, 1)
+		if err != nil {
+			return err
+		}
+
+		current, _, err := g.client.Gists.Get(ctx, g.indexGistID)
+		if err != nil {
+			return fmt.Errorf("failed to get index gist: %w", classifyGitHubError(err))
+		}
+
+		theirs, err := parseIndexGist(current)
+		if err != nil {
+			return err
+		}
+
+		if base != nil && !sameIndexEntries(base, theirs) {
+			merged, err := mergeIndex(base, index, theirs)
+			if err != nil {
+				return err
+			}
+			index = merged
+		}
+		base = theirs
+
+		if _, err := g.writeIndex(ctx, index); err != nil {
+			return err
+		}
+
+		// A write landing between our check and our edit shows up as an
+		// unexpected revision right before ours in the history
+		revisions, err := g.indexRevisions(ctx, 2)
+		if err != nil {
+			return err
+		}
+
+		if len(baseRevision) == 0 || len(revisions) < 2 || revisions[0] == baseRevision[0] || revisions[1] == baseRevision[0] {
+			g.indexBase = cloneIndex(index)
+			return nil
+		}
+
+		revision, _, err := g.client.Gists.GetRevision(ctx, g.indexGistID, revisions[1])
+		if err != nil {
+			return fmt.Errorf("failed to get index gist revision %s: %w", revisions[1], classifyGitHubError(err))
+		}
+
+		intervening, err := parseIndexGist(revision)
+		if err != nil {
+			return err
+		}
+
+		merged, err := mergeIndex(base, index, intervening)
+		if err != nil {
+			return err
+		}
+
+		// What we just wrote is now the remote state to build on
+		index, base = merged, cloneIndex(index)
+	}
+
+	return fmt.Errorf("%w: gave up after %d attempts", ErrIndexConflict, maxIndexWriteAttempts)
+}
+
+// indexRevisions returns up to n of the latest index gist revisions, newest first
+func (g *GitHubStore) indexRevisions(ctx context.Context, n int) ([]string, error) {
+	commits, _, err := g.client.Gists.ListCommits(ctx, g.indexGistID, &github.ListOptions{PerPage: n})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list index gist revisions: %w", classifyGitHubError(err))
+	}
 
+	var revisions []string
+	for _, commit := range commits {
+		if len(revisions) == n {
+			break
+		}
+		revisions = append(revisions, commit.GetVersion())
+	}
+	return revisions, nil
+}
+
+// writeIndex writes the index content to the index gist
+func (g *GitHubStore) writeIndex(ctx context.Context, index *model.Index) (*github.Gist, error) {
 	index.LastUpdated = time.Now()
 
-	indexContent, err := json.MarshalIndent(index, "", "  ")
+	indexContent, err := encodeIndex(index)
 	if err != nil {
-		return fmt.Errorf("failed to marshal index: %w", err)
+		return nil, fmt.Errorf("failed to marshal index: %w", err)
 	}
 
 	gist := &github.Gist{
@@ -182,21 +323,21 @@ func (g *GitHubStore) saveIndex(index *model.Index) error {
 		},
 	}
 
-	_, _, err = g.client.Gists.Edit(ctx, g.indexGistID, gist)
+	written, _, err := g.client.Gists.Edit(ctx, g.indexGistID, gist)
 	if err != nil {
-		return fmt.Errorf("failed to update index gist: %w", err)
+		return nil, fmt.Errorf("failed to update index gist: %w", classifyGitHubError(err))
 	}
 
-	return nil
+	return written, nil
 }
 
 // List returns all prompts from the index
-func (g *GitHubStore) List() ([]model.Prompt, error) {
-	if err := g.ensureInitialized(); err != nil {
+func (g *GitHubStore) List(ctx context.Context) ([]model.Prompt, error) {
+	if err := g.ensureInitialized(ctx); err != nil {
 		return nil, err
 	}
 
-	index, err := g.loadIndex()
+	index, err := g.loadIndex(ctx)
 	if err != nil {
 		// Check if this is because no index gist exists (404 error specifically)
 		if strings.Contains(err.Error(), "failed to get index gist") && strings.Contains(err.Error(), "404") {
@@ -210,36 +351,43 @@ func (g *GitHubStore) List() ([]model.Prompt, error) {
 		return nil, ErrEmptyIndex
 	}
 
-	var prompts []model.Prompt
-	ctx := context.Background()
-
+	// Check that each prompt's gist is still accessible, several at a time
+	filePaths := make(map[string]string)
+	var gistIDs []string
 	for _, indexedPrompt := range index.Prompts {
-		// Extract gist ID from URL
 		gistID := utils.ExtractGistIDFromURL(indexedPrompt.GistURL)
 		if gistID == "" {
 			continue
 		}
+		if _, seen := filePaths[gistID]; !seen {
+			gistIDs = append(gistIDs, gistID)
+		}
+		filePaths[gistID] = indexedPrompt.FilePath
+	}
 
-		// Get gist details
-		gist, _, err := g.client.Gists.Get(ctx, gistID)
+	accessible, err := fetchConcurrently(ctx, gistIDs, DefaultFetchWorkers, func(ctx context.Context, gistID string) (string, error) {
+		gist, err := g.getGist(ctx, gistID)
 		if err != nil {
-			continue // Skip if gist is not accessible
+			return "", err
 		}
-
-		// Find the prompt file
-		_, exists := gist.Files[github.GistFilename(indexedPrompt.FilePath)]
-		if !exists {
-			continue
+		if _, exists := gist.Files[github.GistFilename(filePaths[gistID])]; !exists {
+			return "", fmt.Errorf("prompt file missing from gist %s", gistID)
 		}
+		return "", nil
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
 
-		prompt := model.Prompt{
-			ID:      gistID,
-			Name:    indexedPrompt.Name,   // 直接使用索引中的 name
-			Author:  indexedPrompt.Author, // 直接使用索引中的 author
-			GistURL: indexedPrompt.GistURL,
+	var prompts []model.Prompt
+	for _, indexedPrompt := range index.Prompts {
+		gistID := utils.ExtractGistIDFromURL(indexedPrompt.GistURL)
+		if _, ok := accessible[gistID]; !ok {
+			continue // Skip if gist is not accessible
 		}
 
-		prompts = append(prompts, prompt)
+		// 直接使用索引中的 name、author 等元数据
+		prompts = append(prompts, promptFromIndex(gistID, indexedPrompt))
 	}
 
 	// If we processed some indexed prompts but none were accessible, treat as empty
@@ -251,12 +399,12 @@ func (g *GitHubStore) List() ([]model.Prompt, error) {
 }
 
 // findExistingPrompt searches for an existing prompt with the same name and author
-func (g *GitHubStore) findExistingPrompt(name, author string) (*model.Prompt, error) {
-	if err := g.ensureInitialized(); err != nil {
+func (g *GitHubStore) findExistingPrompt(ctx context.Context, name, author string) (*model.Prompt, error) {
+	if err := g.ensureInitialized(ctx); err != nil {
 		return nil, err
 	}
 
-	allPrompts, err := g.List()
+	allPrompts, err := g.List(ctx)
 	if err != nil {
 		// If we get ErrNoIndex or ErrEmptyIndex, that's fine - no existing prompts
 		if err == ErrNoIndex || err == ErrEmptyIndex {
@@ -264,11 +412,8 @@ func (g *GitHubStore) findExistingPrompt(name, author string) (*model.Prompt, er
 		}
 		return nil, err
 	}
-	fmt.Println("allPrompts:", allPrompts)
 
 	for _, prompt := range allPrompts {
-		fmt.Println("name equals:", prompt.Name, name, prompt.Name == name)
-		fmt.Println("author equals:", prompt.Author, author, prompt.Author == author)
 		if prompt.Name == name && prompt.Author == author {
 			return &prompt, nil
 		}
@@ -278,12 +423,12 @@ func (g *GitHubStore) findExistingPrompt(name, author string) (*model.Prompt, er
 }
 
 // FindExistingPromptByURL 根据 gist URL 查找已存在的提示词
-func (g *GitHubStore) FindExistingPromptByURL(gistURL string) (*model.Prompt, error) {
-	if err := g.ensureInitialized(); err != nil {
+func (g *GitHubStore) FindExistingPromptByURL(ctx context.Context, gistURL string) (*model.Prompt, error) {
+	if err := g.ensureInitialized(ctx); err != nil {
 		return nil, err
 	}
 
-	allPrompts, err := g.List()
+	allPrompts, err := g.List(ctx)
 	if err != nil {
 		// If we get ErrNoIndex or ErrEmptyIndex, that's fine - no existing prompts
 		if err == ErrNoIndex || err == ErrEmptyIndex {
@@ -303,22 +448,17 @@ func (g *GitHubStore) FindExistingPromptByURL(gistURL string) (*model.Prompt, er
 
 // Add creates a new prompt gist and updates the index
 
-func (g *GitHubStore) Add(prompt model.Prompt) error {
-	if err := g.ensureInitialized(); err != nil {
+func (g *GitHubStore) Add(ctx context.Context, prompt model.Prompt) error {
+	if err := g.ensureInitialized(ctx); err != nil {
 		return err
 	}
 
 	// Check if a prompt with the same name and author already exists
-	existingPrompt, err := g.findExistingPrompt(prompt.Name, prompt.Author)
+	existingPrompt, err := g.findExistingPrompt(ctx, prompt.Name, prompt.Author)
 	if err != nil {
 		return fmt.Errorf("failed to check for existing prompt: %w", err)
 	}
 
-	fmt.Println(
-		"existingPrompt",
-		existingPrompt,
-	)
-
 	// If an existing prompt is found, update it instead of creating a new one
 	if existingPrompt != nil {
 		// Copy the new content and other updatable fields to the existing prompt
@@ -326,14 +466,86 @@ func (g *GitHubStore) Add(prompt model.Prompt) error {
 		existingPrompt.Description = prompt.Description
 		existingPrompt.Tags = prompt.Tags
 		existingPrompt.Version = prompt.Version
+		existingPrompt.Format = prompt.Format
+
+		return g.Update(ctx, *existingPrompt)
+	}
+
+	indexedPrompt, err := g.createPromptGist(ctx, prompt)
+	if err != nil {
+		return err
+	}
+
+	// Update index
+	index, err := g.loadIndex(ctx)
+	if err != nil {
+		return err
+	}
+
+	index.Prompts = append(index.Prompts, indexedPrompt)
+
+	return g.saveIndex(ctx, index)
+}
+
+// AddBatch adds several prompts like Add, writing the index once after all of them
+// A prompt with the name and author of an indexed prompt updates it. The returned prompts
+// carry the gist they are stored in; when a prompt fails, the ones stored before it are
+// returned and recorded in the index along with the error.
+func (g *GitHubStore) AddBatch(ctx context.Context, prompts []model.Prompt) ([]model.Prompt, error) {
+	if err := g.ensureInitialized(ctx); err != nil {
+		return nil, err
+	}
+
+	index, err := g.loadIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var stored []model.Prompt
+	var addErr error
+	for _, prompt := range prompts {
+		existing := -1
+		for i, indexedPrompt := range index.Prompts {
+			if indexedPrompt.Name == prompt.Name && indexedPrompt.Author == prompt.Author {
+				existing = i
+				break
+			}
+		}
 
-		return g.Update(*existingPrompt)
+		if existing >= 0 {
+			prompt.GistURL = index.Prompts[existing].GistURL
+			prompt.ID = utils.ExtractGistIDFromURL(prompt.GistURL)
+			renamedTo, err := g.editPromptGist(ctx, prompt)
+			if err != nil {
+				addErr = err
+				break
+			}
+			updateIndexedPrompt(&index.Prompts[existing], prompt, renamedTo)
+		} else {
+			indexedPrompt, err := g.createPromptGist(ctx, prompt)
+			if err != nil {
+				addErr = err
+				break
+			}
+			index.Prompts = append(index.Prompts, indexedPrompt)
+			prompt.GistURL = indexedPrompt.GistURL
+			prompt.ID = utils.ExtractGistIDFromURL(prompt.GistURL)
+		}
+		stored = append(stored, prompt)
 	}
 
-	ctx := context.Background()
+	if len(stored) > 0 {
+		if err := g.saveIndex(ctx, index); err != nil {
+			return nil, err
+		}
+	}
+	return stored, addErr
+}
 
-	// Create filename using YAML format from prompt name
-	fileName := prompt.Name + ".yaml"
+// createPromptGist creates the private gist of a new prompt and returns its index entry
+func (g *GitHubStore) createPromptGist(ctx context.Context, prompt model.Prompt) (model.IndexedPrompt, error) {
+	// Create filename from prompt name, with the extension of its format
+	fileName := prompt.FileName()
 
 	// Build gist description with prompt metadata
 	description := fmt.Sprintf("Prompt: %s", prompt.Name)
@@ -352,15 +564,9 @@ func (g *GitHubStore) Add(prompt model.Prompt) error {
 		},
 	}
 
-	createdGist, _, err := g.client.Gists.Create(ctx, gist)
+	createdGist, err := g.createGist(ctx, gist)
 	if err != nil {
-		return fmt.Errorf("failed to create gist: %w", err)
-	}
-
-	// Update index
-	index, err := g.loadIndex()
-	if err != nil {
-		return err
+		return model.IndexedPrompt{}, fmt.Errorf("failed to create gist: %w", classifyGitHubError(err))
 	}
 
 	indexedPrompt := model.IndexedPrompt{
@@ -370,21 +576,17 @@ func (g *GitHubStore) Add(prompt model.Prompt) error {
 		Name:        prompt.Name,   // 使用 YAML 中的 name
 		LastUpdated: time.Now(),
 	}
-
-	index.Prompts = append(index.Prompts, indexedPrompt)
-
-	return g.saveIndex(index)
+	indexedPrompt.ApplyMetadata(prompt)
+	return indexedPrompt, nil
 }
 
 // Delete removes a prompt by deleting its gist and updating the index
-func (g *GitHubStore) Delete(keyword string) error {
-	if err := g.ensureInitialized(); err != nil {
+func (g *GitHubStore) Delete(ctx context.Context, keyword string) error {
+	if err := g.ensureInitialized(ctx); err != nil {
 		return err
 	}
 
-	ctx := context.Background()
-
-	index, err := g.loadIndex()
+	index, err := g.loadIndex(ctx)
 	if err != nil {
 		return err
 	}
@@ -397,7 +599,7 @@ func (g *GitHubStore) Delete(keyword string) error {
 			// Delete the gist
 			_, err := g.client.Gists.Delete(ctx, gistID)
 			if err != nil {
-				return fmt.Errorf("failed to delete gist %s: %w", gistID, err)
+				return fmt.Errorf("failed to delete gist %s: %w", gistID, classifyGitHubError(err))
 			}
 			toDelete = append(toDelete, i)
 		}
@@ -409,34 +611,61 @@ func (g *GitHubStore) Delete(keyword string) error {
 		index.Prompts = append(index.Prompts[:idx], index.Prompts[idx+1:]...)
 	}
 
-	return g.saveIndex(index)
+	return g.saveIndex(ctx, index)
 }
 
 // Update modifies an existing prompt
-func (g *GitHubStore) Update(prompt model.Prompt) error {
-	if err := g.ensureInitialized(); err != nil {
+func (g *GitHubStore) Update(ctx context.Context, prompt model.Prompt) error {
+	if err := g.ensureInitialized(ctx); err != nil {
+		return err
+	}
+
+	renamedTo, err := g.editPromptGist(ctx, prompt)
+	if err != nil {
 		return err
 	}
 
-	ctx := context.Background()
+	// Update index timestamp and filename if changed
+	index, err := g.loadIndex(ctx)
+	if err != nil {
+		return err
+	}
 
+	for i, indexedPrompt := range index.Prompts {
+		if utils.ExtractGistIDFromURL(indexedPrompt.GistURL) == prompt.ID {
+			updateIndexedPrompt(&index.Prompts[i], prompt, renamedTo)
+			break
+		}
+	}
+
+	return g.saveIndex(ctx, index)
+}
+
+// editPromptGist writes the content of prompt to its gist
+// It returns the new file name when the prompt was renamed, otherwise an empty string.
+func (g *GitHubStore) editPromptGist(ctx context.Context, prompt model.Prompt) (string, error) {
 	// Get existing gist
 	existingGist, _, err := g.client.Gists.Get(ctx, prompt.ID)
 	if err != nil {
-		return fmt.Errorf("failed to get gist: %w", err)
+		return "", fmt.Errorf("failed to get gist: %w", classifyGitHubError(err))
 	}
 
-	// Find the existing file name (should be prompt.Name + ".yaml")
-	fileName := prompt.Name + ".yaml"
+	// Find the existing prompt file (.yaml or .md)
 	var existingFileName string
 	for filename := range existingGist.Files {
 		// Try to find the existing file - it might have a different name if the prompt name changed
-		if strings.HasSuffix(string(filename), ".yaml") {
+		if model.FormatFromPath(string(filename)) != "" {
 			existingFileName = string(filename)
 			break
 		}
 	}
 
+	// A prompt without a format keeps the format of its existing file
+	if prompt.Format == "" {
+		prompt.Format = model.FormatFromPath(existingFileName)
+	}
+	fileName := prompt.FileName()
+
 	// If no existing file found, use the new filename
 	if existingFileName == "" {
 		existingFileName = fileName
@@ -449,68 +678,57 @@ func (g *GitHubStore) Update(prompt model.Prompt) error {
 	}
 
 	// Prepare the updated gist
+	// When the prompt was renamed the existing file is renamed along with the content
+	// change, which keeps a single prompt file in the gist and its history continuous
+	file := github.GistFile{Content: github.Ptr(prompt.Content)}
+	if existingFileName != fileName {
+		file.Filename = github.Ptr(fileName)
+	}
 	updatedGist := &github.Gist{
 		Description: github.Ptr(description),
 		Files: map[github.GistFilename]github.GistFile{
-			github.GistFilename(fileName): {
-				Content: github.Ptr(prompt.Content),
-			},
+			github.GistFilename(existingFileName): file,
 		},
 	}
 
-	// If the filename changed, we need to delete the old file
-	if existingFileName != fileName {
-		updatedGist.Files[github.GistFilename(existingFileName)] = github.GistFile{
-			Content: nil, // Setting content to nil deletes the file
-		}
-	}
-
 	// Update the gist
 	_, _, err = g.client.Gists.Edit(ctx, prompt.ID, updatedGist)
 	if err != nil {
-		return fmt.Errorf("failed to update gist: %w", err)
+		return "", fmt.Errorf("failed to update gist: %w", classifyGitHubError(err))
 	}
 
-	// Update index timestamp and filename if changed
-	index, err := g.loadIndex()
-	if err != nil {
-		return err
+	if existingFileName != fileName {
+		return fileName, nil
 	}
+	return "", nil
+}
 
-	for i, indexedPrompt := range index.Prompts {
-		if utils.ExtractGistIDFromURL(indexedPrompt.GistURL) == prompt.ID {
-			index.Prompts[i].LastUpdated = time.Now()
-			index.Prompts[i].Author = prompt.Author // 更新 author
-			index.Prompts[i].Name = prompt.Name     // 更新 name
-			// Update filename in index if it changed
-			if existingFileName != fileName {
-				index.Prompts[i].FilePath = fileName
-			}
-			break
-		}
+// updateIndexedPrompt records the metadata of an updated prompt in its index entry
+func updateIndexedPrompt(entry *model.IndexedPrompt, prompt model.Prompt, renamedTo string) {
+	entry.LastUpdated = time.Now()
+	entry.Author = prompt.Author // 更新 author
+	entry.Name = prompt.Name     // 更新 name
+	entry.ApplyMetadata(prompt)
+	// Update filename in index if it changed
+	if renamedTo != "" {
+		entry.FilePath = renamedTo
 	}
-
-	return g.saveIndex(index)
 }
 
 // Get searches for prompts by keyword
-func (g *GitHubStore) Get(keyword string) ([]model.Prompt, error) {
-	if err := g.ensureInitialized(); err != nil {
+func (g *GitHubStore) Get(ctx context.Context, keyword string) ([]model.Prompt, error) {
+	if err := g.ensureInitialized(ctx); err != nil {
 		return nil, err
 	}
 
-	allPrompts, err := g.List()
+	allPrompts, err := g.List(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	var matchingPrompts []model.Prompt
-	keyword = strings.ToLower(keyword)
-
 	for _, prompt := range allPrompts {
-		if strings.Contains(strings.ToLower(prompt.Name), keyword) ||
-			strings.Contains(strings.ToLower(prompt.Author), keyword) ||
-			strings.Contains(strings.ToLower(prompt.ID), keyword) {
+		if matchesKeyword(prompt, keyword) {
 			matchingPrompts = append(matchingPrompts, prompt)
 		}
 	}
@@ -518,35 +736,216 @@ func (g *GitHubStore) Get(keyword string) ([]model.Prompt, error) {
 	return matchingPrompts, nil
 }
 
+// BackfillMetadata records the description, tags, version and content hash of
+// index entries written by older pv versions, fetching each of their gists once
+func (g *GitHubStore) BackfillMetadata(ctx context.Context) (int, error) {
+	if err := g.ensureInitialized(ctx); err != nil {
+		return 0, err
+	}
+
+	index, err := g.loadIndex(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	// Fetch the contents of the entries to backfill in parallel first
+	var gistIDs []string
+	for _, entry := range index.Prompts {
+		if entry.ContentHash == "" {
+			gistIDs = append(gistIDs, utils.ExtractGistIDFromURL(entry.GistURL))
+		}
+	}
+	contents, err := g.FetchContents(ctx, gistIDs, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	filled := backfillIndexMetadata(index, func(entry model.IndexedPrompt) (string, error) {
+		content, ok := contents[utils.ExtractGistIDFromURL(entry.GistURL)]
+		if !ok {
+			return "", fmt.Errorf("content of %s not available", entry.GistURL)
+		}
+		return content, nil
+	})
+	if filled == 0 {
+		return 0, nil
+	}
+
+	if err := g.saveIndex(ctx, index); err != nil {
+		return 0, err
+	}
+
+	return filled, nil
+}
+
 // GetContent retrieves the actual content of a prompt from its GitHub Gist
-func (g *GitHubStore) GetContent(gistID string) (string, error) {
-	if err := g.ensureInitialized(); err != nil {
+func (g *GitHubStore) GetContent(ctx context.Context, gistID string) (string, error) {
+	if err := g.ensureInitialized(ctx); err != nil {
 		return "", err
 	}
 
-	ctx := context.Background()
+	return g.fetchContent(ctx, gistID)
+}
 
-	// Get the gist
-	gist, _, err := g.client.Gists.Get(ctx, gistID)
+// FetchContents fetches the contents of many prompt gists on a bounded worker pool
+// Requests slow down or pause when the API rate limit runs low.
+func (g *GitHubStore) FetchContents(ctx context.Context, gistIDs []string, progress FetchProgress) (map[string]string, error) {
+	if err := g.ensureInitialized(ctx); err != nil {
+		return nil, err
+	}
+
+	return fetchConcurrently(ctx, gistIDs, DefaultFetchWorkers, g.fetchContent, progress)
+}
+
+// fetchContent returns the YAML content of a prompt gist
+func (g *GitHubStore) fetchContent(ctx context.Context, gistID string) (string, error) {
+	gist, err := g.getGist(ctx, gistID)
 	if err != nil {
-		return "", fmt.Errorf("failed to get gist %s: %w", gistID, err)
+		return "", fmt.Errorf("failed to get gist %s: %w", gistID, classifyGitHubError(err))
 	}
 
-	// Find the prompt file (should be a .yaml file)
+	return promptFileContent(gist, gistID)
+}
+
+// promptFileContent returns the content of the prompt file (the .yaml or .md file) of a gist
+func promptFileContent(gist *github.Gist, gistID string) (string, error) {
 	for filename, file := range gist.Files {
-		if strings.HasSuffix(string(filename), ".yaml") {
+		if model.FormatFromPath(string(filename)) != "" {
 			return file.GetContent(), nil
 		}
 	}
 
-	return "", fmt.Errorf("no .yaml file found in gist %s", gistID)
+	return "", fmt.Errorf("no prompt file (.yaml or .md) found in gist %s", gistID)
+}
+
+// ListRevisions returns the revision history of a prompt gist, newest first
+func (g *GitHubStore) ListRevisions(ctx context.Context, gistID string) ([]model.Revision, error) {
+	if err := g.ensureInitialized(ctx); err != nil {
+		return nil, err
+	}
+
+	var revisions []model.Revision
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		commits, resp, err := g.client.Gists.ListCommits(ctx, gistID, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list revisions of gist %s: %w", gistID, classifyGitHubError(err))
+		}
+
+		for _, commit := range commits {
+			revision := model.Revision{
+				SHA:         commit.GetVersion(),
+				CommittedAt: commit.GetCommittedAt().Time,
+			}
+			if status := commit.ChangeStatus; status != nil {
+				revision.Additions = status.GetAdditions()
+				revision.Deletions = status.GetDeletions()
+			}
+			revisions = append(revisions, revision)
+		}
+
+		if resp == nil || resp.NextPage == 0 {
+			return revisions, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// GetRevisionContent returns the prompt content of a gist as of revision sha
+func (g *GitHubStore) GetRevisionContent(ctx context.Context, gistID, sha string) (string, error) {
+	if err := g.ensureInitialized(ctx); err != nil {
+		return "", err
+	}
+
+	gist, _, err := g.client.Gists.GetRevision(ctx, gistID, sha)
+	if err != nil {
+		return "", fmt.Errorf("failed to get revision %s of gist %s: %w", sha, gistID, classifyGitHubError(err))
+	}
+
+	return promptFileContent(gist, gistID)
+}
+
+// getGist gets a prompt gist, waiting for the rate limit when it runs low
+// Rate limited requests are retried by the retrying transport only; a rejection that
+// still reaches here makes the requests that follow wait for the reset.
+func (g *GitHubStore) getGist(ctx context.Context, gistID string) (*github.Gist, error) {
+	if err := g.limiter.wait(ctx); err != nil {
+		return nil, err
+	}
+
+	gist, resp, err := g.client.Gists.Get(ctx, gistID)
+	g.limiter.observe(resp)
+	if err != nil {
+		g.limiter.throttled(err)
+		return nil, err
+	}
+	return gist, nil
+}
+
+// createGist creates a gist, retrying transient failures without creating duplicates
+// Creating a gist is not idempotent and a request that failed on the way back may
+// still have created it, so the retrying transport never repeats it. Instead, before
+// each retry, the gists created since the first attempt are searched for this one.
+func (g *GitHubStore) createGist(ctx context.Context, gist *github.Gist) (*github.Gist, error) {
+	// Leave room for clock skew between this machine and GitHub
+	since := time.Now().Add(-time.Minute)
+
+	for attempt := 0; ; attempt++ {
+		created, _, err := g.client.Gists.Create(ctx, gist)
+		if err == nil {
+			return created, nil
+		}
+		if attempt >= maxCreateGistRetries || ctx.Err() != nil || !isTransientGitHubError(err) {
+			return nil, err
+		}
+
+		existing, findErr := g.findCreatedGist(ctx, gist, since)
+		if findErr != nil {
+			// Without knowing whether the gist exists, retrying could duplicate it
+			return nil, err
+		}
+		if existing != nil {
+			return existing, nil
+		}
+
+		if err := utils.SleepContext(ctx, utils.RetryBackoff(attempt)); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// findCreatedGist looks for a gist created since the given time with the
+// description, visibility and file names of gist
+func (g *GitHubStore) findCreatedGist(ctx context.Context, gist *github.Gist, since time.Time) (*github.Gist, error) {
+	gists, _, err := g.client.Gists.List(ctx, "", &github.GistListOptions{Since: since})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, candidate := range gists {
+		if candidate.GetDescription() != gist.GetDescription() || candidate.GetPublic() != gist.GetPublic() ||
+			len(candidate.Files) != len(gist.Files) {
+			continue
+		}
+		sameFiles := true
+		for name := range gist.Files {
+			if _, ok := candidate.Files[name]; !ok {
+				sameFiles = false
+				break
+			}
+		}
+		if sameFiles {
+			return candidate, nil
+		}
+	}
+	return nil, nil
 }
 
 // CreatePublicGist 创建新的公开 gist
-func (g *GitHubStore) CreatePublicGist(prompt model.Prompt) (string, error) {
+func (g *GitHubStore) CreatePublicGist(ctx context.Context, prompt model.Prompt) (string, error) {
 	// 构建 gist 文件内容
-	filename := fmt.Sprintf("%s.yaml", prompt.Name)
-	content := g.buildYAMLContent(prompt)
+	filename := prompt.FileName()
+	content := buildYAMLContent(prompt)
 
 	public := true
 	gist := &github.Gist{
@@ -559,27 +958,27 @@ func (g *GitHubStore) CreatePublicGist(prompt model.Prompt) (string, error) {
 		},
 	}
 
-	createdGist, _, err := g.client.Gists.Create(context.Background(), gist)
+	createdGist, err := g.createGist(ctx, gist)
 	if err != nil {
-		return "", errors.NewShareError("创建公开 gist", "", err)
+		return "", errors.NewShareError("创建公开 gist", "", classifyGitHubError(err))
 	}
 
 	return createdGist.GetHTMLURL(), nil
 }
 
 // UpdateGist 更新现有 gist 的内容
-func (g *GitHubStore) UpdateGist(gistURL string, prompt model.Prompt) error {
+func (g *GitHubStore) UpdateGist(ctx context.Context, gistURL string, prompt model.Prompt) error {
 	gistID := g.extractGistID(gistURL)
 
 	// 获取现有 gist
-	existingGist, _, err := g.client.Gists.Get(context.Background(), gistID)
+	existingGist, _, err := g.client.Gists.Get(ctx, gistID)
 	if err != nil {
-		return errors.NewShareError("获取现有 gist", gistURL, err)
+		return errors.NewShareError("获取现有 gist", gistURL, classifyGitHubError(err))
 	}
 
 	// 构建新内容
-	filename := fmt.Sprintf("%s.yaml", prompt.Name)
-	content := g.buildYAMLContent(prompt)
+	filename := prompt.FileName()
+	content := buildYAMLContent(prompt)
 
 	// 更新 gist
 	gist := &github.Gist{
@@ -600,23 +999,23 @@ func (g *GitHubStore) UpdateGist(gistURL string, prompt model.Prompt) error {
 		}
 	}
 
-	_, _, err = g.client.Gists.Edit(context.Background(), gistID, gist)
+	_, _, err = g.client.Gists.Edit(ctx, gistID, gist)
 	if err != nil {
-		return errors.NewShareError("更新 gist", gistURL, err)
+		return errors.NewShareError("更新 gist", gistURL, classifyGitHubError(err))
 	}
 
 	return nil
 }
 
 // GetGistInfo 获取 gist 的基本信息
-func (g *GitHubStore) GetGistInfo(gistURL string) (*GistInfo, error) {
-	if err := g.ensureInitialized(); err != nil {
+func (g *GitHubStore) GetGistInfo(ctx context.Context, gistURL string) (*GistInfo, error) {
+	if err := g.ensureInitialized(ctx); err != nil {
 		return nil, err
 	}
 
 	gistID := g.extractGistID(gistURL)
 
-	gist, resp, err := g.client.Gists.Get(context.Background(), gistID)
+	gist, resp, err := g.client.Gists.Get(ctx, gistID)
 	if err != nil {
 		// 处理权限和访问错误
 		if resp != nil && resp.StatusCode == 404 {
@@ -627,7 +1026,7 @@ func (g *GitHubStore) GetGistInfo(gistURL string) (*GistInfo, error) {
 				HasAccess: false,
 			}, nil
 		}
-		return nil, errors.NewShareError("获取 gist 信息", gistURL, err)
+		return nil, errors.NewShareError("获取 gist 信息", gistURL, classifyGitHubError(err))
 	}
 
 	// 安全获取 owner 信息
@@ -647,29 +1046,29 @@ func (g *GitHubStore) GetGistInfo(gistURL string) (*GistInfo, error) {
 }
 
 // AddExport 添加新的导出记录
-func (g *GitHubStore) AddExport(prompt model.IndexedPrompt) error {
-	err := g.ensureInitialized()
+func (g *GitHubStore) AddExport(ctx context.Context, prompt model.IndexedPrompt) error {
+	err := g.ensureInitialized(ctx)
 	if err != nil {
 		return err
 	}
 
-	index, err := g.loadIndex()
+	index, err := g.loadIndex(ctx)
 	if err != nil {
 		return err
 	}
 
 	index.Exports = append(index.Exports, prompt)
-	return g.saveIndex(index)
+	return g.saveIndex(ctx, index)
 }
 
 // UpdateExport 更新现有的导出记录
-func (g *GitHubStore) UpdateExport(prompt model.IndexedPrompt) error {
-	err := g.ensureInitialized()
+func (g *GitHubStore) UpdateExport(ctx context.Context, prompt model.IndexedPrompt) error {
+	err := g.ensureInitialized(ctx)
 	if err != nil {
 		return err
 	}
 
-	index, err := g.loadIndex()
+	index, err := g.loadIndex(ctx)
 	if err != nil {
 		return err
 	}
@@ -677,23 +1076,23 @@ func (g *GitHubStore) UpdateExport(prompt model.IndexedPrompt) error {
 	for i, export := range index.Exports {
 		if export.GistURL == prompt.GistURL {
 			index.Exports[i] = prompt
-			return g.saveIndex(index)
+			return g.saveIndex(ctx, index)
 		}
 	}
 
 	// 如果没找到，则添加
 	index.Exports = append(index.Exports, prompt)
-	return g.saveIndex(index)
+	return g.saveIndex(ctx, index)
 }
 
 // GetExports 获取所有导出记录
-func (g *GitHubStore) GetExports() ([]model.IndexedPrompt, error) {
-	err := g.ensureInitialized()
+func (g *GitHubStore) GetExports(ctx context.Context) ([]model.IndexedPrompt, error) {
+	err := g.ensureInitialized(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	index, err := g.loadIndex()
+	index, err := g.loadIndex(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -702,7 +1101,7 @@ func (g *GitHubStore) GetExports() ([]model.IndexedPrompt, error) {
 }
 
 // buildYAMLContent 构建 YAML 内容字符串
-func (g *GitHubStore) buildYAMLContent(prompt model.Prompt) string {
+func buildYAMLContent(prompt model.Prompt) string {
 	// 如果 prompt.Content 包含完整的原始 YAML 内容，则直接使用
 	// 这确保了 share 命令生成的 gist 内容与原始文件完全一致
 	if strings.TrimSpace(prompt.Content) != "" {
@@ -722,11 +1121,11 @@ content: |
 		prompt.Description,
 		prompt.Tags,
 		prompt.Version,
-		g.indentContent(prompt.Content, "  "))
+		indentContent(prompt.Content, "  "))
 }
 
 // indentContent 为内容添加缩进
-func (g *GitHubStore) indentContent(content string, indent string) string {
+func indentContent(content string, indent string) string {
 	lines := strings.Split(content, "\n")
 	for i, line := range lines {
 		if line != "" {
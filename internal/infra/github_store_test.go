@@ -0,0 +1,613 @@
+package infra
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grigri/pv/internal/model"
+	"github.com/grigri/pv/internal/utils"
+)
+
+// fakeGistRevision is one revision of a gist held by fakeGistAPI
+type fakeGistRevision struct {
+	version string
+	files   map[string]string
+}
+
+// fakeGist is a gist held by fakeGistAPI, newest revision first
+type fakeGist struct {
+	description string
+	public      bool
+	history     []fakeGistRevision
+}
+
+// fakeGistAPI is an in-memory stand-in for the GitHub Gists API served under /api/v3
+type fakeGistAPI struct {
+	mu       sync.Mutex
+	server   *httptest.Server
+	nextID   int
+	nextRev  int
+	gists    map[string]*fakeGist
+	requests []string
+
+	// notModified counts the conditional reads answered with 304 Not Modified
+	notModified int
+
+	// beforeEdit, when set, runs before a PATCH is applied, with the lock released
+	beforeEdit func(id string)
+
+	// fail, when set, may fail a request with the returned status; when applied is
+	// true the request is still served first, as if only the response got lost
+	fail func(r *http.Request) (status int, applied bool)
+}
+
+func newFakeGistAPI(t *testing.T) *fakeGistAPI {
+	t.Helper()
+	f := &fakeGistAPI{gists: map[string]*fakeGist{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v3/gists", f.list)
+	mux.HandleFunc("POST /api/v3/gists", f.create)
+	mux.HandleFunc("GET /api/v3/gists/{id}", f.get)
+	mux.HandleFunc("PATCH /api/v3/gists/{id}", f.edit)
+	mux.HandleFunc("DELETE /api/v3/gists/{id}", f.delete)
+	mux.HandleFunc("GET /api/v3/gists/{id}/commits", f.commits)
+	mux.HandleFunc("GET /api/v3/gists/{id}/{sha}", f.revision)
+
+	f.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer test-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		f.mu.Lock()
+		f.requests = append(f.requests, r.Method+" "+r.URL.Path)
+		fail := f.fail
+		f.mu.Unlock()
+		if fail != nil {
+			if status, applied := fail(r); status != 0 {
+				if applied {
+					mux.ServeHTTP(httptest.NewRecorder(), r)
+				}
+				w.WriteHeader(status)
+				return
+			}
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+// newStore returns a GitHubStore talking to the fake API
+func (f *fakeGistAPI) newStore(t *testing.T) *GitHubStore {
+	t.Helper()
+	endpoints, err := utils.NewGitHubEndpoints(f.server.URL, "", "")
+	if err != nil {
+		t.Fatalf("NewGitHubEndpoints failed: %v", err)
+	}
+	return NewGitHubEnterpriseStore(&MockConfigStore{}, endpoints).(*GitHubStore)
+}
+
+func (f *fakeGistAPI) newRevision(files map[string]string) fakeGistRevision {
+	f.nextRev++
+	return fakeGistRevision{version: fmt.Sprintf("%040d", f.nextRev), files: files}
+}
+
+func (f *fakeGistAPI) payload(id string, revision fakeGistRevision) map[string]interface{} {
+	gist := f.gists[id]
+	files := map[string]interface{}{}
+	for name, content := range revision.files {
+		files[name] = map[string]interface{}{"filename": name, "content": content}
+	}
+	return map[string]interface{}{
+		"id":          id,
+		"description": gist.description,
+		"public":      gist.public,
+		"html_url":    fmt.Sprintf("%s/gist/alice/%s", f.server.URL, id),
+		"files":       files,
+		"owner":       map[string]string{"login": "alice"},
+		"updated_at":  time.Now().UTC().Format(time.RFC3339),
+	}
+}
+
+func (f *fakeGistAPI) list(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	gists := []map[string]interface{}{}
+	for id, gist := range f.gists {
+		gists = append(gists, f.payload(id, gist.history[0]))
+	}
+	json.NewEncoder(w).Encode(gists)
+}
+
+func (f *fakeGistAPI) create(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Description string `json:"description"`
+		Public      bool   `json:"public"`
+		Files       map[string]struct {
+			Content string `json:"content"`
+		} `json:"files"`
+	}
+	json.NewDecoder(r.Body).Decode(&req)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	files := map[string]string{}
+	for name, file := range req.Files {
+		files[name] = file.Content
+	}
+
+	f.nextID++
+	id := fmt.Sprintf("%032x", f.nextID)
+	f.gists[id] = &fakeGist{description: req.Description, public: req.Public, history: []fakeGistRevision{f.newRevision(files)}}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(f.payload(id, f.gists[id].history[0]))
+}
+
+func (f *fakeGistAPI) get(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	gist, ok := f.gists[r.PathValue("id")]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	etag := fmt.Sprintf(`W/"%s"`, gist.history[0].version)
+	if r.Header.Get("If-None-Match") == etag {
+		f.notModified++
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("ETag", etag)
+	json.NewEncoder(w).Encode(f.payload(r.PathValue("id"), gist.history[0]))
+}
+
+func (f *fakeGistAPI) edit(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if f.beforeEdit != nil {
+		f.beforeEdit(id)
+	}
+
+	var req struct {
+		Description *string                     `json:"description"`
+		Files       map[string]*json.RawMessage `json:"files"`
+	}
+	json.NewDecoder(r.Body).Decode(&req)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	gist, ok := f.gists[id]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if req.Description != nil {
+		gist.description = *req.Description
+	}
+
+	files := map[string]string{}
+	for name, content := range gist.history[0].files {
+		files[name] = content
+	}
+	for name, raw := range req.Files {
+		if raw == nil || string(*raw) == "null" {
+			delete(files, name)
+			continue
+		}
+		var file struct {
+			Filename *string `json:"filename"`
+			Content  *string `json:"content"`
+		}
+		json.Unmarshal(*raw, &file)
+		content := files[name]
+		if file.Content != nil {
+			content = *file.Content
+		}
+		if file.Filename != nil && *file.Filename != name {
+			delete(files, name)
+			name = *file.Filename
+		}
+		files[name] = content
+	}
+
+	gist.history = append([]fakeGistRevision{f.newRevision(files)}, gist.history...)
+	json.NewEncoder(w).Encode(f.payload(id, gist.history[0]))
+}
+
+func (f *fakeGistAPI) delete(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	delete(f.gists, r.PathValue("id"))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (f *fakeGistAPI) commits(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	gist, ok := f.gists[r.PathValue("id")]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	commits := []map[string]string{}
+	for _, revision := range gist.history {
+		commits = append(commits, map[string]string{"version": revision.version})
+	}
+	json.NewEncoder(w).Encode(commits)
+}
+
+func (f *fakeGistAPI) revision(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	gist, ok := f.gists[r.PathValue("id")]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	for _, revision := range gist.history {
+		if revision.version == r.PathValue("sha") {
+			json.NewEncoder(w).Encode(f.payload(r.PathValue("id"), revision))
+			return
+		}
+	}
+	w.WriteHeader(http.StatusNotFound)
+}
+
+// indexNames returns the prompt names recorded in the remote index
+func indexNames(t *testing.T, store *GitHubStore) string {
+	t.Helper()
+	index, err := store.loadIndex(context.Background())
+	if err != nil {
+		t.Fatalf("loadIndex failed: %v", err)
+	}
+	var names []string
+	for _, prompt := range index.Prompts {
+		names = append(names, prompt.Name)
+	}
+	return strings.Join(names, ",")
+}
+
+func TestGitHubStore_EnterpriseEndpoints(t *testing.T) {
+	fake := newFakeGistAPI(t)
+	store := fake.newStore(t)
+
+	if _, err := store.List(context.Background()); err != ErrEmptyIndex {
+		t.Fatalf("Expected ErrEmptyIndex, got: %v", err)
+	}
+
+	if len(fake.requests) == 0 || fake.requests[0] != "GET /api/v3/gists" {
+		t.Errorf("Expected gists to be listed on the enterprise API, got %v", fake.requests)
+	}
+}
+
+func TestGitHubStore_ConcurrentAddsAreMerged(t *testing.T) {
+	fake := newFakeGistAPI(t)
+	alice := fake.newStore(t)
+	bob := fake.newStore(t)
+
+	if err := alice.Add(context.Background(), model.Prompt{Name: "First", Author: "alice", Content: "a"}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	// Alice reads the index, then Bob adds a prompt before Alice writes
+	index, err := alice.loadIndex(context.Background())
+	if err != nil {
+		t.Fatalf("loadIndex failed: %v", err)
+	}
+	if err := bob.Add(context.Background(), model.Prompt{Name: "Bob's", Author: "bob", Content: "b"}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	index.Prompts = append(index.Prompts, model.IndexedPrompt{GistURL: "https://example.com/gist/alice/second", Name: "Second", Author: "alice"})
+	if err := alice.saveIndex(context.Background(), index); err != nil {
+		t.Fatalf("saveIndex failed: %v", err)
+	}
+
+	if got := indexNames(t, alice); got != "First,Bob's,Second" {
+		t.Errorf("Expected all entries to survive, got %s", got)
+	}
+}
+
+func TestGitHubStore_WriteRacingTheEditIsMerged(t *testing.T) {
+	fake := newFakeGistAPI(t)
+	alice := fake.newStore(t)
+	bob := fake.newStore(t)
+
+	if err := alice.Add(context.Background(), model.Prompt{Name: "First", Author: "alice", Content: "a"}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if err := bob.ensureInitialized(context.Background()); err != nil {
+		t.Fatalf("ensureInitialized failed: %v", err)
+	}
+
+	// Bob's index write lands after Alice checked the index but before her edit applies
+	raced := false
+	fake.beforeEdit = func(id string) {
+		if raced || id != alice.indexGistID {
+			return
+		}
+		raced = true
+		index, err := bob.loadIndex(context.Background())
+		if err != nil {
+			t.Errorf("loadIndex failed: %v", err)
+			return
+		}
+		index.Prompts = append(index.Prompts, model.IndexedPrompt{GistURL: "https://example.com/gist/bob/racing", Name: "Racing", Author: "bob"})
+		if _, err := bob.writeIndex(t.Context(), index); err != nil {
+			t.Errorf("writeIndex failed: %v", err)
+		}
+	}
+
+	if err := alice.Add(context.Background(), model.Prompt{Name: "Second", Author: "alice", Content: "b"}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	if !raced {
+		t.Fatal("Expected the racing write to run")
+	}
+
+	if got := indexNames(t, alice); got != "First,Racing,Second" {
+		t.Errorf("Expected racing entry to survive, got %s", got)
+	}
+}
+
+func TestGitHubStore_ConflictingIndexChanges(t *testing.T) {
+	fake := newFakeGistAPI(t)
+	alice := fake.newStore(t)
+	bob := fake.newStore(t)
+
+	if err := alice.Add(context.Background(), model.Prompt{Name: "Shared", Author: "alice", Content: "a"}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	if err := bob.ensureInitialized(context.Background()); err != nil {
+		t.Fatalf("ensureInitialized failed: %v", err)
+	}
+
+	aliceIndex, err := alice.loadIndex(context.Background())
+	if err != nil {
+		t.Fatalf("loadIndex failed: %v", err)
+	}
+	bobIndex, err := bob.loadIndex(context.Background())
+	if err != nil {
+		t.Fatalf("loadIndex failed: %v", err)
+	}
+
+	bobIndex.Prompts[0].Name = "Bob's name"
+	if err := bob.saveIndex(context.Background(), bobIndex); err != nil {
+		t.Fatalf("saveIndex failed: %v", err)
+	}
+
+	aliceIndex.Prompts[0].Name = "Alice's name"
+	err = alice.saveIndex(context.Background(), aliceIndex)
+	if !stderrors.Is(err, ErrIndexConflict) {
+		t.Fatalf("Expected ErrIndexConflict, got: %v", err)
+	}
+
+	if got := indexNames(t, alice); got != "Bob's name" {
+		t.Errorf("Expected Bob's change to be kept, got %s", got)
+	}
+}
+
+func TestGitHubStore_IndexRecordsMetadata(t *testing.T) {
+	fake := newFakeGistAPI(t)
+	store := fake.newStore(t)
+
+	content := "name: Review\nauthor: alice\ndescription: Reviews code\ntags: [go, review]\nversion: \"1.2\"\n---\nReview {code}"
+	prompt := model.Prompt{Name: "Review", Author: "alice", Description: "Reviews code", Tags: []string{"go", "review"}, Version: "1.2", Content: content}
+	if err := store.Add(context.Background(), prompt); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	prompts, err := store.Get(context.Background(), "review")
+	if err != nil || len(prompts) != 1 {
+		t.Fatalf("Get failed: %v, %v", prompts, err)
+	}
+	if got := prompts[0]; got.Description != "Reviews code" || strings.Join(got.Tags, ",") != "go,review" || got.Version != "1.2" {
+		t.Errorf("Expected metadata from the index, got %+v", got)
+	}
+	if matches, _ := store.Get(context.Background(), "go"); len(matches) != 1 {
+		t.Errorf("Expected a tag to match, got %v", matches)
+	}
+
+	// Strip the metadata as an older pv would have written the entry
+	index, err := store.loadIndex(context.Background())
+	if err != nil {
+		t.Fatalf("loadIndex failed: %v", err)
+	}
+	if index.Prompts[0].ContentHash != model.ContentHash(content) {
+		t.Errorf("Expected the content hash to be recorded, got %q", index.Prompts[0].ContentHash)
+	}
+	index.Prompts[0] = model.IndexedPrompt{GistURL: index.Prompts[0].GistURL, FilePath: index.Prompts[0].FilePath, Name: "Review", Author: "alice"}
+	if err := store.saveIndex(context.Background(), index); err != nil {
+		t.Fatalf("saveIndex failed: %v", err)
+	}
+
+	filled, err := store.BackfillMetadata(context.Background())
+	if err != nil || filled != 1 {
+		t.Fatalf("Expected 1 entry to be backfilled, got %d, %v", filled, err)
+	}
+	if filled, _ := store.BackfillMetadata(context.Background()); filled != 0 {
+		t.Errorf("Expected the backfill to run only once, filled %d again", filled)
+	}
+
+	index, err = store.loadIndex(context.Background())
+	if err != nil {
+		t.Fatalf("loadIndex failed: %v", err)
+	}
+	if got := index.Prompts[0]; got.Description != "Reviews code" || strings.Join(got.Tags, ",") != "go,review" || got.Version != "1.2" || got.ContentHash != model.ContentHash(content) {
+		t.Errorf("Expected backfilled metadata, got %+v", got)
+	}
+}
+
+func TestGitHubStore_UpdateRename(t *testing.T) {
+	fake := newFakeGistAPI(t)
+	store := fake.newStore(t)
+
+	if err := store.Add(context.Background(), model.Prompt{Name: "Draft", Author: "alice", Content: "v1"}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	prompts, err := store.List(context.Background())
+	if err != nil || len(prompts) != 1 {
+		t.Fatalf("List failed: %v, %v", prompts, err)
+	}
+	prompts[0].Name = "Review"
+	prompts[0].Content = "v2"
+	if err := store.Update(context.Background(), prompts[0]); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	fake.mu.Lock()
+	files := fake.gists[prompts[0].ID].history[0].files
+	fake.mu.Unlock()
+	if len(files) != 1 || files["Review.yaml"] != "v2" {
+		t.Errorf("Expected the prompt file to be renamed, got %v", files)
+	}
+
+	index, err := store.loadIndex(context.Background())
+	if err != nil {
+		t.Fatalf("loadIndex failed: %v", err)
+	}
+	if got := index.Prompts[0]; got.Name != "Review" || got.FilePath != "Review.yaml" {
+		t.Errorf("Expected the index entry to follow the rename, got %+v", got)
+	}
+}
+
+func TestGitHubStore_Markdown(t *testing.T) {
+	fake := newFakeGistAPI(t)
+	store := fake.newStore(t)
+	ctx := context.Background()
+
+	content := "---\nname: Notes\nauthor: alice\n---\n# Notes\n"
+	if err := store.Add(ctx, model.Prompt{Name: "Notes", Author: "alice", Content: content, Format: model.FormatMarkdown}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	prompts, err := store.List(ctx)
+	if err != nil || len(prompts) != 1 || prompts[0].Format != model.FormatMarkdown {
+		t.Fatalf("Expected a Markdown prompt, got %+v, %v", prompts, err)
+	}
+	if got, err := store.GetContent(ctx, prompts[0].ID); err != nil || got != content {
+		t.Errorf("Expected the Markdown content, got %q, %v", got, err)
+	}
+
+	// 未指定格式的更新保留 .md 文件
+	if err := store.Update(ctx, model.Prompt{ID: prompts[0].ID, Name: "Notes", Author: "alice", Content: "v2"}); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	fake.mu.Lock()
+	files := fake.gists[prompts[0].ID].history[0].files
+	fake.mu.Unlock()
+	if len(files) != 1 || files["Notes.md"] != "v2" {
+		t.Errorf("Expected the Markdown file to be kept, got %v", files)
+	}
+
+	index, err := store.loadIndex(ctx)
+	if err != nil {
+		t.Fatalf("loadIndex failed: %v", err)
+	}
+	if got := index.Prompts[0].FilePath; got != "Notes.md" {
+		t.Errorf("Expected the index to record Notes.md, got %q", got)
+	}
+}
+
+func TestGitHubStore_AddBatch(t *testing.T) {
+	fake := newFakeGistAPI(t)
+	store := fake.newStore(t)
+	ctx := context.Background()
+
+	if err := store.Add(ctx, model.Prompt{Name: "Review", Author: "alice", Content: "v1"}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	fake.mu.Lock()
+	fake.requests = nil
+	fake.mu.Unlock()
+
+	stored, err := store.AddBatch(ctx, []model.Prompt{
+		{Name: "Review", Author: "alice", Content: "v2", Tags: []string{"code"}},
+		{Name: "Summary", Author: "alice", Content: "summary"},
+		{Name: "Translate", Author: "bob", Content: "translate"},
+	})
+	if err != nil {
+		t.Fatalf("AddBatch failed: %v", err)
+	}
+	if len(stored) != 3 || stored[1].ID == "" || stored[1].GistURL == "" {
+		t.Fatalf("Expected the stored prompts with their gists, got %+v", stored)
+	}
+
+	fake.mu.Lock()
+	indexWrites := 0
+	for _, request := range fake.requests {
+		if request == "PATCH /api/v3/gists/"+store.indexGistID {
+			indexWrites++
+		}
+	}
+	reviewFiles := fake.gists[stored[0].ID].history[0].files
+	fake.mu.Unlock()
+	if indexWrites != 1 {
+		t.Errorf("Expected a single index write, got %d", indexWrites)
+	}
+	if reviewFiles["Review.yaml"] != "v2" {
+		t.Errorf("Expected the existing prompt to be updated, got %v", reviewFiles)
+	}
+
+	prompts, err := store.List(ctx)
+	if err != nil || len(prompts) != 3 {
+		t.Fatalf("Expected 3 prompts in the index, got %v, %v", prompts, err)
+	}
+	if prompts[0].ID != stored[0].ID || strings.Join(prompts[0].Tags, ",") != "code" {
+		t.Errorf("Expected the index entry of the update to be refreshed, got %+v", prompts[0])
+	}
+}
+
+func TestGitHubStore_Revisions(t *testing.T) {
+	fake := newFakeGistAPI(t)
+	store := fake.newStore(t)
+
+	if err := store.Add(context.Background(), model.Prompt{Name: "History", Author: "alice", Content: "v1"}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	prompts, err := store.List(context.Background())
+	if err != nil || len(prompts) != 1 {
+		t.Fatalf("List failed: %v, %v", prompts, err)
+	}
+	prompts[0].Content = "v2"
+	if err := store.Update(context.Background(), prompts[0]); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	revisions, err := store.ListRevisions(context.Background(), prompts[0].ID)
+	if err != nil || len(revisions) != 2 {
+		t.Fatalf("Expected 2 revisions, got %v, %v", revisions, err)
+	}
+
+	// Newest first
+	for i, want := range []string{"v2", "v1"} {
+		content, err := store.GetRevisionContent(context.Background(), prompts[0].ID, revisions[i].SHA)
+		if err != nil || content != want {
+			t.Errorf("Expected revision %d to hold %q, got %q, %v", i, want, content, err)
+		}
+	}
+
+	if _, err := store.GetRevisionContent(context.Background(), prompts[0].ID, strings.Repeat("f", 40)); err == nil {
+		t.Error("Expected an unknown revision to fail")
+	}
+}
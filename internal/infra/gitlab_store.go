@@ -0,0 +1,697 @@
+package infra
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grigri/pv/internal/config"
+	"github.com/grigri/pv/internal/errors"
+	"github.com/grigri/pv/internal/model"
+	"github.com/grigri/pv/internal/utils"
+)
+
+const (
+	// gitLabVisibilityPrivate is the visibility of prompt and index snippets
+	gitLabVisibilityPrivate = "private"
+	// gitLabVisibilityPublic is the visibility of shared snippets
+	gitLabVisibilityPublic = "public"
+	// gitLabPageSize is the page size used when listing snippets
+	gitLabPageSize = 100
+)
+
+// gitLabSnippet is the subset of the GitLab snippet payload used by pv
+type gitLabSnippet struct {
+	ID          int                 `json:"id"`
+	Title       string              `json:"title"`
+	Description string              `json:"description"`
+	Visibility  string              `json:"visibility"`
+	WebURL      string              `json:"web_url"`
+	Author      *gitLabAuthor       `json:"author,omitempty"`
+	Files       []gitLabSnippetFile `json:"files,omitempty"`
+}
+
+// gitLabAuthor is the author of a snippet
+type gitLabAuthor struct {
+	Username string `json:"username"`
+}
+
+// gitLabSnippetFile describes a file of an existing snippet
+type gitLabSnippetFile struct {
+	Path string `json:"path"`
+}
+
+// gitLabFileAction is a file entry of a create/update snippet request
+type gitLabFileAction struct {
+	Action       string  `json:"action,omitempty"`
+	FilePath     string  `json:"file_path"`
+	PreviousPath string  `json:"previous_path,omitempty"`
+	Content      *string `json:"content,omitempty"`
+}
+
+// gitLabSnippetRequest is the body of create/update snippet requests
+type gitLabSnippetRequest struct {
+	Title       string             `json:"title,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Visibility  string             `json:"visibility,omitempty"`
+	Files       []gitLabFileAction `json:"files"`
+}
+
+// gitLabAPIError is returned for non-2xx responses from the snippets API
+type gitLabAPIError struct {
+	StatusCode int
+	Method     string
+	Path       string
+}
+
+// Error implements the error interface
+func (e *gitLabAPIError) Error() string {
+	return fmt.Sprintf("GitLab API %s %s returned status %d", e.Method, e.Path, e.StatusCode)
+}
+
+// GitLabStore implements the Store interface using GitLab personal snippets
+// It follows the same conventions as GitHubStore: one private snippet per prompt
+// and an index snippet titled "pv-prompts-index" holding index.json.
+type GitLabStore struct {
+	baseURL        string
+	httpClient     *http.Client
+	configStore    config.Store
+	token          string
+	indexSnippetID string
+}
+
+// NewGitLabStore creates a new GitLabStore for the instance at baseURL
+// An empty baseURL selects gitlab.com
+func NewGitLabStore(configStore config.Store, baseURL string) Store {
+	if baseURL == "" {
+		baseURL = "https://gitlab.com"
+	}
+	return &GitLabStore{
+		baseURL:     strings.TrimRight(baseURL, "/") + "/api/v4",
+		httpClient:  &http.Client{Timeout: 30 * time.Second},
+		configStore: configStore,
+	}
+}
+
+// ensureInitialized loads the token and finds or creates the index snippet
+func (g *GitLabStore) ensureInitialized(ctx context.Context) error {
+	if g.indexSnippetID != "" {
+		return nil
+	}
+
+	token, err := g.configStore.GetToken()
+	if err != nil {
+		return fmt.Errorf("failed to get GitLab token: %w", err)
+	}
+
+	if token == "" {
+		return fmt.Errorf("GitLab token is not configured")
+	}
+	g.token = token
+
+	if err := g.initializeIndex(ctx); err != nil {
+		return fmt.Errorf("failed to initialize index: %w", err)
+	}
+
+	return nil
+}
+
+// initializeIndex finds or creates the index snippet
+func (g *GitLabStore) initializeIndex(ctx context.Context) error {
+	for page := 1; ; page++ {
+		var snippets []gitLabSnippet
+		path := fmt.Sprintf("/snippets?per_page=%d&page=%d", gitLabPageSize, page)
+		if err := g.do(ctx, http.MethodGet, path, nil, &snippets); err != nil {
+			return fmt.Errorf("failed to list snippets: %w", err)
+		}
+
+		for _, snippet := range snippets {
+			if snippet.Title == IndexGistDescription {
+				g.indexSnippetID = strconv.Itoa(snippet.ID)
+				return nil
+			}
+		}
+
+		if len(snippets) < gitLabPageSize {
+			break
+		}
+	}
+
+	emptyIndex := model.Index{
+		Prompts:     []model.IndexedPrompt{},
+		Exports:     []model.IndexedPrompt{},
+		LastUpdated: time.Now(),
+	}
+
+	indexContent, err := encodeIndex(&emptyIndex)
+	if err != nil {
+		return fmt.Errorf("failed to marshal empty index: %w", err)
+	}
+
+	content := string(indexContent)
+	created, err := g.createSnippet(ctx, IndexGistDescription, "", gitLabVisibilityPrivate, IndexFileName, content)
+	if err != nil {
+		return fmt.Errorf("failed to create index snippet: %w", err)
+	}
+
+	g.indexSnippetID = strconv.Itoa(created.ID)
+	return nil
+}
+
+// do performs an authenticated JSON request against the snippets API
+func (g *GitLabStore) do(ctx context.Context, method, path string, body interface{}, out interface{}) error {
+	data, err := g.doRaw(ctx, method, path, body)
+	if err != nil {
+		return err
+	}
+
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to decode GitLab response: %w", err)
+	}
+
+	return nil
+}
+
+// doRaw performs an authenticated request and returns the response body
+func (g *GitLabStore) doRaw(ctx context.Context, method, path string, body interface{}) ([]byte, error) {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode GitLab request: %w", err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, g.baseURL+path, reader)
+	if err != nil {
+		return nil, errors.NewAppError(errors.ErrNetwork, "failed to create request", err)
+	}
+
+	req.Header.Set("PRIVATE-TOKEN", g.token)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := g.httpClient.Do(req)
+	if err != nil {
+		return nil, errors.NewAppError(errors.ErrNetwork, "Unable to connect to GitLab API", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.NewAppError(errors.ErrNetwork, "failed to read GitLab response", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, &gitLabAPIError{StatusCode: resp.StatusCode, Method: method, Path: path}
+	}
+
+	return data, nil
+}
+
+// createSnippet creates a single-file snippet
+func (g *GitLabStore) createSnippet(ctx context.Context, title, description, visibility, fileName, content string) (*gitLabSnippet, error) {
+	request := gitLabSnippetRequest{
+		Title:       title,
+		Description: description,
+		Visibility:  visibility,
+		Files: []gitLabFileAction{
+			{FilePath: fileName, Content: &content},
+		},
+	}
+
+	var created gitLabSnippet
+	if err := g.do(ctx, http.MethodPost, "/snippets", request, &created); err != nil {
+		return nil, err
+	}
+
+	return &created, nil
+}
+
+// getSnippet fetches snippet metadata
+func (g *GitLabStore) getSnippet(ctx context.Context, snippetID string) (*gitLabSnippet, error) {
+	var snippet gitLabSnippet
+	if err := g.do(ctx, http.MethodGet, "/snippets/"+url.PathEscape(snippetID), nil, &snippet); err != nil {
+		return nil, err
+	}
+	return &snippet, nil
+}
+
+// getSnippetContent fetches the raw content of a single-file snippet
+func (g *GitLabStore) getSnippetContent(ctx context.Context, snippetID string) (string, error) {
+	data, err := g.doRaw(ctx, http.MethodGet, "/snippets/"+url.PathEscape(snippetID)+"/raw", nil)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// writeSnippetFile replaces the file of a snippet, renaming it if the name changed
+func (g *GitLabStore) writeSnippetFile(ctx context.Context, snippetID, title, description, fileName, content string) error {
+	existing, err := g.getSnippet(ctx, snippetID)
+	if err != nil {
+		return err
+	}
+
+	action := gitLabFileAction{Action: "create", FilePath: fileName, Content: &content}
+	var removals []gitLabFileAction
+	for _, file := range existing.Files {
+		switch {
+		case file.Path == fileName:
+			action.Action = "update"
+		case action.Action == "create":
+			// Reuse the first other file as a rename so the snippet keeps one file
+			action.Action = "move"
+			action.PreviousPath = file.Path
+		default:
+			removals = append(removals, gitLabFileAction{Action: "delete", FilePath: file.Path})
+		}
+	}
+
+	request := gitLabSnippetRequest{
+		Title:       title,
+		Description: description,
+		Files:       append([]gitLabFileAction{action}, removals...),
+	}
+
+	return g.do(ctx, http.MethodPut, "/snippets/"+url.PathEscape(snippetID), request, nil)
+}
+
+// loadIndex loads the current index from the index snippet
+func (g *GitLabStore) loadIndex(ctx context.Context) (*model.Index, error) {
+	content, err := g.getSnippetContent(ctx, g.indexSnippetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get index snippet: %w", err)
+	}
+
+	index, err := DecodeIndex([]byte(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal index: %w", err)
+	}
+
+	return index, nil
+}
+
+// saveIndex saves the index to the index snippet
+func (g *GitLabStore) saveIndex(ctx context.Context, index *model.Index) error {
+	index.LastUpdated = time.Now()
+
+	indexContent, err := encodeIndex(index)
+	if err != nil {
+		return fmt.Errorf("failed to marshal index: %w", err)
+	}
+
+	content := string(indexContent)
+	request := gitLabSnippetRequest{
+		Files: []gitLabFileAction{
+			{Action: "update", FilePath: IndexFileName, Content: &content},
+		},
+	}
+
+	if err := g.do(ctx, http.MethodPut, "/snippets/"+g.indexSnippetID, request, nil); err != nil {
+		return fmt.Errorf("failed to update index snippet: %w", err)
+	}
+
+	return nil
+}
+
+// GetRawIndexContent retrieves the raw index.json content from GitLab
+func (g *GitLabStore) GetRawIndexContent(ctx context.Context) (string, error) {
+	if err := g.ensureInitialized(ctx); err != nil {
+		return "", err
+	}
+
+	content, err := g.getSnippetContent(ctx, g.indexSnippetID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get index snippet: %w", err)
+	}
+
+	return content, nil
+}
+
+// List returns all prompts from the index
+func (g *GitLabStore) List(ctx context.Context) ([]model.Prompt, error) {
+	if err := g.ensureInitialized(ctx); err != nil {
+		return nil, err
+	}
+
+	index, err := g.loadIndex(ctx)
+	if err != nil {
+		var apiErr *gitLabAPIError
+		if stderrors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+			return nil, ErrNoIndex
+		}
+		return nil, err
+	}
+
+	if len(index.Prompts) == 0 {
+		return nil, ErrEmptyIndex
+	}
+
+	var prompts []model.Prompt
+	for _, indexedPrompt := range index.Prompts {
+		snippetID := utils.ExtractGistIDFromURL(indexedPrompt.GistURL)
+		if snippetID == "" {
+			continue
+		}
+
+		prompts = append(prompts, promptFromIndex(snippetID, indexedPrompt))
+	}
+
+	if len(prompts) == 0 {
+		return nil, ErrEmptyIndex
+	}
+
+	return prompts, nil
+}
+
+// findExistingPrompt searches for an existing prompt with the same name and author
+func (g *GitLabStore) findExistingPrompt(ctx context.Context, name, author string) (*model.Prompt, error) {
+	allPrompts, err := g.List(ctx)
+	if err != nil {
+		if err == ErrNoIndex || err == ErrEmptyIndex {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	for _, prompt := range allPrompts {
+		if prompt.Name == name && prompt.Author == author {
+			return &prompt, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// FindExistingPromptByURL looks up a prompt by its snippet URL
+func (g *GitLabStore) FindExistingPromptByURL(ctx context.Context, gistURL string) (*model.Prompt, error) {
+	allPrompts, err := g.List(ctx)
+	if err != nil {
+		if err == ErrNoIndex || err == ErrEmptyIndex {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	for _, prompt := range allPrompts {
+		if prompt.GistURL == gistURL {
+			return &prompt, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// Add creates a new private prompt snippet and updates the index
+func (g *GitLabStore) Add(ctx context.Context, prompt model.Prompt) error {
+	if err := g.ensureInitialized(ctx); err != nil {
+		return err
+	}
+
+	existingPrompt, err := g.findExistingPrompt(ctx, prompt.Name, prompt.Author)
+	if err != nil {
+		return fmt.Errorf("failed to check for existing prompt: %w", err)
+	}
+
+	if existingPrompt != nil {
+		existingPrompt.Content = prompt.Content
+		existingPrompt.Description = prompt.Description
+		existingPrompt.Tags = prompt.Tags
+		existingPrompt.Version = prompt.Version
+
+		return g.Update(ctx, *existingPrompt)
+	}
+
+	fileName := prompt.Name + ".yaml"
+	created, err := g.createSnippet(ctx, snippetTitle(prompt), prompt.Description, gitLabVisibilityPrivate, fileName, prompt.Content)
+	if err != nil {
+		return fmt.Errorf("failed to create snippet: %w", err)
+	}
+
+	index, err := g.loadIndex(ctx)
+	if err != nil {
+		return err
+	}
+
+	indexedPrompt := model.IndexedPrompt{
+		GistURL:     created.WebURL,
+		FilePath:    fileName,
+		Author:      prompt.Author,
+		Name:        prompt.Name,
+		LastUpdated: time.Now(),
+	}
+	indexedPrompt.ApplyMetadata(prompt)
+	index.Prompts = append(index.Prompts, indexedPrompt)
+
+	return g.saveIndex(ctx, index)
+}
+
+// Delete removes matching prompt snippets and updates the index
+func (g *GitLabStore) Delete(ctx context.Context, keyword string) error {
+	if err := g.ensureInitialized(ctx); err != nil {
+		return err
+	}
+
+	index, err := g.loadIndex(ctx)
+	if err != nil {
+		return err
+	}
+
+	var remaining []model.IndexedPrompt
+	for _, indexedPrompt := range index.Prompts {
+		snippetID := utils.ExtractGistIDFromURL(indexedPrompt.GistURL)
+		if snippetID == keyword || strings.Contains(indexedPrompt.FilePath, keyword) {
+			if err := g.do(ctx, http.MethodDelete, "/snippets/"+url.PathEscape(snippetID), nil, nil); err != nil {
+				return fmt.Errorf("failed to delete snippet %s: %w", snippetID, err)
+			}
+			continue
+		}
+		remaining = append(remaining, indexedPrompt)
+	}
+
+	index.Prompts = remaining
+	return g.saveIndex(ctx, index)
+}
+
+// Update modifies an existing prompt snippet
+func (g *GitLabStore) Update(ctx context.Context, prompt model.Prompt) error {
+	if err := g.ensureInitialized(ctx); err != nil {
+		return err
+	}
+
+	fileName := prompt.Name + ".yaml"
+	if err := g.writeSnippetFile(ctx, prompt.ID, snippetTitle(prompt), prompt.Description, fileName, prompt.Content); err != nil {
+		return fmt.Errorf("failed to update snippet: %w", err)
+	}
+
+	index, err := g.loadIndex(ctx)
+	if err != nil {
+		return err
+	}
+
+	for i, indexedPrompt := range index.Prompts {
+		if utils.ExtractGistIDFromURL(indexedPrompt.GistURL) == prompt.ID {
+			index.Prompts[i].LastUpdated = time.Now()
+			index.Prompts[i].Author = prompt.Author
+			index.Prompts[i].Name = prompt.Name
+			index.Prompts[i].FilePath = fileName
+			index.Prompts[i].ApplyMetadata(prompt)
+			break
+		}
+	}
+
+	return g.saveIndex(ctx, index)
+}
+
+// Get searches for prompts by keyword
+func (g *GitLabStore) Get(ctx context.Context, keyword string) ([]model.Prompt, error) {
+	allPrompts, err := g.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var matchingPrompts []model.Prompt
+	for _, prompt := range allPrompts {
+		if matchesKeyword(prompt, keyword) {
+			matchingPrompts = append(matchingPrompts, prompt)
+		}
+	}
+
+	return matchingPrompts, nil
+}
+
+// BackfillMetadata records the metadata of index entries written by older pv versions
+func (g *GitLabStore) BackfillMetadata(ctx context.Context) (int, error) {
+	if err := g.ensureInitialized(ctx); err != nil {
+		return 0, err
+	}
+
+	index, err := g.loadIndex(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	filled := backfillIndexMetadata(index, func(entry model.IndexedPrompt) (string, error) {
+		return g.GetContent(ctx, utils.ExtractGistIDFromURL(entry.GistURL))
+	})
+	if filled == 0 {
+		return 0, nil
+	}
+
+	if err := g.saveIndex(ctx, index); err != nil {
+		return 0, err
+	}
+
+	return filled, nil
+}
+
+// GetContent retrieves the content of a prompt snippet
+func (g *GitLabStore) GetContent(ctx context.Context, gistID string) (string, error) {
+	if err := g.ensureInitialized(ctx); err != nil {
+		return "", err
+	}
+
+	content, err := g.getSnippetContent(ctx, gistID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get snippet %s: %w", gistID, err)
+	}
+
+	return content, nil
+}
+
+// CreatePublicGist creates a public snippet for sharing
+func (g *GitLabStore) CreatePublicGist(ctx context.Context, prompt model.Prompt) (string, error) {
+	if err := g.ensureInitialized(ctx); err != nil {
+		return "", err
+	}
+
+	created, err := g.createSnippet(ctx, snippetTitle(prompt), prompt.Description, gitLabVisibilityPublic, prompt.Name+".yaml", buildYAMLContent(prompt))
+	if err != nil {
+		return "", errors.NewShareError("创建公开 snippet", "", err)
+	}
+
+	return created.WebURL, nil
+}
+
+// UpdateGist updates the content of an existing snippet
+func (g *GitLabStore) UpdateGist(ctx context.Context, gistURL string, prompt model.Prompt) error {
+	if err := g.ensureInitialized(ctx); err != nil {
+		return err
+	}
+
+	snippetID := utils.ExtractGistIDFromURL(gistURL)
+	if err := g.writeSnippetFile(ctx, snippetID, snippetTitle(prompt), prompt.Description, prompt.Name+".yaml", buildYAMLContent(prompt)); err != nil {
+		return errors.NewShareError("更新 snippet", gistURL, err)
+	}
+
+	return nil
+}
+
+// GetGistInfo returns basic information about a snippet
+func (g *GitLabStore) GetGistInfo(ctx context.Context, gistURL string) (*GistInfo, error) {
+	if err := g.ensureInitialized(ctx); err != nil {
+		return nil, err
+	}
+
+	snippetID := utils.ExtractGistIDFromURL(gistURL)
+
+	snippet, err := g.getSnippet(ctx, snippetID)
+	if err != nil {
+		var apiErr *gitLabAPIError
+		if stderrors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+			return &GistInfo{
+				ID:        snippetID,
+				URL:       gistURL,
+				IsPublic:  false,
+				HasAccess: false,
+			}, nil
+		}
+		return nil, errors.NewShareError("获取 snippet 信息", gistURL, err)
+	}
+
+	var owner string
+	if snippet.Author != nil {
+		owner = snippet.Author.Username
+	}
+
+	return &GistInfo{
+		ID:          snippetID,
+		URL:         gistURL,
+		IsPublic:    snippet.Visibility == gitLabVisibilityPublic,
+		HasAccess:   true,
+		Description: snippet.Description,
+		Owner:       owner,
+	}, nil
+}
+
+// AddExport adds a new export record
+func (g *GitLabStore) AddExport(ctx context.Context, prompt model.IndexedPrompt) error {
+	if err := g.ensureInitialized(ctx); err != nil {
+		return err
+	}
+
+	index, err := g.loadIndex(ctx)
+	if err != nil {
+		return err
+	}
+
+	index.Exports = append(index.Exports, prompt)
+	return g.saveIndex(ctx, index)
+}
+
+// UpdateExport updates an existing export record or adds it if missing
+func (g *GitLabStore) UpdateExport(ctx context.Context, prompt model.IndexedPrompt) error {
+	if err := g.ensureInitialized(ctx); err != nil {
+		return err
+	}
+
+	index, err := g.loadIndex(ctx)
+	if err != nil {
+		return err
+	}
+
+	for i, export := range index.Exports {
+		if export.GistURL == prompt.GistURL {
+			index.Exports[i] = prompt
+			return g.saveIndex(ctx, index)
+		}
+	}
+
+	index.Exports = append(index.Exports, prompt)
+	return g.saveIndex(ctx, index)
+}
+
+// GetExports returns all export records
+func (g *GitLabStore) GetExports(ctx context.Context) ([]model.IndexedPrompt, error) {
+	if err := g.ensureInitialized(ctx); err != nil {
+		return nil, err
+	}
+
+	index, err := g.loadIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return index.Exports, nil
+}
+
+// snippetTitle builds the snippet title used for prompts, mirroring gist descriptions
+func snippetTitle(prompt model.Prompt) string {
+	return fmt.Sprintf("Prompt: %s", prompt.Name)
+}
@@ -0,0 +1,294 @@
+package infra
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/grigri/pv/internal/model"
+)
+
+// fakeSnippet is a snippet held by fakeGitLab
+type fakeSnippet struct {
+	title       string
+	description string
+	visibility  string
+	files       []string
+	contents    map[string]string
+}
+
+// fakeGitLab is an in-memory stand-in for the GitLab snippets API
+type fakeGitLab struct {
+	mu       sync.Mutex
+	server   *httptest.Server
+	nextID   int
+	snippets map[int]*fakeSnippet
+}
+
+func newFakeGitLab(t *testing.T) *fakeGitLab {
+	t.Helper()
+	f := &fakeGitLab{nextID: 1, snippets: map[int]*fakeSnippet{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v4/snippets", f.list)
+	mux.HandleFunc("POST /api/v4/snippets", f.create)
+	mux.HandleFunc("GET /api/v4/snippets/{id}", f.get)
+	mux.HandleFunc("GET /api/v4/snippets/{id}/raw", f.raw)
+	mux.HandleFunc("PUT /api/v4/snippets/{id}", f.update)
+	mux.HandleFunc("DELETE /api/v4/snippets/{id}", f.delete)
+
+	f.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("PRIVATE-TOKEN") != "test-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+func (f *fakeGitLab) payload(id int) gitLabSnippet {
+	s := f.snippets[id]
+	snippet := gitLabSnippet{
+		ID:          id,
+		Title:       s.title,
+		Description: s.description,
+		Visibility:  s.visibility,
+		WebURL:      fmt.Sprintf("%s/-/snippets/%d", f.server.URL, id),
+		Author:      &gitLabAuthor{Username: "alice"},
+	}
+	for _, path := range s.files {
+		snippet.Files = append(snippet.Files, gitLabSnippetFile{Path: path})
+	}
+	return snippet
+}
+
+func (f *fakeGitLab) lookup(w http.ResponseWriter, r *http.Request) (int, *fakeSnippet) {
+	id, _ := strconv.Atoi(r.PathValue("id"))
+	s, ok := f.snippets[id]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return 0, nil
+	}
+	return id, s
+}
+
+func (f *fakeGitLab) list(w http.ResponseWriter, r *http.Request) {
+	snippets := []gitLabSnippet{}
+	if r.URL.Query().Get("page") == "1" {
+		for id := 1; id < f.nextID; id++ {
+			if _, ok := f.snippets[id]; ok {
+				snippets = append(snippets, f.payload(id))
+			}
+		}
+	}
+	json.NewEncoder(w).Encode(snippets)
+}
+
+func (f *fakeGitLab) create(w http.ResponseWriter, r *http.Request) {
+	var req gitLabSnippetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	s := &fakeSnippet{title: req.Title, description: req.Description, visibility: req.Visibility, contents: map[string]string{}}
+	for _, file := range req.Files {
+		s.files = append(s.files, file.FilePath)
+		s.contents[file.FilePath] = *file.Content
+	}
+
+	id := f.nextID
+	f.nextID++
+	f.snippets[id] = s
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(f.payload(id))
+}
+
+func (f *fakeGitLab) get(w http.ResponseWriter, r *http.Request) {
+	if id, s := f.lookup(w, r); s != nil {
+		json.NewEncoder(w).Encode(f.payload(id))
+	}
+}
+
+func (f *fakeGitLab) raw(w http.ResponseWriter, r *http.Request) {
+	if _, s := f.lookup(w, r); s != nil {
+		fmt.Fprint(w, s.contents[s.files[0]])
+	}
+}
+
+func (f *fakeGitLab) update(w http.ResponseWriter, r *http.Request) {
+	id, s := f.lookup(w, r)
+	if s == nil {
+		return
+	}
+
+	var req gitLabSnippetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if req.Title != "" {
+		s.title = req.Title
+	}
+
+	for _, file := range req.Files {
+		switch file.Action {
+		case "create":
+			s.files = append(s.files, file.FilePath)
+		case "move":
+			for i, path := range s.files {
+				if path == file.PreviousPath {
+					s.files[i] = file.FilePath
+				}
+			}
+			delete(s.contents, file.PreviousPath)
+		case "delete":
+			var kept []string
+			for _, path := range s.files {
+				if path != file.FilePath {
+					kept = append(kept, path)
+				}
+			}
+			s.files = kept
+			delete(s.contents, file.FilePath)
+			continue
+		}
+		s.contents[file.FilePath] = *file.Content
+	}
+
+	json.NewEncoder(w).Encode(f.payload(id))
+}
+
+func (f *fakeGitLab) delete(w http.ResponseWriter, r *http.Request) {
+	if id, s := f.lookup(w, r); s != nil {
+		delete(f.snippets, id)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func TestGitLabStore_CreatesPrivateIndexSnippet(t *testing.T) {
+	fake := newFakeGitLab(t)
+	store := NewGitLabStore(&MockConfigStore{}, fake.server.URL)
+
+	if _, err := store.List(context.Background()); err != ErrEmptyIndex {
+		t.Fatalf("Expected ErrEmptyIndex, got: %v", err)
+	}
+
+	index := fake.snippets[1]
+	if index == nil || index.title != IndexGistDescription {
+		t.Fatalf("Expected index snippet to be created, got %+v", fake.snippets)
+	}
+	if index.visibility != gitLabVisibilityPrivate {
+		t.Errorf("Expected private index snippet, got %q", index.visibility)
+	}
+
+	// A second store finds the existing index instead of creating another one
+	again := NewGitLabStore(&MockConfigStore{}, fake.server.URL)
+	if _, err := again.List(context.Background()); err != ErrEmptyIndex {
+		t.Fatalf("Expected ErrEmptyIndex, got: %v", err)
+	}
+	if len(fake.snippets) != 1 {
+		t.Errorf("Expected a single index snippet, got %d snippets", len(fake.snippets))
+	}
+}
+
+func TestGitLabStore_AddUpdateDelete(t *testing.T) {
+	fake := newFakeGitLab(t)
+	store := NewGitLabStore(&MockConfigStore{}, fake.server.URL)
+
+	if err := store.Add(context.Background(), model.Prompt{Name: "Code Review", Author: "alice", Content: testPromptContent}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	prompts, err := store.List(context.Background())
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(prompts) != 1 {
+		t.Fatalf("Expected 1 prompt, got %d", len(prompts))
+	}
+
+	got := prompts[0]
+	if got.GistURL != fake.server.URL+"/-/snippets/"+got.ID {
+		t.Errorf("Unexpected snippet URL: %s", got.GistURL)
+	}
+
+	id, _ := strconv.Atoi(got.ID)
+	if visibility := fake.snippets[id].visibility; visibility != gitLabVisibilityPrivate {
+		t.Errorf("Expected private prompt snippet, got %q", visibility)
+	}
+
+	content, err := store.GetContent(context.Background(), got.ID)
+	if err != nil {
+		t.Fatalf("GetContent failed: %v", err)
+	}
+	if content != testPromptContent {
+		t.Errorf("GetContent = %q, want %q", content, testPromptContent)
+	}
+
+	got.Name = "Renamed"
+	got.Content = "v2"
+	if err := store.Update(context.Background(), got); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if files := fake.snippets[id].files; len(files) != 1 || files[0] != "Renamed.yaml" {
+		t.Errorf("Expected renamed snippet file, got %v", files)
+	}
+
+	if err := store.Delete(context.Background(), got.ID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, ok := fake.snippets[id]; ok {
+		t.Error("Expected prompt snippet to be deleted")
+	}
+	if _, err := store.List(context.Background()); err != ErrEmptyIndex {
+		t.Errorf("Expected ErrEmptyIndex after delete, got: %v", err)
+	}
+}
+
+func TestGitLabStore_ShareCreatesPublicSnippet(t *testing.T) {
+	fake := newFakeGitLab(t)
+	store := NewGitLabStore(&MockConfigStore{}, fake.server.URL)
+
+	publicURL, err := store.CreatePublicGist(context.Background(), model.Prompt{Name: "Shared", Author: "alice", Content: "body"})
+	if err != nil {
+		t.Fatalf("CreatePublicGist failed: %v", err)
+	}
+
+	info, err := store.GetGistInfo(context.Background(), publicURL)
+	if err != nil {
+		t.Fatalf("GetGistInfo failed: %v", err)
+	}
+	if !info.IsPublic || !info.HasAccess || info.Owner != "alice" {
+		t.Errorf("Unexpected snippet info: %+v", info)
+	}
+
+	if err := store.AddExport(context.Background(), model.IndexedPrompt{GistURL: publicURL, Name: "Shared"}); err != nil {
+		t.Fatalf("AddExport failed: %v", err)
+	}
+	exports, err := store.GetExports(context.Background())
+	if err != nil {
+		t.Fatalf("GetExports failed: %v", err)
+	}
+	if len(exports) != 1 || exports[0].GistURL != publicURL {
+		t.Errorf("Unexpected exports: %+v", exports)
+	}
+
+	missing, err := store.GetGistInfo(context.Background(), fake.server.URL+"/-/snippets/999")
+	if err != nil {
+		t.Fatalf("GetGistInfo failed: %v", err)
+	}
+	if missing.HasAccess {
+		t.Error("Expected missing snippet to report no access")
+	}
+}
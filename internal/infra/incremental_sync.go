@@ -0,0 +1,95 @@
+package infra
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/grigri/pv/internal/model"
+	"github.com/grigri/pv/internal/utils"
+)
+
+// SyncPlan is the outcome of refreshing the cached index during an incremental sync
+type SyncPlan struct {
+	// UpToDate holds the IDs of prompts whose cached content matches the remote index
+	UpToDate map[string]bool
+
+	// Evicted is the number of cached contents removed because their prompt was deleted remotely
+	Evicted int
+}
+
+// IsUpToDate reports whether the cached content of a prompt can be kept
+func (p *SyncPlan) IsUpToDate(gistID string) bool {
+	return p != nil && p.UpToDate[gistID]
+}
+
+// SyncIndex mirrors the remote index into the cache and works out which prompt
+// contents changed since the last sync. Content of prompts deleted remotely is evicted.
+func (c *CachedStore) SyncIndex(ctx context.Context) (*SyncPlan, error) {
+	// The index cached by the previous sync, if any
+	previous, err := c.cache.LoadIndex()
+	if err != nil {
+		previous = &model.Index{}
+	}
+
+	if err := c.SyncRawIndex(ctx); err != nil {
+		return nil, err
+	}
+
+	current, err := c.cache.LoadIndex()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read synced index: %w", err)
+	}
+
+	previousByURL := entriesByURL(previous.Prompts)
+	plan := &SyncPlan{UpToDate: make(map[string]bool)}
+
+	kept := make(map[string]bool)
+	for _, entry := range current.Prompts {
+		gistID := utils.ExtractGistIDFromURL(entry.GistURL)
+		kept[gistID] = true
+
+		if c.contentUpToDate(gistID, entry, previousByURL) {
+			plan.UpToDate[gistID] = true
+		}
+	}
+	for _, entry := range current.Exports {
+		kept[utils.ExtractGistIDFromURL(entry.GistURL)] = true
+	}
+
+	for _, entry := range previous.Prompts {
+		gistID := utils.ExtractGistIDFromURL(entry.GistURL)
+		if gistID == "" || kept[gistID] || strings.HasPrefix(gistID, pendingIDPrefix) {
+			continue
+		}
+
+		removed, err := c.cache.DeleteContent(gistID)
+		if err != nil {
+			return nil, err
+		}
+		c.cache.DeleteResponse(gistID)
+		c.cache.DeleteRevisions(gistID)
+		if removed {
+			plan.Evicted++
+		}
+	}
+
+	return plan, nil
+}
+
+// contentUpToDate reports whether the cached content of an index entry is current
+// The content hash is compared when the index records one; otherwise the entry must
+// be unchanged since the previous sync
+func (c *CachedStore) contentUpToDate(gistID string, entry model.IndexedPrompt, previousByURL map[string]model.IndexedPrompt) bool {
+	content, err := c.cache.LoadContent(gistID)
+	if err != nil {
+		return false
+	}
+
+	if entry.ContentHash != "" {
+		return model.ContentHash(content) == entry.ContentHash
+	}
+
+	previous, ok := previousByURL[entry.GistURL]
+	return ok && previous.LastUpdated.Equal(entry.LastUpdated)
+}
@@ -0,0 +1,77 @@
+package infra
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/grigri/pv/internal/model"
+)
+
+func TestCachedStore_SyncIndex(t *testing.T) {
+	remote := NewFileSystemStore(filepath.Join(t.TempDir(), "vault"))
+	cache := &CacheManager{cacheDir: t.TempDir()}
+	store := NewCachedStore(remote, cache, &MockConfigStore{}, false).(*CachedStore)
+
+	for _, name := range []string{"Kept", "Changed", "Removed"} {
+		if err := remote.Add(context.Background(), model.Prompt{Name: name, Author: "alice", Content: testPromptContent}); err != nil {
+			t.Fatalf("Add %s failed: %v", name, err)
+		}
+	}
+
+	// First sync: nothing is cached yet, so everything needs downloading
+	plan, err := store.SyncIndex(context.Background())
+	if err != nil {
+		t.Fatalf("SyncIndex failed: %v", err)
+	}
+	if len(plan.UpToDate) != 0 || plan.Evicted != 0 {
+		t.Fatalf("Expected an empty plan on the first sync, got %+v", plan)
+	}
+
+	prompts, err := store.List(context.Background())
+	if err != nil || len(prompts) != 3 {
+		t.Fatalf("List failed: %+v, %v", prompts, err)
+	}
+	ids := make(map[string]model.Prompt)
+	for _, prompt := range prompts {
+		if _, err := store.GetContent(context.Background(), prompt.ID); err != nil {
+			t.Fatalf("GetContent failed: %v", err)
+		}
+		ids[prompt.Name] = prompt
+	}
+
+	// Change one prompt and delete another remotely
+	changed := ids["Changed"]
+	changed.Content = testPromptContent + "\nchanged"
+	if err := remote.Update(context.Background(), changed); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if err := remote.Delete(context.Background(), ids["Removed"].ID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+
+	plan, err = store.SyncIndex(context.Background())
+	if err != nil {
+		t.Fatalf("SyncIndex failed: %v", err)
+	}
+
+	if !plan.IsUpToDate(ids["Kept"].ID) {
+		t.Errorf("Expected the unchanged prompt to be up to date, got %+v", plan)
+	}
+	if plan.IsUpToDate(changed.ID) {
+		t.Errorf("Expected the changed prompt to need downloading, got %+v", plan)
+	}
+	if plan.Evicted != 1 {
+		t.Errorf("Expected one evicted prompt, got %d", plan.Evicted)
+	}
+	if _, err := cache.LoadContent(ids["Removed"].ID); err == nil {
+		t.Error("Expected the deleted prompt's content to be evicted from the cache")
+	}
+}
+
+func TestSyncPlan_IsUpToDate_Nil(t *testing.T) {
+	var plan *SyncPlan
+	if plan.IsUpToDate("any") {
+		t.Error("Expected a nil plan to report nothing as up to date")
+	}
+}
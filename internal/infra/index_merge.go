@@ -0,0 +1,165 @@
+package infra
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/grigri/pv/internal/model"
+)
+
+// ErrIndexConflict is returned when concurrent index changes touch the same entry
+var ErrIndexConflict = fmt.Errorf("the prompt index was changed elsewhere and the changes could not be merged automatically - run `pv sync` and try again")
+
+// cloneIndex returns a deep copy of an index so later edits don't alter the snapshot
+func cloneIndex(index *model.Index) *model.Index {
+	if index == nil {
+		return nil
+	}
+
+	clone := *index
+	clone.Prompts = cloneEntries(index.Prompts)
+	clone.Exports = cloneEntries(index.Exports)
+	return &clone
+}
+
+// cloneEntries copies a slice of index entries, including their Parent pointers
+func cloneEntries(entries []model.IndexedPrompt) []model.IndexedPrompt {
+	if entries == nil {
+		return nil
+	}
+
+	clone := make([]model.IndexedPrompt, len(entries))
+	for i, entry := range entries {
+		if entry.Parent != nil {
+			parent := *entry.Parent
+			entry.Parent = &parent
+		}
+		clone[i] = entry
+	}
+	return clone
+}
+
+// mergeIndex performs a three-way merge of index entries keyed by gist URL
+// base is the index that was read, ours is the index about to be written and
+// theirs is the index written concurrently by someone else. Entries changed on
+// only one side take that side's value; entries changed differently on both
+// sides are a conflict.
+func mergeIndex(base, ours, theirs *model.Index) (*model.Index, error) {
+	var conflicts []string
+
+	prompts, promptConflicts := mergeEntries(base.Prompts, ours.Prompts, theirs.Prompts)
+	conflicts = append(conflicts, promptConflicts...)
+
+	exports, exportConflicts := mergeEntries(base.Exports, ours.Exports, theirs.Exports)
+	conflicts = append(conflicts, exportConflicts...)
+
+	if len(conflicts) > 0 {
+		return nil, fmt.Errorf("%w (conflicting entries: %s)", ErrIndexConflict, strings.Join(conflicts, ", "))
+	}
+
+	merged := cloneIndex(ours)
+	merged.Prompts = prompts
+	merged.Exports = exports
+	return merged, nil
+}
+
+// mergeEntries merges one list of index entries, keeping theirs' order and appending our new entries
+func mergeEntries(base, ours, theirs []model.IndexedPrompt) ([]model.IndexedPrompt, []string) {
+	baseByURL := entriesByURL(base)
+	oursByURL := entriesByURL(ours)
+	theirsByURL := entriesByURL(theirs)
+
+	var merged []model.IndexedPrompt
+	var conflicts []string
+
+	resolve := func(url string) {
+		b, inBase := baseByURL[url]
+		o, inOurs := oursByURL[url]
+		t, inTheirs := theirsByURL[url]
+
+		switch {
+		case sameEntry(o, inOurs, t, inTheirs):
+			// Both sides agree (including both deleting)
+			if inOurs {
+				merged = append(merged, o)
+			}
+		case sameEntry(o, inOurs, b, inBase):
+			// Only theirs changed this entry
+			if inTheirs {
+				merged = append(merged, t)
+			}
+		case sameEntry(t, inTheirs, b, inBase):
+			// Only we changed this entry
+			if inOurs {
+				merged = append(merged, o)
+			}
+		default:
+			conflicts = append(conflicts, url)
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, entry := range theirs {
+		if !seen[entry.GistURL] {
+			seen[entry.GistURL] = true
+			resolve(entry.GistURL)
+		}
+	}
+	for _, entry := range ours {
+		if !seen[entry.GistURL] {
+			seen[entry.GistURL] = true
+			resolve(entry.GistURL)
+		}
+	}
+	for _, entry := range base {
+		if !seen[entry.GistURL] {
+			seen[entry.GistURL] = true
+			resolve(entry.GistURL)
+		}
+	}
+
+	return merged, conflicts
+}
+
+// sameIndexEntries reports whether two indexes hold the same prompts and exports
+func sameIndexEntries(a, b *model.Index) bool {
+	return sameEntryList(a.Prompts, b.Prompts) && sameEntryList(a.Exports, b.Exports)
+}
+
+// sameEntryList reports whether two entry lists are identical in content and order
+func sameEntryList(a, b []model.IndexedPrompt) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !sameEntry(a[i], true, b[i], true) {
+			return false
+		}
+	}
+	return true
+}
+
+// entriesByURL indexes entries by gist URL
+func entriesByURL(entries []model.IndexedPrompt) map[string]model.IndexedPrompt {
+	byURL := make(map[string]model.IndexedPrompt, len(entries))
+	for _, entry := range entries {
+		byURL[entry.GistURL] = entry
+	}
+	return byURL
+}
+
+// sameEntry reports whether two possibly-absent entries are identical once serialized
+func sameEntry(a model.IndexedPrompt, hasA bool, b model.IndexedPrompt, hasB bool) bool {
+	if hasA != hasB {
+		return false
+	}
+	if !hasA {
+		return true
+	}
+
+	aJSON, errA := json.Marshal(a)
+	bJSON, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(aJSON, bJSON)
+}
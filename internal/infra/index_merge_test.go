@@ -0,0 +1,58 @@
+package infra
+
+import (
+	stderrors "errors"
+	"testing"
+
+	"github.com/grigri/pv/internal/model"
+)
+
+func TestMergeIndex(t *testing.T) {
+	entry := func(url, name string) model.IndexedPrompt {
+		return model.IndexedPrompt{GistURL: url, Name: name}
+	}
+
+	base := &model.Index{
+		Prompts: []model.IndexedPrompt{entry("a", "A"), entry("b", "B"), entry("c", "C")},
+		Exports: []model.IndexedPrompt{entry("x", "X")},
+	}
+
+	// We delete b and add d; they rename c, delete x and add e
+	ours := cloneIndex(base)
+	ours.Prompts = []model.IndexedPrompt{entry("a", "A"), entry("c", "C"), entry("d", "D")}
+
+	theirs := cloneIndex(base)
+	theirs.Prompts = []model.IndexedPrompt{entry("a", "A"), entry("b", "B"), entry("c", "C2"), entry("e", "E")}
+	theirs.Exports = nil
+
+	merged, err := mergeIndex(base, ours, theirs)
+	if err != nil {
+		t.Fatalf("mergeIndex failed: %v", err)
+	}
+
+	var names []string
+	for _, prompt := range merged.Prompts {
+		names = append(names, prompt.Name)
+	}
+	if got, want := len(names), 4; got != want {
+		t.Fatalf("Expected %d prompts, got %v", want, names)
+	}
+	for i, want := range []string{"A", "C2", "E", "D"} {
+		if names[i] != want {
+			t.Errorf("Prompt %d = %q, want %q (all: %v)", i, names[i], want, names)
+		}
+	}
+	if len(merged.Exports) != 0 {
+		t.Errorf("Expected export deleted on their side to stay deleted, got %+v", merged.Exports)
+	}
+
+	// Deleting an entry the other side modified is a conflict
+	ours = cloneIndex(base)
+	ours.Prompts = ours.Prompts[1:]
+	theirs = cloneIndex(base)
+	theirs.Prompts[0].Name = "A2"
+
+	if _, err := mergeIndex(base, ours, theirs); !stderrors.Is(err, ErrIndexConflict) {
+		t.Errorf("Expected ErrIndexConflict, got: %v", err)
+	}
+}
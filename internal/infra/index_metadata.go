@@ -0,0 +1,80 @@
+package infra
+
+import (
+	"strings"
+
+	"github.com/grigri/pv/internal/model"
+	"github.com/grigri/pv/internal/validator"
+)
+
+// promptFromIndex builds a metadata-only prompt from an index entry
+// Content is left empty and fetched on demand with GetContent
+func promptFromIndex(id string, entry model.IndexedPrompt) model.Prompt {
+	return model.Prompt{
+		ID:          id,
+		Name:        entry.Name,
+		Author:      entry.Author,
+		GistURL:     entry.GistURL,
+		Description: entry.Description,
+		Tags:        entry.Tags,
+		Version:     entry.Version,
+		Format:      model.FormatFromPath(entry.FilePath),
+	}
+}
+
+// matchesKeyword reports whether the prompt's name, author, ID, description or tags contain keyword
+func matchesKeyword(prompt model.Prompt, keyword string) bool {
+	keyword = strings.ToLower(keyword)
+
+	if strings.Contains(strings.ToLower(prompt.Name), keyword) ||
+		strings.Contains(strings.ToLower(prompt.Author), keyword) ||
+		strings.Contains(strings.ToLower(prompt.ID), keyword) ||
+		strings.Contains(strings.ToLower(prompt.Description), keyword) {
+		return true
+	}
+
+	for _, tag := range prompt.Tags {
+		if strings.Contains(strings.ToLower(tag), keyword) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// backfillIndexMetadata fills in the metadata of entries that have no content hash yet
+// fetch returns the content of an entry; entries it fails on are left for the next run.
+// It returns the number of entries filled in.
+func backfillIndexMetadata(index *model.Index, fetch func(entry model.IndexedPrompt) (string, error)) int {
+	yamlValidator := validator.NewYAMLValidator()
+	filled := 0
+
+	for i, entry := range index.Prompts {
+		if entry.ContentHash != "" {
+			continue
+		}
+
+		content, err := fetch(entry)
+		if err != nil {
+			continue
+		}
+
+		// Content that doesn't parse still gets a hash so it isn't fetched again
+		prompt := model.Prompt{
+			Description: entry.Description,
+			Tags:        entry.Tags,
+			Version:     entry.Version,
+			Content:     content,
+		}
+		if parsed, err := yamlValidator.ValidatePromptFile([]byte(content)); err == nil {
+			prompt.Description = parsed.Metadata.Description
+			prompt.Tags = parsed.Metadata.Tags
+			prompt.Version = parsed.Metadata.Version
+		}
+
+		index.Prompts[i].ApplyMetadata(prompt)
+		filled++
+	}
+
+	return filled
+}
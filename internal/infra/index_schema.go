@@ -0,0 +1,92 @@
+package infra
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/grigri/pv/internal/model"
+)
+
+// ErrIndexSchemaTooNew is returned when writing an index created by a newer pv
+// Writing it back would drop fields this build doesn't know about
+var ErrIndexSchemaTooNew = fmt.Errorf("the prompt index was written by a newer version of pv - please upgrade pv before making changes")
+
+// indexMigration upgrades a raw index document from one schema version to the next
+type indexMigration struct {
+	from    int
+	migrate func(doc map[string]json.RawMessage) error
+}
+
+// indexMigrations are applied in order to bring old indexes up to model.CurrentIndexSchemaVersion
+// Add a new entry here, and bump the version, whenever the index format changes
+var indexMigrations = []indexMigration{
+	{from: 0, migrate: migrateIndexV0},
+	{from: 1, migrate: migrateIndexV1},
+}
+
+// migrateIndexV0 upgrades indexes written before schema versioning
+// Early indexes had no exports list and may hold null lists
+func migrateIndexV0(doc map[string]json.RawMessage) error {
+	for _, key := range []string{"prompts", "exports"} {
+		if raw, ok := doc[key]; !ok || string(raw) == "null" {
+			doc[key] = json.RawMessage("[]")
+		}
+	}
+	return nil
+}
+
+// migrateIndexV1 upgrades indexes written before entries carried prompt metadata
+// The new description, tags, version and content_hash fields are optional, so
+// entries are left as they are and filled in later by BackfillMetadata
+func migrateIndexV1(doc map[string]json.RawMessage) error {
+	return nil
+}
+
+// DecodeIndex parses index.json content and migrates it to the current schema version
+// An index from a newer pv is returned as-is with its version, so it can be read but not written
+func DecodeIndex(data []byte) (*model.Index, error) {
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	version := 0
+	if raw, ok := doc["schema_version"]; ok {
+		if err := json.Unmarshal(raw, &version); err != nil {
+			return nil, fmt.Errorf("invalid schema_version: %w", err)
+		}
+	}
+
+	for _, migration := range indexMigrations {
+		if migration.from != version {
+			continue
+		}
+		if err := migration.migrate(doc); err != nil {
+			return nil, fmt.Errorf("failed to migrate index from schema version %d: %w", version, err)
+		}
+		version = migration.from + 1
+	}
+
+	migrated, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	var index model.Index
+	if err := json.Unmarshal(migrated, &index); err != nil {
+		return nil, err
+	}
+	index.SchemaVersion = version
+
+	return &index, nil
+}
+
+// encodeIndex serializes an index at the current schema version
+func encodeIndex(index *model.Index) ([]byte, error) {
+	if index.SchemaVersion > model.CurrentIndexSchemaVersion {
+		return nil, fmt.Errorf("%w (index schema version %d, supported %d)", ErrIndexSchemaTooNew, index.SchemaVersion, model.CurrentIndexSchemaVersion)
+	}
+
+	index.SchemaVersion = model.CurrentIndexSchemaVersion
+	return json.MarshalIndent(index, "", "  ")
+}
@@ -0,0 +1,108 @@
+package infra
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grigri/pv/internal/model"
+)
+
+func TestDecodeIndex_MigratesLegacyIndex(t *testing.T) {
+	legacy := `{"prompts":[{"gist_url":"https://gist.github.com/u/1","filename":"a.yaml","name":"A","author":"u","last_updated":"2024-01-01T00:00:00Z"}],"last_updated":"2024-01-01T00:00:00Z"}`
+
+	index, err := DecodeIndex([]byte(legacy))
+	if err != nil {
+		t.Fatalf("DecodeIndex failed: %v", err)
+	}
+
+	if index.SchemaVersion != model.CurrentIndexSchemaVersion {
+		t.Errorf("Expected schema version %d, got %d", model.CurrentIndexSchemaVersion, index.SchemaVersion)
+	}
+	if len(index.Prompts) != 1 || index.Prompts[0].Name != "A" {
+		t.Errorf("Expected the legacy prompt to survive migration, got %+v", index.Prompts)
+	}
+	if index.Exports == nil {
+		t.Error("Expected a missing exports list to be migrated to an empty list")
+	}
+
+	data, err := encodeIndex(index)
+	if err != nil {
+		t.Fatalf("encodeIndex failed: %v", err)
+	}
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("encoded index is not valid JSON: %v", err)
+	}
+	if got := doc["schema_version"]; got != float64(model.CurrentIndexSchemaVersion) {
+		t.Errorf("Expected schema_version %d in encoded index, got %v", model.CurrentIndexSchemaVersion, got)
+	}
+}
+
+func TestDecodeIndex_NullLists(t *testing.T) {
+	index, err := DecodeIndex([]byte(`{"prompts":null,"exports":null,"last_updated":"2024-01-01T00:00:00Z"}`))
+	if err != nil {
+		t.Fatalf("DecodeIndex failed: %v", err)
+	}
+	if index.Prompts == nil || index.Exports == nil {
+		t.Errorf("Expected null lists to be migrated to empty lists, got %+v", index)
+	}
+}
+
+func TestDecodeIndex_InvalidSchemaVersion(t *testing.T) {
+	if _, err := DecodeIndex([]byte(`{"schema_version":"one","prompts":[]}`)); err == nil {
+		t.Error("Expected an error for a non-numeric schema_version")
+	}
+}
+
+func TestEncodeIndex_RefusesNewerSchema(t *testing.T) {
+	newer := `{"schema_version":99,"prompts":[],"exports":[],"last_updated":"2024-01-01T00:00:00Z","future_field":true}`
+
+	index, err := DecodeIndex([]byte(newer))
+	if err != nil {
+		t.Fatalf("Expected an index from a newer pv to stay readable, got %v", err)
+	}
+	if index.SchemaVersion != 99 {
+		t.Errorf("Expected schema version 99 to be kept, got %d", index.SchemaVersion)
+	}
+
+	if _, err := encodeIndex(index); !stderrors.Is(err, ErrIndexSchemaTooNew) {
+		t.Errorf("Expected ErrIndexSchemaTooNew, got %v", err)
+	}
+}
+
+func TestCacheManager_LoadIndex_MigratesLegacyFile(t *testing.T) {
+	tempDir := t.TempDir()
+	manager := &CacheManager{cacheDir: tempDir}
+
+	legacy := `{"prompts":[],"last_updated":"2024-01-01T00:00:00Z"}`
+	if err := os.WriteFile(filepath.Join(tempDir, "index.json"), []byte(legacy), 0644); err != nil {
+		t.Fatalf("failed to write legacy index: %v", err)
+	}
+
+	index, err := manager.LoadIndex()
+	if err != nil {
+		t.Fatalf("LoadIndex failed: %v", err)
+	}
+	if index.SchemaVersion != model.CurrentIndexSchemaVersion {
+		t.Errorf("Expected schema version %d, got %d", model.CurrentIndexSchemaVersion, index.SchemaVersion)
+	}
+}
+
+func TestDecodeIndex_MigratesV1Entries(t *testing.T) {
+	v1 := `{"schema_version":1,"prompts":[{"gist_url":"https://gist.github.com/u/1","file_path":"a.yaml","name":"A","author":"u","last_updated":"2024-01-01T00:00:00Z"}],"exports":[],"last_updated":"2024-01-01T00:00:00Z"}`
+
+	index, err := DecodeIndex([]byte(v1))
+	if err != nil {
+		t.Fatalf("DecodeIndex failed: %v", err)
+	}
+	if index.SchemaVersion != model.CurrentIndexSchemaVersion {
+		t.Errorf("Expected schema version %d, got %d", model.CurrentIndexSchemaVersion, index.SchemaVersion)
+	}
+	if index.Prompts[0].ContentHash != "" {
+		t.Errorf("Expected v1 entries to be left for the metadata backfill, got %+v", index.Prompts[0])
+	}
+}
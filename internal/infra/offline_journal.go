@@ -0,0 +1,474 @@
+package infra
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v74/github"
+	"github.com/grigri/pv/internal/errors"
+	"github.com/grigri/pv/internal/model"
+	"github.com/grigri/pv/internal/utils"
+)
+
+const (
+	// JournalFileName is the offline operation journal kept in the cache directory
+	JournalFileName = "journal.json"
+
+	// pendingIDPrefix marks the IDs of prompts added offline that have no remote ID yet
+	pendingIDPrefix = "pending-"
+
+	// pendingURLPrefix is the URL scheme of prompts added offline
+	pendingURLPrefix = "pending://"
+)
+
+// OperationConflict is a queued operation dropped because the remote changed in the meantime
+type OperationConflict struct {
+	Operation model.PendingOperation
+	Reason    string
+
+	// SavedTo is the path of the local content kept in the cache, empty if none was kept
+	SavedTo string
+}
+
+// String describes the conflict for the user
+func (c OperationConflict) String() string {
+	target := c.Operation.Keyword
+	if c.Operation.Prompt != nil {
+		target = c.Operation.Prompt.Name
+	} else if c.Operation.Export != nil {
+		target = c.Operation.Export.Name
+	}
+
+	message := fmt.Sprintf("%s %q: %s", c.Operation.Type, target, c.Reason)
+	if c.SavedTo != "" {
+		message += fmt.Sprintf(" (local copy saved to %s)", c.SavedTo)
+	}
+	return message
+}
+
+// ReplayReport summarises a replay of the offline journal
+type ReplayReport struct {
+	// Applied is the number of operations pushed to the remote
+	Applied int
+
+	// Pending is the number of operations still queued because the remote is unreachable
+	Pending int
+
+	// Conflicts are the operations dropped because they conflict with remote changes
+	Conflicts []OperationConflict
+}
+
+// isOfflineError reports whether err means the remote store could not be reached
+func isOfflineError(err error) bool {
+	var netErr net.Error
+	if stderrors.As(err, &netErr) {
+		return true
+	}
+
+	var appErr errors.AppError
+	return stderrors.As(err, &appErr) && appErr.Type == errors.ErrNetwork
+}
+
+// isNotFoundError reports whether err means the remote store has no such prompt
+func isNotFoundError(err error) bool {
+	var appErr errors.AppError
+	if stderrors.As(err, &appErr) && appErr.Type == errors.ErrNotFound {
+		return true
+	}
+
+	var githubErr *github.ErrorResponse
+	if stderrors.As(err, &githubErr) && githubErr.Response != nil {
+		return githubErr.Response.StatusCode == http.StatusNotFound
+	}
+	var gitLabErr *gitLabAPIError
+	if stderrors.As(err, &gitLabErr) {
+		return gitLabErr.StatusCode == http.StatusNotFound
+	}
+	if isGiteaNotFound(err) {
+		return true
+	}
+
+	// 本地目录后端的条目不存在
+	return stderrors.Is(err, fs.ErrNotExist)
+}
+
+// isInterrupted reports whether a remote call failed because the command was cancelled
+// Unlike timeouts, cancellations are neither served from the cache nor queued
+func isInterrupted(ctx context.Context, err error) bool {
+	return stderrors.Is(err, context.Canceled) || stderrors.Is(ctx.Err(), context.Canceled)
+}
+
+// canQueue reports whether a failed write should be queued in the offline journal
+func (c *CachedStore) canQueue(ctx context.Context, err error) bool {
+	return !c.forceRemote && !isInterrupted(ctx, err) && isOfflineError(err)
+}
+
+// queueOperation appends an operation to the offline journal
+func (c *CachedStore) queueOperation(operation model.PendingOperation) error {
+	operations, err := c.cache.LoadJournal()
+	if err != nil {
+		return err
+	}
+
+	operation.QueuedAt = time.Now()
+	return c.cache.SaveJournal(append(operations, operation))
+}
+
+// cachedContentHash returns the content hash of a prompt as last seen from the remote
+// The cached content is preferred; the hash recorded in the cached index is the fallback
+func (c *CachedStore) cachedContentHash(gistID string) string {
+	if content, err := c.cache.LoadContent(gistID); err == nil {
+		return model.ContentHash(content)
+	}
+
+	if index, err := c.cache.LoadIndex(); err == nil {
+		for _, indexedPrompt := range index.Prompts {
+			if utils.ExtractGistIDFromURL(indexedPrompt.GistURL) == gistID {
+				return indexedPrompt.ContentHash
+			}
+		}
+	}
+
+	return ""
+}
+
+// addOffline adds a prompt to the cache under a pending ID and queues it
+func (c *CachedStore) addOffline(prompt model.Prompt) error {
+	id, err := newEntryID()
+	if err != nil {
+		return err
+	}
+
+	prompt.ID = pendingIDPrefix + id
+	prompt.GistURL = pendingURLPrefix + prompt.ID
+
+	if err := c.queueOperation(model.PendingOperation{Type: model.OperationAdd, Prompt: &prompt}); err != nil {
+		return fmt.Errorf("failed to queue offline add: %w", err)
+	}
+
+	c.addToCache(prompt)
+	return nil
+}
+
+// pendingPrompts returns the prompts added offline that are still queued in the journal
+func (c *CachedStore) pendingPrompts() []model.Prompt {
+	operations, err := c.cache.LoadJournal()
+	if err != nil {
+		return nil
+	}
+
+	var prompts []model.Prompt
+	for _, operation := range operations {
+		if operation.Type == model.OperationAdd && operation.Prompt != nil && strings.HasPrefix(operation.Prompt.ID, pendingIDPrefix) {
+			prompts = append(prompts, *operation.Prompt)
+		}
+	}
+	return prompts
+}
+
+// updateOffline applies a prompt change to the cache and queues it
+// Changes to a prompt that was itself added offline are folded into its queued add
+func (c *CachedStore) updateOffline(prompt model.Prompt) error {
+	if strings.HasPrefix(prompt.ID, pendingIDPrefix) {
+		operations, err := c.cache.LoadJournal()
+		if err != nil {
+			return err
+		}
+
+		found := false
+		for i, operation := range operations {
+			if operation.Type == model.OperationAdd && operation.Prompt != nil && operation.Prompt.ID == prompt.ID {
+				updated := prompt
+				operations[i].Prompt = &updated
+				found = true
+			}
+		}
+		if !found {
+			return fmt.Errorf("offline prompt %s is no longer queued", prompt.ID)
+		}
+
+		if err := c.cache.SaveJournal(operations); err != nil {
+			return fmt.Errorf("failed to queue offline update: %w", err)
+		}
+	} else {
+		operation := model.PendingOperation{
+			Type:       model.OperationUpdate,
+			Prompt:     &prompt,
+			BaseHashes: map[string]string{prompt.ID: c.cachedContentHash(prompt.ID)},
+		}
+		if err := c.queueOperation(operation); err != nil {
+			return fmt.Errorf("failed to queue offline update: %w", err)
+		}
+	}
+
+	c.updateCache(prompt)
+	return nil
+}
+
+// deleteOffline removes the matching cached prompts and queues their deletion
+// Prompts that were added offline are simply dropped from the journal
+func (c *CachedStore) deleteOffline(keyword string) error {
+	index, err := c.cache.LoadIndex()
+	if err != nil {
+		return fmt.Errorf("remote is unreachable and no cache is available: %w", err)
+	}
+
+	baseHashes := make(map[string]string)
+	dropped := make(map[string]bool)
+	for _, indexedPrompt := range index.Prompts {
+		if !matchesDeleteKeyword(indexedPrompt, keyword) {
+			continue
+		}
+
+		gistID := utils.ExtractGistIDFromURL(indexedPrompt.GistURL)
+		if strings.HasPrefix(gistID, pendingIDPrefix) {
+			dropped[gistID] = true
+		} else {
+			baseHashes[gistID] = c.cachedContentHash(gistID)
+		}
+	}
+
+	operations, err := c.cache.LoadJournal()
+	if err != nil {
+		return err
+	}
+
+	var remaining []model.PendingOperation
+	for _, operation := range operations {
+		if operation.Type == model.OperationAdd && operation.Prompt != nil && dropped[operation.Prompt.ID] {
+			continue
+		}
+		remaining = append(remaining, operation)
+	}
+
+	if len(baseHashes) > 0 {
+		remaining = append(remaining, model.PendingOperation{
+			Type:       model.OperationDelete,
+			Keyword:    keyword,
+			BaseHashes: baseHashes,
+			QueuedAt:   time.Now(),
+		})
+	}
+
+	if err := c.cache.SaveJournal(remaining); err != nil {
+		return fmt.Errorf("failed to queue offline delete: %w", err)
+	}
+
+	c.deleteFromCache(keyword)
+	return nil
+}
+
+// addExportOffline records an export in the cache and queues it
+func (c *CachedStore) addExportOffline(prompt model.IndexedPrompt) error {
+	if err := c.queueOperation(model.PendingOperation{Type: model.OperationAddExport, Export: &prompt}); err != nil {
+		return fmt.Errorf("failed to queue offline export: %w", err)
+	}
+
+	c.addExportToCache(prompt)
+	return nil
+}
+
+// replayPending pushes queued offline operations before a write to the remote
+// Reads don't replay, so read-only commands pay no extra round-trips; pv sync and the next
+// write push the journal. Operations that still can't be pushed stay queued; the outcome
+// is reported on stderr.
+func (c *CachedStore) replayPending(ctx context.Context) {
+	report, err := c.ReplayJournal(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  离线修改推送失败，将在下次联网时重试: %v\n", err)
+		return
+	}
+
+	if report.Applied > 0 {
+		fmt.Fprintf(os.Stderr, "⏫ 已推送 %d 个离线修改\n", report.Applied)
+	}
+	for _, conflict := range report.Conflicts {
+		fmt.Fprintf(os.Stderr, "⚠️  离线修改与远程冲突，已放弃: %s\n", conflict)
+	}
+}
+
+// ReplayJournal pushes the operations queued while offline to the remote store
+// Operations stay queued while the remote is unreachable. Operations that conflict with
+// remote changes are dropped and reported, keeping a copy of their local content.
+func (c *CachedStore) ReplayJournal(ctx context.Context) (*ReplayReport, error) {
+	report := &ReplayReport{}
+
+	operations, err := c.cache.LoadJournal()
+	if err != nil {
+		return nil, err
+	}
+	if len(operations) == 0 {
+		return report, nil
+	}
+
+	for i, operation := range operations {
+		conflict, err := c.replayOperation(ctx, operation)
+		if err != nil {
+			// Keep this and the later operations queued, in order
+			if saveErr := c.cache.SaveJournal(operations[i:]); saveErr != nil {
+				return nil, saveErr
+			}
+			if isOfflineError(err) {
+				report.Pending = len(operations) - i
+				return report, nil
+			}
+			return nil, fmt.Errorf("failed to replay offline %s: %w", operation.Type, err)
+		}
+
+		if conflict != nil {
+			report.Conflicts = append(report.Conflicts, *conflict)
+		} else {
+			report.Applied++
+		}
+
+		// Persist progress so an interrupted replay doesn't push an operation twice
+		if err := c.cache.SaveJournal(operations[i+1:]); err != nil {
+			return nil, err
+		}
+	}
+
+	// Replace the entries written to the cache while offline with the remote state
+	if report.Applied > 0 {
+		if prompts, err := c.remote.List(ctx); err == nil {
+			c.updateCacheFromPrompts(prompts)
+		}
+	}
+
+	return report, nil
+}
+
+// replayOperation pushes one queued operation, returning a conflict instead when the
+// remote changed in a way the operation would overwrite
+func (c *CachedStore) replayOperation(ctx context.Context, operation model.PendingOperation) (*OperationConflict, error) {
+	switch operation.Type {
+	case model.OperationAdd:
+		return c.replayAdd(ctx, operation)
+	case model.OperationUpdate:
+		return c.replayUpdate(ctx, operation)
+	case model.OperationDelete:
+		return c.replayDelete(ctx, operation)
+	case model.OperationAddExport:
+		return c.replayAddExport(ctx, operation)
+	default:
+		return &OperationConflict{Operation: operation, Reason: "unknown operation type"}, nil
+	}
+}
+
+// conflict builds an OperationConflict, saving the local prompt content when there is any
+func (c *CachedStore) conflict(operation model.PendingOperation, reason string) *OperationConflict {
+	result := &OperationConflict{Operation: operation, Reason: reason}
+	if operation.Prompt != nil && operation.Prompt.Content != "" {
+		if path, err := c.cache.SaveConflictCopy(operation.Prompt.Name, operation.Prompt.Content); err == nil {
+			result.SavedTo = path
+		}
+	}
+	return result
+}
+
+// replayAdd creates a prompt added offline, unless one with the same name and author appeared remotely
+func (c *CachedStore) replayAdd(ctx context.Context, operation model.PendingOperation) (*OperationConflict, error) {
+	prompt := *operation.Prompt
+	prompt.ID = ""
+	prompt.GistURL = ""
+
+	existing, err := c.remote.List(ctx)
+	if err != nil && err != ErrNoIndex && err != ErrEmptyIndex {
+		return nil, err
+	}
+	for _, remotePrompt := range existing {
+		if remotePrompt.Name == prompt.Name && remotePrompt.Author == prompt.Author {
+			return c.conflict(operation, "a prompt with the same name and author was added remotely"), nil
+		}
+	}
+
+	return nil, c.remote.Add(ctx, prompt)
+}
+
+// replayUpdate applies an offline change, unless the remote content changed since it was queued
+func (c *CachedStore) replayUpdate(ctx context.Context, operation model.PendingOperation) (*OperationConflict, error) {
+	prompt := *operation.Prompt
+
+	current, err := c.remote.GetContent(ctx, prompt.ID)
+	if err != nil {
+		if isNotFoundError(err) {
+			return c.conflict(operation, "the prompt was deleted remotely"), nil
+		}
+		// 认证、权限等其他错误不能说明提示词已删除，保留在队列中
+		return nil, err
+	}
+
+	currentHash := model.ContentHash(current)
+	if currentHash == model.ContentHash(prompt.Content) {
+		// The same change already reached the remote
+		return nil, nil
+	}
+	if base := operation.BaseHashes[prompt.ID]; base != "" && base != currentHash {
+		return c.conflict(operation, "the prompt was changed remotely"), nil
+	}
+
+	return nil, c.remote.Update(ctx, prompt)
+}
+
+// replayDelete deletes the prompts removed offline, keeping those changed remotely since
+func (c *CachedStore) replayDelete(ctx context.Context, operation model.PendingOperation) (*OperationConflict, error) {
+	ids := make([]string, 0, len(operation.BaseHashes))
+	for id := range operation.BaseHashes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var changed []string
+	for _, id := range ids {
+		current, err := c.remote.GetContent(ctx, id)
+		if err != nil {
+			if isNotFoundError(err) {
+				// Already gone remotely
+				continue
+			}
+			return nil, err
+		}
+
+		if base := operation.BaseHashes[id]; base != "" && base != model.ContentHash(current) {
+			changed = append(changed, id)
+			continue
+		}
+
+		if err := c.remote.Delete(ctx, id); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(changed) > 0 {
+		return c.conflict(operation, fmt.Sprintf("changed remotely, not deleted: %s", strings.Join(changed, ", "))), nil
+	}
+	return nil, nil
+}
+
+// replayAddExport records an offline export, unless a different record for it exists remotely
+func (c *CachedStore) replayAddExport(ctx context.Context, operation model.PendingOperation) (*OperationConflict, error) {
+	export := *operation.Export
+
+	exports, err := c.remote.GetExports(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, existing := range exports {
+		if existing.GistURL != export.GistURL {
+			continue
+		}
+		if sameEntry(existing, true, export, true) {
+			return nil, nil
+		}
+		return c.conflict(operation, "the export was recorded differently remotely"), nil
+	}
+
+	return nil, c.remote.AddExport(ctx, export)
+}
@@ -0,0 +1,343 @@
+package infra
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	appErrors "github.com/grigri/pv/internal/errors"
+	"github.com/grigri/pv/internal/model"
+)
+
+// switchableStore wraps a store and fails its calls with a network error while offline
+type switchableStore struct {
+	Store
+	offline bool
+	// contentErr, when set, is returned by GetContent while online
+	contentErr error
+}
+
+func (s *switchableStore) networkError() error {
+	return &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+}
+
+func (s *switchableStore) List(ctx context.Context) ([]model.Prompt, error) {
+	if s.offline {
+		return nil, s.networkError()
+	}
+	return s.Store.List(ctx)
+}
+
+func (s *switchableStore) Add(ctx context.Context, prompt model.Prompt) error {
+	if s.offline {
+		return s.networkError()
+	}
+	return s.Store.Add(ctx, prompt)
+}
+
+func (s *switchableStore) Update(ctx context.Context, prompt model.Prompt) error {
+	if s.offline {
+		return s.networkError()
+	}
+	return s.Store.Update(ctx, prompt)
+}
+
+func (s *switchableStore) Delete(ctx context.Context, keyword string) error {
+	if s.offline {
+		return s.networkError()
+	}
+	return s.Store.Delete(ctx, keyword)
+}
+
+func (s *switchableStore) GetContent(ctx context.Context, gistID string) (string, error) {
+	if s.offline {
+		return "", s.networkError()
+	}
+	if s.contentErr != nil {
+		return "", s.contentErr
+	}
+	return s.Store.GetContent(ctx, gistID)
+}
+
+func newOfflineTestStores(t *testing.T) (*switchableStore, *CachedStore, *CacheManager) {
+	t.Helper()
+	remote := &switchableStore{Store: NewFileSystemStore(filepath.Join(t.TempDir(), "vault"))}
+	cache := &CacheManager{cacheDir: t.TempDir()}
+	return remote, NewCachedStore(remote, cache, &MockConfigStore{}, false).(*CachedStore), cache
+}
+
+func TestCachedStore_OfflineAddIsReplayed(t *testing.T) {
+	remote, store, cache := newOfflineTestStores(t)
+
+	remote.offline = true
+	if err := store.Add(context.Background(), model.Prompt{Name: "Offline", Author: "alice", Content: testPromptContent}); err != nil {
+		t.Fatalf("Expected the offline add to be queued, got %v", err)
+	}
+
+	operations, err := cache.LoadJournal()
+	if err != nil || len(operations) != 1 || operations[0].Type != model.OperationAdd {
+		t.Fatalf("Expected one queued add, got %+v, %v", operations, err)
+	}
+
+	// The prompt is listed from the cache while offline
+	prompts, err := store.List(context.Background())
+	if err != nil || len(prompts) != 1 || prompts[0].Name != "Offline" {
+		t.Fatalf("Expected the offline prompt in the cache, got %+v, %v", prompts, err)
+	}
+
+	// Reads don't replay the journal
+	remote.offline = false
+	if _, err := store.List(context.Background()); err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if operations, _ := cache.LoadJournal(); len(operations) != 1 {
+		t.Fatalf("Expected the add to stay queued after a read, got %+v", operations)
+	}
+
+	// pv sync pushes it
+	if report, err := store.ReplayJournal(context.Background()); err != nil || report.Applied != 1 {
+		t.Fatalf("ReplayJournal failed: %+v, %v", report, err)
+	}
+	prompts, err = store.List(context.Background())
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(prompts) != 1 || prompts[0].Name != "Offline" || prompts[0].GistURL == pendingURLPrefix+prompts[0].ID {
+		t.Errorf("Expected the prompt to be pushed to the remote, got %+v", prompts)
+	}
+
+	if _, err := os.Stat(filepath.Join(cache.GetCacheDir(), JournalFileName)); !os.IsNotExist(err) {
+		t.Errorf("Expected the journal to be cleared, got %v", err)
+	}
+}
+
+// switchableBatchStore is a switchableStore whose remote adds prompts in batches
+type switchableBatchStore struct {
+	*switchableStore
+}
+
+func (s switchableBatchStore) AddBatch(ctx context.Context, prompts []model.Prompt) ([]model.Prompt, error) {
+	if s.offline {
+		return nil, s.networkError()
+	}
+	return s.Store.(BatchAdder).AddBatch(ctx, prompts)
+}
+
+func TestCachedStore_OfflineBatchIsQueued(t *testing.T) {
+	remote, _, cache := newOfflineTestStores(t)
+	store := NewCachedStore(switchableBatchStore{remote}, cache, &MockConfigStore{}, false).(*CachedStore)
+
+	remote.offline = true
+	prompts := []model.Prompt{{Name: "Offline", Author: "alice", Content: testPromptContent}}
+	if stored, err := store.AddBatch(context.Background(), prompts); err != nil || len(stored) != 1 {
+		t.Fatalf("Expected the offline batch to be queued, got %+v, %v", stored, err)
+	}
+	if operations, _ := cache.LoadJournal(); len(operations) != 1 || operations[0].Type != model.OperationAdd {
+		t.Fatalf("Expected the add to be queued, got %+v", operations)
+	}
+
+	remote.offline = false
+	if result, err := store.ReplayJournal(context.Background()); err != nil || result.Applied != 1 {
+		t.Fatalf("Expected the add to be replayed, got %+v, %v", result, err)
+	}
+	if listed, err := remote.Store.List(context.Background()); err != nil || len(listed) != 1 || listed[0].Name != "Offline" {
+		t.Errorf("Expected the prompt on the remote, got %+v, %v", listed, err)
+	}
+}
+
+func TestCachedStore_OnlineListKeepsOfflineAdds(t *testing.T) {
+	remote, store, cache := newOfflineTestStores(t)
+	if err := store.Add(context.Background(), model.Prompt{Name: "Existing", Author: "alice", Content: testPromptContent}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	remote.offline = true
+	if err := store.Add(context.Background(), model.Prompt{Name: "Offline", Author: "alice", Content: testPromptContent}); err != nil {
+		t.Fatalf("Expected the offline add to be queued, got %v", err)
+	}
+
+	// An online list rebuilds the cache from the remote but keeps the queued prompt
+	remote.offline = false
+	prompts, err := store.List(context.Background())
+	if err != nil || len(prompts) != 2 || prompts[1].Name != "Offline" {
+		t.Fatalf("Expected the offline prompt to stay listed, got %+v, %v", prompts, err)
+	}
+	if index, err := cache.LoadIndex(); err != nil || len(index.Prompts) != 2 {
+		t.Fatalf("Expected the offline prompt to stay in the cache, got %+v, %v", index, err)
+	}
+
+	// Deleting it offline drops the queued add, so the replay doesn't recreate it
+	remote.offline = true
+	if err := store.Delete(context.Background(), prompts[1].ID); err != nil {
+		t.Fatalf("Expected the offline delete to succeed, got %v", err)
+	}
+	if operations, _ := cache.LoadJournal(); len(operations) != 0 {
+		t.Fatalf("Expected the queued add to be dropped, got %+v", operations)
+	}
+
+	remote.offline = false
+	if _, err := store.ReplayJournal(context.Background()); err != nil {
+		t.Fatalf("ReplayJournal failed: %v", err)
+	}
+	if prompts, err := remote.Store.List(context.Background()); err != nil || len(prompts) != 1 || prompts[0].Name != "Existing" {
+		t.Errorf("Expected the deleted prompt not to reach the remote, got %+v, %v", prompts, err)
+	}
+}
+
+func TestCachedStore_OfflineDeleteIsReplayed(t *testing.T) {
+	remote, store, cache := newOfflineTestStores(t)
+
+	if err := store.Add(context.Background(), model.Prompt{Name: "Doomed", Author: "alice", Content: testPromptContent}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	prompts, err := store.List(context.Background())
+	if err != nil || len(prompts) != 1 {
+		t.Fatalf("List failed: %+v, %v", prompts, err)
+	}
+	id := prompts[0].ID
+	if _, err := store.GetContent(context.Background(), id); err != nil {
+		t.Fatalf("GetContent failed: %v", err)
+	}
+
+	remote.offline = true
+	if err := store.Delete(context.Background(), id); err != nil {
+		t.Fatalf("Expected the offline delete to be queued, got %v", err)
+	}
+
+	remote.offline = false
+	report, err := store.ReplayJournal(context.Background())
+	if err != nil {
+		t.Fatalf("ReplayJournal failed: %v", err)
+	}
+	if report.Applied != 1 || len(report.Conflicts) != 0 {
+		t.Errorf("Expected the delete to be applied, got %+v", report)
+	}
+	if _, err := remote.Store.List(context.Background()); err != ErrEmptyIndex {
+		t.Errorf("Expected the prompt to be deleted remotely, got %v", err)
+	}
+	if operations, _ := cache.LoadJournal(); len(operations) != 0 {
+		t.Errorf("Expected an empty journal, got %+v", operations)
+	}
+}
+
+func TestCachedStore_OfflineUpdateConflict(t *testing.T) {
+	remote, store, cache := newOfflineTestStores(t)
+
+	if err := store.Add(context.Background(), model.Prompt{Name: "Shared", Author: "alice", Content: testPromptContent}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	prompts, err := store.List(context.Background())
+	if err != nil || len(prompts) != 1 {
+		t.Fatalf("List failed: %+v, %v", prompts, err)
+	}
+	prompt := prompts[0]
+	if _, err := store.GetContent(context.Background(), prompt.ID); err != nil {
+		t.Fatalf("GetContent failed: %v", err)
+	}
+
+	remote.offline = true
+	prompt.Content = testPromptContent + "\nmine"
+	if err := store.Update(context.Background(), prompt); err != nil {
+		t.Fatalf("Expected the offline update to be queued, got %v", err)
+	}
+
+	// Someone else changes the prompt in the meantime
+	theirs := prompt
+	theirs.Content = testPromptContent + "\ntheirs"
+	if err := remote.Store.Update(context.Background(), theirs); err != nil {
+		t.Fatalf("remote Update failed: %v", err)
+	}
+
+	remote.offline = false
+	report, err := store.ReplayJournal(context.Background())
+	if err != nil {
+		t.Fatalf("ReplayJournal failed: %v", err)
+	}
+	if report.Applied != 0 || len(report.Conflicts) != 1 {
+		t.Fatalf("Expected one conflict, got %+v", report)
+	}
+
+	conflict := report.Conflicts[0]
+	saved, err := os.ReadFile(conflict.SavedTo)
+	if err != nil || string(saved) != prompt.Content {
+		t.Errorf("Expected the local change to be kept at %q, got %q, %v", conflict.SavedTo, saved, err)
+	}
+
+	content, err := remote.Store.GetContent(context.Background(), prompt.ID)
+	if err != nil || content != theirs.Content {
+		t.Errorf("Expected the remote change to be kept, got %q, %v", content, err)
+	}
+	if operations, _ := cache.LoadJournal(); len(operations) != 0 {
+		t.Errorf("Expected the conflicting operation to be dropped, got %+v", operations)
+	}
+}
+
+func TestCachedStore_NonNetworkErrorsAreNotQueued(t *testing.T) {
+	cache := &CacheManager{cacheDir: t.TempDir()}
+	remote := &MockStore{addFunc: func(model.Prompt) error { return errors.New("validation failed") }}
+	store := NewCachedStore(remote, cache, &MockConfigStore{}, false)
+
+	if err := store.Add(context.Background(), model.Prompt{Name: "Bad", Author: "alice"}); err == nil {
+		t.Fatal("Expected the error to be returned")
+	}
+	if operations, _ := cache.LoadJournal(); len(operations) != 0 {
+		t.Errorf("Expected nothing to be queued, got %+v", operations)
+	}
+}
+
+func TestCachedStore_ReplayKeepsOperationsOnRemoteErrors(t *testing.T) {
+	remoteErrors := map[string]error{
+		"auth":         appErrors.NewAppError(appErrors.ErrAuth, "GitHub token 无效或已过期", nil),
+		"server error": fmt.Errorf("failed to get snippet: %w", &gitLabAPIError{StatusCode: http.StatusInternalServerError, Method: http.MethodGet, Path: "/snippets/1"}),
+	}
+
+	for name, remoteErr := range remoteErrors {
+		for _, operation := range []string{"update", "delete"} {
+			t.Run(name+" "+operation, func(t *testing.T) {
+				remote, store, cache := newOfflineTestStores(t)
+				if err := store.Add(context.Background(), model.Prompt{Name: "Kept", Author: "alice", Content: testPromptContent}); err != nil {
+					t.Fatalf("Add failed: %v", err)
+				}
+				prompts, err := store.List(context.Background())
+				if err != nil || len(prompts) != 1 {
+					t.Fatalf("List failed: %+v, %v", prompts, err)
+				}
+				prompt := prompts[0]
+
+				remote.offline = true
+				if operation == "update" {
+					prompt.Content = testPromptContent + "\nmine"
+					err = store.Update(context.Background(), prompt)
+				} else {
+					err = store.Delete(context.Background(), prompt.ID)
+				}
+				if err != nil {
+					t.Fatalf("Expected the offline %s to be queued, got %v", operation, err)
+				}
+
+				remote.offline = false
+				remote.contentErr = remoteErr
+				if report, err := store.ReplayJournal(context.Background()); err == nil {
+					t.Fatalf("Expected the replay to fail, got %+v", report)
+				}
+				if operations, _ := cache.LoadJournal(); len(operations) != 1 {
+					t.Errorf("Expected the %s to stay queued, got %+v", operation, operations)
+				}
+				if content, err := remote.Store.GetContent(context.Background(), prompt.ID); err != nil || content != testPromptContent {
+					t.Errorf("Expected the remote prompt to be untouched, got %q, %v", content, err)
+				}
+
+				// Once the remote answers again the operation goes through
+				remote.contentErr = nil
+				if report, err := store.ReplayJournal(context.Background()); err != nil || report.Applied != 1 {
+					t.Errorf("Expected the %s to be applied, got %+v, %v", operation, report, err)
+				}
+			})
+		}
+	}
+}
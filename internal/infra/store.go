@@ -1,6 +1,9 @@
 package infra
 
 import (
+	"context"
+	"fmt"
+
 	"github.com/grigri/pv/internal/model"
 )
 
@@ -14,25 +17,63 @@ type GistInfo struct {
 	Owner       string
 }
 
+// Store persists prompts in a backend
+// Every method takes the context of the running command; remote backends abort
+// their requests when it is cancelled or its deadline passes.
 type Store interface {
 	// 现有方法
-	List() ([]model.Prompt, error)
-	Add(model.Prompt) error
-	Delete(keyword string) error
-	Update(model.Prompt) error
-	Get(keyword string) ([]model.Prompt, error)
-	GetContent(gistID string) (string, error)
+	List(ctx context.Context) ([]model.Prompt, error)
+	Add(ctx context.Context, prompt model.Prompt) error
+	Delete(ctx context.Context, keyword string) error
+	Update(ctx context.Context, prompt model.Prompt) error
+	Get(ctx context.Context, keyword string) ([]model.Prompt, error)
+	GetContent(ctx context.Context, gistID string) (string, error)
 	
 	// 新增 gist 管理方法
-	CreatePublicGist(prompt model.Prompt) (string, error)
-	UpdateGist(gistURL string, prompt model.Prompt) error
-	GetGistInfo(gistURL string) (*GistInfo, error)
+	CreatePublicGist(ctx context.Context, prompt model.Prompt) (string, error)
+	UpdateGist(ctx context.Context, gistURL string, prompt model.Prompt) error
+	GetGistInfo(ctx context.Context, gistURL string) (*GistInfo, error)
 	
 	// 新增 export 管理方法
-	AddExport(prompt model.IndexedPrompt) error
-	UpdateExport(prompt model.IndexedPrompt) error
-	GetExports() ([]model.IndexedPrompt, error)
+	AddExport(ctx context.Context, prompt model.IndexedPrompt) error
+	UpdateExport(ctx context.Context, prompt model.IndexedPrompt) error
+	GetExports(ctx context.Context) ([]model.IndexedPrompt, error)
 	
 	// 新增 URL 重复检查方法
-	FindExistingPromptByURL(gistURL string) (*model.Prompt, error)
+	FindExistingPromptByURL(ctx context.Context, gistURL string) (*model.Prompt, error)
+}
+
+// RawIndexProvider is implemented by stores that can return their index.json verbatim
+// CachedStore.SyncRawIndex uses it to mirror the remote index into the local cache
+type RawIndexProvider interface {
+	GetRawIndexContent(ctx context.Context) (string, error)
+}
+
+// ErrRawIndexUnsupported is returned when the storage backend cannot return its index.json
+var ErrRawIndexUnsupported = fmt.Errorf("the storage backend does not provide its raw index")
+
+// BatchAdder is implemented by stores that can add many prompts with a single index write
+type BatchAdder interface {
+	// AddBatch adds prompts like Add, creating or updating each by name and author,
+	// and returns them with the ID and gist URL they are stored under
+	AddBatch(ctx context.Context, prompts []model.Prompt) ([]model.Prompt, error)
+}
+
+// MetadataBackfiller is implemented by stores that can fill in the prompt metadata of
+// index entries written before the index recorded description, tags and version
+type MetadataBackfiller interface {
+	// BackfillMetadata fetches the content of entries without a content hash and
+	// records their metadata, returning the number of entries filled in
+	BackfillMetadata(ctx context.Context) (int, error)
+}
+
+// ErrRevisionsUnsupported is returned when the storage backend keeps no revision history
+var ErrRevisionsUnsupported = fmt.Errorf("the storage backend does not keep a revision history of prompts")
+
+// RevisionProvider is implemented by stores that keep the revision history of prompts
+type RevisionProvider interface {
+	// ListRevisions returns the revisions of a prompt gist, newest first
+	ListRevisions(ctx context.Context, gistID string) ([]model.Revision, error)
+	// GetRevisionContent returns the prompt content as of revision sha
+	GetRevisionContent(ctx context.Context, gistID, sha string) (string, error)
 }
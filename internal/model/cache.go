@@ -7,4 +7,27 @@ type CacheInfo struct {
 	LastUpdated  time.Time `json:"last_updated"`
 	TotalPrompts int       `json:"total_prompts"`
 	CacheSize    int64     `json:"cache_size_bytes"`
-}
\ No newline at end of file
+}
+
+// Operation types recorded in the offline journal
+const (
+	OperationAdd       = "add"
+	OperationUpdate    = "update"
+	OperationDelete    = "delete"
+	OperationAddExport = "add_export"
+)
+
+// PendingOperation is a write made while the remote store was unreachable
+// It is kept in the cache directory until it can be replayed against the remote
+type PendingOperation struct {
+	Type    string         `json:"type"`
+	Prompt  *Prompt        `json:"prompt,omitempty"`  // add, update
+	Keyword string         `json:"keyword,omitempty"` // delete
+	Export  *IndexedPrompt `json:"export,omitempty"`  // add_export
+
+	// BaseHashes maps the IDs touched by the operation to their content hash when
+	// it was queued, so remote changes made in the meantime are detected on replay
+	BaseHashes map[string]string `json:"base_hashes,omitempty"`
+
+	QueuedAt time.Time `json:"queued_at"`
+}
@@ -1,6 +1,10 @@
 package model
 
-import "time"
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
 
 // Index represents a prompt index entry that links prompt files to GitHub gist URLs
 type IndexedPrompt struct {
@@ -10,9 +14,32 @@ type IndexedPrompt struct {
 	Name        string    `json:"name"`        // 存储 prompt 名称
 	LastUpdated time.Time `json:"last_updated"`
 	Parent      *string   `json:"parent,omitempty"`  // 新增：父级 Prompt 的 gist URL（仅用于 exports）
+	Description string    `json:"description,omitempty"`  // 存储 YAML 中的 description
+	Tags        []string  `json:"tags,omitempty"`         // 存储 YAML 中的 tags
+	Version     string    `json:"version,omitempty"`      // 存储 YAML 中的 version
+	ContentHash string    `json:"content_hash,omitempty"` // prompt 内容的 sha256，空表示尚未回填
 }
 
+// ContentHash returns the digest recorded in the index for prompt content
+func ContentHash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// ApplyMetadata copies the description, tags, version and content hash of a prompt into the entry
+func (p *IndexedPrompt) ApplyMetadata(prompt Prompt) {
+	p.Description = prompt.Description
+	p.Tags = prompt.Tags
+	p.Version = prompt.Version
+	p.ContentHash = ContentHash(prompt.Content)
+}
+
+// CurrentIndexSchemaVersion is the index.json schema version written by this build
+// Indexes without a schema_version are version 0
+const CurrentIndexSchemaVersion = 2
+
 type Index struct {
+	SchemaVersion int             `json:"schema_version"`
 	Prompts     []IndexedPrompt `json:"prompts"`
 	LastUpdated time.Time       `json:"last_updated"`
 	Exports     []IndexedPrompt `json:"exports,omitempty"`  // 新增：已分享的公开 Prompt 列表
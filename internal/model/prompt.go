@@ -1,5 +1,10 @@
 package model
 
+import (
+	"path/filepath"
+	"strings"
+)
+
 type Prompt struct {
 	ID          string   `json:"id"`
 	Name        string   `json:"name"`
@@ -10,4 +15,33 @@ type Prompt struct {
 	Version     string   `json:"version"`
 	Content     string   `json:"content"`
 	Parent      *string  `json:"parent,omitempty"`  // 新增：父级 Prompt 的 gist URL
+	Format      string   `json:"format,omitempty"`  // 提示词文件的格式，空表示 FormatYAML
+}
+
+// Prompt file formats
+const (
+	// FormatYAML is a YAML metadata block followed by --- and the prompt body, stored as .yaml
+	FormatYAML = "yaml"
+	// FormatMarkdown is a Markdown body with ----fenced front matter, stored as .md
+	FormatMarkdown = "markdown"
+)
+
+// FormatFromPath returns the prompt file format of path by its extension,
+// or an empty string when path is not a prompt file
+func FormatFromPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return FormatYAML
+	case ".md", ".markdown":
+		return FormatMarkdown
+	}
+	return ""
+}
+
+// FileName returns the name of the file the prompt is stored in
+func (p Prompt) FileName() string {
+	if p.Format == FormatMarkdown {
+		return p.Name + ".md"
+	}
+	return p.Name + ".yaml"
 }
@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// Revision is a past version of a prompt, read from the revision history of its gist
+type Revision struct {
+	SHA         string    `json:"sha"`
+	CommittedAt time.Time `json:"committed_at"`
+	Additions   int       `json:"additions"`         // 本次修订新增的行数
+	Deletions   int       `json:"deletions"`         // 本次修订删除的行数
+	Version     string    `json:"version,omitempty"` // 该修订中 YAML 的 version 字段，读取内容后才会填充
+}
+
+// ShortSHA returns the abbreviated SHA shown in the history, which pv also accepts
+func (r Revision) ShortSHA() string {
+	if len(r.SHA) > 7 {
+		return r.SHA[:7]
+	}
+	return r.SHA
+}
@@ -0,0 +1,224 @@
+// Package scaffold builds new prompt files from templates.
+//
+// A template is a prompt file whose front matter holds the defaults of a new prompt.
+// pv ships a few built-in templates; users add their own as .yaml files in the
+// templates directory of the pv configuration.
+package scaffold
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/grigri/pv/internal/model"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultTemplate is the template used when none is chosen
+const DefaultTemplate = "basic"
+
+//go:embed templates/*.yaml
+var builtinTemplates embed.FS
+
+// Template is a skeleton a new prompt file is built from
+type Template struct {
+	// Name identifies the template, the file name without .yaml
+	Name string
+	// Path is the file of a user template, empty for built-in ones
+	Path    string
+	Content string
+}
+
+// Builtin reports whether the template ships with pv
+func (t Template) Builtin() bool {
+	return t.Path == ""
+}
+
+// Builtin returns the templates shipped with pv, sorted by name
+func Builtin() []Template {
+	entries, _ := builtinTemplates.ReadDir("templates")
+	templates := make([]Template, 0, len(entries))
+	for _, entry := range entries {
+		content, err := builtinTemplates.ReadFile("templates/" + entry.Name())
+		if err != nil {
+			continue
+		}
+		templates = append(templates, Template{
+			Name:    strings.TrimSuffix(entry.Name(), ".yaml"),
+			Content: string(content),
+		})
+	}
+	return templates
+}
+
+// Load returns the built-in templates together with the user templates in dir, sorted by name
+// A user template named like a built-in one replaces it. A missing dir holds no templates.
+func Load(dir string) ([]Template, error) {
+	byName := map[string]Template{}
+	for _, template := range Builtin() {
+		byName[template.Name] = template
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list templates: %w", err)
+	}
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read template %s: %w", path, err)
+		}
+		name := strings.TrimSuffix(filepath.Base(path), ".yaml")
+		byName[name] = Template{Name: name, Path: path, Content: string(content)}
+	}
+
+	templates := make([]Template, 0, len(byName))
+	for _, template := range byName {
+		templates = append(templates, template)
+	}
+	sort.Slice(templates, func(i, j int) bool { return templates[i].Name < templates[j].Name })
+	return templates, nil
+}
+
+// Find returns the template with the given name
+func Find(templates []Template, name string) (Template, bool) {
+	for _, template := range templates {
+		if template.Name == name {
+			return template, true
+		}
+	}
+	return Template{}, false
+}
+
+// Defaults returns the metadata a template's front matter sets
+// Render with the returned prompt reproduces the template's values.
+func Defaults(template Template) (model.Prompt, error) {
+	frontMatter, _, err := split(template.Content)
+	if err != nil {
+		return model.Prompt{}, err
+	}
+
+	var metadata struct {
+		Name        string   `yaml:"name"`
+		Author      string   `yaml:"author"`
+		Description string   `yaml:"description"`
+		Tags        []string `yaml:"tags"`
+		Version     string   `yaml:"version"`
+	}
+	if err := yaml.Unmarshal([]byte(frontMatter), &metadata); err != nil {
+		return model.Prompt{}, fmt.Errorf("invalid front matter in template %s: %w", template.Name, err)
+	}
+	return model.Prompt{
+		Name:        metadata.Name,
+		Author:      metadata.Author,
+		Description: metadata.Description,
+		Tags:        metadata.Tags,
+		Version:     metadata.Version,
+	}, nil
+}
+
+// Render fills the metadata of prompt into the front matter of a template
+// Empty fields of prompt keep the template's value. Comments and the order of the
+// template's keys are kept; keys the template lacks are added after them.
+func Render(template Template, prompt model.Prompt) (string, error) {
+	frontMatter, body, err := split(template.Content)
+	if err != nil {
+		return "", err
+	}
+
+	var document yaml.Node
+	if err := yaml.Unmarshal([]byte(frontMatter), &document); err != nil {
+		return "", fmt.Errorf("invalid front matter in template %s: %w", template.Name, err)
+	}
+	if len(document.Content) == 0 {
+		document = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	mapping := document.Content[0]
+	if mapping.Kind != yaml.MappingNode {
+		return "", fmt.Errorf("invalid front matter in template %s: not a mapping", template.Name)
+	}
+
+	setScalar(mapping, "name", prompt.Name)
+	setScalar(mapping, "author", prompt.Author)
+	setScalar(mapping, "description", prompt.Description)
+	if len(prompt.Tags) > 0 {
+		setSequence(mapping, "tags", prompt.Tags)
+	}
+	setScalar(mapping, "version", prompt.Version)
+
+	var out strings.Builder
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&document); err != nil {
+		return "", fmt.Errorf("failed to render template %s: %w", template.Name, err)
+	}
+	encoder.Close()
+
+	return out.String() + "---\n" + body, nil
+}
+
+// Compose builds a prompt file from the metadata of prompt and a body
+// Only the metadata fields prompt sets are written to the front matter.
+func Compose(prompt model.Prompt, body string) (string, error) {
+	return Render(Template{Name: "prompt", Content: "---\n---\n" + body}, prompt)
+}
+
+// split separates the front matter of a prompt file from its body
+// Both the "---" opened and the bare front matter layouts of prompt files are accepted.
+func split(content string) (frontMatter, body string, err error) {
+	lines := strings.SplitAfter(content, "\n")
+	start := 0
+	if len(lines) > 0 && strings.TrimSpace(lines[0]) == "---" {
+		start = 1
+	}
+	for i := start; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == "---" {
+			return strings.Join(lines[start:i], ""), strings.Join(lines[i+1:], ""), nil
+		}
+	}
+	return "", "", fmt.Errorf("template has no front matter separator '---'")
+}
+
+// valueNode returns the value of key in a mapping, adding the key when it is missing
+func valueNode(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	keyNode := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}
+	value := &yaml.Node{}
+	mapping.Content = append(mapping.Content, keyNode, value)
+	return value
+}
+
+// setScalar sets key to a string value, keeping the template's quoting and comments
+func setScalar(mapping *yaml.Node, key, value string) {
+	if value == "" {
+		return
+	}
+	node := valueNode(mapping, key)
+	if node.Kind != yaml.ScalarNode {
+		*node = yaml.Node{LineComment: node.LineComment, Style: yaml.DoubleQuotedStyle}
+	}
+	node.Kind = yaml.ScalarNode
+	node.Tag = "!!str"
+	node.Value = value
+}
+
+// setSequence sets key to a list of strings, keeping the template's list style and comments
+func setSequence(mapping *yaml.Node, key string, values []string) {
+	node := valueNode(mapping, key)
+	if node.Kind != yaml.SequenceNode {
+		*node = yaml.Node{LineComment: node.LineComment}
+	}
+	node.Kind = yaml.SequenceNode
+	node.Tag = "!!seq"
+	node.Content = nil
+	for _, value := range values {
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value, Style: yaml.DoubleQuotedStyle})
+	}
+}
@@ -0,0 +1,140 @@
+package scaffold
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/grigri/pv/internal/model"
+	"github.com/grigri/pv/internal/validator"
+	"github.com/grigri/pv/internal/variable"
+)
+
+func TestBuiltin(t *testing.T) {
+	templates := Builtin()
+	if _, ok := Find(templates, DefaultTemplate); !ok {
+		t.Fatalf("Expected the default template %q to be built in", DefaultTemplate)
+	}
+
+	for _, template := range templates {
+		if !template.Builtin() {
+			t.Errorf("Expected %s to be built in", template.Name)
+		}
+		if _, err := Defaults(template); err != nil {
+			t.Errorf("Expected template %s to have valid front matter: %v", template.Name, err)
+		}
+	}
+}
+
+func TestBuiltin_Variables(t *testing.T) {
+	want := map[string][]string{
+		"basic":       {"变量名"},
+		"code-review": {"code", "language"},
+		"role":        {"question", "role"},
+	}
+
+	parser := variable.NewParser()
+	for _, template := range Builtin() {
+		if got := parser.ExtractVariables(template.Content); !reflect.DeepEqual(got, want[template.Name]) {
+			t.Errorf("Expected template %s to declare variables %v, got %v", template.Name, want[template.Name], got)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	custom := "name: \"\"\nauthor: \"\"\ntags: [\"team\"]\n---\nTeam prompt\n"
+	for name, content := range map[string]string{"team.yaml": custom, DefaultTemplate + ".yaml": custom, "notes.txt": "ignored"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	templates, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(templates) != len(Builtin())+1 {
+		t.Errorf("Expected the built-in templates plus team, got %d templates", len(templates))
+	}
+
+	basic, _ := Find(templates, DefaultTemplate)
+	if basic.Builtin() || basic.Content != custom {
+		t.Errorf("Expected the user template to replace the built-in %s", DefaultTemplate)
+	}
+	if _, ok := Find(templates, "notes"); ok {
+		t.Error("Expected files other than .yaml to be ignored")
+	}
+
+	if templates, err := Load(filepath.Join(dir, "missing")); err != nil || len(templates) != len(Builtin()) {
+		t.Errorf("Expected a missing directory to hold no templates, got %d, %v", len(templates), err)
+	}
+}
+
+func TestRender(t *testing.T) {
+	template, _ := Find(Builtin(), DefaultTemplate)
+	prompt := model.Prompt{Name: "Code Review", Author: "alice", Tags: []string{"code"}}
+
+	content, err := Render(template, prompt)
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+
+	// 渲染结果是一个有效的提示词文件
+	file, err := validator.NewYAMLValidator().ValidatePromptFile([]byte(content))
+	if err != nil {
+		t.Fatalf("Expected a valid prompt file, got %v:\n%s", err, content)
+	}
+	parsed := file.Metadata
+	if parsed.Name != "Code Review" || parsed.Author != "alice" || !reflect.DeepEqual(parsed.Tags, []string{"code"}) {
+		t.Errorf("Expected the metadata to be filled in, got %+v", parsed)
+	}
+
+	// 模板的注释、未填写字段的值和正文保持不变
+	defaults, _ := Defaults(template)
+	if parsed.Version != defaults.Version {
+		t.Errorf("Expected version %q from the template, got %q", defaults.Version, parsed.Version)
+	}
+	if !strings.Contains(content, "# 提示词的名称") {
+		t.Errorf("Expected the template comments to be kept, got:\n%s", content)
+	}
+	_, body, _ := split(template.Content)
+	if !strings.HasSuffix(content, "---\n"+body) {
+		t.Errorf("Expected the template body to be kept, got:\n%s", content)
+	}
+}
+
+func TestRender_BareTemplate(t *testing.T) {
+	template := Template{Name: "bare", Content: "---\ndescription: Notes\n---\nBody\n"}
+
+	content, err := Render(template, model.Prompt{Name: "Notes", Author: "bob", Tags: []string{"notes"}})
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	defaults, err := Defaults(Template{Content: content})
+	if err != nil {
+		t.Fatalf("Expected the rendered file to parse: %v", err)
+	}
+	want := model.Prompt{Name: "Notes", Author: "bob", Description: "Notes", Tags: []string{"notes"}}
+	if !reflect.DeepEqual(defaults, want) {
+		t.Errorf("Expected %+v, got %+v", want, defaults)
+	}
+
+	if _, err := Render(Template{Name: "broken", Content: "name: x\n"}, model.Prompt{}); err == nil {
+		t.Error("Expected a template without a separator to fail")
+	}
+}
+
+func TestCompose(t *testing.T) {
+	content, err := Compose(model.Prompt{Name: "Review", Author: "alice", Tags: []string{"code"}}, "Review {code}\n---\nmore\n")
+	if err != nil {
+		t.Fatalf("Compose failed: %v", err)
+	}
+
+	want := "name: \"Review\"\nauthor: \"alice\"\ntags:\n  - \"code\"\n---\nReview {code}\n---\nmore\n"
+	if content != want {
+		t.Errorf("Expected %q, got %q", want, content)
+	}
+}
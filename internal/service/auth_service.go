@@ -1,15 +1,17 @@
 package service
 
+import "context"
+
 // AuthService defines the interface for authentication operations
 type AuthService interface {
 	// Login authenticates with the provided GitHub token
-	Login(token string) error
+	Login(ctx context.Context, token string) error
 
 	// GetStatus returns the current authentication status
-	GetStatus() (*AuthStatus, error)
+	GetStatus(ctx context.Context) (*AuthStatus, error)
 
 	// Logout clears the stored authentication
-	Logout() error
+	Logout(ctx context.Context) error
 }
 
 // AuthStatus represents the current authentication status
@@ -1,7 +1,9 @@
 package service
 
 import (
+	"context"
 	"fmt"
+	"strings"
 
 	"github.com/grigri/pv/internal/auth"
 	"github.com/grigri/pv/internal/config"
@@ -29,17 +31,21 @@ func NewAuthService(
 }
 
 // Login authenticates with the provided GitHub token
-func (s *authService) Login(token string) error {
+func (s *authService) Login(ctx context.Context, token string) error {
 	// Validate the token
-	result, err := s.tokenValidator.Validate(token)
+	result, err := s.tokenValidator.Validate(ctx, token)
 	if err != nil {
 		return err
 	}
 
 	if !result.IsValid {
-		if result.Error == "Token lacks required 'gist' scope" {
+		if result.Error == errors.ErrMissingScope.Message {
 			return errors.ErrMissingScope
 		}
+		if strings.HasPrefix(result.Error, "Token lacks required") {
+			// Non-gist scopes come from other backends (e.g. GitLab 'api')
+			return errors.NewAppError(errors.ErrAuth, result.Error, nil)
+		}
 		return errors.ErrInvalidToken
 	}
 
@@ -60,7 +66,7 @@ func (s *authService) Login(token string) error {
 }
 
 // GetStatus returns the current authentication status
-func (s *authService) GetStatus() (*AuthStatus, error) {
+func (s *authService) GetStatus(ctx context.Context) (*AuthStatus, error) {
 	status := &AuthStatus{
 		IsAuthenticated: false,
 	}
@@ -76,7 +82,7 @@ func (s *authService) GetStatus() (*AuthStatus, error) {
 	}
 
 	// Validate the stored token
-	result, err := s.tokenValidator.Validate(token)
+	result, err := s.tokenValidator.Validate(ctx, token)
 	if err != nil {
 		// If validation fails due to network issues, return error
 		if err == errors.ErrGitHubAPIUnavailable {
@@ -96,7 +102,7 @@ func (s *authService) GetStatus() (*AuthStatus, error) {
 }
 
 // Logout clears the stored authentication
-func (s *authService) Logout() error {
+func (s *authService) Logout(ctx context.Context) error {
 	if err := s.configStore.DeleteToken(); err != nil {
 		return errors.NewAppError(
 			errors.ErrStorage,
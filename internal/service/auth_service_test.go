@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"testing"
 
 	"github.com/grigri/pv/internal/auth"
@@ -133,7 +134,7 @@ func TestAuthService_Login(t *testing.T) {
 			service := NewAuthService(mockConfigStore, mockGitHubClient, mockTokenValidator)
 
 			// Test login
-			err := service.Login(tc.token)
+			err := service.Login(context.Background(), tc.token)
 
 			// Check error
 			if tc.expectError {
@@ -230,7 +231,7 @@ func TestAuthService_GetStatus(t *testing.T) {
 			service := NewAuthService(mockConfigStore, mockGitHubClient, mockTokenValidator)
 
 			// Test get status
-			status, err := service.GetStatus()
+			status, err := service.GetStatus(context.Background())
 
 			// Check error
 			if tc.expectError {
@@ -291,7 +292,7 @@ func TestAuthService_Logout(t *testing.T) {
 			service := NewAuthService(mockConfigStore, mockGitHubClient, mockTokenValidator)
 
 			// Test logout
-			err := service.Logout()
+			err := service.Logout(context.Background())
 
 			// Check error
 			if tc.expectError {
@@ -377,7 +378,7 @@ type MockTokenValidator struct {
 	validateFunc func(token string) (*auth.ValidationResult, error)
 }
 
-func (m *MockTokenValidator) Validate(token string) (*auth.ValidationResult, error) {
+func (m *MockTokenValidator) Validate(ctx context.Context, token string) (*auth.ValidationResult, error) {
 	if m.validateFunc != nil {
 		return m.validateFunc(token)
 	}
@@ -1,6 +1,13 @@
 package service
 
-import "github.com/grigri/pv/internal/model"
+import (
+	"context"
+
+	"github.com/grigri/pv/internal/archive"
+	"github.com/grigri/pv/internal/diff"
+	"github.com/grigri/pv/internal/infra"
+	"github.com/grigri/pv/internal/model"
+)
 
 // GistInfo 包含 Gist 的基本信息
 type GistInfo struct {
@@ -12,68 +19,277 @@ type GistInfo struct {
 	Owner       string
 }
 
+// MetadataChange is a prompt metadata field that differs between two versions of a prompt
+type MetadataChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// PromptDiff holds the differences between two versions of a prompt
+// Metadata changes are listed apart from the diff of the prompt body.
+type PromptDiff struct {
+	From     string           `json:"from"`
+	To       string           `json:"to"`
+	Metadata []MetadataChange `json:"metadata"`
+	Body     []diff.Hunk      `json:"body"`
+}
+
+// HasChanges reports whether the two versions differ
+func (d *PromptDiff) HasChanges() bool {
+	return len(d.Metadata) > 0 || len(d.Body) > 0
+}
+
+// RollbackResult describes a prompt restored to a past revision
+type RollbackResult struct {
+	// Prompt is the restored prompt, including its full content
+	Prompt *model.Prompt
+	// Revision is the revision whose content was restored
+	Revision model.Revision
+	// Export is the public copy the prompt was shared as, nil if it was never shared.
+	// The rollback does not touch it; SharePrompt with Prompt brings it up to date.
+	Export *model.IndexedPrompt
+}
+
+// AddAction is what adding a prompt file does to the vault
+type AddAction string
+
+const (
+	// AddCreate creates a new prompt
+	AddCreate AddAction = "create"
+	// AddUpdate updates the prompt with the same name and author
+	AddUpdate AddAction = "update"
+)
+
+// PlannedAdd is a valid prompt file of a bulk add and what adding it will do
+type PlannedAdd struct {
+	Path   string
+	Prompt *model.Prompt
+	Action AddAction
+	// Existing is the prompt in the vault that an update replaces, nil for creates
+	Existing *model.Prompt
+}
+
+// AddFailure is a file of a bulk add that cannot be added
+type AddFailure struct {
+	Path string
+	Err  error
+}
+
+// PromptSource is the content of a prompt file to plan, with where it came from
+// Path names the source in plans and reports and need not be a file on disk.
+type PromptSource struct {
+	Path    string
+	Content string
+	// Format is the format the prompt is stored in, empty for YAML
+	Format string
+}
+
+// AddPlan is the result of validating the files of a bulk add before anything is stored
+type AddPlan struct {
+	Adds     []PlannedAdd
+	Failures []AddFailure
+}
+
+// Count returns the number of planned adds with the given action
+func (p *AddPlan) Count(action AddAction) int {
+	count := 0
+	for _, add := range p.Adds {
+		if add.Action == action {
+			count++
+		}
+	}
+	return count
+}
+
+// RestoreAction is what restoring an archived index entry did to the vault
+type RestoreAction string
+
+const (
+	// RestoreCreated recreated the entry
+	RestoreCreated RestoreAction = "create"
+	// RestoreSkipped left an entry that already exists in the vault untouched
+	RestoreSkipped RestoreAction = "skip"
+)
+
+// RestoredEntry is an index entry of an archive and how it was restored
+type RestoredEntry struct {
+	// Entry is the index entry as archived
+	Entry model.IndexedPrompt
+	// URL is the gist URL the entry is stored under in the vault now
+	URL    string
+	Action RestoreAction
+}
+
+// RestoreReport lists the prompts and exports restored from an archive, in archive order
+type RestoreReport struct {
+	Prompts []RestoredEntry
+	Exports []RestoredEntry
+}
+
+// Count returns the number of prompts and exports restored with the given action
+func (r *RestoreReport) Count(action RestoreAction) int {
+	count := 0
+	for _, entry := range append(append([]RestoredEntry{}, r.Prompts...), r.Exports...) {
+		if entry.Action == action {
+			count++
+		}
+	}
+	return count
+}
+
+// ContentProgress is called each time FetchPromptContents finishes a prompt
+// done counts the finished prompts out of total; err is set when the prompt failed.
+type ContentProgress func(done, total int, prompt model.Prompt, err error)
+
 // PromptService defines the interface for prompt business logic operations
+// Every method takes the context of the running command, so remote calls stop
+// when the command is interrupted or its --timeout passes.
 type PromptService interface {
 	// AddFromFile adds a prompt from a YAML file to the vault
-	AddFromFile(filePath string) (*model.Prompt, error)
+	AddFromFile(ctx context.Context, filePath string) (*model.Prompt, error)
+
+	// AddFromContent adds a prompt to the vault from the content of a YAML prompt file.
+	// Returns the added prompt or a validation error if the content is not a valid prompt file.
+	AddFromContent(ctx context.Context, content string) (*model.Prompt, error)
+
+	// ValidatePromptContent parses the content of a YAML prompt file without storing it.
+	// Returns the prompt it describes or a validation error.
+	ValidatePromptContent(content string) (*model.Prompt, error)
+
+	// PlanAddFromFiles validates the prompt files named by paths without storing anything.
+	// A path is a file, a directory of .yaml/.yml files or a glob pattern; recursive also
+	// searches the subdirectories of directories. Every file is validated, so the plan lists
+	// all failures together, and each valid file is planned as a create or as an update of
+	// the prompt with the same name and author.
+	PlanAddFromFiles(ctx context.Context, paths []string, recursive bool) (*AddPlan, error)
+
+	// PlanAddFromContents plans prompt file contents like PlanAddFromFiles, for prompts that
+	// do not come from prompt files on disk such as the ones converted by pv import.
+	PlanAddFromContents(ctx context.Context, sources []PromptSource) (*AddPlan, error)
+
+	// ApplyAddPlan stores the prompts of a plan without failures, writing the index once
+	// when the store supports it. Returns the stored prompts.
+	ApplyAddPlan(ctx context.Context, plan *AddPlan) ([]model.Prompt, error)
 
 	// DeleteByKeyword deletes prompts that match the given keyword.
 	// The keyword is used to search across prompt names, authors, and descriptions.
 	// Returns an error if no matching prompts are found or if the deletion fails.
-	DeleteByKeyword(keyword string) error
+	DeleteByKeyword(ctx context.Context, keyword string) error
 
 	// DeleteByURL deletes a prompt identified by its GitHub Gist URL.
 	// The gistURL should be a valid GitHub Gist URL (e.g., https://gist.github.com/user/gist_id).
 	// Returns an error if the URL format is invalid, the prompt is not found, or deletion fails.
-	DeleteByURL(gistURL string) error
+	DeleteByURL(ctx context.Context, gistURL string) error
 
 	// ListPrompts retrieves all prompts available in the vault.
 	// This method is used to display the complete list of prompts to users
 	// in various interactive modes (list, delete, get). Returns an error if the listing fails.
-	ListPrompts() ([]model.Prompt, error)
+	ListPrompts(ctx context.Context) ([]model.Prompt, error)
 
 	// FilterPrompts retrieves prompts that match the given keyword.
-	// The keyword is used to filter prompts by name, author, or description.
+	// The keyword is used to filter prompts by name, author, description or tags.
 	// Returns an empty slice if no prompts match the keyword, or an error if filtering fails.
-	FilterPrompts(keyword string) ([]model.Prompt, error)
+	FilterPrompts(ctx context.Context, keyword string) ([]model.Prompt, error)
 
 	// GetPromptByURL retrieves a specific prompt by its GitHub Gist URL.
 	// The gistURL should be a valid GitHub Gist URL (e.g., https://gist.github.com/user/gist_id).
 	// Returns the prompt if found, or an error if the URL format is invalid or the prompt is not found.
-	GetPromptByURL(gistURL string) (*model.Prompt, error)
+	GetPromptByURL(ctx context.Context, gistURL string) (*model.Prompt, error)
 
 	// GetPromptContent retrieves the actual content (YAML file content) of a prompt from GitHub Gist.
 	// The prompt parameter should contain a valid ID that corresponds to a GitHub Gist ID.
 	// Returns the raw content string of the prompt or an error if the content cannot be retrieved.
-	GetPromptContent(prompt *model.Prompt) (string, error)
+	GetPromptContent(ctx context.Context, prompt *model.Prompt) (string, error)
 
 	// SharePrompt shares a private prompt by creating a public gist.
 	// If the prompt has already been shared, updates the existing public gist.
 	// Returns the shared prompt with the public gist URL, or an error if sharing fails.
-	SharePrompt(prompt *model.Prompt) (*model.Prompt, error)
+	SharePrompt(ctx context.Context, prompt *model.Prompt) (*model.Prompt, error)
 
 	// AddFromURL adds a prompt from a public GitHub Gist URL.
 	// Validates that the gist is public and contains valid prompt format.
 	// Returns the added prompt or an error if the URL is invalid or gist is not accessible.
-	AddFromURL(gistURL string) (*model.Prompt, error)
+	AddFromURL(ctx context.Context, gistURL string) (*model.Prompt, error)
 
 	// ValidateGistAccess validates user access to a gist and returns its information.
 	// Checks if the user has read/write access and whether the gist is public or private.
 	// Returns gist information or an error if access validation fails.
-	ValidateGistAccess(gistURL string) (*GistInfo, error)
+	ValidateGistAccess(ctx context.Context, gistURL string) (*GistInfo, error)
 
 	// ListPrivatePrompts retrieves all private prompts available in the vault.
 	// Filters the complete prompt list to show only private gists.
 	// Used in share command interactive mode. Returns an error if listing fails.
-	ListPrivatePrompts() ([]model.Prompt, error)
+	ListPrivatePrompts(ctx context.Context) ([]model.Prompt, error)
 
 	// FilterPrivatePrompts retrieves private prompts that match the given keyword.
 	// Similar to FilterPrompts but only returns private gists matching the keyword.
 	// Used in share command keyword filtering mode. Returns an error if filtering fails.
 	// Sync synchronizes the local cache with GitHub, downloading the raw index.json file.
 	// This ensures the local cache has the complete and up-to-date index from GitHub.
-	// Returns an error if the synchronization fails.
-	Sync() error
+	// The returned plan tells which cached prompt contents are still current, so only
+	// changed prompts need to be downloaded. Returns an error if the synchronization fails.
+	Sync(ctx context.Context) (*infra.SyncPlan, error)
+
+	// ReplayOfflineChanges pushes the writes queued while the remote was unreachable.
+	// Writes that conflict with remote changes are dropped and listed in the report.
+	// Returns an empty report when the store keeps no offline journal.
+	ReplayOfflineChanges(ctx context.Context) (*infra.ReplayReport, error)
+
+	// FetchPromptContents downloads the contents of many prompts and caches them.
+	// Stores that support it fetch several prompts at a time, pacing requests by the API rate limit.
+	// progress is called as each prompt finishes; per-prompt failures are reported there.
+	// The returned error is only set when the whole operation stops, e.g. because ctx was cancelled.
+	FetchPromptContents(ctx context.Context, prompts []model.Prompt, progress ContentProgress) error
+
+	FilterPrivatePrompts(ctx context.Context, keyword string) ([]model.Prompt, error)
+
+	// GetPromptHistory retrieves the revisions of a prompt from its gist history, newest first.
+	// Each revision carries the version field of the prompt at that point. Revisions are cached,
+	// so the history can still be browsed offline. Returns an error if the store keeps no history.
+	GetPromptHistory(ctx context.Context, prompt *model.Prompt) ([]model.Revision, error)
+
+	// GetPromptRevision retrieves the content of a prompt as of a past revision.
+	// sha may be abbreviated as long as it matches a single revision.
+	// Returns the content or an error if the revision does not exist.
+	GetPromptRevision(ctx context.Context, prompt *model.Prompt, sha string) (string, error)
+
+	// DiffRevisions compares two revisions of a prompt; the SHAs may be abbreviated.
+	// An empty to stands for the latest revision and an empty from for the revision before to.
+	// Returns the differences or an error if a revision does not exist.
+	DiffRevisions(ctx context.Context, prompt *model.Prompt, from, to string) (*PromptDiff, error)
+
+	// DiffWithFile compares the vault copy of a prompt with a local YAML file.
+	// Returns the differences or an error if either side cannot be read.
+	DiffWithFile(ctx context.Context, prompt *model.Prompt, filePath string) (*PromptDiff, error)
+
+	// DiffWithExport compares a private prompt with the public copy it was shared as,
+	// found through the parent of the export records. Returns an error if it was never shared.
+	DiffWithExport(ctx context.Context, prompt *model.Prompt) (*PromptDiff, error)
+
+	// UpdatePromptContent validates edited content and stores it as the new content of a prompt.
+	// A changed name or author renames the prompt, unless another prompt already has them.
+	// Returns the updated prompt, or a validation error the content can be corrected for.
+	UpdatePromptContent(ctx context.Context, prompt *model.Prompt, content string) (*model.Prompt, error)
+
+	// PreviewRollback compares the latest revision of a prompt with the revision a rollback
+	// to sha would restore. sha may be abbreviated as long as it matches a single revision.
+	PreviewRollback(ctx context.Context, prompt *model.Prompt, sha string) (*PromptDiff, error)
+
+	// RollbackPrompt restores the content of a past revision as the new content of a prompt.
+	// The gist, its index entry and the cache are updated as for any other update.
+	// Returns an error if the revision does not exist or matches the current content, or if it
+	// restores a name and author another prompt has taken since.
+	RollbackPrompt(ctx context.Context, prompt *model.Prompt, sha string) (*RollbackResult, error)
+
+	// ExportArchive reads the index of the vault and the content of every prompt and export
+	// into an archive. progress is called as each content is read; the export stops at the
+	// first content that cannot be read.
+	ExportArchive(ctx context.Context, progress ContentProgress) (*archive.Archive, error)
 
-	FilterPrivatePrompts(keyword string) ([]model.Prompt, error)
+	// RestoreArchive recreates the prompts and exports of an archive through the store, so
+	// it works with any backend. Exports are linked to the new URLs of their parents. Entries
+	// that already exist, by URL or by name and author, are skipped, so a restore that stopped
+	// part way can be run again. The report lists the entries handled before any error.
+	RestoreArchive(ctx context.Context, a *archive.Archive) (*RestoreReport, error)
 }
@@ -1,6 +1,10 @@
 package service
 
 import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"io/fs"
 	"io/ioutil"
 	"log"
 	"os"
@@ -8,6 +12,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/grigri/pv/internal/archive"
+	"github.com/grigri/pv/internal/diff"
 	"github.com/grigri/pv/internal/errors"
 	"github.com/grigri/pv/internal/infra"
 	"github.com/grigri/pv/internal/model"
@@ -32,8 +38,8 @@ func NewPromptService(
 	}
 }
 
-// AddFromFile reads a YAML file, validates it, and adds it to the store
-func (p *promptServiceImpl) AddFromFile(filePath string) (*model.Prompt, error) {
+// AddFromFile reads a YAML or Markdown prompt file, validates it, and adds it to the store
+func (p *promptServiceImpl) AddFromFile(ctx context.Context, filePath string) (*model.Prompt, error) {
 	// Validate file path
 	if strings.TrimSpace(filePath) == "" {
 		return nil, errors.NewAppError(
@@ -72,31 +78,42 @@ func (p *promptServiceImpl) AddFromFile(filePath string) (*model.Prompt, error)
 		)
 	}
 
-	// Validate file content using the YAML validator
-	promptFileContent, err := p.validator.ValidatePromptFile(content)
-	if err != nil {
-		return nil, err // Error already wrapped by validator
-	}
+	return p.addContent(ctx, string(content), model.FormatFromPath(absPath))
+}
 
-	// Validate required fields
-	if err := p.validator.ValidateRequired(promptFileContent); err != nil {
-		return nil, err // Error already wrapped by validator
-	}
+// AddFromContent validates the content of a prompt file and adds it to the store
+func (p *promptServiceImpl) AddFromContent(ctx context.Context, content string) (*model.Prompt, error) {
+	return p.addContent(ctx, content, "")
+}
 
-	// Convert PromptFileContent to Prompt model
-	prompt := &model.Prompt{
-		ID:          "", // ID will be generated by the store
-		Name:        promptFileContent.Metadata.Name,
-		Author:      promptFileContent.Metadata.Author,
-		Description: promptFileContent.Metadata.Description,
-		Tags:        promptFileContent.Metadata.Tags,
-		Version:     promptFileContent.Metadata.Version,
-		Content:     string(content),
-		GistURL:     "", // GistURL will be set by the store after creating the gist
+// addContent adds the content of a prompt file stored in the given format
+// An empty format keeps the format of the prompt it updates, or is YAML for a new prompt.
+func (p *promptServiceImpl) addContent(ctx context.Context, content string, format string) (*model.Prompt, error) {
+	prompt, err := p.ValidatePromptContent(content)
+	if err != nil {
+		return nil, err
 	}
+	prompt.Format = format
 
 	// Add prompt to store (this will handle GitHub Gist creation and index updates)
-	if err := p.store.Add(*prompt); err != nil {
+	// A batch of one returns where the prompt is stored; store.Add doesn't, so the
+	// prompt is returned without its gist URL
+	if batcher, ok := p.store.(infra.BatchAdder); ok {
+		stored, err := batcher.AddBatch(ctx, []model.Prompt{*prompt})
+		if err != nil {
+			return nil, errors.NewAppError(
+				errors.ErrStorage,
+				"failed to add prompt to store",
+				err,
+			)
+		}
+		if len(stored) == 1 {
+			prompt.ID, prompt.GistURL = stored[0].ID, stored[0].GistURL
+		}
+		return prompt, nil
+	}
+
+	if err := p.store.Add(ctx, *prompt); err != nil {
 		return nil, errors.NewAppError(
 			errors.ErrStorage,
 			"failed to add prompt to store",
@@ -107,8 +124,220 @@ func (p *promptServiceImpl) AddFromFile(filePath string) (*model.Prompt, error)
 	return prompt, nil
 }
 
+// PlanAddFromFiles expands paths into prompt files, validates every one of them and
+// plans each valid file as a create or an update
+func (p *promptServiceImpl) PlanAddFromFiles(ctx context.Context, paths []string, recursive bool) (*AddPlan, error) {
+	files, failures := expandPromptPaths(paths, recursive)
+
+	var sources []PromptSource
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			failures = append(failures, AddFailure{
+				Path: file,
+				Err:  errors.NewAppError(errors.ErrValidation, "failed to read file", err),
+			})
+			continue
+		}
+		sources = append(sources, PromptSource{Path: file, Content: string(content), Format: model.FormatFromPath(file)})
+	}
+
+	return p.planAdds(ctx, sources, failures)
+}
+
+// PlanAddFromContents validates the given prompt file contents and plans each valid one
+// as a create or an update
+func (p *promptServiceImpl) PlanAddFromContents(ctx context.Context, sources []PromptSource) (*AddPlan, error) {
+	return p.planAdds(ctx, sources, nil)
+}
+
+// planAdds validates sources and plans them after the given failures
+func (p *promptServiceImpl) planAdds(ctx context.Context, sources []PromptSource, failures []AddFailure) (*AddPlan, error) {
+	plan := &AddPlan{Failures: failures}
+
+	// name 和 author 都相同时更新已有的提示词，与 store.Add 的匹配规则一致
+	existing, err := p.store.List(ctx)
+	if err != nil && err != infra.ErrNoIndex && err != infra.ErrEmptyIndex {
+		return nil, errors.NewAppError(
+			errors.TypeOf(err, errors.ErrStorage),
+			"failed to list prompts",
+			err,
+		)
+	}
+
+	planned := map[string]string{}
+	for _, source := range sources {
+		prompt, err := p.ValidatePromptContent(source.Content)
+		if err != nil {
+			plan.Failures = append(plan.Failures, AddFailure{Path: source.Path, Err: err})
+			continue
+		}
+		prompt.Format = source.Format
+
+		key := prompt.Name + "\x00" + prompt.Author
+		if other, ok := planned[key]; ok {
+			plan.Failures = append(plan.Failures, AddFailure{
+				Path: source.Path,
+				Err:  errors.NewAppError(errors.ErrValidation, fmt.Sprintf("与 %s 的 name 和 author 相同", other), nil),
+			})
+			continue
+		}
+		planned[key] = source.Path
+
+		add := PlannedAdd{Path: source.Path, Prompt: prompt, Action: AddCreate}
+		for i := range existing {
+			if existing[i].Name == prompt.Name && existing[i].Author == prompt.Author {
+				add.Action = AddUpdate
+				add.Existing = &existing[i]
+				break
+			}
+		}
+		plan.Adds = append(plan.Adds, add)
+	}
+
+	log.Printf("Planned bulk add: %d creates, %d updates, %d failures", plan.Count(AddCreate), plan.Count(AddUpdate), len(plan.Failures))
+	return plan, nil
+}
+
+// ApplyAddPlan stores the prompts of a plan, with a single index write when the store supports it
+func (p *promptServiceImpl) ApplyAddPlan(ctx context.Context, plan *AddPlan) ([]model.Prompt, error) {
+	if len(plan.Failures) > 0 {
+		return nil, errors.ErrAddPlanHasFailures
+	}
+
+	prompts := make([]model.Prompt, 0, len(plan.Adds))
+	for _, add := range plan.Adds {
+		prompts = append(prompts, *add.Prompt)
+	}
+
+	var stored []model.Prompt
+	var err error
+	if batcher, ok := p.store.(infra.BatchAdder); ok {
+		stored, err = batcher.AddBatch(ctx, prompts)
+	} else {
+		// 不支持批量写入的 store 逐个添加
+		for _, prompt := range prompts {
+			if err = p.store.Add(ctx, prompt); err != nil {
+				break
+			}
+			stored = append(stored, prompt)
+		}
+	}
+	if err != nil {
+		return stored, errors.NewAppError(
+			errors.TypeOf(err, errors.ErrStorage),
+			fmt.Sprintf("failed to add prompts, %d of %d added", len(stored), len(prompts)),
+			err,
+		)
+	}
+
+	return stored, nil
+}
+
+// expandPromptPaths resolves files, directories and glob patterns into prompt files
+// Directories contribute their .yaml and .yml files and the .md and .markdown files that
+// open with front matter, and with recursive those of their subdirectories too; hidden
+// subdirectories are skipped. A path that matches no prompt file is reported as a failure.
+func expandPromptPaths(paths []string, recursive bool) ([]string, []AddFailure) {
+	var files []string
+	var failures []AddFailure
+	seen := map[string]bool{}
+	addFile := func(file string) {
+		key, err := filepath.Abs(file)
+		if err != nil {
+			key = file
+		}
+		if !seen[key] {
+			seen[key] = true
+			files = append(files, file)
+		}
+	}
+
+	for _, path := range paths {
+		matches := []string{path}
+		if strings.ContainsAny(path, "*?[") {
+			var err error
+			if matches, err = filepath.Glob(path); err != nil {
+				failures = append(failures, AddFailure{
+					Path: path,
+					Err:  errors.NewAppError(errors.ErrValidation, "invalid glob pattern", err),
+				})
+				continue
+			}
+		}
+
+		// 路径不存在时已经报告过失败，不再报告没有匹配的文件
+		matched, missing := 0, false
+		for _, match := range matches {
+			info, err := os.Stat(match)
+			if err != nil {
+				failures = append(failures, AddFailure{Path: match, Err: errors.ErrFileNotFound})
+				missing = true
+				continue
+			}
+			if !info.IsDir() {
+				addFile(match)
+				matched++
+				continue
+			}
+
+			err = filepath.WalkDir(match, func(file string, entry fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				if entry.IsDir() {
+					if file != match && (!recursive || strings.HasPrefix(entry.Name(), ".")) {
+						return filepath.SkipDir
+					}
+					return nil
+				}
+				switch model.FormatFromPath(file) {
+				case model.FormatYAML:
+					addFile(file)
+					matched++
+				case model.FormatMarkdown:
+					// 目录中的 README 等普通 Markdown 文件不是提示词
+					if hasFrontMatter(file) {
+						addFile(file)
+						matched++
+					}
+				}
+				return nil
+			})
+			if err != nil {
+				failures = append(failures, AddFailure{
+					Path: match,
+					Err:  errors.NewAppError(errors.ErrValidation, "failed to read directory", err),
+				})
+				missing = true
+			}
+		}
+
+		if matched == 0 && !missing {
+			failures = append(failures, AddFailure{Path: path, Err: errors.ErrNoPromptFiles})
+		}
+	}
+
+	return files, failures
+}
+
+// hasFrontMatter reports whether the file at path opens with a --- fence
+func hasFrontMatter(path string) bool {
+	file, err := os.Open(path)
+	if err != nil {
+		// 无法读取的文件交给验证报告
+		return true
+	}
+	defer file.Close()
+
+	head := make([]byte, 512)
+	n, _ := file.Read(head)
+	text := strings.TrimLeft(strings.TrimPrefix(string(head[:n]), "\ufeff"), " \t\r\n")
+	return strings.HasPrefix(text, "---")
+}
+
 // DeleteByKeyword deletes prompts that match the given keyword
-func (p *promptServiceImpl) DeleteByKeyword(keyword string) error {
+func (p *promptServiceImpl) DeleteByKeyword(ctx context.Context, keyword string) error {
 	log.Printf("Starting delete by keyword: %s", keyword)
 	
 	// Validate input
@@ -121,7 +350,7 @@ func (p *promptServiceImpl) DeleteByKeyword(keyword string) error {
 	}
 
 	// Use Store.Get() to find matching prompts
-	matchingPrompts, err := p.store.Get(keyword)
+	matchingPrompts, err := p.store.Get(ctx, keyword)
 	if err != nil {
 		log.Printf("Failed to search prompts by keyword '%s': %v", keyword, err)
 		return errors.NewAppError(
@@ -145,7 +374,7 @@ func (p *promptServiceImpl) DeleteByKeyword(keyword string) error {
 	// Execute deletion for each matching prompt
 	for _, prompt := range matchingPrompts {
 		log.Printf("Deleting prompt: %s (ID: %s)", prompt.Name, prompt.ID)
-		err := p.store.Delete(prompt.ID)
+		err := p.store.Delete(ctx, prompt.ID)
 		if err != nil {
 			log.Printf("Failed to delete prompt %s: %v", prompt.Name, err)
 			return errors.NewAppError(
@@ -161,7 +390,7 @@ func (p *promptServiceImpl) DeleteByKeyword(keyword string) error {
 }
 
 // DeleteByURL deletes a prompt identified by its GitHub Gist URL
-func (p *promptServiceImpl) DeleteByURL(gistURL string) error {
+func (p *promptServiceImpl) DeleteByURL(ctx context.Context, gistURL string) error {
 	log.Printf("Starting delete by URL: %s", gistURL)
 	
 	// Validate URL format
@@ -174,7 +403,7 @@ func (p *promptServiceImpl) DeleteByURL(gistURL string) error {
 	log.Printf("Extracted Gist ID: %s", gistID)
 
 	// Use Store.Get() to verify the prompt exists by searching with the gist ID
-	matchingPrompts, err := p.store.Get(gistID)
+	matchingPrompts, err := p.store.Get(ctx, gistID)
 	if err != nil {
 		log.Printf("Failed to search for prompt with Gist ID '%s': %v", gistID, err)
 		return errors.NewAppError(
@@ -192,7 +421,7 @@ func (p *promptServiceImpl) DeleteByURL(gistURL string) error {
 
 	// Delete the prompt using the gist ID
 	log.Printf("Deleting prompt with Gist ID: %s", gistID)
-	err = p.store.Delete(gistID)
+	err = p.store.Delete(ctx, gistID)
 	if err != nil {
 		log.Printf("Failed to delete prompt with Gist ID '%s': %v", gistID, err)
 		return errors.NewAppError(
@@ -207,10 +436,10 @@ func (p *promptServiceImpl) DeleteByURL(gistURL string) error {
 }
 
 // ListPrompts retrieves all prompts available in the vault
-func (p *promptServiceImpl) ListPrompts() ([]model.Prompt, error) {
+func (p *promptServiceImpl) ListPrompts(ctx context.Context) ([]model.Prompt, error) {
 	log.Printf("Listing all prompts")
 	
-	prompts, err := p.store.List()
+	prompts, err := p.store.List(ctx)
 	if err != nil {
 		// Handle specific store errors with user-friendly messages
 		if err == infra.ErrNoIndex {
@@ -228,7 +457,7 @@ func (p *promptServiceImpl) ListPrompts() ([]model.Prompt, error) {
 		
 		log.Printf("Failed to list prompts: %v", err)
 		return nil, errors.NewAppError(
-			errors.ErrStorage,
+			errors.TypeOf(err, errors.ErrStorage),
 			"failed to list prompts",
 			err,
 		)
@@ -239,7 +468,7 @@ func (p *promptServiceImpl) ListPrompts() ([]model.Prompt, error) {
 }
 
 // FilterPrompts retrieves prompts that match the given keyword
-func (p *promptServiceImpl) FilterPrompts(keyword string) ([]model.Prompt, error) {
+func (p *promptServiceImpl) FilterPrompts(ctx context.Context, keyword string) ([]model.Prompt, error) {
 	log.Printf("Filtering prompts with keyword: %s", keyword)
 	
 	// Validate input
@@ -252,7 +481,7 @@ func (p *promptServiceImpl) FilterPrompts(keyword string) ([]model.Prompt, error
 	}
 
 	// Use Store.Get() to filter prompts
-	matchingPrompts, err := p.store.Get(keyword)
+	matchingPrompts, err := p.store.Get(ctx, keyword)
 	if err != nil {
 		// Handle specific store errors
 		if err == infra.ErrNoIndex {
@@ -283,7 +512,7 @@ func (p *promptServiceImpl) FilterPrompts(keyword string) ([]model.Prompt, error
 }
 
 // GetPromptByURL retrieves a specific prompt by its GitHub Gist URL
-func (p *promptServiceImpl) GetPromptByURL(gistURL string) (*model.Prompt, error) {
+func (p *promptServiceImpl) GetPromptByURL(ctx context.Context, gistURL string) (*model.Prompt, error) {
 	log.Printf("Getting prompt by URL: %s", gistURL)
 	
 	// Validate URL format and extract Gist ID
@@ -296,7 +525,7 @@ func (p *promptServiceImpl) GetPromptByURL(gistURL string) (*model.Prompt, error
 	log.Printf("Extracted Gist ID: %s", gistID)
 
 	// Use Store.Get() to find the prompt by gist ID
-	matchingPrompts, err := p.store.Get(gistID)
+	matchingPrompts, err := p.store.Get(ctx, gistID)
 	if err != nil {
 		// Handle specific store errors
 		if err == infra.ErrNoIndex {
@@ -337,7 +566,7 @@ func (p *promptServiceImpl) GetPromptByURL(gistURL string) (*model.Prompt, error
 }
 
 // GetPromptContent retrieves the actual content of a prompt from GitHub Gist
-func (p *promptServiceImpl) GetPromptContent(prompt *model.Prompt) (string, error) {
+func (p *promptServiceImpl) GetPromptContent(ctx context.Context, prompt *model.Prompt) (string, error) {
 	// Validate input first before logging
 	if prompt == nil {
 		log.Printf("GetPromptContent called with nil prompt")
@@ -359,7 +588,7 @@ func (p *promptServiceImpl) GetPromptContent(prompt *model.Prompt) (string, erro
 	}
 
 	// Use the store to get the content
-	content, err := p.store.GetContent(prompt.ID)
+	content, err := p.store.GetContent(ctx, prompt.ID)
 	if err != nil {
 		log.Printf("Failed to get content for prompt %s: %v", prompt.ID, err)
 		return "", errors.NewAppError(
@@ -374,12 +603,12 @@ func (p *promptServiceImpl) GetPromptContent(prompt *model.Prompt) (string, erro
 }
 
 // SharePrompt 实现分享私有 prompt 的完整流程
-func (p *promptServiceImpl) SharePrompt(prompt *model.Prompt) (*model.Prompt, error) {
+func (p *promptServiceImpl) SharePrompt(ctx context.Context, prompt *model.Prompt) (*model.Prompt, error) {
 	// 0. 获取完整的 prompt 内容（因为传入的 prompt 可能只包含元数据）
 	completePrompt := prompt
 	if strings.TrimSpace(prompt.Content) == "" {
 		// 如果 Content 为空，说明只有元数据，需要获取完整内容
-		content, err := p.store.GetContent(prompt.ID)
+		content, err := p.store.GetContent(ctx, prompt.ID)
 		if err != nil {
 			return nil, errors.NewShareError("获取完整内容", prompt.GistURL, err)
 		}
@@ -401,11 +630,12 @@ func (p *promptServiceImpl) SharePrompt(prompt *model.Prompt) (*model.Prompt, er
 			Version:     parsedPrompt.Version,
 			Content:     content,            // 完整的原始内容
 			Parent:      prompt.Parent,
+			Format:      prompt.Format,
 		}
 	}
 	
 	// 1. 验证访问权限
-	gistInfo, err := p.store.GetGistInfo(completePrompt.GistURL)
+	gistInfo, err := p.store.GetGistInfo(ctx, completePrompt.GistURL)
 	if err != nil {
 		return nil, errors.NewShareError("验证访问权限", completePrompt.GistURL, err)
 	}
@@ -419,7 +649,7 @@ func (p *promptServiceImpl) SharePrompt(prompt *model.Prompt) (*model.Prompt, er
 	}
 	
 	// 2. 检查现有导出
-	exports, err := p.store.GetExports()
+	exports, err := p.store.GetExports(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -439,15 +669,16 @@ func (p *promptServiceImpl) SharePrompt(prompt *model.Prompt) (*model.Prompt, er
 	
 	if existingExport != nil {
 		// 更新现有公开 gist 的内容
-		err = p.store.UpdateGist(existingExport.GistURL, *completePrompt)
+		err = p.store.UpdateGist(ctx, existingExport.GistURL, *completePrompt)
 		if err != nil {
 			return nil, err
 		}
 		gistURL = existingExport.GistURL
 		
-		// 更新导出记录的时间戳
+		// 更新导出记录的时间戳和元数据
 		existingExport.LastUpdated = time.Now()
-		err = p.store.UpdateExport(*existingExport)
+		existingExport.ApplyMetadata(*completePrompt)
+		err = p.store.UpdateExport(ctx, *existingExport)
 		if err != nil {
 			return nil, err
 		}
@@ -466,7 +697,7 @@ func (p *promptServiceImpl) SharePrompt(prompt *model.Prompt) (*model.Prompt, er
 		}
 	} else {
 		// 创建新的公开 gist
-		gistURL, err = p.store.CreatePublicGist(*completePrompt)
+		gistURL, err = p.store.CreatePublicGist(ctx, *completePrompt)
 		if err != nil {
 			return nil, err
 		}
@@ -480,8 +711,9 @@ func (p *promptServiceImpl) SharePrompt(prompt *model.Prompt) (*model.Prompt, er
 			LastUpdated: time.Now(),
 			Parent:      &completePrompt.GistURL,
 		}
+		exportRecord.ApplyMetadata(*completePrompt)
 		
-		err = p.store.AddExport(exportRecord)
+		err = p.store.AddExport(ctx, exportRecord)
 		if err != nil {
 			return nil, err
 		}
@@ -504,9 +736,9 @@ func (p *promptServiceImpl) SharePrompt(prompt *model.Prompt) (*model.Prompt, er
 }
 
 // AddFromURL 从公开 gist URL 导入 prompt
-func (p *promptServiceImpl) AddFromURL(gistURL string) (*model.Prompt, error) {
+func (p *promptServiceImpl) AddFromURL(ctx context.Context, gistURL string) (*model.Prompt, error) {
 	// 首先检查该 gist URL 是否已经存在
-	existingPromptByURL, err := p.store.FindExistingPromptByURL(gistURL)
+	existingPromptByURL, err := p.store.FindExistingPromptByURL(ctx, gistURL)
 	if err != nil {
 		return nil, errors.NewAddFromURLError("检查 gist URL 重复", gistURL, err)
 	}
@@ -516,7 +748,7 @@ func (p *promptServiceImpl) AddFromURL(gistURL string) (*model.Prompt, error) {
 	}
 	
 	// 验证 gist 是公开的
-	gistInfo, err := p.store.GetGistInfo(gistURL)
+	gistInfo, err := p.store.GetGistInfo(ctx, gistURL)
 	if err != nil {
 		return nil, errors.NewAddFromURLError("验证 gist 信息", gistURL, err)
 	}
@@ -527,7 +759,7 @@ func (p *promptServiceImpl) AddFromURL(gistURL string) (*model.Prompt, error) {
 	
 	// 获取 gist 内容
 	gistID := p.extractGistID(gistURL)
-	content, err := p.store.GetContent(gistID)
+	content, err := p.store.GetContent(ctx, gistID)
 	if err != nil {
 		return nil, errors.NewAddFromURLError("获取 gist 内容", gistURL, err)
 	}
@@ -539,7 +771,7 @@ func (p *promptServiceImpl) AddFromURL(gistURL string) (*model.Prompt, error) {
 	}
 	
 	// 添加到本地索引
-	err = p.store.Add(*prompt)
+	err = p.store.Add(ctx, *prompt)
 	if err != nil {
 		return nil, errors.NewAddFromURLError("添加到本地索引", gistURL, err)
 	}
@@ -548,8 +780,8 @@ func (p *promptServiceImpl) AddFromURL(gistURL string) (*model.Prompt, error) {
 }
 
 // ValidateGistAccess 验证用户对 gist 的访问权限
-func (p *promptServiceImpl) ValidateGistAccess(gistURL string) (*GistInfo, error) {
-	infraGistInfo, err := p.store.GetGistInfo(gistURL)
+func (p *promptServiceImpl) ValidateGistAccess(ctx context.Context, gistURL string) (*GistInfo, error) {
+	infraGistInfo, err := p.store.GetGistInfo(ctx, gistURL)
 	if err != nil {
 		return nil, err
 	}
@@ -566,15 +798,15 @@ func (p *promptServiceImpl) ValidateGistAccess(gistURL string) (*GistInfo, error
 }
 
 // ListPrivatePrompts 列出所有私有 prompts
-func (p *promptServiceImpl) ListPrivatePrompts() ([]model.Prompt, error) {
-	allPrompts, err := p.ListPrompts()
+func (p *promptServiceImpl) ListPrivatePrompts(ctx context.Context) ([]model.Prompt, error) {
+	allPrompts, err := p.ListPrompts(ctx)
 	if err != nil {
 		return nil, err
 	}
 	
 	var privatePrompts []model.Prompt
 	for _, prompt := range allPrompts {
-		gistInfo, err := p.store.GetGistInfo(prompt.GistURL)
+		gistInfo, err := p.store.GetGistInfo(ctx, prompt.GistURL)
 		if err != nil {
 			continue // 跳过无法访问的 gist
 		}
@@ -587,8 +819,8 @@ func (p *promptServiceImpl) ListPrivatePrompts() ([]model.Prompt, error) {
 }
 
 // FilterPrivatePrompts 根据关键字筛选私有 prompts
-func (p *promptServiceImpl) FilterPrivatePrompts(keyword string) ([]model.Prompt, error) {
-	allPrivatePrompts, err := p.ListPrivatePrompts()
+func (p *promptServiceImpl) FilterPrivatePrompts(ctx context.Context, keyword string) ([]model.Prompt, error) {
+	allPrivatePrompts, err := p.ListPrivatePrompts(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -597,30 +829,857 @@ func (p *promptServiceImpl) FilterPrivatePrompts(keyword string) ([]model.Prompt
 }
 
 // Sync synchronizes the local cache with GitHub by downloading the raw index.json file
-func (p *promptServiceImpl) Sync() error {
+// and working out which cached prompt contents changed
+func (p *promptServiceImpl) Sync(ctx context.Context) (*infra.SyncPlan, error) {
 	log.Printf("Starting cache synchronization with GitHub")
 	
-	// Cast store to CachedStore to access SyncRawIndex
+	// 回填旧版本 pv 写入的索引条目的元数据（只处理缺少 content hash 的条目，完成后不再请求）
+	if backfiller, ok := p.store.(infra.MetadataBackfiller); ok {
+		filled, err := backfiller.BackfillMetadata(ctx)
+		if err != nil {
+			log.Printf("Failed to backfill prompt metadata: %v", err)
+		} else if filled > 0 {
+			log.Printf("Backfilled metadata for %d prompts", filled)
+		}
+	}
+	
+	// Cast store to CachedStore to access SyncIndex
 	cachedStore, ok := p.store.(*infra.CachedStore)
 	if !ok {
 		log.Printf("Store is not a CachedStore, skipping raw index sync")
-		return nil // Not an error, just means we're not using cached storage
+		return &infra.SyncPlan{}, nil // Not an error, just means we're not using cached storage
 	}
 	
-	// Sync raw index.json content from GitHub to local cache
-	if err := cachedStore.SyncRawIndex(); err != nil {
+	// Sync raw index.json content from GitHub to local cache and compare it with the cached contents
+	plan, err := cachedStore.SyncIndex(ctx)
+	if err != nil {
 		log.Printf("Failed to sync raw index: %v", err)
-		return errors.NewAppError(
-			errors.ErrStorage,
+		// 保留已分类的网络、认证和权限错误类型，sync 命令据此给出提示
+		return nil, errors.NewAppError(
+			errors.TypeOf(err, errors.ErrStorage),
 			"failed to sync index with GitHub",
 			err,
 		)
 	}
 	
-	log.Printf("Successfully synchronized cache with GitHub")
+	log.Printf("Successfully synchronized cache with GitHub: %d prompts up to date, %d evicted", len(plan.UpToDate), plan.Evicted)
+	return plan, nil
+}
+
+// ReplayOfflineChanges pushes the writes queued in the offline journal to the remote store
+func (p *promptServiceImpl) ReplayOfflineChanges(ctx context.Context) (*infra.ReplayReport, error) {
+	cachedStore, ok := p.store.(*infra.CachedStore)
+	if !ok {
+		// Without a cache there is no offline journal to replay
+		return &infra.ReplayReport{}, nil
+	}
+	
+	report, err := cachedStore.ReplayJournal(ctx)
+	if err != nil {
+		log.Printf("Failed to replay offline changes: %v", err)
+		return nil, errors.NewAppError(
+			errors.TypeOf(err, errors.ErrStorage),
+			"failed to push offline changes",
+			err,
+		)
+	}
+	
+	log.Printf("Replayed offline changes: %d applied, %d pending, %d conflicts", report.Applied, report.Pending, len(report.Conflicts))
+	return report, nil
+}
+
+// FetchPromptContents downloads and caches the contents of the given prompts
+// Stores implementing infra.ContentFetcher fetch them in parallel; others one at a time.
+func (p *promptServiceImpl) FetchPromptContents(ctx context.Context, prompts []model.Prompt, progress ContentProgress) error {
+	report := func(done int, prompt model.Prompt, err error) {
+		if progress != nil {
+			progress(done, len(prompts), prompt, err)
+		}
+	}
+
+	fetcher, ok := p.store.(infra.ContentFetcher)
+	if !ok {
+		for i := range prompts {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			_, err := p.GetPromptContent(ctx, &prompts[i])
+			report(i+1, prompts[i], err)
+		}
+		return nil
+	}
+
+	byID := make(map[string]model.Prompt, len(prompts))
+	var gistIDs []string
+	for _, prompt := range prompts {
+		if _, seen := byID[prompt.ID]; !seen {
+			gistIDs = append(gistIDs, prompt.ID)
+		}
+		byID[prompt.ID] = prompt
+	}
+
+	_, err := fetcher.FetchContents(ctx, gistIDs, func(done, total int, result infra.FetchResult) {
+		var err error
+		if result.Err != nil {
+			log.Printf("Failed to get content for prompt %s: %v", result.GistID, result.Err)
+			err = errors.NewAppError(errors.TypeOf(result.Err, errors.ErrStorage), "failed to retrieve prompt content", result.Err)
+		}
+		if progress != nil {
+			progress(done, total, byID[result.GistID], err)
+		}
+	})
+	return err
+}
+
+// GetPromptHistory lists the revisions of a prompt and reads the version of each one
+// Revision contents are cached by the store, so only new revisions are downloaded.
+func (p *promptServiceImpl) GetPromptHistory(ctx context.Context, prompt *model.Prompt) ([]model.Revision, error) {
+	provider, err := p.revisionProvider(prompt)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("Listing revisions of prompt: %s (ID: %s)", prompt.Name, prompt.ID)
+	revisions, err := p.listRevisions(ctx, provider, prompt)
+	if err != nil {
+		return nil, err
+	}
+
+	// 版本号只能从每个修订的内容中读取；读取失败的修订仍然列出，只是没有版本号
+	shas := make([]string, len(revisions))
+	for i, revision := range revisions {
+		shas[i] = revision.SHA
+	}
+	// 中断时返回已经读取到版本号的修订
+	contents, _ := infra.FetchRevisionContents(ctx, provider, prompt.ID, shas, func(done, total int, result infra.FetchResult) {
+		if result.Err != nil {
+			log.Printf("Failed to get revision %s of prompt %s: %v", result.GistID, prompt.ID, result.Err)
+		}
+	})
+	for i := range revisions {
+		content, ok := contents[revisions[i].SHA]
+		if !ok {
+			continue
+		}
+		if parsed, err := p.validator.ValidatePromptFile([]byte(content)); err == nil {
+			revisions[i].Version = parsed.Metadata.Version
+		}
+	}
+
+	return revisions, nil
+}
+
+// GetPromptRevision returns the content of a prompt as of a revision
+// An abbreviated sha is resolved against the revision history first.
+func (p *promptServiceImpl) GetPromptRevision(ctx context.Context, prompt *model.Prompt, sha string) (string, error) {
+	provider, err := p.revisionProvider(prompt)
+	if err != nil {
+		return "", err
+	}
+
+	sha = strings.ToLower(strings.TrimSpace(sha))
+	if sha == "" {
+		return "", errors.ErrRevisionNotFound
+	}
+
+	if len(sha) < fullRevisionSHALength {
+		revisions, err := p.listRevisions(ctx, provider, prompt)
+		if err != nil {
+			return "", err
+		}
+		revision, err := resolveRevision(revisions, sha)
+		if err != nil {
+			return "", err
+		}
+		sha = revision.SHA
+	}
+
+	return p.getRevisionContent(ctx, provider, prompt, sha)
+}
+
+// DiffRevisions compares two revisions of a prompt
+// An empty to stands for the latest revision and an empty from for the one before to.
+func (p *promptServiceImpl) DiffRevisions(ctx context.Context, prompt *model.Prompt, from, to string) (*PromptDiff, error) {
+	provider, err := p.revisionProvider(prompt)
+	if err != nil {
+		return nil, err
+	}
+
+	revisions, err := p.listRevisions(ctx, provider, prompt)
+	if err != nil {
+		return nil, err
+	}
+	if len(revisions) == 0 {
+		return nil, errors.ErrRevisionNotFound
+	}
+
+	// revisions 从新到旧排列
+	toIndex := 0
+	if strings.TrimSpace(to) != "" {
+		if toIndex, err = revisionIndex(revisions, to); err != nil {
+			return nil, err
+		}
+	}
+	fromIndex := toIndex + 1
+	if strings.TrimSpace(from) != "" {
+		if fromIndex, err = revisionIndex(revisions, from); err != nil {
+			return nil, err
+		}
+	} else if fromIndex >= len(revisions) {
+		return nil, errors.ErrNoPreviousRevision
+	}
+
+	fromRevision, toRevision := revisions[fromIndex], revisions[toIndex]
+	fromContent, err := p.getRevisionContent(ctx, provider, prompt, fromRevision.SHA)
+	if err != nil {
+		return nil, err
+	}
+	toContent, err := p.getRevisionContent(ctx, provider, prompt, toRevision.SHA)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.comparePromptContents(fromRevision.ShortSHA(), fromContent, toRevision.ShortSHA(), toContent), nil
+}
+
+// DiffWithFile compares the vault copy of a prompt with a local YAML file
+func (p *promptServiceImpl) DiffWithFile(ctx context.Context, prompt *model.Prompt, filePath string) (*PromptDiff, error) {
+	fileContent, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, errors.NewAppError(
+			errors.ErrValidation,
+			"failed to read file",
+			err,
+		)
+	}
+
+	content, err := p.GetPromptContent(ctx, prompt)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.comparePromptContents(prompt.GistURL, content, filePath, string(fileContent)), nil
+}
+
+// DiffWithExport compares a private prompt with the public copy it was shared as
+// The public copy is the export whose parent is the prompt's gist.
+func (p *promptServiceImpl) DiffWithExport(ctx context.Context, prompt *model.Prompt) (*PromptDiff, error) {
+	export, err := p.findExport(ctx, prompt.GistURL)
+	if err != nil {
+		return nil, err
+	}
+	if export == nil {
+		return nil, errors.ErrExportNotFound
+	}
+
+	content, err := p.GetPromptContent(ctx, prompt)
+	if err != nil {
+		return nil, err
+	}
+	exportContent, err := p.GetPromptContent(ctx, &model.Prompt{ID: p.extractGistID(export.GistURL), Name: export.Name})
+	if err != nil {
+		return nil, err
+	}
+
+	return p.comparePromptContents(prompt.GistURL, content, export.GistURL, exportContent), nil
+}
+
+// ValidatePromptContent parses the content of a prompt file and checks its required metadata
+func (p *promptServiceImpl) ValidatePromptContent(content string) (*model.Prompt, error) {
+	// Validate file content using the YAML validator
+	promptFileContent, err := p.validator.ValidatePromptFile([]byte(content))
+	if err != nil {
+		return nil, err // Error already wrapped by validator
+	}
+
+	// Validate required fields
+	if err := p.validator.ValidateRequired(promptFileContent); err != nil {
+		return nil, errors.NewAppError(
+			errors.ErrValidation,
+			"invalid prompt metadata",
+			err,
+		)
+	}
+
+	// Convert PromptFileContent to Prompt model
+	return &model.Prompt{
+		ID:          "", // ID will be generated by the store
+		Name:        promptFileContent.Metadata.Name,
+		Author:      promptFileContent.Metadata.Author,
+		Description: promptFileContent.Metadata.Description,
+		Tags:        promptFileContent.Metadata.Tags,
+		Version:     promptFileContent.Metadata.Version,
+		Content:     content,
+		GistURL:     "", // GistURL will be set by the store after creating the gist
+	}, nil
+}
+
+// UpdatePromptContent validates the edited content of a prompt and writes it to the store
+func (p *promptServiceImpl) UpdatePromptContent(ctx context.Context, prompt *model.Prompt, content string) (*model.Prompt, error) {
+	if prompt == nil || strings.TrimSpace(prompt.ID) == "" {
+		return nil, errors.NewAppError(
+			errors.ErrValidation,
+			"prompt ID cannot be empty",
+			errors.ErrPromptNotFound.Err,
+		)
+	}
+
+	updated, err := p.ValidatePromptContent(content)
+	if err != nil {
+		return nil, err
+	}
+	updated.ID = prompt.ID
+	updated.GistURL = prompt.GistURL
+	updated.Parent = prompt.Parent
+	updated.Format = prompt.Format
+
+	if err := p.checkRename(ctx, prompt, updated); err != nil {
+		return nil, err
+	}
+
+	log.Printf("Updating prompt: %s (ID: %s)", updated.Name, updated.ID)
+	if err := p.store.Update(ctx, *updated); err != nil {
+		log.Printf("Failed to update prompt %s: %v", prompt.ID, err)
+		return nil, errors.NewAppError(
+			errors.TypeOf(err, errors.ErrStorage),
+			"failed to update prompt",
+			err,
+		)
+	}
+
+	return updated, nil
+}
+
+// checkRename returns ErrPromptNameTaken when updated renames prompt to the name and author of another prompt
+func (p *promptServiceImpl) checkRename(ctx context.Context, prompt, updated *model.Prompt) error {
+	// name 和 author 共同标识一个提示词（pv add 依此决定新建还是更新），重命名不能与其他提示词重复
+	if updated.Name == prompt.Name && updated.Author == prompt.Author {
+		return nil
+	}
+
+	prompts, err := p.store.List(ctx)
+	if err != nil {
+		return errors.NewAppError(
+			errors.TypeOf(err, errors.ErrStorage),
+			"failed to check for prompts with the same name",
+			err,
+		)
+	}
+	for _, other := range prompts {
+		if other.ID != prompt.ID && other.Name == updated.Name && other.Author == updated.Author {
+			return errors.ErrPromptNameTaken
+		}
+	}
 	return nil
 }
 
+// PreviewRollback compares the latest revision of a prompt with the revision to restore
+func (p *promptServiceImpl) PreviewRollback(ctx context.Context, prompt *model.Prompt, sha string) (*PromptDiff, error) {
+	current, target, err := p.rollbackRevisions(ctx, prompt, sha)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.comparePromptContents(current.revision.ShortSHA(), current.content, target.revision.ShortSHA(), target.content), nil
+}
+
+// RollbackPrompt writes the content of a past revision back to the prompt's gist
+// The restored content becomes a new revision, so the rollback itself can be undone.
+func (p *promptServiceImpl) RollbackPrompt(ctx context.Context, prompt *model.Prompt, sha string) (*RollbackResult, error) {
+	current, target, err := p.rollbackRevisions(ctx, prompt, sha)
+	if err != nil {
+		return nil, err
+	}
+	if current.content == target.content {
+		return nil, errors.ErrNothingToRollback
+	}
+
+	// 修订内容包含完整的元数据，回滚时一并恢复 name、description、tags 等字段
+	restored, err := p.parseYAMLContent(target.content, prompt.GistURL)
+	if err != nil {
+		return nil, errors.NewAppError(
+			errors.ErrValidation,
+			"revision content is not a valid prompt file",
+			err,
+		)
+	}
+	restored.ID = prompt.ID
+	restored.Parent = prompt.Parent
+	restored.Format = prompt.Format
+
+	// 回滚到重命名之前的修订时，旧名称可能已被其他提示词使用
+	if err := p.checkRename(ctx, prompt, restored); err != nil {
+		return nil, err
+	}
+
+	log.Printf("Rolling back prompt %s (ID: %s) to revision %s", prompt.Name, prompt.ID, target.revision.SHA)
+	if err := p.store.Update(ctx, *restored); err != nil {
+		log.Printf("Failed to roll back prompt %s: %v", prompt.ID, err)
+		return nil, errors.NewAppError(
+			errors.TypeOf(err, errors.ErrStorage),
+			"failed to restore prompt revision",
+			err,
+		)
+	}
+
+	result := &RollbackResult{Prompt: restored, Revision: target.revision}
+
+	// 回滚已经完成，查找公开副本失败不影响结果，只是不再提示同步
+	export, err := p.findExport(ctx, prompt.GistURL)
+	if err != nil {
+		log.Printf("Failed to look up the export of prompt %s: %v", prompt.ID, err)
+	}
+	result.Export = export
+
+	return result, nil
+}
+
+// revisionContent is a revision of a prompt together with its content
+type revisionContent struct {
+	revision model.Revision
+	content  string
+}
+
+// rollbackRevisions returns the latest revision of a prompt and the revision sha resolves to
+func (p *promptServiceImpl) rollbackRevisions(ctx context.Context, prompt *model.Prompt, sha string) (current, target revisionContent, err error) {
+	provider, err := p.revisionProvider(prompt)
+	if err != nil {
+		return current, target, err
+	}
+	if strings.TrimSpace(sha) == "" {
+		return current, target, errors.ErrRevisionNotFound
+	}
+
+	revisions, err := p.listRevisions(ctx, provider, prompt)
+	if err != nil {
+		return current, target, err
+	}
+	targetIndex, err := revisionIndex(revisions, sha)
+	if err != nil {
+		return current, target, err
+	}
+
+	// revisions 从新到旧排列，第一个即当前内容
+	current.revision, target.revision = revisions[0], revisions[targetIndex]
+	if current.content, err = p.getRevisionContent(ctx, provider, prompt, current.revision.SHA); err != nil {
+		return current, target, err
+	}
+	if target.content, err = p.getRevisionContent(ctx, provider, prompt, target.revision.SHA); err != nil {
+		return current, target, err
+	}
+	return current, target, nil
+}
+
+// ExportArchive reads the index and every prompt and export content of the vault into an archive
+func (p *promptServiceImpl) ExportArchive(ctx context.Context, progress ContentProgress) (*archive.Archive, error) {
+	stored, err := p.storedIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+	prompts, err := p.store.List(ctx)
+	if err != nil && err != infra.ErrNoIndex && err != infra.ErrEmptyIndex {
+		return nil, errors.NewAppError(
+			errors.TypeOf(err, errors.ErrStorage),
+			"failed to list prompts",
+			err,
+		)
+	}
+
+	now := time.Now()
+	a := &archive.Archive{CreatedAt: now, Contents: map[string]string{}}
+	if stored != nil {
+		// 存储提供了 index.json 时原样归档，保留条目的时间和元数据
+		a.Index = *stored
+	} else {
+		exports, err := p.store.GetExports(ctx)
+		if err != nil && err != infra.ErrNoIndex && err != infra.ErrEmptyIndex {
+			return nil, errors.NewAppError(
+				errors.TypeOf(err, errors.ErrStorage),
+				"failed to retrieve exports",
+				err,
+			)
+		}
+		a.Index = model.Index{
+			SchemaVersion: model.CurrentIndexSchemaVersion,
+			Prompts:       []model.IndexedPrompt{},
+			LastUpdated:   now,
+			Exports:       append([]model.IndexedPrompt{}, exports...),
+		}
+		// store.List 只返回提示词的元数据，索引条目的其余字段在读取内容后补齐
+		for _, prompt := range prompts {
+			a.Index.Prompts = append(a.Index.Prompts, model.IndexedPrompt{
+				GistURL:     prompt.GistURL,
+				FilePath:    prompt.FileName(),
+				Author:      prompt.Author,
+				Name:        prompt.Name,
+				LastUpdated: now,
+			})
+		}
+	}
+	total := len(a.Index.Prompts) + len(a.Index.Exports)
+
+	for i := range a.Index.Prompts {
+		entry := &a.Index.Prompts[i]
+		prompt := p.indexedPrompt(prompts, *entry)
+		content, err := p.GetPromptContent(ctx, &prompt)
+		if progress != nil {
+			progress(i+1, total, prompt, err)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if stored == nil {
+			prompt.Content = content
+			entry.ApplyMetadata(prompt)
+		}
+		a.Contents[entry.GistURL] = content
+	}
+
+	for i, export := range a.Index.Exports {
+		prompt := model.Prompt{ID: p.extractGistID(export.GistURL), Name: export.Name, Author: export.Author, GistURL: export.GistURL}
+		content, err := p.store.GetContent(ctx, prompt.ID)
+		if err != nil {
+			log.Printf("Failed to get content for export %s: %v", export.GistURL, err)
+			err = errors.NewAppError(errors.TypeOf(err, errors.ErrStorage), "failed to retrieve export content", err)
+		}
+		if progress != nil {
+			progress(len(a.Index.Prompts)+i+1, total, prompt, err)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		a.Contents[export.GistURL] = content
+	}
+
+	log.Printf("Exported archive: %d prompts, %d exports", len(a.Index.Prompts), len(a.Index.Exports))
+	return a, nil
+}
+
+// storedIndex returns the index.json of the store, or nil when the store cannot return it
+func (p *promptServiceImpl) storedIndex(ctx context.Context) (*model.Index, error) {
+	provider, ok := p.store.(infra.RawIndexProvider)
+	if !ok {
+		return nil, nil
+	}
+
+	content, err := provider.GetRawIndexContent(ctx)
+	if stderrors.Is(err, infra.ErrRawIndexUnsupported) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.NewAppError(
+			errors.TypeOf(err, errors.ErrStorage),
+			"failed to read index",
+			err,
+		)
+	}
+
+	index, err := infra.DecodeIndex([]byte(content))
+	if err != nil {
+		return nil, errors.NewAppError(errors.ErrStorage, "invalid index", err)
+	}
+	return index, nil
+}
+
+// indexedPrompt returns the listed prompt of an index entry
+// Entries the store doesn't list are read by the ID in their gist URL.
+func (p *promptServiceImpl) indexedPrompt(prompts []model.Prompt, entry model.IndexedPrompt) model.Prompt {
+	for _, prompt := range prompts {
+		if prompt.GistURL == entry.GistURL {
+			return prompt
+		}
+	}
+	return model.Prompt{ID: p.extractGistID(entry.GistURL), Name: entry.Name, Author: entry.Author, GistURL: entry.GistURL}
+}
+
+// RestoreArchive recreates the prompts and then the exports of an archive that the vault lacks
+// The missing prompts are added as one plan, with a single index write when the store supports it.
+func (p *promptServiceImpl) RestoreArchive(ctx context.Context, a *archive.Archive) (*RestoreReport, error) {
+	report := &RestoreReport{}
+
+	existing, err := p.store.List(ctx)
+	if err != nil && err != infra.ErrNoIndex && err != infra.ErrEmptyIndex {
+		return report, errors.NewAppError(
+			errors.TypeOf(err, errors.ErrStorage),
+			"failed to list prompts",
+			err,
+		)
+	}
+
+	// urls 把归档中提示词的地址映射到它们在 vault 中的地址，用于重新关联 exports 的 Parent
+	urls := map[string]string{}
+	formats := map[string]string{}
+	plan := &AddPlan{}
+	// planned 记录每个计划添加的提示词在 report.Prompts 中的位置
+	var planned []int
+	for _, entry := range a.Index.Prompts {
+		formats[entry.GistURL] = model.FormatFromPath(entry.FilePath)
+
+		if url, ok := findRestoredPrompt(existing, entry); ok {
+			urls[entry.GistURL] = url
+			report.Prompts = append(report.Prompts, RestoredEntry{Entry: entry, URL: url, Action: RestoreSkipped})
+			continue
+		}
+
+		prompt, err := p.ValidatePromptContent(a.Contents[entry.GistURL])
+		if err != nil {
+			return report, errors.NewAppError(
+				errors.TypeOf(err, errors.ErrValidation),
+				fmt.Sprintf("failed to restore prompt %s", entry.Name),
+				err,
+			)
+		}
+		prompt.Format = formats[entry.GistURL]
+		plan.Adds = append(plan.Adds, PlannedAdd{Path: entry.GistURL, Prompt: prompt, Action: AddCreate})
+		planned = append(planned, len(report.Prompts))
+		report.Prompts = append(report.Prompts, RestoredEntry{Entry: entry, Action: RestoreCreated})
+		// 归档中 name 和 author 重复的条目只恢复一次
+		existing = append(existing, model.Prompt{Name: entry.Name, Author: entry.Author, GistURL: entry.GistURL})
+	}
+
+	if len(plan.Adds) > 0 {
+		stored, err := p.ApplyAddPlan(ctx, plan)
+		for i := range planned {
+			if i >= len(stored) {
+				break
+			}
+			entry := &report.Prompts[planned[i]]
+			entry.URL = stored[i].GistURL
+			if entry.URL != "" {
+				urls[entry.Entry.GistURL] = entry.URL
+			}
+		}
+		if err != nil {
+			// 只报告已经添加的提示词
+			if len(stored) < len(planned) {
+				report.Prompts = report.Prompts[:planned[len(stored)]]
+			}
+			return report, errors.NewAppError(
+				errors.TypeOf(err, errors.ErrStorage),
+				"failed to restore prompts",
+				err,
+			)
+		}
+		// 与归档中前面的条目重复而跳过的提示词指向它恢复后的地址
+		for i := range report.Prompts {
+			if url, ok := urls[report.Prompts[i].URL]; ok && report.Prompts[i].Action == RestoreSkipped {
+				report.Prompts[i].URL = url
+			}
+		}
+	}
+
+	exports, err := p.store.GetExports(ctx)
+	if err != nil && err != infra.ErrNoIndex && err != infra.ErrEmptyIndex {
+		return report, errors.NewAppError(
+			errors.TypeOf(err, errors.ErrStorage),
+			"failed to retrieve exports",
+			err,
+		)
+	}
+
+	for _, entry := range a.Index.Exports {
+		record := entry
+		if entry.Parent != nil {
+			parent := *entry.Parent
+			if url, ok := urls[parent]; ok {
+				parent = url
+			}
+			record.Parent = &parent
+		}
+
+		if url, ok := findRestoredExport(exports, record); ok {
+			report.Exports = append(report.Exports, RestoredEntry{Entry: entry, URL: url, Action: RestoreSkipped})
+			continue
+		}
+
+		prompt, err := p.ValidatePromptContent(a.Contents[entry.GistURL])
+		if err != nil {
+			return report, errors.NewAppError(
+				errors.TypeOf(err, errors.ErrValidation),
+				fmt.Sprintf("failed to restore export %s", entry.Name),
+				err,
+			)
+		}
+		// 公开副本与父级提示词使用相同的格式
+		if entry.Parent != nil {
+			prompt.Format = formats[*entry.Parent]
+		}
+
+		record.GistURL, err = p.store.CreatePublicGist(ctx, *prompt)
+		if err == nil {
+			err = p.store.AddExport(ctx, record)
+		}
+		if err != nil {
+			return report, errors.NewAppError(
+				errors.TypeOf(err, errors.ErrStorage),
+				fmt.Sprintf("failed to restore export %s", entry.Name),
+				err,
+			)
+		}
+		exports = append(exports, record)
+		report.Exports = append(report.Exports, RestoredEntry{Entry: entry, URL: record.GistURL, Action: RestoreCreated})
+	}
+
+	log.Printf("Restored archive: %d created, %d skipped", report.Count(RestoreCreated), report.Count(RestoreSkipped))
+	return report, nil
+}
+
+// findRestoredPrompt returns the URL of the prompt in the vault an archived entry already exists as
+func findRestoredPrompt(prompts []model.Prompt, entry model.IndexedPrompt) (string, bool) {
+	for _, prompt := range prompts {
+		if prompt.GistURL == entry.GistURL || (prompt.Name == entry.Name && prompt.Author == entry.Author) {
+			return prompt.GistURL, true
+		}
+	}
+	return "", false
+}
+
+// findRestoredExport returns the URL of the export an archived export already exists as
+// A prompt is shared as a single public copy, so an export of the same parent counts as well.
+func findRestoredExport(exports []model.IndexedPrompt, record model.IndexedPrompt) (string, bool) {
+	for _, export := range exports {
+		if export.GistURL == record.GistURL || (record.Parent != nil && export.Parent != nil && *export.Parent == *record.Parent) {
+			return export.GistURL, true
+		}
+	}
+	return "", false
+}
+
+// findExport returns the export record of the public copy a prompt was shared as
+// Returns nil if the prompt was never shared.
+func (p *promptServiceImpl) findExport(ctx context.Context, gistURL string) (*model.IndexedPrompt, error) {
+	exports, err := p.store.GetExports(ctx)
+	if err != nil {
+		return nil, errors.NewAppError(
+			errors.TypeOf(err, errors.ErrStorage),
+			"failed to retrieve exports",
+			err,
+		)
+	}
+
+	for i := range exports {
+		if exports[i].Parent != nil && *exports[i].Parent == gistURL {
+			return &exports[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// comparePromptContents diffs the metadata and the body of two prompt contents separately
+// Content that doesn't parse as a prompt file is compared as body text only.
+func (p *promptServiceImpl) comparePromptContents(fromLabel, fromContent, toLabel, toContent string) *PromptDiff {
+	// 空列表而不是 null，便于 --json 输出的使用者处理
+	promptDiff := &PromptDiff{From: fromLabel, To: toLabel, Metadata: []MetadataChange{}, Body: []diff.Hunk{}}
+
+	fromParsed, fromErr := p.validator.ValidatePromptFile([]byte(fromContent))
+	toParsed, toErr := p.validator.ValidatePromptFile([]byte(toContent))
+	if fromErr != nil || toErr != nil {
+		promptDiff.Body = append(promptDiff.Body, diff.Hunks(diff.Lines(fromContent, toContent), diff.DefaultContext)...)
+		return promptDiff
+	}
+
+	fields := []struct {
+		name     string
+		old, new string
+	}{
+		{"name", fromParsed.Metadata.Name, toParsed.Metadata.Name},
+		{"author", fromParsed.Metadata.Author, toParsed.Metadata.Author},
+		{"description", fromParsed.Metadata.Description, toParsed.Metadata.Description},
+		{"tags", strings.Join(fromParsed.Metadata.Tags, ", "), strings.Join(toParsed.Metadata.Tags, ", ")},
+		{"version", fromParsed.Metadata.Version, toParsed.Metadata.Version},
+	}
+	for _, field := range fields {
+		if field.old != field.new {
+			promptDiff.Metadata = append(promptDiff.Metadata, MetadataChange{Field: field.name, Old: field.old, New: field.new})
+		}
+	}
+
+	promptDiff.Body = append(promptDiff.Body, diff.Hunks(diff.Lines(fromParsed.Content, toParsed.Content), diff.DefaultContext)...)
+	return promptDiff
+}
+
+// revisionProvider returns the store's revision history for a prompt, if the store keeps one
+func (p *promptServiceImpl) revisionProvider(prompt *model.Prompt) (infra.RevisionProvider, error) {
+	if prompt == nil || strings.TrimSpace(prompt.ID) == "" {
+		return nil, errors.NewAppError(
+			errors.ErrValidation,
+			"prompt ID cannot be empty",
+			errors.ErrPromptNotFound.Err,
+		)
+	}
+
+	provider, ok := p.store.(infra.RevisionProvider)
+	if !ok {
+		return nil, errors.ErrHistoryUnsupported
+	}
+	return provider, nil
+}
+
+// listRevisions lists the revisions of a prompt, newest first
+func (p *promptServiceImpl) listRevisions(ctx context.Context, provider infra.RevisionProvider, prompt *model.Prompt) ([]model.Revision, error) {
+	revisions, err := provider.ListRevisions(ctx, prompt.ID)
+	if err != nil {
+		log.Printf("Failed to list revisions of prompt %s: %v", prompt.ID, err)
+		if stderrors.Is(err, infra.ErrRevisionsUnsupported) {
+			return nil, errors.ErrHistoryUnsupported
+		}
+		return nil, errors.NewAppError(
+			errors.TypeOf(err, errors.ErrStorage),
+			"failed to retrieve prompt history",
+			err,
+		)
+	}
+	return revisions, nil
+}
+
+// getRevisionContent returns the content of a prompt as of a full revision SHA
+func (p *promptServiceImpl) getRevisionContent(ctx context.Context, provider infra.RevisionProvider, prompt *model.Prompt, sha string) (string, error) {
+	content, err := provider.GetRevisionContent(ctx, prompt.ID, sha)
+	if err != nil {
+		log.Printf("Failed to get revision %s of prompt %s: %v", sha, prompt.ID, err)
+		return "", errors.NewAppError(
+			errors.TypeOf(err, errors.ErrStorage),
+			"failed to retrieve prompt revision",
+			err,
+		)
+	}
+	return content, nil
+}
+
+// fullRevisionSHALength is the length of a full gist revision SHA
+const fullRevisionSHALength = 40
+
+// resolveRevision returns the single revision whose SHA starts with prefix
+func resolveRevision(revisions []model.Revision, prefix string) (model.Revision, error) {
+	index, err := revisionIndex(revisions, prefix)
+	if err != nil {
+		return model.Revision{}, err
+	}
+	return revisions[index], nil
+}
+
+// revisionIndex returns the index of the single revision whose SHA starts with prefix
+func revisionIndex(revisions []model.Revision, prefix string) (int, error) {
+	prefix = strings.ToLower(strings.TrimSpace(prefix))
+	found := -1
+	for i, revision := range revisions {
+		if !strings.HasPrefix(revision.SHA, prefix) {
+			continue
+		}
+		if found >= 0 {
+			return -1, errors.ErrAmbiguousRevision
+		}
+		found = i
+	}
+
+	if found < 0 {
+		return -1, errors.ErrRevisionNotFound
+	}
+	return found, nil
+}
+
 // parseYAMLContent 解析 YAML 内容为 Prompt 对象
 func (p *promptServiceImpl) parseYAMLContent(content string, gistURL string) (*model.Prompt, error) {
 	// 使用现有的验证器解析 YAML 内容
@@ -660,7 +1719,8 @@ func (p *promptServiceImpl) filterPromptsByKeyword(prompts []model.Prompt, keywo
 	for _, prompt := range prompts {
 		if strings.Contains(strings.ToLower(prompt.Name), lowerKeyword) ||
 			strings.Contains(strings.ToLower(prompt.Author), lowerKeyword) ||
-			strings.Contains(strings.ToLower(prompt.Description), lowerKeyword) {
+			strings.Contains(strings.ToLower(prompt.Description), lowerKeyword) ||
+			hasTagContaining(prompt.Tags, lowerKeyword) {
 			filtered = append(filtered, prompt)
 		}
 	}
@@ -668,4 +1728,14 @@ func (p *promptServiceImpl) filterPromptsByKeyword(prompts []model.Prompt, keywo
 	return filtered
 }
 
+// hasTagContaining 判断是否有 tag 包含小写关键字
+func hasTagContaining(tags []string, lowerKeyword string) bool {
+	for _, tag := range tags {
+		if strings.Contains(strings.ToLower(tag), lowerKeyword) {
+			return true
+		}
+	}
+	return false
+}
+
 // extractGistID extracts and validates the Gist ID from a GitHub Gist URL
@@ -1,11 +1,15 @@
 package service
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/grigri/pv/internal/errors"
 	"github.com/grigri/pv/internal/infra"
@@ -28,7 +32,7 @@ type MockStore struct {
 	prompts        []model.Prompt
 }
 
-func (m *MockStore) List() ([]model.Prompt, error) {
+func (m *MockStore) List(ctx context.Context) ([]model.Prompt, error) {
 	if m.listError != nil {
 		return nil, m.listError
 	}
@@ -38,7 +42,7 @@ func (m *MockStore) List() ([]model.Prompt, error) {
 	return m.prompts, nil
 }
 
-func (m *MockStore) Add(prompt model.Prompt) error {
+func (m *MockStore) Add(ctx context.Context, prompt model.Prompt) error {
 	if m.addFunc != nil {
 		return m.addFunc(prompt)
 	}
@@ -49,7 +53,7 @@ func (m *MockStore) Add(prompt model.Prompt) error {
 	return nil
 }
 
-func (m *MockStore) Delete(keyword string) error {
+func (m *MockStore) Delete(ctx context.Context, keyword string) error {
 	if m.deleteError != nil {
 		return m.deleteError
 	}
@@ -66,11 +70,11 @@ func (m *MockStore) Delete(keyword string) error {
 	return nil
 }
 
-func (m *MockStore) Update(prompt model.Prompt) error {
+func (m *MockStore) Update(ctx context.Context, prompt model.Prompt) error {
 	return nil
 }
 
-func (m *MockStore) Get(keyword string) ([]model.Prompt, error) {
+func (m *MockStore) Get(ctx context.Context, keyword string) ([]model.Prompt, error) {
 	if m.getError != nil {
 		return nil, m.getError
 	}
@@ -90,7 +94,7 @@ func (m *MockStore) Get(keyword string) ([]model.Prompt, error) {
 	return matches, nil
 }
 
-func (m *MockStore) GetContent(gistID string) (string, error) {
+func (m *MockStore) GetContent(ctx context.Context, gistID string) (string, error) {
 	if m.getContentError != nil {
 		return "", m.getContentError
 	}
@@ -101,15 +105,15 @@ func (m *MockStore) GetContent(gistID string) (string, error) {
 	return "metadata:\n  name: Test Prompt\n  author: Test Author\nprompt: Test content", nil
 }
 
-func (m *MockStore) CreatePublicGist(prompt model.Prompt) (string, error) {
+func (m *MockStore) CreatePublicGist(ctx context.Context, prompt model.Prompt) (string, error) {
 	return "https://gist.github.com/test/123", nil
 }
 
-func (m *MockStore) UpdateGist(gistURL string, prompt model.Prompt) error {
+func (m *MockStore) UpdateGist(ctx context.Context, gistURL string, prompt model.Prompt) error {
 	return nil
 }
 
-func (m *MockStore) GetGistInfo(gistURL string) (*infra.GistInfo, error) {
+func (m *MockStore) GetGistInfo(ctx context.Context, gistURL string) (*infra.GistInfo, error) {
 	return &infra.GistInfo{
 		ID:        "123",
 		URL:       gistURL,
@@ -118,19 +122,19 @@ func (m *MockStore) GetGistInfo(gistURL string) (*infra.GistInfo, error) {
 	}, nil
 }
 
-func (m *MockStore) AddExport(prompt model.IndexedPrompt) error {
+func (m *MockStore) AddExport(ctx context.Context, prompt model.IndexedPrompt) error {
 	return nil
 }
 
-func (m *MockStore) UpdateExport(prompt model.IndexedPrompt) error {
+func (m *MockStore) UpdateExport(ctx context.Context, prompt model.IndexedPrompt) error {
 	return nil
 }
 
-func (m *MockStore) GetExports() ([]model.IndexedPrompt, error) {
+func (m *MockStore) GetExports(ctx context.Context) ([]model.IndexedPrompt, error) {
 	return []model.IndexedPrompt{}, nil
 }
 
-func (m *MockStore) FindExistingPromptByURL(gistURL string) (*model.Prompt, error) {
+func (m *MockStore) FindExistingPromptByURL(ctx context.Context, gistURL string) (*model.Prompt, error) {
 	return nil, nil
 }
 
@@ -314,7 +318,7 @@ This is test prompt content`,
 			service := NewPromptService(tc.mockStore, tc.mockValidator)
 
 			// Test AddFromFile
-			prompt, err := service.AddFromFile(tc.filePath)
+			prompt, err := service.AddFromFile(context.Background(), tc.filePath)
 
 			// Check error expectations
 			if tc.expectError {
@@ -512,7 +516,7 @@ Missing closing bracket and other syntax errors.`
 			mockStore := &MockStore{}
 			service := NewPromptService(mockStore, tc.mockValidator)
 
-			_, err := service.AddFromFile(filePath)
+			_, err := service.AddFromFile(context.Background(), filePath)
 
 			if tc.expectError && err == nil {
 				t.Errorf("Expected error but got none")
@@ -605,7 +609,7 @@ Content with special characters: àáâãäå æçèéêë ìíîï ñòóôõö
 			mockStore := &MockStore{}
 			service := NewPromptService(mockStore, tc.validator)
 
-			_, err = service.AddFromFile(testFile)
+			_, err = service.AddFromFile(context.Background(), testFile)
 
 			if tc.expectError && err == nil {
 				t.Errorf("Expected error but got none")
@@ -744,7 +748,7 @@ func TestPromptService_DeleteByKeyword(t *testing.T) {
 			mockValidator := &MockYAMLValidator{}
 			service := NewPromptService(tc.mockStore, mockValidator)
 
-			err := service.DeleteByKeyword(tc.keyword)
+			err := service.DeleteByKeyword(context.Background(), tc.keyword)
 
 			if tc.expectError {
 				if err == nil {
@@ -870,7 +874,7 @@ func TestPromptService_DeleteByURL(t *testing.T) {
 			mockValidator := &MockYAMLValidator{}
 			service := NewPromptService(tc.mockStore, mockValidator)
 
-			err := service.DeleteByURL(tc.gistURL)
+			err := service.DeleteByURL(context.Background(), tc.gistURL)
 
 			if tc.expectError {
 				if err == nil {
@@ -952,7 +956,7 @@ func TestPromptService_ListPrompts(t *testing.T) {
 			mockValidator := &MockYAMLValidator{}
 			service := NewPromptService(tc.mockStore, mockValidator)
 
-			prompts, err := service.ListPrompts()
+			prompts, err := service.ListPrompts(context.Background())
 
 			if tc.expectError {
 				if err == nil {
@@ -1080,7 +1084,7 @@ func TestPromptService_FilterPrompts(t *testing.T) {
 			mockValidator := &MockYAMLValidator{}
 			service := NewPromptService(tc.mockStore, mockValidator)
 
-			prompts, err := service.FilterPrompts(tc.keyword)
+			prompts, err := service.FilterPrompts(context.Background(), tc.keyword)
 
 			if tc.expectError {
 				if err == nil {
@@ -1159,7 +1163,7 @@ func TestPromptService_DeleteByKeyword_EdgeCases(t *testing.T) {
 			mockValidator := &MockYAMLValidator{}
 			service := NewPromptService(tc.mockStore, mockValidator)
 
-			err := service.DeleteByKeyword(tc.keyword)
+			err := service.DeleteByKeyword(context.Background(), tc.keyword)
 
 			if tc.expectError && err == nil {
 				t.Errorf("Expected error but got none")
@@ -1241,7 +1245,7 @@ func TestPromptService_DeleteByURL_EdgeCases(t *testing.T) {
 			mockValidator := &MockYAMLValidator{}
 			service := NewPromptService(tc.mockStore, mockValidator)
 
-			err := service.DeleteByURL(tc.gistURL)
+			err := service.DeleteByURL(context.Background(), tc.gistURL)
 
 			if tc.expectError && err == nil {
 				t.Errorf("Expected error but got none")
@@ -1353,7 +1357,7 @@ func TestPromptService_GetPromptByURL(t *testing.T) {
 			mockValidator := &MockYAMLValidator{}
 			service := NewPromptService(tc.mockStore, mockValidator)
 
-			prompt, err := service.GetPromptByURL(tc.gistURL)
+			prompt, err := service.GetPromptByURL(context.Background(), tc.gistURL)
 
 			if tc.expectError {
 				if err == nil {
@@ -1484,7 +1488,7 @@ func TestPromptService_GetPromptContent(t *testing.T) {
 			mockValidator := &MockYAMLValidator{}
 			service := NewPromptService(tc.mockStore, mockValidator)
 
-			content, err := service.GetPromptContent(tc.prompt)
+			content, err := service.GetPromptContent(context.Background(), tc.prompt)
 
 			if tc.expectError {
 				if err == nil {
@@ -1594,7 +1598,7 @@ func TestPromptService_GetPromptByURL_AdditionalEdgeCases(t *testing.T) {
 			mockValidator := &MockYAMLValidator{}
 			service := NewPromptService(tc.mockStore, mockValidator)
 
-			_, err := service.GetPromptByURL(tc.gistURL)
+			_, err := service.GetPromptByURL(context.Background(), tc.gistURL)
 
 			if tc.expectError && err == nil {
 				t.Errorf("Expected error but got none")
@@ -1665,7 +1669,7 @@ func TestPromptService_GetPromptContent_AdditionalEdgeCases(t *testing.T) {
 			mockValidator := &MockYAMLValidator{}
 			service := NewPromptService(tc.mockStore, mockValidator)
 
-			content, err := service.GetPromptContent(tc.prompt)
+			content, err := service.GetPromptContent(context.Background(), tc.prompt)
 
 			if tc.expectError {
 				if err == nil {
@@ -1681,3 +1685,688 @@ func TestPromptService_GetPromptContent_AdditionalEdgeCases(t *testing.T) {
 		})
 	}
 }
+
+// historyMockStore is a MockStore that also serves a revision history
+type historyMockStore struct {
+	MockStore
+	revisions []model.Revision
+	contents  map[string]string
+	exports   []model.IndexedPrompt
+	updated   []model.Prompt
+}
+
+func (m *historyMockStore) Update(ctx context.Context, prompt model.Prompt) error {
+	m.updated = append(m.updated, prompt)
+	return nil
+}
+
+func (m *historyMockStore) GetExports(ctx context.Context) ([]model.IndexedPrompt, error) {
+	return m.exports, nil
+}
+
+func (m *historyMockStore) ListRevisions(ctx context.Context, gistID string) ([]model.Revision, error) {
+	return m.revisions, nil
+}
+
+func (m *historyMockStore) GetRevisionContent(ctx context.Context, gistID, sha string) (string, error) {
+	content, ok := m.contents[sha]
+	if !ok {
+		return "", fmt.Errorf("revision %s not found", sha)
+	}
+	return content, nil
+}
+
+func TestPromptService_History(t *testing.T) {
+	store := &historyMockStore{
+		revisions: []model.Revision{
+			{SHA: "b2c3d4e5f60718293a4b5c6d7e8f9a0b1c2d3e4f"},
+			{SHA: "b2c3ffff60718293a4b5c6d7e8f9a0b1c2d3e4f5"},
+			{SHA: "a1b2c3d4e5f60718293a4b5c6d7e8f9a0b1c2d3e"},
+		},
+		contents: map[string]string{
+			"b2c3d4e5f60718293a4b5c6d7e8f9a0b1c2d3e4f": "name: Review\nauthor: alice\nversion: \"1.1\"\n---\nReview {{code}} carefully",
+			"a1b2c3d4e5f60718293a4b5c6d7e8f9a0b1c2d3e": "name: Review\nauthor: alice\nversion: \"1.0\"\n---\nReview {{code}}",
+		},
+	}
+	svc := NewPromptService(store, validator.NewYAMLValidator())
+	prompt := &model.Prompt{ID: "gist", Name: "Review"}
+
+	revisions, err := svc.GetPromptHistory(context.Background(), prompt)
+	if err != nil || len(revisions) != 3 {
+		t.Fatalf("GetPromptHistory failed: %v, %v", revisions, err)
+	}
+	// A revision whose content can't be read is still listed, without a version
+	if revisions[0].Version != "1.1" || revisions[1].Version != "" || revisions[2].Version != "1.0" {
+		t.Errorf("Expected the versions of the revisions, got %+v", revisions)
+	}
+
+	content, err := svc.GetPromptRevision(context.Background(), prompt, "a1b2c3d")
+	if err != nil || !strings.Contains(content, `version: "1.0"`) {
+		t.Errorf("Expected an abbreviated SHA to be resolved, got %q, %v", content, err)
+	}
+	if _, err := svc.GetPromptRevision(context.Background(), prompt, "b2c3"); err != errors.ErrAmbiguousRevision {
+		t.Errorf("Expected ErrAmbiguousRevision, got %v", err)
+	}
+	if _, err := svc.GetPromptRevision(context.Background(), prompt, "ffff"); err != errors.ErrRevisionNotFound {
+		t.Errorf("Expected ErrRevisionNotFound, got %v", err)
+	}
+
+	// Stores without a history say so
+	plain := NewPromptService(&MockStore{}, validator.NewYAMLValidator())
+	if _, err := plain.GetPromptHistory(context.Background(), prompt); err != errors.ErrHistoryUnsupported {
+		t.Errorf("Expected ErrHistoryUnsupported, got %v", err)
+	}
+}
+
+// slowHistoryMockStore serves revision contents slowly and records how many are read at once
+type slowHistoryMockStore struct {
+	historyMockStore
+	mu        sync.Mutex
+	active    int
+	maxActive int
+}
+
+func (m *slowHistoryMockStore) GetRevisionContent(ctx context.Context, gistID, sha string) (string, error) {
+	m.mu.Lock()
+	m.active++
+	if m.active > m.maxActive {
+		m.maxActive = m.active
+	}
+	m.mu.Unlock()
+
+	time.Sleep(20 * time.Millisecond)
+
+	m.mu.Lock()
+	m.active--
+	m.mu.Unlock()
+	return m.historyMockStore.GetRevisionContent(ctx, gistID, sha)
+}
+
+func TestPromptService_HistoryFetchesConcurrently(t *testing.T) {
+	store := &slowHistoryMockStore{historyMockStore: historyMockStore{contents: map[string]string{}}}
+	for i := 0; i < 8; i++ {
+		sha := fmt.Sprintf("%040d", i)
+		store.revisions = append(store.revisions, model.Revision{SHA: sha})
+		store.contents[sha] = fmt.Sprintf("name: Review\nauthor: alice\nversion: \"1.%d\"\n---\nReview {code}", i)
+	}
+	svc := NewPromptService(store, validator.NewYAMLValidator())
+
+	revisions, err := svc.GetPromptHistory(context.Background(), &model.Prompt{ID: "gist", Name: "Review"})
+	if err != nil || len(revisions) != 8 {
+		t.Fatalf("GetPromptHistory failed: %v, %v", revisions, err)
+	}
+	for i, revision := range revisions {
+		if want := fmt.Sprintf("1.%d", i); revision.SHA != store.revisions[i].SHA || revision.Version != want {
+			t.Errorf("Expected revision %d to keep its order with version %s, got %+v", i, want, revision)
+		}
+	}
+	if store.maxActive < 2 || store.maxActive > infra.DefaultFetchWorkers {
+		t.Errorf("Expected revisions to be read on the worker pool, got %d at once", store.maxActive)
+	}
+}
+
+func TestPromptService_Diff(t *testing.T) {
+	const (
+		v1 = "name: Review\nauthor: alice\nversion: \"1.0\"\n---\nReview {{code}}\nBe brief.\n"
+		v2 = "name: Review\nauthor: alice\nversion: \"1.1\"\n---\nReview {{code}}\nBe thorough.\n"
+	)
+	privateURL := "https://gist.github.com/alice/private"
+	store := &historyMockStore{
+		revisions: []model.Revision{{SHA: "bbbbbbbbbb"}, {SHA: "aaaaaaaaaa"}},
+		contents:  map[string]string{"aaaaaaaaaa": v1, "bbbbbbbbbb": v2},
+		exports:   []model.IndexedPrompt{{GistURL: "https://gist.github.com/alice/public", Parent: &privateURL}},
+	}
+	store.getContentFunc = func(gistID string) (string, error) {
+		if gistID == "public" {
+			return v1, nil
+		}
+		return v2, nil
+	}
+	svc := NewPromptService(store, validator.NewYAMLValidator())
+	prompt := &model.Prompt{ID: "private", Name: "Review", GistURL: privateURL}
+
+	assertVersionBump := func(t *testing.T, d *PromptDiff) {
+		t.Helper()
+		if len(d.Metadata) != 1 || d.Metadata[0] != (MetadataChange{Field: "version", Old: "1.0", New: "1.1"}) {
+			t.Errorf("Expected only the version to change in the metadata, got %+v", d.Metadata)
+		}
+		if len(d.Body) != 1 || d.Body[0].Header() != "@@ -1,2 +1,2 @@" {
+			t.Fatalf("Expected one body hunk, got %+v", d.Body)
+		}
+	}
+
+	t.Run("latest revision against the one before", func(t *testing.T) {
+		d, err := svc.DiffRevisions(context.Background(), prompt, "", "")
+		if err != nil {
+			t.Fatalf("DiffRevisions failed: %v", err)
+		}
+		if d.From != "aaaaaaa" || d.To != "bbbbbbb" {
+			t.Errorf("Expected the revisions as labels, got %s..%s", d.From, d.To)
+		}
+		assertVersionBump(t, d)
+	})
+
+	t.Run("same revision", func(t *testing.T) {
+		d, err := svc.DiffRevisions(context.Background(), prompt, "bbb", "bbb")
+		if err != nil || d.HasChanges() {
+			t.Errorf("Expected no changes, got %+v, %v", d, err)
+		}
+	})
+
+	t.Run("no previous revision", func(t *testing.T) {
+		if _, err := svc.DiffRevisions(context.Background(), prompt, "", "aaa"); err != errors.ErrNoPreviousRevision {
+			t.Errorf("Expected ErrNoPreviousRevision, got %v", err)
+		}
+	})
+
+	t.Run("local file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "review.yaml")
+		if err := os.WriteFile(path, []byte(v2), 0600); err != nil {
+			t.Fatalf("WriteFile failed: %v", err)
+		}
+		d, err := svc.DiffWithFile(context.Background(), prompt, path)
+		if err != nil || d.HasChanges() || d.To != path {
+			t.Errorf("Expected the file to match the vault copy, got %+v, %v", d, err)
+		}
+	})
+
+	t.Run("exported copy", func(t *testing.T) {
+		d, err := svc.DiffWithExport(context.Background(), prompt)
+		if err != nil {
+			t.Fatalf("DiffWithExport failed: %v", err)
+		}
+		if d.From != privateURL || d.To != "https://gist.github.com/alice/public" {
+			t.Errorf("Expected the gist URLs as labels, got %s..%s", d.From, d.To)
+		}
+		// The public copy is older, so the diff runs backwards
+		if len(d.Metadata) != 1 || d.Metadata[0].Old != "1.1" || d.Metadata[0].New != "1.0" {
+			t.Errorf("Expected the version to differ, got %+v", d.Metadata)
+		}
+
+		unshared := &model.Prompt{ID: "other", GistURL: "https://gist.github.com/alice/other"}
+		if _, err := svc.DiffWithExport(context.Background(), unshared); err != errors.ErrExportNotFound {
+			t.Errorf("Expected ErrExportNotFound, got %v", err)
+		}
+	})
+}
+
+func TestPromptService_Rollback(t *testing.T) {
+	const (
+		v1 = "name: Review\nauthor: alice\ndescription: Code review\nversion: \"1.0\"\n---\nReview {{code}}\n"
+		v2 = "name: Review\nauthor: alice\nversion: \"1.1\"\n---\nReview {{code}} thoroughly\n"
+	)
+	privateURL := "https://gist.github.com/alice/private"
+	store := &historyMockStore{
+		revisions: []model.Revision{{SHA: "cccccccccc"}, {SHA: "bbbbbbbbbb"}, {SHA: "aaaaaaaaaa"}},
+		contents:  map[string]string{"aaaaaaaaaa": v1, "bbbbbbbbbb": v2, "cccccccccc": v2},
+	}
+	svc := NewPromptService(store, validator.NewYAMLValidator())
+	prompt := &model.Prompt{ID: "private", Name: "Review", Author: "alice", GistURL: privateURL}
+
+	t.Run("preview", func(t *testing.T) {
+		d, err := svc.PreviewRollback(context.Background(), prompt, "aaa")
+		if err != nil {
+			t.Fatalf("PreviewRollback failed: %v", err)
+		}
+		if d.From != "ccccccc" || d.To != "aaaaaaa" {
+			t.Errorf("Expected the latest revision against the target, got %s..%s", d.From, d.To)
+		}
+		if len(d.Metadata) != 2 || len(d.Body) != 1 {
+			t.Errorf("Expected the description, version and body to change, got %+v", d)
+		}
+	})
+
+	t.Run("restores the revision", func(t *testing.T) {
+		store.updated = nil
+		result, err := svc.RollbackPrompt(context.Background(), prompt, "aaa")
+		if err != nil {
+			t.Fatalf("RollbackPrompt failed: %v", err)
+		}
+		if len(store.updated) != 1 {
+			t.Fatalf("Expected one store update, got %d", len(store.updated))
+		}
+		restored := store.updated[0]
+		if restored.ID != "private" || restored.Content != v1 || restored.Version != "1.0" || restored.Description != "Code review" {
+			t.Errorf("Expected the content and metadata of the revision, got %+v", restored)
+		}
+		if result.Revision.SHA != "aaaaaaaaaa" || result.Prompt.Content != v1 || result.Export != nil {
+			t.Errorf("Unexpected rollback result %+v", result)
+		}
+	})
+
+	t.Run("nothing to roll back", func(t *testing.T) {
+		store.updated = nil
+		// The previous revision has the same content as the latest one
+		if _, err := svc.RollbackPrompt(context.Background(), prompt, "bbb"); err != errors.ErrNothingToRollback {
+			t.Errorf("Expected ErrNothingToRollback, got %v", err)
+		}
+		if _, err := svc.RollbackPrompt(context.Background(), prompt, ""); err != errors.ErrRevisionNotFound {
+			t.Errorf("Expected ErrRevisionNotFound, got %v", err)
+		}
+		if len(store.updated) != 0 {
+			t.Errorf("Expected no store update, got %+v", store.updated)
+		}
+	})
+
+	t.Run("reports the public copy", func(t *testing.T) {
+		store.exports = []model.IndexedPrompt{{GistURL: "https://gist.github.com/alice/public", Parent: &privateURL}}
+		defer func() { store.exports = nil }()
+
+		result, err := svc.RollbackPrompt(context.Background(), prompt, "aaa")
+		if err != nil {
+			t.Fatalf("RollbackPrompt failed: %v", err)
+		}
+		if result.Export == nil || result.Export.GistURL != "https://gist.github.com/alice/public" {
+			t.Errorf("Expected the export of the prompt, got %+v", result.Export)
+		}
+	})
+
+	t.Run("name taken", func(t *testing.T) {
+		// The prompt was renamed since the revision, and another prompt took its old name
+		renamed := &model.Prompt{ID: "private", Name: "Code Review", Author: "alice", GistURL: privateURL}
+		store.prompts = []model.Prompt{{ID: "private", Name: "Code Review", Author: "alice"}, {ID: "other", Name: "Review", Author: "alice"}}
+		defer func() { store.prompts = nil }()
+		store.updated = nil
+
+		if _, err := svc.RollbackPrompt(context.Background(), renamed, "aaa"); err != errors.ErrPromptNameTaken {
+			t.Errorf("Expected ErrPromptNameTaken, got %v", err)
+		}
+		if len(store.updated) != 0 {
+			t.Errorf("Expected no store update, got %+v", store.updated)
+		}
+	})
+}
+
+func TestPromptService_UpdatePromptContent(t *testing.T) {
+	store := &historyMockStore{}
+	store.prompts = []model.Prompt{
+		{ID: "review", Name: "Review", Author: "alice"},
+		{ID: "summary", Name: "Summary", Author: "alice"},
+	}
+	svc := NewPromptService(store, validator.NewYAMLValidator())
+	prompt := &model.Prompt{ID: "review", Name: "Review", Author: "alice", GistURL: "https://gist.github.com/alice/review"}
+
+	t.Run("valid content", func(t *testing.T) {
+		store.updated = nil
+		content := "name: Review\nauthor: alice\ntags: [go]\nversion: \"1.1\"\n---\nReview {{code}}\n"
+		updated, err := svc.UpdatePromptContent(context.Background(), prompt, content)
+		if err != nil {
+			t.Fatalf("UpdatePromptContent failed: %v", err)
+		}
+		if len(store.updated) != 1 || store.updated[0].Content != content || store.updated[0].ID != "review" {
+			t.Fatalf("Expected the content to be stored, got %+v", store.updated)
+		}
+		if updated.Version != "1.1" || strings.Join(updated.Tags, ",") != "go" || updated.GistURL != prompt.GistURL {
+			t.Errorf("Expected the metadata of the content, got %+v", updated)
+		}
+	})
+
+	t.Run("rename", func(t *testing.T) {
+		store.updated = nil
+		updated, err := svc.UpdatePromptContent(context.Background(), prompt, "name: Code Review\nauthor: alice\n---\nReview {{code}}\n")
+		if err != nil || updated.Name != "Code Review" || len(store.updated) != 1 {
+			t.Errorf("Expected the prompt to be renamed, got %+v, %v", updated, err)
+		}
+	})
+
+	t.Run("keeps the format", func(t *testing.T) {
+		store.updated = nil
+		markdown := *prompt
+		markdown.Format = model.FormatMarkdown
+		if _, err := svc.UpdatePromptContent(context.Background(), &markdown, "---\nname: Review\nauthor: alice\n---\n# Review\n"); err != nil || len(store.updated) != 1 || store.updated[0].Format != model.FormatMarkdown {
+			t.Errorf("Expected the prompt to stay Markdown, got %+v, %v", store.updated, err)
+		}
+	})
+
+	invalid := []struct {
+		name    string
+		content string
+		want    error
+	}{
+		{"no front matter", "Review {{code}}\n", nil},
+		{"no name", "author: alice\n---\nReview {{code}}\n", nil},
+		{"name taken", "name: Summary\nauthor: alice\n---\nReview {{code}}\n", errors.ErrPromptNameTaken},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			store.updated = nil
+			_, err := svc.UpdatePromptContent(context.Background(), prompt, tt.content)
+			if errors.TypeOf(err, errors.ErrUnknown) != errors.ErrValidation {
+				t.Errorf("Expected a validation error, got %v", err)
+			}
+			if tt.want != nil && err != tt.want {
+				t.Errorf("Expected %v, got %v", tt.want, err)
+			}
+			if len(store.updated) != 0 {
+				t.Errorf("Expected invalid content not to be stored, got %+v", store.updated)
+			}
+		})
+	}
+}
+
+func TestPromptService_AddFromContent(t *testing.T) {
+	store := &MockStore{}
+	svc := NewPromptService(store, validator.NewYAMLValidator())
+
+	content := "name: Review\nauthor: alice\ntags: [go]\n---\nReview {{code}}\n"
+	prompt, err := svc.AddFromContent(context.Background(), content)
+	if err != nil {
+		t.Fatalf("AddFromContent failed: %v", err)
+	}
+	if prompt.Name != "Review" || prompt.Content != content {
+		t.Errorf("Expected the prompt of the content, got %+v", prompt)
+	}
+	if len(store.prompts) != 1 || store.prompts[0].Content != content {
+		t.Errorf("Expected the content to be stored, got %+v", store.prompts)
+	}
+
+	// 支持批量写入的 store 以一个批次添加，返回 gist 的地址，不需要再列出提示词
+	vault := infra.NewFileSystemStore(filepath.Join(t.TempDir(), "vault"))
+	added, err := NewPromptService(vault, validator.NewYAMLValidator()).AddFromContent(context.Background(), content)
+	listed, _ := vault.List(context.Background())
+	if err != nil || len(listed) != 1 || added.ID == "" || added.ID != listed[0].ID || added.GistURL != listed[0].GistURL {
+		t.Errorf("Expected the added prompt to carry its gist %+v, got %+v, %v", listed, added, err)
+	}
+
+	// ValidatePromptContent 只验证，不写入 store
+	if _, err := svc.ValidatePromptContent(content); err != nil {
+		t.Errorf("Expected the content to be valid, got %v", err)
+	}
+	if _, err := svc.ValidatePromptContent("author: alice\n---\nReview {{code}}\n"); errors.TypeOf(err, errors.ErrUnknown) != errors.ErrValidation {
+		t.Errorf("Expected a validation error, got %v", err)
+	}
+	if len(store.prompts) != 1 {
+		t.Errorf("Expected validation not to store anything, got %+v", store.prompts)
+	}
+}
+
+func TestPromptService_AddFromFile_Markdown(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.md")
+	content := "\n---\nname: Notes\nauthor: alice\ntags: [docs]\n---\n# Notes\n\n---\n\nTake notes on {{topic}}\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	store := &MockStore{}
+	prompt, err := NewPromptService(store, validator.NewYAMLValidator()).AddFromFile(context.Background(), path)
+	if err != nil {
+		t.Fatalf("AddFromFile failed: %v", err)
+	}
+	if prompt.Name != "Notes" || strings.Join(prompt.Tags, ",") != "docs" {
+		t.Errorf("Expected the front matter to be parsed, got %+v", prompt)
+	}
+	if len(store.prompts) != 1 || store.prompts[0].Format != model.FormatMarkdown || store.prompts[0].Content != content {
+		t.Errorf("Expected the file to be stored as Markdown, got %+v", store.prompts)
+	}
+}
+
+// batchMockStore records the batches of prompts added to it
+type batchMockStore struct {
+	MockStore
+	batches [][]model.Prompt
+}
+
+func (m *batchMockStore) AddBatch(ctx context.Context, prompts []model.Prompt) ([]model.Prompt, error) {
+	m.batches = append(m.batches, prompts)
+	return prompts, nil
+}
+
+func TestPromptService_PlanAddFromFiles(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	review := write("prompts/review.yaml", "name: Review\nauthor: alice\n---\nReview {{code}}\n")
+	summary := write("prompts/summary.yml", "name: Summary\nauthor: alice\n---\nSummarize\n")
+	write("prompts/README.md", "not a prompt")
+	nested := write("prompts/team/translate.yaml", "name: Translate\nauthor: bob\n---\nTranslate\n")
+	write("prompts/.drafts/draft.yaml", "name: Draft\nauthor: bob\n---\nDraft\n")
+	invalid := write("invalid.yaml", "author: alice\n---\nNo name\n")
+	duplicate := write("copy/review.yaml", "name: Review\nauthor: alice\n---\nAnother review\n")
+
+	store := &batchMockStore{}
+	store.prompts = []model.Prompt{{ID: "review", Name: "Review", Author: "alice"}}
+	svc := NewPromptService(store, validator.NewYAMLValidator())
+	promptsDir := filepath.Join(dir, "prompts")
+
+	t.Run("directory", func(t *testing.T) {
+		plan, err := svc.PlanAddFromFiles(context.Background(), []string{promptsDir}, false)
+		if err != nil || len(plan.Failures) != 0 {
+			t.Fatalf("PlanAddFromFiles failed: %v, %+v", err, plan.Failures)
+		}
+		if len(plan.Adds) != 2 || plan.Adds[0].Path != review || plan.Adds[1].Path != summary {
+			t.Fatalf("Expected the prompt files of the directory, got %+v", plan.Adds)
+		}
+		if plan.Adds[0].Action != AddUpdate || plan.Adds[0].Existing.ID != "review" || plan.Adds[1].Action != AddCreate {
+			t.Errorf("Expected review to be updated and summary created, got %+v", plan.Adds)
+		}
+	})
+
+	t.Run("recursive skips hidden directories", func(t *testing.T) {
+		plan, err := svc.PlanAddFromFiles(context.Background(), []string{promptsDir}, true)
+		if err != nil || len(plan.Adds) != 3 || plan.Adds[2].Path != nested {
+			t.Errorf("Expected the nested prompt too, got %+v, %v", plan.Adds, err)
+		}
+	})
+
+	t.Run("reports all failures", func(t *testing.T) {
+		paths := []string{filepath.Join(promptsDir, "*.yaml"), invalid, duplicate, filepath.Join(dir, "missing.yaml"), filepath.Join(dir, "*.json")}
+		plan, err := svc.PlanAddFromFiles(context.Background(), paths, false)
+		if err != nil {
+			t.Fatalf("PlanAddFromFiles failed: %v", err)
+		}
+		if len(plan.Adds) != 1 || plan.Adds[0].Path != review {
+			t.Errorf("Expected only the glob match to be planned, got %+v", plan.Adds)
+		}
+
+		failed := map[string]error{}
+		for _, failure := range plan.Failures {
+			failed[filepath.Base(failure.Path)] = failure.Err
+		}
+		if len(failed) != 4 || failed["invalid.yaml"] == nil || failed["missing.yaml"] == nil || failed["*.json"] != errors.ErrNoPromptFiles {
+			t.Errorf("Expected every failure to be reported, got %v", failed)
+		}
+		if err := failed["review.yaml"]; err == nil || !strings.Contains(err.Error(), review) {
+			t.Errorf("Expected the duplicate to name the first file, got %v", err)
+		}
+
+		if _, err := svc.ApplyAddPlan(context.Background(), plan); err != errors.ErrAddPlanHasFailures {
+			t.Errorf("Expected a plan with failures not to be applied, got %v", err)
+		}
+		if len(store.batches) != 0 {
+			t.Errorf("Expected nothing to be stored, got %v", store.batches)
+		}
+	})
+
+	t.Run("apply", func(t *testing.T) {
+		plan, _ := svc.PlanAddFromFiles(context.Background(), []string{promptsDir}, true)
+		stored, err := svc.ApplyAddPlan(context.Background(), plan)
+		if err != nil || len(stored) != 3 {
+			t.Fatalf("ApplyAddPlan failed: %v, %v", stored, err)
+		}
+		if len(store.batches) != 1 || len(store.batches[0]) != 3 {
+			t.Errorf("Expected a single batch, got %v", store.batches)
+		}
+
+		// Stores without batches get one Add per prompt
+		plain := &MockStore{}
+		if _, err := NewPromptService(plain, validator.NewYAMLValidator()).ApplyAddPlan(context.Background(), plan); err != nil || len(plain.prompts) != 3 {
+			t.Errorf("Expected the prompts to be added one by one, got %v, %v", plain.prompts, err)
+		}
+	})
+
+	t.Run("markdown", func(t *testing.T) {
+		notes := write("docs/notes.md", "\ufeff---\nname: Notes\nauthor: alice\n---\n# Notes\n")
+		write("docs/README.md", "# Docs\n\n---\n")
+		plan, err := svc.PlanAddFromFiles(context.Background(), []string{filepath.Join(dir, "docs")}, false)
+		if err != nil || len(plan.Failures) != 0 || len(plan.Adds) != 1 || plan.Adds[0].Path != notes {
+			t.Fatalf("Expected only the Markdown file with front matter, got %+v, %v", plan, err)
+		}
+		if format := plan.Adds[0].Prompt.Format; format != model.FormatMarkdown {
+			t.Errorf("Expected the prompt to be stored as Markdown, got %q", format)
+		}
+	})
+}
+
+func TestPromptService_ExportRestoreArchive(t *testing.T) {
+	ctx := context.Background()
+	sourceStore := infra.NewFileSystemStore(filepath.Join(t.TempDir(), "source"))
+	source := NewPromptService(sourceStore, validator.NewYAMLValidator())
+
+	review, err := source.AddFromContent(ctx, "name: Review\nauthor: alice\ntags: [code]\n---\nReview {code}\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := source.AddFromContent(ctx, "name: Summary\nauthor: bob\n---\nSummarize {text}\n"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := source.SharePrompt(ctx, review); err != nil {
+		t.Fatal(err)
+	}
+
+	var reads int
+	exported, err := source.ExportArchive(ctx, func(done, total int, prompt model.Prompt, err error) {
+		if err != nil || total != 3 {
+			t.Errorf("Unexpected progress %d/%d %s: %v", done, total, prompt.Name, err)
+		}
+		reads++
+	})
+	if err != nil {
+		t.Fatalf("ExportArchive failed: %v", err)
+	}
+	if reads != 3 || len(exported.Index.Prompts) != 2 || len(exported.Index.Exports) != 1 {
+		t.Fatalf("Expected 2 prompts and 1 export, got %+v", exported.Index)
+	}
+	if entry := exported.Index.Prompts[0]; entry.ContentHash != model.ContentHash(exported.Contents[entry.GistURL]) {
+		t.Errorf("Expected the content hash of the archived content, got %+v", entry)
+	}
+	raw, err := sourceStore.(infra.RawIndexProvider).GetRawIndexContent(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if index, err := infra.DecodeIndex([]byte(raw)); err != nil || !reflect.DeepEqual(exported.Index, *index) {
+		t.Errorf("Expected the index of the store to be archived as it is, got %+v, %v", exported.Index, err)
+	}
+
+	// 目标 vault 中已经有同名同作者的 Summary
+	targetStore := infra.NewFileSystemStore(filepath.Join(t.TempDir(), "target"))
+	target := NewPromptService(targetStore, validator.NewYAMLValidator())
+	summary, err := target.AddFromContent(ctx, "name: Summary\nauthor: bob\n---\nSummarize {text} briefly\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := target.RestoreArchive(ctx, exported)
+	if err != nil {
+		t.Fatalf("RestoreArchive failed: %v", err)
+	}
+	if report.Count(RestoreCreated) != 2 || report.Count(RestoreSkipped) != 1 {
+		t.Errorf("Expected Review and its export to be created and Summary skipped, got %+v", report)
+	}
+	if skipped := report.Prompts[1]; skipped.Action != RestoreSkipped || skipped.URL != summary.GistURL {
+		t.Errorf("Expected Summary to keep its URL, got %+v", skipped)
+	}
+
+	restored := report.Prompts[0].URL
+	if restored == "" || restored == review.GistURL {
+		t.Fatalf("Expected Review to get a new URL, got %q", restored)
+	}
+	content, err := targetStore.GetContent(ctx, filepath.Base(restored))
+	if err != nil || content != exported.Contents[review.GistURL] {
+		t.Errorf("Expected the archived content, got %q, %v", content, err)
+	}
+
+	exports, err := targetStore.GetExports(ctx)
+	if err != nil || len(exports) != 1 {
+		t.Fatalf("Expected one export, got %+v, %v", exports, err)
+	}
+	if exports[0].Parent == nil || *exports[0].Parent != restored || exports[0].GistURL != report.Exports[0].URL {
+		t.Errorf("Expected the export to link to the restored prompt, got %+v", exports[0])
+	}
+
+	// 再次恢复时全部跳过
+	report, err = target.RestoreArchive(ctx, exported)
+	if err != nil || report.Count(RestoreCreated) != 0 || report.Count(RestoreSkipped) != 3 {
+		t.Errorf("Expected everything to be skipped, got %+v, %v", report, err)
+	}
+	if exports, _ := targetStore.GetExports(ctx); len(exports) != 1 {
+		t.Errorf("Expected no new export, got %+v", exports)
+	}
+}
+
+// batchFileSystemStore adds the prompts of a batch to a FileSystemStore and counts the batches
+type batchFileSystemStore struct {
+	infra.Store
+	batches int
+}
+
+func (b *batchFileSystemStore) AddBatch(ctx context.Context, prompts []model.Prompt) ([]model.Prompt, error) {
+	b.batches++
+	for _, prompt := range prompts {
+		if err := b.Add(ctx, prompt); err != nil {
+			return nil, err
+		}
+	}
+	listed, err := b.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stored := append([]model.Prompt{}, prompts...)
+	for i := range stored {
+		for _, prompt := range listed {
+			if prompt.Name == stored[i].Name && prompt.Author == stored[i].Author {
+				stored[i].ID, stored[i].GistURL = prompt.ID, prompt.GistURL
+			}
+		}
+	}
+	return stored, nil
+}
+
+func TestPromptService_RestoreArchive_Batch(t *testing.T) {
+	ctx := context.Background()
+	source := NewPromptService(infra.NewFileSystemStore(filepath.Join(t.TempDir(), "source")), validator.NewYAMLValidator())
+	review, err := source.AddFromContent(ctx, "name: Review\nauthor: alice\n---\nReview {code}\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := source.AddFromContent(ctx, "name: Summary\nauthor: bob\n---\nSummarize {text}\n"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := source.SharePrompt(ctx, review); err != nil {
+		t.Fatal(err)
+	}
+	exported, err := source.ExportArchive(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store := &batchFileSystemStore{Store: infra.NewFileSystemStore(filepath.Join(t.TempDir(), "target"))}
+	report, err := NewPromptService(store, validator.NewYAMLValidator()).RestoreArchive(ctx, exported)
+	if err != nil {
+		t.Fatalf("RestoreArchive failed: %v", err)
+	}
+	if store.batches != 1 || report.Count(RestoreCreated) != 3 {
+		t.Fatalf("Expected both prompts to be added in a single batch, got %d batches, %+v", store.batches, report)
+	}
+
+	prompts, err := store.List(ctx)
+	if err != nil || len(prompts) != 2 {
+		t.Fatalf("Expected 2 prompts, got %+v, %v", prompts, err)
+	}
+	for i, entry := range report.Prompts {
+		if entry.Entry.Name != exported.Index.Prompts[i].Name || entry.URL == "" || entry.URL == entry.Entry.GistURL {
+			t.Errorf("Expected %s to be reported in archive order with its new URL, got %+v", exported.Index.Prompts[i].Name, entry)
+		}
+	}
+	exports, err := store.GetExports(ctx)
+	if err != nil || len(exports) != 1 || exports[0].Parent == nil || *exports[0].Parent != report.Prompts[0].URL {
+		t.Errorf("Expected the export to link to the URL from the batch, got %+v, %v", exports, err)
+	}
+}
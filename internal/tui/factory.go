@@ -242,6 +242,46 @@ func (tui *BubbleTeaTUI) ShowVariableForm(variables []string) (map[string]string
 	return nil, fmt.Errorf("%s: invalid model type", ErrMsgTUIInitFailed)
 }
 
+// ShowPromptForm displays a form for entering the metadata of a new prompt.
+// This method implements the interactive mode of the new command.
+func (tui *BubbleTeaTUI) ShowPromptForm(defaults model.Prompt) (model.Prompt, error) {
+	// Create the prompt form model
+	formModel := NewPromptFormModel(defaults)
+
+	// Configure program options
+	var options []tea.ProgramOption
+	if tui.altScreen {
+		options = append(options, tea.WithAltScreen())
+	}
+	if tui.mouseEnabled {
+		options = append(options, tea.WithMouseCellMotion())
+	}
+
+	// Create and run the bubbletea program
+	program := tea.NewProgram(formModel, options...)
+
+	finalModel, err := program.Run()
+	if err != nil {
+		return model.Prompt{}, fmt.Errorf("%s: %w", ErrMsgTUIRenderFailed, err)
+	}
+
+	// Extract the final state from the model
+	if promptFormModel, ok := finalModel.(PromptFormModel); ok {
+		if promptFormModel.IsCancelled() {
+			return model.Prompt{}, fmt.Errorf(ErrMsgUserCancelled)
+		}
+		if promptFormModel.IsDone() {
+			return promptFormModel.GetPrompt(), nil
+		}
+
+		// Form was not completed (should not happen if not cancelled)
+		return model.Prompt{}, fmt.Errorf(ErrMsgInvalidSelection)
+	}
+
+	// Model type assertion failed
+	return model.Prompt{}, fmt.Errorf("%s: invalid model type", ErrMsgTUIInitFailed)
+}
+
 // ShowError displays an error message to the user using the ErrorModel.
 // This is a helper method for displaying errors in a consistent TUI format.
 func (tui *BubbleTeaTUI) ShowError(err error) error {
@@ -20,6 +20,11 @@ type TUIInterface interface {
 	// Takes a list of variable names and returns a map of variable names to values.
 	// Returns an error if the user cancels the operation or if there's an interface error.
 	ShowVariableForm(variables []string) (map[string]string, error)
+
+	// ShowPromptForm displays a form for entering the metadata of a new prompt,
+	// pre-filled with defaults, and returns the metadata the user entered.
+	// Returns an error if the user cancels the operation or if there's an interface error.
+	ShowPromptForm(defaults model.Prompt) (model.Prompt, error)
 }
 
 // ListMode represents different modes for displaying prompt lists
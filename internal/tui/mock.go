@@ -16,15 +16,18 @@ type MockTUI struct {
 	SelectedPrompt      model.Prompt
 	ConfirmResult       bool
 	VariableFormResult  map[string]string
+	PromptFormResult    *model.Prompt
 	ShowPromptListErr   error
 	ShowConfirmErr      error
 	ShowVariableFormErr error
+	ShowPromptFormErr   error
 
 	// Method call history for verification in tests
 	CallHistory           []MethodCall
 	ShowPromptListArgs    [][]model.Prompt
 	ShowConfirmArgs       []model.Prompt
 	ShowVariableFormArgs  [][]string
+	ShowPromptFormArgs    []model.Prompt
 
 	// Test scenario configurations
 	ShouldSimulateUserCancel      bool
@@ -48,6 +51,7 @@ func NewMockTUI() *MockTUI {
 		ShowPromptListArgs:    make([][]model.Prompt, 0),
 		ShowConfirmArgs:       make([]model.Prompt, 0),
 		ShowVariableFormArgs:  make([][]string, 0),
+		ShowPromptFormArgs:    make([]model.Prompt, 0),
 		VariableFormResult:    make(map[string]string),
 	}
 }
@@ -156,6 +160,32 @@ func (m *MockTUI) ShowVariableForm(variables []string) (map[string]string, error
 	return result, nil
 }
 
+// ShowPromptForm implements TUIInterface.ShowPromptForm for testing
+// Returns PromptFormResult when set, otherwise the defaults unchanged.
+func (m *MockTUI) ShowPromptForm(defaults model.Prompt) (model.Prompt, error) {
+	// Record method call for verification
+	m.CallHistory = append(m.CallHistory, MethodCall{
+		Method: "ShowPromptForm",
+		Args:   defaults,
+	})
+	m.ShowPromptFormArgs = append(m.ShowPromptFormArgs, defaults)
+
+	// Simulate user cancellation scenario
+	if m.ShouldSimulateUserCancel {
+		return model.Prompt{}, errors.New(ErrMsgUserCancelled)
+	}
+
+	// Return pre-configured error if set
+	if m.ShowPromptFormErr != nil {
+		return model.Prompt{}, m.ShowPromptFormErr
+	}
+
+	if m.PromptFormResult != nil {
+		return *m.PromptFormResult, nil
+	}
+	return defaults, nil
+}
+
 // Reset clears all recorded data and resets the mock to initial state
 // This is useful for cleaning up between test cases.
 func (m *MockTUI) Reset() {
@@ -165,10 +195,13 @@ func (m *MockTUI) Reset() {
 	m.ShowPromptListErr = nil
 	m.ShowConfirmErr = nil
 	m.ShowVariableFormErr = nil
+	m.PromptFormResult = nil
+	m.ShowPromptFormErr = nil
 	m.CallHistory = make([]MethodCall, 0)
 	m.ShowPromptListArgs = make([][]model.Prompt, 0)
 	m.ShowConfirmArgs = make([]model.Prompt, 0)
 	m.ShowVariableFormArgs = make([][]string, 0)
+	m.ShowPromptFormArgs = make([]model.Prompt, 0)
 	m.ShouldSimulateUserCancel = false
 	m.ShouldSimulateSelectionErr = false
 	m.ShouldSimulateConfirmErr = false
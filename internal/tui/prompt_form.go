@@ -0,0 +1,216 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/grigri/pv/internal/model"
+)
+
+// promptFormField describes one input of the prompt form
+type promptFormField struct {
+	label    string
+	required bool
+}
+
+// promptFormFields are the metadata fields of a prompt file, in form order
+var promptFormFields = []promptFormField{
+	{label: "name", required: true},
+	{label: "author", required: true},
+	{label: "description"},
+	{label: "tags（逗号分隔）"},
+	{label: "version"},
+}
+
+// PromptFormModel represents the bubbletea model for filling in the metadata of a new prompt
+type PromptFormModel struct {
+	inputs       []textinput.Model
+	currentField int
+	done         bool
+	cancelled    bool
+	err          error
+
+	// Styles
+	focusedStyle   lipgloss.Style
+	blurredStyle   lipgloss.Style
+	helpStyle      lipgloss.Style
+	titleStyle     lipgloss.Style
+	errorStyle     lipgloss.Style
+	containerStyle lipgloss.Style
+}
+
+// NewPromptFormModel creates a new prompt form pre-filled with the metadata of defaults
+func NewPromptFormModel(defaults model.Prompt) PromptFormModel {
+	values := []string{defaults.Name, defaults.Author, defaults.Description, strings.Join(defaults.Tags, ", "), defaults.Version}
+	inputs := make([]textinput.Model, len(promptFormFields))
+
+	for i, field := range promptFormFields {
+		inputs[i] = textinput.New()
+		inputs[i].Placeholder = fmt.Sprintf("输入 %s", field.label)
+		inputs[i].CharLimit = 500
+		inputs[i].Width = VariableInputWidth
+		inputs[i].SetValue(values[i])
+
+		if i == 0 {
+			inputs[i].Focus()
+		}
+	}
+
+	return PromptFormModel{
+		inputs: inputs,
+
+		focusedStyle: lipgloss.NewStyle().
+			Foreground(lipgloss.Color(ColorPrimary)).
+			Bold(true),
+
+		blurredStyle: lipgloss.NewStyle().
+			Foreground(lipgloss.Color(ColorSecondary)),
+
+		helpStyle: lipgloss.NewStyle().
+			Foreground(lipgloss.Color(ColorMuted)).
+			Margin(1, 0),
+
+		titleStyle: lipgloss.NewStyle().
+			Foreground(lipgloss.Color(ColorPrimary)).
+			Bold(true).
+			Margin(0, 0, 1, 0),
+
+		errorStyle: lipgloss.NewStyle().
+			Foreground(lipgloss.Color(ColorError)).
+			Bold(true),
+
+		containerStyle: lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color(ColorBorder)).
+			Padding(1, 2).
+			Width(VariableFormWidth),
+	}
+}
+
+// Init implements the tea.Model interface
+func (m PromptFormModel) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+// Update implements the tea.Model interface
+func (m PromptFormModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if m.done || m.cancelled {
+		return m, tea.Quit
+	}
+
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case KeyCtrlC, KeyEscape:
+			m.cancelled = true
+			return m, tea.Quit
+
+		case KeyEnter:
+			return m.handleEnter()
+
+		case KeyTab, KeyDown:
+			return m.moveField(1)
+
+		case KeyShiftTab, KeyUp:
+			return m.moveField(-1)
+		}
+	}
+
+	// Update current input field
+	var cmd tea.Cmd
+	m.inputs[m.currentField], cmd = m.inputs[m.currentField].Update(msg)
+	return m, cmd
+}
+
+// View implements the tea.Model interface
+func (m PromptFormModel) View() string {
+	var content strings.Builder
+
+	content.WriteString(m.titleStyle.Render("新建提示词"))
+	content.WriteString("\n\n")
+
+	for i, field := range promptFormFields {
+		style := m.blurredStyle
+		indicator := "  "
+		if i == m.currentField {
+			style = m.focusedStyle
+			indicator = "▶ "
+		}
+
+		label := indicator + field.label
+		if field.required {
+			label += " *"
+		}
+		content.WriteString(style.Render(label + ":"))
+		content.WriteString("\n")
+		content.WriteString(m.inputs[i].View())
+		content.WriteString("\n\n")
+	}
+
+	if m.err != nil {
+		content.WriteString(m.errorStyle.Render(fmt.Sprintf("错误: %v", m.err)))
+		content.WriteString("\n")
+	}
+
+	content.WriteString(m.helpStyle.Render(HelpTextVariableForm))
+
+	return m.containerStyle.Render(content.String())
+}
+
+// handleEnter checks the required fields and completes the form
+func (m PromptFormModel) handleEnter() (PromptFormModel, tea.Cmd) {
+	for i, field := range promptFormFields {
+		if field.required && strings.TrimSpace(m.inputs[i].Value()) == "" {
+			m.err = fmt.Errorf("%s 不能为空", field.label)
+			m.inputs[m.currentField].Blur()
+			m.currentField = i
+			return m, m.inputs[i].Focus()
+		}
+	}
+
+	m.err = nil
+	m.done = true
+	return m, tea.Quit
+}
+
+// moveField moves the focus by delta fields, wrapping around
+func (m PromptFormModel) moveField(delta int) (PromptFormModel, tea.Cmd) {
+	m.inputs[m.currentField].Blur()
+	m.currentField = (m.currentField + delta + len(m.inputs)) % len(m.inputs)
+	return m, m.inputs[m.currentField].Focus()
+}
+
+// IsDone returns true if the form is completed successfully
+func (m PromptFormModel) IsDone() bool {
+	return m.done
+}
+
+// IsCancelled returns true if the form was cancelled
+func (m PromptFormModel) IsCancelled() bool {
+	return m.cancelled
+}
+
+// GetPrompt returns the metadata filled into the form
+func (m PromptFormModel) GetPrompt() model.Prompt {
+	value := func(i int) string {
+		return strings.TrimSpace(m.inputs[i].Value())
+	}
+
+	var tags []string
+	for _, tag := range strings.Split(value(3), ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+
+	return model.Prompt{
+		Name:        value(0),
+		Author:      value(1),
+		Description: value(2),
+		Tags:        tags,
+		Version:     value(4),
+	}
+}
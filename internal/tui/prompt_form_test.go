@@ -0,0 +1,65 @@
+package tui
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/grigri/pv/internal/model"
+)
+
+func TestPromptFormModel_Defaults(t *testing.T) {
+	defaults := model.Prompt{Name: "Review", Author: "alice", Tags: []string{"code", "review"}, Version: "1.0"}
+	form := NewPromptFormModel(defaults)
+
+	got := form.GetPrompt()
+	if !reflect.DeepEqual(got, defaults) {
+		t.Errorf("Expected the form to start with %+v, got %+v", defaults, got)
+	}
+}
+
+func TestPromptFormModel_RequiredFields(t *testing.T) {
+	form := NewPromptFormModel(model.Prompt{Name: "Review"})
+	form.moveField(1)
+
+	result, _ := form.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	form = result.(PromptFormModel)
+	if form.IsDone() {
+		t.Fatal("Expected the form not to complete without an author")
+	}
+	if form.currentField != 1 {
+		t.Errorf("Expected the focus to move to the author field, got %d", form.currentField)
+	}
+	if !strings.Contains(form.View(), "author 不能为空") {
+		t.Error("Expected the view to show the missing author")
+	}
+
+	for _, r := range "bob" {
+		result, _ = form.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		form = result.(PromptFormModel)
+	}
+	result, _ = form.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	form = result.(PromptFormModel)
+	if !form.IsDone() || form.GetPrompt().Author != "bob" {
+		t.Errorf("Expected the form to complete with author bob, got %+v", form.GetPrompt())
+	}
+}
+
+func TestPromptFormModel_Tags(t *testing.T) {
+	form := NewPromptFormModel(model.Prompt{})
+	form.inputs[3].SetValue(" code, review ,, ")
+
+	if got := form.GetPrompt().Tags; !reflect.DeepEqual(got, []string{"code", "review"}) {
+		t.Errorf("Expected tags [code review], got %v", got)
+	}
+}
+
+func TestPromptFormModel_Cancel(t *testing.T) {
+	form := NewPromptFormModel(model.Prompt{})
+
+	result, _ := form.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if form = result.(PromptFormModel); !form.IsCancelled() {
+		t.Error("Expected Esc to cancel the form")
+	}
+}
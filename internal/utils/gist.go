@@ -23,8 +23,8 @@ func ExtractGistID(gistURL string) (string, error) {
 		return "", errors.ErrInvalidGistURL
 	}
 
-	// Check if it's a GitHub Gist URL
-	if parsedURL.Host != "gist.github.com" && parsedURL.Host != "github.com" {
+	// Check if it's a GitHub Gist URL on the configured host (github.com or GitHub Enterprise)
+	if !IsGistHost(parsedURL.Host) && !(parsedURL.Host == githubWebHost && !CurrentGitHubEndpoints().IsEnterprise()) {
 		return "", errors.ErrInvalidGistURL
 	}
 
@@ -0,0 +1,124 @@
+package utils
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+const (
+	// DefaultGistHost is the host serving gists on github.com
+	DefaultGistHost = "gist.github.com"
+
+	// githubWebHost is the github.com web host, also accepted for gist URLs
+	githubWebHost = "github.com"
+)
+
+// GitHubEndpoints holds the GitHub locations pv talks to
+// The zero value targets github.com
+type GitHubEndpoints struct {
+	// APIURL is the REST API base URL, empty for https://api.github.com/
+	APIURL string
+
+	// UploadURL is the upload API base URL, empty for https://uploads.github.com/
+	UploadURL string
+
+	// GistHosts are the hosts serving gist web URLs
+	GistHosts []string
+}
+
+// IsEnterprise reports whether the endpoints target a GitHub Enterprise Server
+func (e GitHubEndpoints) IsEnterprise() bool {
+	return e.APIURL != ""
+}
+
+// GistBaseURL returns the URL gists are served under, e.g. https://gist.github.com
+// GitHub Enterprise Server serves them under /gist on its main host.
+func (e GitHubEndpoints) GistBaseURL() string {
+	if len(e.GistHosts) == 0 {
+		return "https://" + DefaultGistHost
+	}
+	if !e.IsEnterprise() {
+		return "https://" + e.GistHosts[0]
+	}
+
+	scheme := "https"
+	if apiURL, err := url.Parse(e.APIURL); err == nil && apiURL.Scheme == "http" {
+		scheme = "http"
+	}
+	return scheme + "://" + e.GistHosts[0] + "/gist"
+}
+
+// NewGitHubEndpoints derives the endpoints of a GitHub Enterprise Server from its web URL
+// (e.g. https://github.example.com). An empty URL returns the github.com endpoints.
+// Explicit API and upload URLs override the derived ones.
+func NewGitHubEndpoints(enterpriseURL, apiURL, uploadURL string) (GitHubEndpoints, error) {
+	if enterpriseURL == "" {
+		if apiURL != "" || uploadURL != "" {
+			return GitHubEndpoints{}, fmt.Errorf("github_api_url and github_upload_url require github_enterprise_url")
+		}
+		return GitHubEndpoints{GistHosts: []string{DefaultGistHost}}, nil
+	}
+
+	parsedURL, err := url.Parse(enterpriseURL)
+	if err != nil || parsedURL.Host == "" || (parsedURL.Scheme != "https" && parsedURL.Scheme != "http") {
+		return GitHubEndpoints{}, fmt.Errorf("invalid GitHub Enterprise URL %q", enterpriseURL)
+	}
+
+	base := parsedURL.Scheme + "://" + parsedURL.Host
+	endpoints := GitHubEndpoints{
+		APIURL:    base + "/api/v3/",
+		UploadURL: base + "/api/uploads/",
+		// Gists live under /gist on the main host, or on a gist. subdomain when
+		// subdomain isolation is enabled
+		GistHosts: []string{parsedURL.Host, "gist." + parsedURL.Host},
+	}
+
+	if apiURL != "" {
+		endpoints.APIURL = apiURL
+	}
+	if uploadURL != "" {
+		endpoints.UploadURL = uploadURL
+	}
+
+	return endpoints, nil
+}
+
+var (
+	endpointsMu      sync.RWMutex
+	currentEndpoints = GitHubEndpoints{GistHosts: []string{DefaultGistHost}}
+)
+
+// SetGitHubEndpoints configures the endpoints used for gist URL detection and parsing
+func SetGitHubEndpoints(endpoints GitHubEndpoints) {
+	endpointsMu.Lock()
+	defer endpointsMu.Unlock()
+	currentEndpoints = endpoints
+}
+
+// CurrentGitHubEndpoints returns the configured endpoints
+func CurrentGitHubEndpoints() GitHubEndpoints {
+	endpointsMu.RLock()
+	defer endpointsMu.RUnlock()
+	return currentEndpoints
+}
+
+// IsGistHost reports whether host serves gist web URLs for the configured endpoints
+func IsGistHost(host string) bool {
+	for _, gistHost := range CurrentGitHubEndpoints().GistHosts {
+		if host == gistHost {
+			return true
+		}
+	}
+	return false
+}
+
+// IsGistURLHost reports whether rawURL points at a configured gist host
+func IsGistURLHost(rawURL string) bool {
+	parsedURL, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return false
+	}
+	return IsGistHost(parsedURL.Host)
+}
@@ -0,0 +1,208 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+const (
+	// DefaultMaxRetries bounds how often a failed request is repeated
+	DefaultMaxRetries = 3
+
+	// defaultRetryBaseDelay is the backoff before the first retry; it doubles on each retry
+	defaultRetryBaseDelay = 500 * time.Millisecond
+
+	// defaultRetryMaxDelay caps the exponential backoff
+	defaultRetryMaxDelay = 8 * time.Second
+
+	// maxRetryAfter is the longest Retry-After the transport waits for;
+	// longer waits are left to the caller
+	maxRetryAfter = time.Minute
+)
+
+// retryTransport repeats idempotent requests that failed transiently:
+// connection resets, timeouts, 5xx responses and rate limits carrying Retry-After.
+//
+// POST requests are never repeated: creating a gist is not idempotent, and a
+// request that failed on the way back may still have created it. Callers that
+// want to retry a creation have to check for the created resource first.
+type retryTransport struct {
+	base       http.RoundTripper
+	maxRetries int
+	baseDelay  time.Duration
+	maxDelay   time.Duration
+
+	// jitter returns a random number in [0, 1); replaced in tests
+	jitter func() float64
+	// sleep waits between attempts, returning early when ctx is done; replaced in tests
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// NewRetryTransport wraps base (http.DefaultTransport when nil) with retries
+// using exponential backoff with jitter
+func NewRetryTransport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &retryTransport{
+		base:       base,
+		maxRetries: DefaultMaxRetries,
+		baseDelay:  defaultRetryBaseDelay,
+		maxDelay:   defaultRetryMaxDelay,
+		jitter:     rand.Float64,
+		sleep:      SleepContext,
+	}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !isIdempotent(req.Method) || (req.Body != nil && req.Body != http.NoBody && req.GetBody == nil) {
+		return t.base.RoundTrip(req)
+	}
+
+	ctx := req.Context()
+	for attempt := 0; ; attempt++ {
+		attemptReq := req
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			attemptReq = req.Clone(ctx)
+			attemptReq.Body = body
+		}
+
+		resp, err := t.base.RoundTrip(attemptReq)
+		if attempt >= t.maxRetries || ctx.Err() != nil {
+			return resp, err
+		}
+
+		wait, retry := t.retryDelay(resp, err, attempt)
+		if !retry {
+			return resp, err
+		}
+
+		if resp != nil {
+			// Drain the body so the connection can be reused
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+		}
+
+		if err := t.sleep(ctx, wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// retryDelay reports whether an attempt should be repeated and how long to wait first
+func (t *retryTransport) retryDelay(resp *http.Response, err error, attempt int) (time.Duration, bool) {
+	if err != nil {
+		return t.backoff(attempt), IsTransientNetworkError(err)
+	}
+
+	switch {
+	case IsRetryableStatus(resp.StatusCode):
+		if wait, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			return wait, wait <= maxRetryAfter
+		}
+		return t.backoff(attempt), true
+	case resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests:
+		// Secondary rate limits say how long to wait; a 403 without Retry-After is
+		// a permission error or an exhausted quota and retrying soon won't help
+		wait, ok := parseRetryAfter(resp.Header.Get("Retry-After"))
+		return wait, ok && wait <= maxRetryAfter
+	}
+	return 0, false
+}
+
+// backoff returns the exponential backoff before retry number attempt+1
+func (t *retryTransport) backoff(attempt int) time.Duration {
+	return exponentialBackoff(attempt, t.baseDelay, t.maxDelay, t.jitter())
+}
+
+// RetryBackoff returns the default exponential backoff with jitter before retry number attempt+1
+func RetryBackoff(attempt int) time.Duration {
+	return exponentialBackoff(attempt, defaultRetryBaseDelay, defaultRetryMaxDelay, rand.Float64())
+}
+
+// exponentialBackoff doubles base for every attempt up to maxDelay
+// Half of the delay is random so concurrent clients don't retry in lockstep.
+func exponentialBackoff(attempt int, base, maxDelay time.Duration, jitter float64) time.Duration {
+	delay := maxDelay
+	if attempt < 16 {
+		delay = min(base<<attempt, maxDelay)
+	}
+	return delay/2 + time.Duration(jitter*float64(delay/2))
+}
+
+// SleepContext waits for d, returning ctx's error if it is done first
+func SleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// IsRetryableStatus reports whether a response status is a transient server failure
+func IsRetryableStatus(code int) bool {
+	switch code {
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// IsTransientNetworkError reports whether a request failed on the network in a way
+// that may succeed when repeated: connection resets, refused connections, timeouts
+// and connections closed mid-response. Cancellations are not transient.
+func IsTransientNetworkError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// isIdempotent reports whether repeating a request of this method is harmless
+// PATCH is included because gist edits carry the complete new file contents.
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete, http.MethodPatch:
+		return true
+	}
+	return false
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as an HTTP date
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(time.Until(at), 0), true
+	}
+	return 0, false
+}
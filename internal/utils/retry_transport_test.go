@@ -0,0 +1,189 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// roundTripFunc adapts a function to http.RoundTripper
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func response(status int, header http.Header) *http.Response {
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{StatusCode: status, Header: header, Body: io.NopCloser(strings.NewReader(""))}
+}
+
+// newTestTransport returns a retryTransport over base that records its waits instead of sleeping
+func newTestTransport(base http.RoundTripper, waits *[]time.Duration) *retryTransport {
+	transport := NewRetryTransport(base).(*retryTransport)
+	transport.jitter = func() float64 { return 0 }
+	transport.sleep = func(ctx context.Context, d time.Duration) error {
+		*waits = append(*waits, d)
+		return ctx.Err()
+	}
+	return transport
+}
+
+func TestRetryTransport(t *testing.T) {
+	tests := []struct {
+		name      string
+		method    string
+		responses []func() (*http.Response, error)
+		wantCalls int
+		wantCode  int
+		wantWaits []time.Duration
+	}{
+		{
+			name:   "server errors back off exponentially",
+			method: http.MethodGet,
+			responses: []func() (*http.Response, error){
+				func() (*http.Response, error) { return response(http.StatusBadGateway, nil), nil },
+				func() (*http.Response, error) { return response(http.StatusServiceUnavailable, nil), nil },
+				func() (*http.Response, error) { return response(http.StatusOK, nil), nil },
+			},
+			wantCalls: 3,
+			wantCode:  http.StatusOK,
+			wantWaits: []time.Duration{250 * time.Millisecond, 500 * time.Millisecond},
+		},
+		{
+			name:   "connection reset is retried",
+			method: http.MethodPatch,
+			responses: []func() (*http.Response, error){
+				func() (*http.Response, error) { return nil, fmt.Errorf("read: %w", syscall.ECONNRESET) },
+				func() (*http.Response, error) { return response(http.StatusOK, nil), nil },
+			},
+			wantCalls: 2,
+			wantCode:  http.StatusOK,
+			wantWaits: []time.Duration{250 * time.Millisecond},
+		},
+		{
+			name:   "secondary rate limit waits for Retry-After",
+			method: http.MethodGet,
+			responses: []func() (*http.Response, error){
+				func() (*http.Response, error) {
+					return response(http.StatusForbidden, http.Header{"Retry-After": []string{"7"}}), nil
+				},
+				func() (*http.Response, error) { return response(http.StatusOK, nil), nil },
+			},
+			wantCalls: 2,
+			wantCode:  http.StatusOK,
+			wantWaits: []time.Duration{7 * time.Second},
+		},
+		{
+			name:   "forbidden without Retry-After is final",
+			method: http.MethodGet,
+			responses: []func() (*http.Response, error){
+				func() (*http.Response, error) { return response(http.StatusForbidden, nil), nil },
+			},
+			wantCalls: 1,
+			wantCode:  http.StatusForbidden,
+		},
+		{
+			name:   "gist creation is never retried",
+			method: http.MethodPost,
+			responses: []func() (*http.Response, error){
+				func() (*http.Response, error) { return response(http.StatusBadGateway, nil), nil },
+			},
+			wantCalls: 1,
+			wantCode:  http.StatusBadGateway,
+		},
+		{
+			name:   "gives up after the retry limit",
+			method: http.MethodGet,
+			responses: []func() (*http.Response, error){
+				func() (*http.Response, error) { return response(http.StatusInternalServerError, nil), nil },
+			},
+			wantCalls: DefaultMaxRetries + 1,
+			wantCode:  http.StatusInternalServerError,
+			wantWaits: []time.Duration{250 * time.Millisecond, 500 * time.Millisecond, time.Second},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var bodies []string
+			calls := 0
+			base := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				if req.Body != nil {
+					body, _ := io.ReadAll(req.Body)
+					bodies = append(bodies, string(body))
+				}
+				next := tt.responses[min(calls, len(tt.responses)-1)]
+				calls++
+				return next()
+			})
+
+			var waits []time.Duration
+			transport := newTestTransport(base, &waits)
+
+			req, err := http.NewRequest(tt.method, "https://api.github.com/gists/abc", strings.NewReader(`{"files":{}}`))
+			if err != nil {
+				t.Fatalf("NewRequest failed: %v", err)
+			}
+			resp, err := transport.RoundTrip(req)
+			if err != nil {
+				t.Fatalf("RoundTrip failed: %v", err)
+			}
+
+			if calls != tt.wantCalls {
+				t.Errorf("Expected %d attempts, got %d", tt.wantCalls, calls)
+			}
+			if resp.StatusCode != tt.wantCode {
+				t.Errorf("Expected status %d, got %d", tt.wantCode, resp.StatusCode)
+			}
+			if fmt.Sprint(waits) != fmt.Sprint(tt.wantWaits) {
+				t.Errorf("Expected waits %v, got %v", tt.wantWaits, waits)
+			}
+			for _, body := range bodies {
+				if body != `{"files":{}}` {
+					t.Errorf("Expected every attempt to send the full body, got %q", body)
+				}
+			}
+		})
+	}
+}
+
+func TestRetryTransport_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	base := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		calls++
+		cancel()
+		return response(http.StatusBadGateway, nil), nil
+	})
+
+	var waits []time.Duration
+	transport := newTestTransport(base, &waits)
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "https://api.github.com/gists/abc", nil)
+	if _, err := transport.RoundTrip(req); err != nil {
+		t.Fatalf("RoundTrip failed: %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("Expected no retry once the request is cancelled, got %d attempts", calls)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	if d, ok := parseRetryAfter("30"); !ok || d != 30*time.Second {
+		t.Errorf("Expected 30s, got %v, %v", d, ok)
+	}
+	if d, ok := parseRetryAfter(time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat)); !ok || d != 0 {
+		t.Errorf("Expected a past date to mean no wait, got %v, %v", d, ok)
+	}
+	if _, ok := parseRetryAfter("soon"); ok {
+		t.Error("Expected an invalid Retry-After to be rejected")
+	}
+}
@@ -59,10 +59,15 @@ func (p *FrontMatterParser) Parse(content []byte) (*ParseResult, error) {
 
 // parseContent 执行实际的解析逻辑
 func (p *FrontMatterParser) parseContent(content []byte) (*ParseResult, error) {
-	// 将内容转换为字符串进行处理
-	contentStr := string(content)
+	// 将内容转换为字符串进行处理，去掉编辑器可能写入的 UTF-8 BOM
+	contentStr := strings.TrimPrefix(string(content), "\ufeff")
 	lines := strings.Split(contentStr, "\n")
 
+	// 跳过开头的空行，使其后以 --- 开头的 front matter 仍能识别
+	for len(lines) > 1 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+
 	if len(lines) == 0 {
 		return nil, errors.NewAppError(
 			errors.ErrValidation,
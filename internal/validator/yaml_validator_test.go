@@ -923,6 +923,13 @@ author: "Author"`,
 			expectedContent: "Content",
 			expectError:     false,
 		},
+		{
+			name:            "Markdown 文件开头的 BOM 和空行",
+			input:           []byte("\ufeff\n\n---\nname: \"Notes\"\nauthor: \"Author\"\n---\n# Notes\n\n---\n\nMore"),
+			expectedYAML:    "name: \"Notes\"\nauthor: \"Author\"",
+			expectedContent: "# Notes\n\n---\n\nMore",
+			expectError:     false,
+		},
 	}
 
 	for _, tc := range testCases {
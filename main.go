@@ -1,7 +1,11 @@
 package main
 
 import (
+	"context"
 	"log"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/grigri/pv/internal/di"
 )
@@ -11,7 +15,11 @@ func main() {
 	if err != nil {
 		log.Fatal(err)
 	}
-	if err := root.Execute(); err != nil {
+	// Ctrl-C 或 SIGTERM 会取消命令 context，正在进行的远程调用随之中止
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := root.ExecuteContext(ctx); err != nil {
 		log.Fatal(err)
 	}
 }